@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
 	"log/slog"
 	"net/http"
@@ -10,13 +14,14 @@ import (
 
 	"github.com/joho/godotenv"
 	_ "github.com/mattn/go-sqlite3"
-	"github.com/pressly/goose/v3"
 
 	"github.com/dukerupert/skalkaho/internal/config"
+	"github.com/dukerupert/skalkaho/internal/database"
 	"github.com/dukerupert/skalkaho/internal/handler/keyboard"
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
 	"github.com/dukerupert/skalkaho/internal/router"
+	"github.com/dukerupert/skalkaho/internal/service/cleanup"
 	keyboardtemplates "github.com/dukerupert/skalkaho/internal/templates/keyboard"
 )
 
@@ -24,6 +29,10 @@ import (
 var migrations embed.FS
 
 func main() {
+	migrateStatus := flag.Bool("migrate-status", false, "print the schema version and migration status, then exit")
+	migrateDownTo := flag.Int64("migrate-down-to", -1, "roll migrations back to the given version, then exit")
+	flag.Parse()
+
 	// Load .env file if present (ignore error if not found)
 	_ = godotenv.Load()
 
@@ -38,6 +47,19 @@ func main() {
 
 	logger.Info("Skalkaho starting", "environment", cfg.Environment)
 
+	// Create the data directory layout and find the database
+	legacyDB, err := cfg.PrepareDataDir()
+	if err != nil {
+		log.Fatalf("Failed to prepare DATA_DIR: %v", err)
+	}
+	switch {
+	case legacyDB == nil:
+	case legacyDB.MoveErr != nil:
+		logger.Warn("Using database at its old location; move it into the data directory's db/ folder", "path", legacyDB.Path, "data_dir", cfg.DataDir, "error", legacyDB.MoveErr)
+	default:
+		logger.Info("Moved database into the data directory", "from", legacyDB.Path, "to", cfg.DatabasePath)
+	}
+
 	// Open database
 	db, err := sql.Open("sqlite3", cfg.DatabasePath+"?_foreign_keys=on")
 	if err != nil {
@@ -45,8 +67,42 @@ func main() {
 	}
 	defer db.Close()
 
+	migrationsFS, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Operator commands exit without starting the server
+	switch {
+	case *migrateStatus:
+		if err := database.WriteMigrationStatus(context.Background(), os.Stdout, db, migrationsFS); err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		return
+	case *migrateDownTo >= 0:
+		if err := database.MigrateDownTo(context.Background(), db, migrationsFS, *migrateDownTo); err != nil {
+			log.Fatalf("Failed to roll back migrations: %v", err)
+		}
+		logger.Info("Rolled back migrations", "version", *migrateDownTo)
+		return
+	}
+
 	// Run migrations
-	if err := runMigrations(db); err != nil {
+	if err := database.Migrate(context.Background(), db, migrationsFS); err != nil {
+		var migrationErr *database.MigrationError
+		if errors.As(err, &migrationErr) {
+			msg := "Migration failed; the failing migration was rolled back"
+			if migrationErr.NoTransaction {
+				msg = "Migration failed outside a transaction; the database may be partly migrated with foreign keys off, restore it from a backup"
+			}
+			logger.Error(msg,
+				"file", migrationErr.File,
+				"current_version", migrationErr.Current,
+				"target_version", migrationErr.Target,
+				"error", migrationErr.Err,
+			)
+			os.Exit(1)
+		}
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
@@ -59,18 +115,30 @@ func main() {
 		log.Fatalf("Failed to initialize templates: %v", err)
 	}
 
+	// Render pages with the saved theme from the first request
+	if settings, err := queries.GetSettings(context.Background()); err == nil {
+		renderer.SetTheme(settings.Theme)
+	}
+
 	// Initialize handler
-	handler := keyboard.NewHandler(queries, renderer, logger, cfg)
+	handler := keyboard.NewHandler(db, queries, renderer, logger, cfg)
+
+	// Start background cleanup
+	if cfg.CleanupInterval > 0 {
+		cleanup.NewRunner(db, queries, logger).Start(context.Background(), cfg.CleanupInterval)
+	}
 
 	// Setup router
 	mux := http.NewServeMux()
 	router.Register(mux, handler)
 
-	// Apply middleware
+	// Apply middleware. Recover runs innermost so panics are logged with the
+	// request ID and reported like any other server error.
 	httpHandler := middleware.Chain(mux,
-		middleware.Recover,
 		middleware.RequestID,
 		middleware.Logger(logger),
+		middleware.ReportErrors(handler.ErrorLog(), cfg.SupportEmail),
+		middleware.Recover,
 	)
 
 	// Start server
@@ -79,17 +147,3 @@ func main() {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
-
-func runMigrations(db *sql.DB) error {
-	goose.SetBaseFS(migrations)
-
-	if err := goose.SetDialect("sqlite3"); err != nil {
-		return err
-	}
-
-	if err := goose.Up(db, "migrations"); err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Add optional follow-up date to jobs for the calendar
+ALTER TABLE jobs ADD COLUMN follow_up_at TEXT;
+
+-- Add indexes for date-range lookups
+CREATE INDEX idx_jobs_expires_at ON jobs(expires_at);
+CREATE INDEX idx_jobs_follow_up_at ON jobs(follow_up_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_follow_up_at;
+DROP INDEX IF EXISTS idx_jobs_expires_at;
+ALTER TABLE jobs DROP COLUMN follow_up_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Add a minimum job total and the flat mobilization fee offered when a quote falls below it
+ALTER TABLE settings ADD COLUMN minimum_job_total REAL NOT NULL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN mobilization_fee REAL NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN mobilization_fee;
+ALTER TABLE settings DROP COLUMN minimum_job_total;
@@ -0,0 +1,87 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Add subcontract and fee as valid line item and item template types.
+-- price_import_matches references item_templates, so the rebuild runs with
+-- foreign keys off in its own transaction on a single connection.
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE line_items_new (
+    id TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('material', 'labor', 'equipment', 'subcontract', 'fee')),
+    name TEXT NOT NULL,
+    description TEXT,
+    quantity REAL NOT NULL,
+    unit TEXT NOT NULL,
+    unit_price REAL NOT NULL,
+    surcharge_percent REAL,
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO line_items_new SELECT * FROM line_items;
+DROP TABLE line_items;
+ALTER TABLE line_items_new RENAME TO line_items;
+CREATE INDEX idx_line_items_category ON line_items(category_id);
+
+CREATE TABLE item_templates_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL CHECK (type IN ('material', 'labor', 'equipment', 'subcontract', 'fee')),
+    category TEXT NOT NULL,
+    name TEXT NOT NULL,
+    default_unit TEXT NOT NULL,
+    default_price REAL NOT NULL DEFAULT 0
+);
+
+INSERT INTO item_templates_new SELECT * FROM item_templates;
+DROP TABLE item_templates;
+ALTER TABLE item_templates_new RENAME TO item_templates;
+CREATE INDEX idx_item_templates_name ON item_templates(name);
+CREATE INDEX idx_item_templates_category ON item_templates(category);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE line_items_old (
+    id TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('material', 'labor', 'equipment')),
+    name TEXT NOT NULL,
+    description TEXT,
+    quantity REAL NOT NULL,
+    unit TEXT NOT NULL,
+    unit_price REAL NOT NULL,
+    surcharge_percent REAL,
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO line_items_old SELECT * FROM line_items WHERE type IN ('material', 'labor', 'equipment');
+DROP TABLE line_items;
+ALTER TABLE line_items_old RENAME TO line_items;
+CREATE INDEX idx_line_items_category ON line_items(category_id);
+
+CREATE TABLE item_templates_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL CHECK (type IN ('material', 'labor', 'equipment')),
+    category TEXT NOT NULL,
+    name TEXT NOT NULL,
+    default_unit TEXT NOT NULL,
+    default_price REAL NOT NULL DEFAULT 0
+);
+
+INSERT INTO item_templates_old SELECT * FROM item_templates WHERE type IN ('material', 'labor', 'equipment');
+DROP TABLE item_templates;
+ALTER TABLE item_templates_old RENAME TO item_templates;
+CREATE INDEX idx_item_templates_name ON item_templates(name);
+CREATE INDEX idx_item_templates_category ON item_templates(category);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
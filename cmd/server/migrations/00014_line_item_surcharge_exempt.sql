@@ -0,0 +1,6 @@
+-- +goose Up
+-- Allow pass-through costs such as permit fees to skip markup entirely
+ALTER TABLE line_items ADD COLUMN exempt_from_surcharge BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE line_items DROP COLUMN exempt_from_surcharge;
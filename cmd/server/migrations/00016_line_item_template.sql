@@ -0,0 +1,50 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Remember which item template a line item was created from so imported
+-- prices can be compared against what jobs actually used.
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE line_items ADD COLUMN template_id INTEGER REFERENCES item_templates(id) ON DELETE SET NULL;
+
+-- Best-effort backfill for existing items that share a template's name and type
+UPDATE line_items SET template_id = (
+    SELECT MIN(t.id) FROM item_templates t
+    WHERE t.name = line_items.name AND t.type = line_items.type
+);
+
+CREATE INDEX idx_line_items_template ON line_items(template_id);
+
+COMMIT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- SQLite cannot drop a column used in a foreign key, so rebuild the table.
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE line_items_old (
+    id TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('material', 'labor', 'equipment', 'subcontract', 'fee')),
+    name TEXT NOT NULL,
+    description TEXT,
+    quantity REAL NOT NULL,
+    unit TEXT NOT NULL,
+    unit_price REAL NOT NULL,
+    surcharge_percent REAL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    exempt_from_surcharge BOOLEAN NOT NULL DEFAULT 0
+);
+
+INSERT INTO line_items_old
+SELECT id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge
+FROM line_items;
+DROP TABLE line_items;
+ALTER TABLE line_items_old RENAME TO line_items;
+CREATE INDEX idx_line_items_category ON line_items(category_id);
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
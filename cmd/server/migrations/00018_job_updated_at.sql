@@ -0,0 +1,77 @@
+-- +goose Up
+-- Track when a job or anything in it last changed. Triggers keep the value
+-- current so handlers don't each have to remember to touch the job.
+ALTER TABLE jobs ADD COLUMN updated_at TEXT NOT NULL DEFAULT '';
+
+UPDATE jobs SET updated_at = created_at;
+
+-- +goose StatementBegin
+CREATE TRIGGER jobs_touch_after_insert AFTER INSERT ON jobs
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER jobs_touch_after_update AFTER UPDATE ON jobs
+WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_touch_job_after_insert AFTER INSERT ON categories
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_touch_job_after_update AFTER UPDATE ON categories
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER categories_touch_job_after_delete AFTER DELETE ON categories
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = OLD.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER line_items_touch_job_after_insert AFTER INSERT ON line_items
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE id = (SELECT job_id FROM categories WHERE id = NEW.category_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER line_items_touch_job_after_update AFTER UPDATE ON line_items
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE id = (SELECT job_id FROM categories WHERE id = NEW.category_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER line_items_touch_job_after_delete AFTER DELETE ON line_items
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE id = (SELECT job_id FROM categories WHERE id = OLD.category_id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS line_items_touch_job_after_delete;
+DROP TRIGGER IF EXISTS line_items_touch_job_after_update;
+DROP TRIGGER IF EXISTS line_items_touch_job_after_insert;
+DROP TRIGGER IF EXISTS categories_touch_job_after_delete;
+DROP TRIGGER IF EXISTS categories_touch_job_after_update;
+DROP TRIGGER IF EXISTS categories_touch_job_after_insert;
+DROP TRIGGER IF EXISTS jobs_touch_after_update;
+DROP TRIGGER IF EXISTS jobs_touch_after_insert;
+ALTER TABLE jobs DROP COLUMN updated_at;
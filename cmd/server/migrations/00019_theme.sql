@@ -0,0 +1,7 @@
+-- +goose Up
+-- Color theme for the keyboard UI; system follows the browser's preference
+ALTER TABLE settings ADD COLUMN theme TEXT NOT NULL DEFAULT 'system'
+    CHECK (theme IN ('light', 'dark', 'system'));
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN theme;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Default number of rows per page on paginated lists
+ALTER TABLE settings ADD COLUMN page_size INTEGER NOT NULL DEFAULT 20
+    CHECK (page_size BETWEEN 10 AND 200);
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN page_size;
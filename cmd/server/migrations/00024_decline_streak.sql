@@ -0,0 +1,12 @@
+-- +goose Up
+-- Warn when starting a quote for a client whose recent quotes were all
+-- rejected; decline_streak is how many in a row trigger the warning
+ALTER TABLE settings ADD COLUMN decline_warning BOOLEAN NOT NULL DEFAULT 1;
+ALTER TABLE settings ADD COLUMN decline_streak INTEGER NOT NULL DEFAULT 3;
+
+CREATE INDEX idx_jobs_client_status_created ON jobs(client_id, status, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_client_status_created;
+ALTER TABLE settings DROP COLUMN decline_streak;
+ALTER TABLE settings DROP COLUMN decline_warning;
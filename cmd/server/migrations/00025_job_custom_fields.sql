@@ -0,0 +1,57 @@
+-- +goose Up
+-- Extra job details such as permit or PO numbers, defined in settings.
+-- Deleting a field archives it so existing values are kept.
+CREATE TABLE job_custom_fields (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    label TEXT NOT NULL,
+    field_type TEXT NOT NULL CHECK (field_type IN ('text', 'number', 'date')),
+    show_on_quote BOOLEAN NOT NULL DEFAULT 0,
+    archived_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE job_custom_field_values (
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    field_id INTEGER NOT NULL REFERENCES job_custom_fields(id),
+    value TEXT NOT NULL,
+    PRIMARY KEY (job_id, field_id)
+);
+
+-- Field values are part of the job, so changing one bumps jobs.updated_at
+-- like category and line item changes do
+-- +goose StatementBegin
+CREATE TRIGGER job_custom_field_values_touch_job_after_insert AFTER INSERT ON job_custom_field_values
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER job_custom_field_values_touch_job_after_update AFTER UPDATE ON job_custom_field_values
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER job_custom_field_values_touch_job_after_delete AFTER DELETE ON job_custom_field_values
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = OLD.job_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER job_custom_fields_touch_jobs_after_update AFTER UPDATE ON job_custom_fields
+BEGIN
+    UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE id IN (SELECT job_id FROM job_custom_field_values WHERE field_id = NEW.id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS job_custom_fields_touch_jobs_after_update;
+DROP TRIGGER IF EXISTS job_custom_field_values_touch_job_after_delete;
+DROP TRIGGER IF EXISTS job_custom_field_values_touch_job_after_update;
+DROP TRIGGER IF EXISTS job_custom_field_values_touch_job_after_insert;
+DROP TABLE IF EXISTS job_custom_field_values;
+DROP TABLE IF EXISTS job_custom_fields;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Pre-send check rules the user turned off for a job, so intentional cases
+-- such as a deliberately empty category stop being reported
+CREATE TABLE job_preflight_dismissals (
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (job_id, rule)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_preflight_dismissals;
@@ -0,0 +1,63 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Remind about price imports left in review, and optionally mark them stale
+-- after a longer window so they stop looking like fresh prices. Adding the
+-- 'stale' status means rebuilding price_imports, which other tables
+-- reference, so the rebuild runs with foreign keys off.
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+CREATE TABLE price_imports_new (
+    id TEXT PRIMARY KEY,
+    filename TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'processing', 'ready', 'applied', 'failed', 'stale')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    matched_rows INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    applied_at TEXT
+);
+
+INSERT INTO price_imports_new SELECT * FROM price_imports;
+DROP TABLE price_imports;
+ALTER TABLE price_imports_new RENAME TO price_imports;
+
+ALTER TABLE settings ADD COLUMN import_reminder_days INTEGER NOT NULL DEFAULT 14;
+ALTER TABLE settings ADD COLUMN import_stale_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE cleanup_runs ADD COLUMN expired_imports INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+PRAGMA foreign_keys = OFF;
+BEGIN;
+
+ALTER TABLE cleanup_runs DROP COLUMN expired_imports;
+ALTER TABLE settings DROP COLUMN import_stale_days;
+ALTER TABLE settings DROP COLUMN import_reminder_days;
+
+CREATE TABLE price_imports_old (
+    id TEXT PRIMARY KEY,
+    filename TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'processing', 'ready', 'applied', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    matched_rows INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    applied_at TEXT
+);
+
+INSERT INTO price_imports_old
+SELECT id, filename, CASE status WHEN 'stale' THEN 'ready' ELSE status END,
+       total_rows, matched_rows, error_message, created_at, applied_at
+FROM price_imports;
+DROP TABLE price_imports;
+ALTER TABLE price_imports_old RENAME TO price_imports;
+
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Item type and unit the inline form starts with in a category. Unset values
+-- inherit from the parent category, then fall back to material/ea.
+ALTER TABLE categories ADD COLUMN default_item_type TEXT
+    CHECK (default_item_type IN ('material', 'labor', 'equipment', 'subcontract', 'fee'));
+ALTER TABLE categories ADD COLUMN default_unit TEXT;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN default_unit;
+ALTER TABLE categories DROP COLUMN default_item_type;
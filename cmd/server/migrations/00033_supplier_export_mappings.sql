@@ -0,0 +1,25 @@
+-- +goose Up
+-- Supplier part numbers, written to order exports that ask for them.
+ALTER TABLE item_templates ADD COLUMN sku TEXT;
+
+-- Each supplier's order template: which order list fields go in which
+-- column, under what header. A mapping with a sku column needs a SKU for
+-- every item it orders.
+CREATE TABLE supplier_export_mappings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE supplier_export_columns (
+    mapping_id INTEGER NOT NULL REFERENCES supplier_export_mappings(id) ON DELETE CASCADE,
+    field TEXT NOT NULL CHECK (field IN ('sku', 'description', 'quantity', 'unit')),
+    position INTEGER NOT NULL,
+    header TEXT NOT NULL,
+    PRIMARY KEY (mapping_id, field)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS supplier_export_columns;
+DROP TABLE IF EXISTS supplier_export_mappings;
+ALTER TABLE item_templates DROP COLUMN sku;
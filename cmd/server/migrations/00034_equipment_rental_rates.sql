@@ -0,0 +1,11 @@
+-- +goose Up
+-- Tiered rental prices for equipment templates. NULL means the period isn't
+-- offered; a template with none set is priced by its default price alone.
+ALTER TABLE item_templates ADD COLUMN day_rate REAL;
+ALTER TABLE item_templates ADD COLUMN week_rate REAL;
+ALTER TABLE item_templates ADD COLUMN month_rate REAL;
+
+-- +goose Down
+ALTER TABLE item_templates DROP COLUMN month_rate;
+ALTER TABLE item_templates DROP COLUMN week_rate;
+ALTER TABLE item_templates DROP COLUMN day_rate;
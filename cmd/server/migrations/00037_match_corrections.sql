@@ -0,0 +1,13 @@
+-- +goose Up
+-- Supplier item names matched to a template by hand during import review.
+-- Later imports match these names directly instead of trusting the AI's
+-- guess, and recent ones are shown to the AI as examples.
+CREATE TABLE match_corrections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    template_id INTEGER NOT NULL REFERENCES item_templates(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- +goose Down
+DROP TABLE match_corrections;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Credits used to be saved as exempt from markup whenever the
+-- surcharge_credits setting was off. The setting is now applied when totals
+-- are calculated, so clear the copied flag; with the setting off those
+-- credits still get no markup.
+UPDATE line_items SET exempt_from_surcharge = 0
+WHERE is_credit = 1
+  AND exempt_from_surcharge = 1
+  AND (SELECT surcharge_credits FROM settings WHERE id = 'default') = 0;
+
+-- +goose Down
+UPDATE line_items SET exempt_from_surcharge = 1
+WHERE is_credit = 1
+  AND (SELECT surcharge_credits FROM settings WHERE id = 'default') = 0;
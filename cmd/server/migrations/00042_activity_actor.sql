@@ -0,0 +1,7 @@
+-- +goose Up
+-- Who made each change in the activity log. Entries from before it was
+-- recorded are attributed to the system.
+ALTER TABLE job_activity ADD COLUMN actor TEXT NOT NULL DEFAULT 'system';
+
+-- +goose Down
+ALTER TABLE job_activity DROP COLUMN actor;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The line item as the edit left it, as JSON, so an undo can tell whether it
+-- has been changed since. NULL when the edit deleted the item.
+ALTER TABLE total_change_alerts ADD COLUMN result TEXT;
+
+-- +goose Down
+ALTER TABLE total_change_alerts DROP COLUMN result;
@@ -3,27 +3,44 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds application configuration.
 type Config struct {
 	Addr                 string
-	DatabasePath         string
+	DataDir              string // Root of the app's files; see PrepareDataDir for the layout
+	DatabasePath         string // Overrides db/quotes.db under DataDir when set
 	Environment          string
 	AnthropicAPIKey      string
 	AutoApproveThreshold float64
-	PriceImportToken     string // Secret token required to access price import feature
+	PriceImportToken     string        // Secret token required to access price import feature
+	CleanupInterval      time.Duration // How often the background cleanup runs; 0 disables it
+	APIToken             string        // Secret token required by the read-only JSON API; empty disables it
+	PrintBatchLimit      int           // Most quotes one combined print may contain
+	SupportEmail         string        // Address error reports link to; empty hides the link
+	AdminToken           string        // Secret token required by /admin pages; empty turns them off
+	Timezone             string        // IANA zone the monthly import budget resets in; empty uses the server's
+	DecimalSeparator     string        // "," to type amounts like 1.200,50; otherwise "." as in 1,200.50
 }
 
 // Load reads configuration from environment variables.
 func Load() *Config {
 	return &Config{
 		Addr:                 getEnv("ADDR", ":8080"),
-		DatabasePath:         getEnv("DATABASE_PATH", "quotes.db"),
+		DataDir:              getEnv("DATA_DIR", "data"),
+		DatabasePath:         getEnv("DATABASE_PATH", ""),
 		Environment:          getEnv("ENVIRONMENT", "development"),
 		AnthropicAPIKey:      getEnv("ANTHROPIC_API_KEY", ""),
 		AutoApproveThreshold: getEnvFloat("AUTO_APPROVE_THRESHOLD", 0.9),
 		PriceImportToken:     getEnv("PRICE_IMPORT_TOKEN", ""),
+		CleanupInterval:      getEnvDuration("CLEANUP_INTERVAL", 24*time.Hour),
+		APIToken:             getEnv("API_TOKEN", ""),
+		PrintBatchLimit:      getEnvInt("PRINT_BATCH_LIMIT", 25),
+		SupportEmail:         getEnv("SUPPORT_EMAIL", ""),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		Timezone:             getEnv("TIMEZONE", ""),
+		DecimalSeparator:     getEnv("DECIMAL_SEPARATOR", "."),
 	}
 }
 
@@ -42,3 +59,24 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if value == "0" {
+			return 0
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Subdirectories of the data directory.
+const (
+	dbDir          = "db"
+	uploadsDir     = "uploads"
+	attachmentsDir = "attachments"
+	backupsDir     = "backups"
+)
+
+// databaseFile is the SQLite database's name inside the db directory.
+const databaseFile = "quotes.db"
+
+// dataDirPerm keeps app data private to the app's user and group.
+const dataDirPerm = 0o750
+
+// DBDir is where the SQLite database lives.
+func (c *Config) DBDir() string { return filepath.Join(c.DataDir, dbDir) }
+
+// UploadsDir is where uploaded files are kept.
+func (c *Config) UploadsDir() string { return filepath.Join(c.DataDir, uploadsDir) }
+
+// AttachmentsDir is where files attached to quotes are kept.
+func (c *Config) AttachmentsDir() string { return filepath.Join(c.DataDir, attachmentsDir) }
+
+// BackupsDir is where database backups are written.
+func (c *Config) BackupsDir() string { return filepath.Join(c.DataDir, backupsDir) }
+
+// LegacyDatabase is a database found at an old default location.
+type LegacyDatabase struct {
+	Path string
+	// MoveErr is why the database couldn't be moved into db/, or nil if it
+	// was moved.
+	MoveErr error
+}
+
+// PrepareDataDir creates the data directory layout and checks that it can be
+// written to, so a misconfigured DATA_DIR stops startup instead of failing on
+// the first upload. When DATABASE_PATH isn't set it also settles where the
+// database lives: a database left at an old default location (quotes.db in
+// the working directory or at the top of the data directory) is moved into
+// db/, or used where it is if it can't be moved. It returns the database found
+// at an old location, or nil if there wasn't one.
+func (c *Config) PrepareDataDir() (*LegacyDatabase, error) {
+	for _, dir := range []string{c.DataDir, c.DBDir(), c.UploadsDir(), c.AttachmentsDir(), c.BackupsDir()} {
+		if err := os.MkdirAll(dir, dataDirPerm); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", dir, err)
+		}
+		if err := checkWritable(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.DatabasePath != "" {
+		return nil, nil
+	}
+	c.DatabasePath = filepath.Join(c.DBDir(), databaseFile)
+	if exists(c.DatabasePath) {
+		return nil, nil
+	}
+
+	for _, legacy := range []string{databaseFile, filepath.Join(c.DataDir, databaseFile)} {
+		if !exists(legacy) {
+			continue
+		}
+		err := moveDatabase(legacy, c.DatabasePath)
+		if err != nil {
+			c.DatabasePath = legacy
+		}
+		return &LegacyDatabase{Path: legacy, MoveErr: err}, nil
+	}
+	return nil, nil
+}
+
+// checkWritable fails with a clear message when files can't be created in dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// moveDatabase renames a SQLite database. A database with journal files left
+// beside it wasn't closed cleanly, so it is left alone rather than separated
+// from them.
+func moveDatabase(from, to string) error {
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if exists(from + suffix) {
+			return fmt.Errorf("%s has a %s file", from, suffix)
+		}
+	}
+	return os.Rename(from, to)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+)
+
+func TestPrepareDataDir_Layout(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &config.Config{DataDir: "data"}
+
+	legacy, err := cfg.PrepareDataDir()
+	if err != nil {
+		t.Fatalf("PrepareDataDir: %v", err)
+	}
+	if legacy != nil {
+		t.Errorf("legacy = %+v, want none", legacy)
+	}
+	for _, dir := range []string{cfg.DBDir(), cfg.UploadsDir(), cfg.AttachmentsDir(), cfg.BackupsDir()} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("%s not created", dir)
+		}
+	}
+	if want := filepath.Join("data", "db", "quotes.db"); cfg.DatabasePath != want {
+		t.Errorf("DatabasePath = %q, want %q", cfg.DatabasePath, want)
+	}
+}
+
+func TestPrepareDataDir_LegacyDatabase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("quotes.db", []byte("db"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{DataDir: "data"}
+	legacy, err := cfg.PrepareDataDir()
+	if err != nil {
+		t.Fatalf("PrepareDataDir: %v", err)
+	}
+	if legacy == nil || legacy.Path != "quotes.db" || legacy.MoveErr != nil || cfg.DatabasePath != filepath.Join("data", "db", "quotes.db") {
+		t.Errorf("legacy = %+v, DatabasePath = %q", legacy, cfg.DatabasePath)
+	}
+	if data, err := os.ReadFile(cfg.DatabasePath); err != nil || string(data) != "db" {
+		t.Errorf("database not moved: %v", err)
+	}
+
+	// A database with a journal beside it is used where it is.
+	if err := os.WriteFile(filepath.Join("data", "quotes.db"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("data", "quotes.db-wal"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg = &config.Config{DataDir: "data"}
+	if err := os.Remove(filepath.Join("data", "db", "quotes.db")); err != nil {
+		t.Fatal(err)
+	}
+	legacy, err = cfg.PrepareDataDir()
+	if err != nil {
+		t.Fatalf("PrepareDataDir: %v", err)
+	}
+	if cfg.DatabasePath != filepath.Join("data", "quotes.db") {
+		t.Errorf("DatabasePath = %q, want the old location", cfg.DatabasePath)
+	}
+	if legacy == nil || legacy.MoveErr == nil || !strings.Contains(legacy.MoveErr.Error(), "-wal") {
+		t.Errorf("legacy = %+v, want the reason it wasn't moved", legacy)
+	}
+}
+
+func TestPrepareDataDir_ExplicitDatabasePath(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("quotes.db", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{DataDir: "data", DatabasePath: "custom.db"}
+	if legacy, err := cfg.PrepareDataDir(); err != nil || legacy != nil {
+		t.Fatalf("legacy = %+v, err = %v", legacy, err)
+	}
+	if cfg.DatabasePath != "custom.db" {
+		t.Errorf("DatabasePath = %q, want custom.db", cfg.DatabasePath)
+	}
+	if _, err := os.Stat("quotes.db"); err != nil {
+		t.Errorf("old database moved despite DATABASE_PATH")
+	}
+}
+
+func TestPrepareDataDir_Unusable(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("data", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := (&config.Config{DataDir: "data"}).PrepareDataDir()
+	if err == nil || !strings.Contains(err.Error(), "data") {
+		t.Errorf("err = %v, want a failure naming the directory", err)
+	}
+}
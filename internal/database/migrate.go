@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// MigrationError reports a failed migration run. Most migrations run in
+// their own transaction, so a failure rolls the migration back and leaves the
+// database at Current, the version of the last migration that applied
+// cleanly. Migrations marked NO TRANSACTION manage their own transaction,
+// usually around a table rebuild with foreign keys turned off; when one of
+// those fails the database may be partly migrated, with foreign keys still
+// off, and should be restored from a backup.
+type MigrationError struct {
+	Current       int64  // schema version after the failed run
+	Target        int64  // latest available schema version
+	File          string // migration that failed
+	NoTransaction bool   // the failed migration ran outside goose's transaction
+	Err           error
+}
+
+func (e *MigrationError) Error() string {
+	msg := fmt.Sprintf("migration %s failed (schema at version %d, target %d): %v", e.File, e.Current, e.Target, e.Err)
+	if e.NoTransaction {
+		msg += "; it runs outside a transaction, so the database may be partly migrated"
+	}
+	return msg
+}
+
+// newMigrationError describes the migration that failed in a partial run.
+func newMigrationError(fsys fs.FS, partial *goose.PartialError) *MigrationError {
+	return &MigrationError{
+		File:          path.Base(partial.Failed.Source.Path),
+		NoTransaction: runsWithoutTransaction(fsys, partial.Failed.Source.Path),
+		Err:           partial.Err,
+	}
+}
+
+// runsWithoutTransaction reports whether the migration file is marked
+// "-- +goose NO TRANSACTION".
+func runsWithoutTransaction(fsys fs.FS, name string) bool {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "-- +goose NO TRANSACTION" {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// newProvider creates a goose provider for the migrations in fsys.
+func newProvider(db *sql.DB, fsys fs.FS) (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	return provider, nil
+}
+
+// Migrate applies all pending migrations in fsys. On failure it returns a
+// *MigrationError naming the failing file and the versions involved.
+func Migrate(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	provider, err := newProvider(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	_, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	_, err = provider.Up(ctx)
+	if err == nil {
+		return nil
+	}
+
+	migrationErr := &MigrationError{Err: err}
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		migrationErr = newMigrationError(fsys, partial)
+	}
+	migrationErr.Target = target
+	if current, versionErr := provider.GetDBVersion(ctx); versionErr == nil {
+		migrationErr.Current = current
+	}
+	return migrationErr
+}
+
+// MigrateDownTo rolls migrations back until the schema is at version.
+func MigrateDownTo(ctx context.Context, db *sql.DB, fsys fs.FS, version int64) error {
+	provider, err := newProvider(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	if _, err := provider.DownTo(ctx, version); err != nil {
+		var partial *goose.PartialError
+		if errors.As(err, &partial) {
+			migrationErr := newMigrationError(fsys, partial)
+			migrationErr.Current, _ = provider.GetDBVersion(ctx)
+			migrationErr.Target = version
+			return migrationErr
+		}
+		return fmt.Errorf("rolling back to version %d: %w", version, err)
+	}
+	return nil
+}
+
+// WriteMigrationStatus writes the current and target schema versions followed
+// by each migration and whether it has been applied.
+func WriteMigrationStatus(ctx context.Context, w io.Writer, db *sql.DB, fsys fs.FS) error {
+	provider, err := newProvider(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("reading migration status: %w", err)
+	}
+
+	fmt.Fprintf(w, "Schema version: %d (latest %d)\n\n", current, target)
+	for _, status := range statuses {
+		applied := "Pending"
+		if status.State == goose.StateApplied {
+			applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-22s %s\n", applied, path.Base(status.Source.Path))
+	}
+	return nil
+}
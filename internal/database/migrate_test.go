@@ -0,0 +1,130 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const createWidgets = `-- +goose Up
+CREATE TABLE widgets (id INTEGER PRIMARY KEY);
+
+-- +goose Down
+DROP TABLE widgets;
+`
+
+// brokenMigration creates a table and then fails, so a partial apply would
+// leave the gadgets table behind.
+const brokenMigration = `-- +goose Up
+CREATE TABLE gadgets (id INTEGER PRIMARY KEY);
+INSERT INTO no_such_table (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE gadgets;
+`
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrate_BrokenMigrationLeavesVersionUnchanged(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	if err := Migrate(ctx, db, fstest.MapFS{
+		"00001_widgets.sql": {Data: []byte(createWidgets)},
+	}); err != nil {
+		t.Fatalf("first migration: %v", err)
+	}
+
+	err := Migrate(ctx, db, fstest.MapFS{
+		"00001_widgets.sql": {Data: []byte(createWidgets)},
+		"00002_broken.sql":  {Data: []byte(brokenMigration)},
+	})
+
+	var migrationErr *MigrationError
+	if !errors.As(err, &migrationErr) {
+		t.Fatalf("err = %v, want *MigrationError", err)
+	}
+	if migrationErr.File != "00002_broken.sql" || migrationErr.Current != 1 || migrationErr.Target != 2 || migrationErr.NoTransaction {
+		t.Errorf("error = %+v, want file 00002_broken.sql, current 1, target 2, in a transaction", migrationErr)
+	}
+	if !strings.Contains(err.Error(), "no_such_table") {
+		t.Errorf("error message %q does not include the cause", err.Error())
+	}
+
+	var version int64
+	if err := db.QueryRow(`SELECT MAX(version_id) FROM goose_db_version WHERE is_applied = 1`).Scan(&version); err != nil {
+		t.Fatalf("reading version: %v", err)
+	}
+	if version != 1 {
+		t.Errorf("schema version = %d, want 1", version)
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'gadgets'`).Scan(&tables); err != nil {
+		t.Fatalf("checking tables: %v", err)
+	}
+	if tables != 0 {
+		t.Error("failed migration was partially applied")
+	}
+}
+
+func TestMigrateDownTo(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	fsys := fstest.MapFS{
+		"00001_widgets.sql": {Data: []byte(createWidgets)},
+		"00002_gadgets.sql": {Data: []byte("-- +goose Up\nCREATE TABLE gadgets (id INTEGER);\n\n-- +goose Down\nDROP TABLE gadgets;\n")},
+	}
+
+	if err := Migrate(ctx, db, fsys); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := MigrateDownTo(ctx, db, fsys, 1); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteMigrationStatus(ctx, &out, db, fsys); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), "Schema version: 1 (latest 2)") {
+		t.Errorf("status missing versions:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Pending                00002_gadgets.sql") {
+		t.Errorf("status missing pending migration:\n%s", out.String())
+	}
+}
+
+func TestMigrate_BrokenNoTransactionMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	err := Migrate(ctx, db, fstest.MapFS{
+		"00001_widgets.sql": {Data: []byte(createWidgets)},
+		"00002_rebuild.sql": {Data: []byte("-- +goose NO TRANSACTION\n" + brokenMigration)},
+	})
+
+	var migrationErr *MigrationError
+	if !errors.As(err, &migrationErr) {
+		t.Fatalf("err = %v, want *MigrationError", err)
+	}
+	if !migrationErr.NoTransaction {
+		t.Errorf("NoTransaction = false, want true for a NO TRANSACTION migration")
+	}
+	if !strings.Contains(err.Error(), "partly migrated") {
+		t.Errorf("error message %q does not warn about a partial migration", err.Error())
+	}
+}
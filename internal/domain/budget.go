@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// BudgetPeriod returns the calendar month containing now, as counted in loc,
+// by the instants it starts and the next one starts. Monthly budgets reset at
+// midnight on the 1st in loc, not in UTC.
+func BudgetPeriod(now time.Time, loc *time.Location) (start, next time.Time) {
+	local := now.In(loc)
+	start = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,38 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+)
+
+func TestBudgetPeriod(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skipf("no timezone data: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		loc         *time.Location
+		start, next string
+	}{
+		{"mid-month", time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC), time.UTC, "2026-10-01T00:00:00Z", "2026-11-01T00:00:00Z"},
+		{"still last month in Denver", time.Date(2026, 11, 1, 3, 0, 0, 0, time.UTC), denver, "2026-10-01T00:00:00-06:00", "2026-11-01T00:00:00-06:00"},
+		{"new month in Denver", time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC), denver, "2026-11-01T00:00:00-06:00", "2026-12-01T00:00:00-07:00"},
+		{"year end", time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), time.UTC, "2026-12-01T00:00:00Z", "2027-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, next := domain.BudgetPeriod(tt.now, tt.loc)
+			if got := start.Format(time.RFC3339); got != tt.start {
+				t.Errorf("start = %s, want %s", got, tt.start)
+			}
+			if got := next.Format(time.RFC3339); got != tt.next {
+				t.Errorf("next = %s, want %s", got, tt.next)
+			}
+		})
+	}
+}
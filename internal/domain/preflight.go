@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreflightRule identifies one pre-send check on a quote.
+type PreflightRule string
+
+const (
+	PreflightEmptyCategory PreflightRule = "empty_category"
+	PreflightZeroPrice     PreflightRule = "zero_price"
+	PreflightBelowCost     PreflightRule = "below_cost"
+	PreflightAreaQuantity  PreflightRule = "area_quantity"
+	PreflightMissingClient PreflightRule = "missing_client"
+	PreflightMissingExpiry PreflightRule = "missing_expiry"
+	PreflightOpenComments  PreflightRule = "open_comments"
+)
+
+// PreflightRules lists every rule in the order findings are shown.
+var PreflightRules = []PreflightRule{
+	PreflightMissingClient,
+	PreflightMissingExpiry,
+	PreflightEmptyCategory,
+	PreflightZeroPrice,
+	PreflightBelowCost,
+	PreflightAreaQuantity,
+	PreflightOpenComments,
+}
+
+// Valid reports whether r is a known rule.
+func (r PreflightRule) Valid() bool {
+	for _, rule := range PreflightRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// PreflightQuote is what the pre-send checks look at.
+type PreflightQuote struct {
+	HasClient  bool
+	HasExpiry  bool
+	Categories []*Category
+	LineItems  []*LineItem
+	Costs      map[string]float64 // price book price by line item ID, for items added from a template
+	Comments   map[string]int64   // unresolved comment count by line item ID
+}
+
+// PreflightFinding is something worth a second look before sending a quote.
+// CategoryID and ItemID point at the row to fix, when there is one.
+type PreflightFinding struct {
+	Rule       PreflightRule
+	Message    string
+	CategoryID string
+	ItemID     string
+}
+
+// Preflight runs every rule not in dismissed and returns the findings in
+// PreflightRules order.
+func Preflight(q PreflightQuote, dismissed map[PreflightRule]bool) []PreflightFinding {
+	checks := map[PreflightRule]func(PreflightQuote) []PreflightFinding{
+		PreflightMissingClient: checkMissingClient,
+		PreflightMissingExpiry: checkMissingExpiry,
+		PreflightEmptyCategory: checkEmptyCategories,
+		PreflightZeroPrice:     checkZeroPrices,
+		PreflightBelowCost:     checkBelowCost,
+		PreflightAreaQuantity:  checkAreaQuantities,
+		PreflightOpenComments:  checkOpenComments,
+	}
+
+	findings := make([]PreflightFinding, 0)
+	for _, rule := range PreflightRules {
+		if !dismissed[rule] {
+			findings = append(findings, checks[rule](q)...)
+		}
+	}
+	return findings
+}
+
+func checkMissingClient(q PreflightQuote) []PreflightFinding {
+	if q.HasClient {
+		return nil
+	}
+	return []PreflightFinding{{Rule: PreflightMissingClient, Message: "No client is assigned"}}
+}
+
+func checkMissingExpiry(q PreflightQuote) []PreflightFinding {
+	if q.HasExpiry {
+		return nil
+	}
+	return []PreflightFinding{{Rule: PreflightMissingExpiry, Message: "The quote has no expiration date"}}
+}
+
+// checkEmptyCategories flags categories with neither line items nor
+// subcategories.
+func checkEmptyCategories(q PreflightQuote) []PreflightFinding {
+	used := make(map[string]bool)
+	for _, item := range q.LineItems {
+		used[item.CategoryID] = true
+	}
+	for _, cat := range q.Categories {
+		if cat.ParentID != nil {
+			used[*cat.ParentID] = true
+		}
+	}
+
+	var findings []PreflightFinding
+	for _, cat := range q.Categories {
+		if !used[cat.ID] {
+			findings = append(findings, PreflightFinding{
+				Rule:       PreflightEmptyCategory,
+				Message:    fmt.Sprintf("%s has no items", cat.Name),
+				CategoryID: cat.ID,
+			})
+		}
+	}
+	return findings
+}
+
+// freeMarkers are words in an item's name or description that mark a $0
+// price as intended.
+var freeMarkers = []string{"free", "no charge", "n/c", "included"}
+
+// checkZeroPrices flags $0 items unless their name or description says they
+// are free.
+func checkZeroPrices(q PreflightQuote) []PreflightFinding {
+	var findings []PreflightFinding
+	for _, item := range q.LineItems {
+		if item.UnitPrice != 0 || isMarkedFree(item) {
+			continue
+		}
+		findings = append(findings, PreflightFinding{
+			Rule:       PreflightZeroPrice,
+			Message:    fmt.Sprintf("%s has a $0 price", item.Name),
+			CategoryID: item.CategoryID,
+			ItemID:     item.ID,
+		})
+	}
+	return findings
+}
+
+func isMarkedFree(item *LineItem) bool {
+	text := strings.ToLower(item.Name)
+	if item.Description != nil {
+		text += " " + strings.ToLower(*item.Description)
+	}
+	for _, marker := range freeMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// BelowCost reports whether a unit price, before markup, is under what the
+// price book says the item costs. Differences under half a cent are rounding.
+func BelowCost(price, cost float64) bool {
+	return price < cost-0.005
+}
+
+// checkBelowCost flags items priced under their price book price. Credits
+// are meant to be negative, and $0 items are left to checkZeroPrices.
+func checkBelowCost(q PreflightQuote) []PreflightFinding {
+	var findings []PreflightFinding
+	for _, item := range q.LineItems {
+		cost, ok := q.Costs[item.ID]
+		if !ok || item.IsCredit || item.UnitPrice == 0 || !BelowCost(item.UnitPrice, cost) {
+			continue
+		}
+		findings = append(findings, PreflightFinding{
+			Rule:       PreflightBelowCost,
+			Message:    fmt.Sprintf("%s is priced at $%.2f, below its $%.2f cost", item.Name, item.UnitPrice, cost),
+			CategoryID: item.CategoryID,
+			ItemID:     item.ID,
+		})
+	}
+	return findings
+}
+
+// areaUnits are units measured by area, where a quantity of exactly 1 is
+// more likely a placeholder than a measurement.
+var areaUnits = map[string]bool{
+	"sqft": true, "sq ft": true, "sf": true, "ft2": true,
+	"sqyd": true, "sq yd": true, "sy": true, "yd2": true,
+	"sqm": true, "m2": true, "square": true, "sq": true,
+}
+
+// checkAreaQuantities flags items with a quantity of 1 on an area unit.
+func checkAreaQuantities(q PreflightQuote) []PreflightFinding {
+	var findings []PreflightFinding
+	for _, item := range q.LineItems {
+		if item.Quantity != 1 || !areaUnits[strings.ToLower(strings.TrimSpace(item.Unit))] {
+			continue
+		}
+		findings = append(findings, PreflightFinding{
+			Rule:       PreflightAreaQuantity,
+			Message:    fmt.Sprintf("%s is quoted as 1 %s", item.Name, item.Unit),
+			CategoryID: item.CategoryID,
+			ItemID:     item.ID,
+		})
+	}
+	return findings
+}
+
+// checkOpenComments flags items with unresolved internal comments.
+func checkOpenComments(q PreflightQuote) []PreflightFinding {
+	var findings []PreflightFinding
+	for _, item := range q.LineItems {
+		n := q.Comments[item.ID]
+		if n == 0 {
+			continue
+		}
+		noun := "comments"
+		if n == 1 {
+			noun = "comment"
+		}
+		findings = append(findings, PreflightFinding{
+			Rule:       PreflightOpenComments,
+			Message:    fmt.Sprintf("%s has %d unresolved %s", item.Name, n, noun),
+			CategoryID: item.CategoryID,
+			ItemID:     item.ID,
+		})
+	}
+	return findings
+}
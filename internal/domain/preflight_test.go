@@ -0,0 +1,140 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+)
+
+// runRule runs only the given preflight rule and returns the IDs of the
+// rows it flagged, or the rule name for quote-level findings.
+func runRule(rule domain.PreflightRule, q domain.PreflightQuote) []string {
+	dismissed := make(map[domain.PreflightRule]bool)
+	for _, r := range domain.PreflightRules {
+		dismissed[r] = r != rule
+	}
+
+	var flagged []string
+	for _, f := range domain.Preflight(q, dismissed) {
+		if f.Rule != rule {
+			panic("dismissed rule " + string(f.Rule) + " ran")
+		}
+		switch {
+		case f.ItemID != "":
+			flagged = append(flagged, f.ItemID)
+		case f.CategoryID != "":
+			flagged = append(flagged, f.CategoryID)
+		default:
+			flagged = append(flagged, string(f.Rule))
+		}
+	}
+	return flagged
+}
+
+func assertFlagged(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("flagged %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("flagged %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPreflight_MissingClientAndExpiry(t *testing.T) {
+	assertFlagged(t, runRule(domain.PreflightMissingClient, domain.PreflightQuote{}), "missing_client")
+	assertFlagged(t, runRule(domain.PreflightMissingClient, domain.PreflightQuote{HasClient: true}))
+	assertFlagged(t, runRule(domain.PreflightMissingExpiry, domain.PreflightQuote{}), "missing_expiry")
+	assertFlagged(t, runRule(domain.PreflightMissingExpiry, domain.PreflightQuote{HasExpiry: true}))
+}
+
+func TestPreflight_EmptyCategories(t *testing.T) {
+	q := domain.PreflightQuote{
+		Categories: []*domain.Category{
+			{ID: "framing", Name: "Framing"},
+			{ID: "electrical", Name: "Electrical"},
+			{ID: "walls", Name: "Walls", ParentID: stringPtr("electrical")},
+			{ID: "paint", Name: "Paint"},
+		},
+		LineItems: []*domain.LineItem{
+			{ID: "i1", CategoryID: "framing", Name: "2x4", Quantity: 10, UnitPrice: 3.5},
+		},
+	}
+
+	// Electrical has no items of its own but holds a subcategory.
+	assertFlagged(t, runRule(domain.PreflightEmptyCategory, q), "walls", "paint")
+}
+
+func TestPreflight_ZeroPrices(t *testing.T) {
+	q := domain.PreflightQuote{
+		LineItems: []*domain.LineItem{
+			{ID: "priced", Name: "Drywall", Quantity: 10, UnitPrice: 12},
+			{ID: "zero", Name: "Drywall screws", Quantity: 1, UnitPrice: 0},
+			{ID: "free-name", Name: "Disposal (no charge)", Quantity: 1, UnitPrice: 0},
+			{ID: "free-desc", Name: "Site visit", Description: stringPtr("Included with estimate"), Quantity: 1, UnitPrice: 0},
+		},
+	}
+
+	assertFlagged(t, runRule(domain.PreflightZeroPrice, q), "zero")
+}
+
+func TestPreflight_BelowCost(t *testing.T) {
+	q := domain.PreflightQuote{
+		LineItems: []*domain.LineItem{
+			{ID: "below", Name: "Drywall", Quantity: 10, UnitPrice: 11.50},
+			{ID: "at-cost", Name: "Joint tape", Quantity: 1, UnitPrice: 4.004},
+			{ID: "above", Name: "Mud", Quantity: 2, UnitPrice: 18},
+			{ID: "no-template", Name: "Corner bead", Quantity: 4, UnitPrice: 1},
+			{ID: "credit", Name: "Returned sheets", Quantity: 2, UnitPrice: -12, IsCredit: true},
+			{ID: "zero", Name: "Screws", Quantity: 1, UnitPrice: 0},
+		},
+		Costs: map[string]float64{
+			"below": 12, "at-cost": 4.00, "above": 15, "credit": 12, "zero": 6,
+		},
+	}
+
+	assertFlagged(t, runRule(domain.PreflightBelowCost, q), "below")
+}
+
+func TestPreflight_AreaQuantities(t *testing.T) {
+	q := domain.PreflightQuote{
+		LineItems: []*domain.LineItem{
+			{ID: "one-sqft", Name: "Tile", Quantity: 1, Unit: "sqft", UnitPrice: 8},
+			{ID: "one-sf-upper", Name: "Underlayment", Quantity: 1, Unit: " SF ", UnitPrice: 2},
+			{ID: "measured", Name: "Flooring", Quantity: 240, Unit: "sqft", UnitPrice: 6},
+			{ID: "one-each", Name: "Threshold", Quantity: 1, Unit: "ea", UnitPrice: 30},
+		},
+	}
+
+	assertFlagged(t, runRule(domain.PreflightAreaQuantity, q), "one-sqft", "one-sf-upper")
+}
+
+func TestPreflight_OpenComments(t *testing.T) {
+	q := domain.PreflightQuote{
+		LineItems: []*domain.LineItem{
+			{ID: "checked", Name: "Drywall", Quantity: 10, UnitPrice: 12},
+			{ID: "open", Name: "Studs", Quantity: 40, UnitPrice: 4},
+		},
+		Comments: map[string]int64{"open": 2},
+	}
+
+	assertFlagged(t, runRule(domain.PreflightOpenComments, q), "open")
+}
+
+func TestPreflight_Dismissed(t *testing.T) {
+	q := domain.PreflightQuote{
+		LineItems: []*domain.LineItem{{ID: "zero", Name: "Screws", Quantity: 1, UnitPrice: 0}},
+	}
+
+	findings := domain.Preflight(q, map[domain.PreflightRule]bool{domain.PreflightZeroPrice: true})
+	for _, f := range findings {
+		if f.Rule == domain.PreflightZeroPrice {
+			t.Errorf("dismissed rule reported %q", f.Message)
+		}
+	}
+	if len(findings) != 2 {
+		t.Errorf("findings = %d, want missing client and expiry", len(findings))
+	}
+}
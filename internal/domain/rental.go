@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rental periods in days. A rental month is billed as four weeks, as most
+// equipment yards do.
+const (
+	RentalWeekDays  = 7
+	RentalMonthDays = 28
+)
+
+// RentalRates are an equipment template's prices per rental period. A zero
+// rate means the period isn't offered.
+type RentalRates struct {
+	Day   float64
+	Week  float64
+	Month float64
+}
+
+// Tiered reports whether any rate is set.
+func (r RentalRates) Tiered() bool {
+	return r.Day > 0 || r.Week > 0 || r.Month > 0
+}
+
+// RentalQuote is the cheapest combination of rental periods covering a
+// duration. It may cover more days than asked for when a longer period costs
+// less than the days it replaces.
+type RentalQuote struct {
+	Duration int // days asked for
+	Months   int
+	Weeks    int
+	Days     int
+	Total    float64
+}
+
+// Breakdown describes the periods billed, such as "9 days: 1 week @ $900.00
+// + 2 days @ $250.00".
+func (q RentalQuote) Breakdown(rates RentalRates) string {
+	var parts []string
+	add := func(n int, period string, rate float64) {
+		if n == 0 {
+			return
+		}
+		if n != 1 {
+			period += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s @ $%.2f", n, period, rate))
+	}
+	add(q.Months, "month", rates.Month)
+	add(q.Weeks, "week", rates.Week)
+	add(q.Days, "day", rates.Day)
+
+	days := "days"
+	if q.Duration == 1 {
+		days = "day"
+	}
+	return fmt.Sprintf("%d %s: %s", q.Duration, days, strings.Join(parts, " + "))
+}
+
+// CheapestRental finds the combination of day, week, and month periods
+// covering at least days that costs least. It returns false when days is not
+// positive or no rate is set.
+func CheapestRental(rates RentalRates, days int) (RentalQuote, bool) {
+	if days <= 0 || !rates.Tiered() {
+		return RentalQuote{}, false
+	}
+
+	type period struct {
+		days int
+		rate float64
+	}
+	var periods []period
+	for _, p := range []period{{1, rates.Day}, {RentalWeekDays, rates.Week}, {RentalMonthDays, rates.Month}} {
+		if p.rate > 0 {
+			periods = append(periods, p)
+		}
+	}
+
+	// cost[d] is the cheapest way to cover d days; last[d] is the period
+	// that ends it. A period may run past the days still needed.
+	cost := make([]float64, days+1)
+	last := make([]int, days+1)
+	for d := 1; d <= days; d++ {
+		cost[d] = -1
+		for i, p := range periods {
+			c := cost[max(0, d-p.days)] + p.rate
+			if cost[d] < 0 || c < cost[d] {
+				cost[d] = c
+				last[d] = i
+			}
+		}
+	}
+
+	quote := RentalQuote{Duration: days, Total: cost[days]}
+	for d := days; d > 0; {
+		p := periods[last[d]]
+		switch p.days {
+		case RentalMonthDays:
+			quote.Months++
+		case RentalWeekDays:
+			quote.Weeks++
+		default:
+			quote.Days++
+		}
+		d -= p.days
+	}
+	return quote, true
+}
@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+)
+
+func TestCheapestRental(t *testing.T) {
+	skidSteer := domain.RentalRates{Day: 250, Week: 900, Month: 2800}
+
+	tests := []struct {
+		name                string
+		rates               domain.RentalRates
+		duration            int
+		months, weeks, days int
+		total               float64
+		breakdown           string
+	}{
+		{"single day", skidSteer, 1, 0, 0, 1, 250, "1 day: 1 day @ $250.00"},
+		{"three days", skidSteer, 3, 0, 0, 3, 750, "3 days: 3 days @ $250.00"},
+		{"four days cost more than a week", skidSteer, 4, 0, 1, 0, 900, "4 days: 1 week @ $900.00"},
+		{"week and two days", skidSteer, 9, 0, 1, 2, 1400, "9 days: 1 week @ $900.00 + 2 days @ $250.00"},
+		{"two weeks beat a week and five days", skidSteer, 12, 0, 2, 0, 1800, "12 days: 2 weeks @ $900.00"},
+		{"month beats four weeks", skidSteer, 28, 1, 0, 0, 2800, "28 days: 1 month @ $2800.00"},
+		{"month beats three weeks and six days", skidSteer, 27, 1, 0, 0, 2800, "27 days: 1 month @ $2800.00"},
+		{"month and a day", skidSteer, 29, 1, 0, 1, 3050, "29 days: 1 month @ $2800.00 + 1 day @ $250.00"},
+		{"week rate only", domain.RentalRates{Week: 600}, 9, 0, 2, 0, 1200, "9 days: 2 weeks @ $600.00"},
+		{"day rate only", domain.RentalRates{Day: 100}, 9, 0, 0, 9, 900, "9 days: 9 days @ $100.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, ok := domain.CheapestRental(tt.rates, tt.duration)
+			if !ok {
+				t.Fatal("no quote")
+			}
+			if quote.Months != tt.months || quote.Weeks != tt.weeks || quote.Days != tt.days {
+				t.Errorf("periods = %d months, %d weeks, %d days; want %d, %d, %d",
+					quote.Months, quote.Weeks, quote.Days, tt.months, tt.weeks, tt.days)
+			}
+			if math.Abs(quote.Total-tt.total) > 0.001 {
+				t.Errorf("Total = %.2f, want %.2f", quote.Total, tt.total)
+			}
+			if got := quote.Breakdown(tt.rates); got != tt.breakdown {
+				t.Errorf("Breakdown() = %q, want %q", got, tt.breakdown)
+			}
+		})
+	}
+}
+
+func TestCheapestRental_NoQuote(t *testing.T) {
+	if _, ok := domain.CheapestRental(domain.RentalRates{}, 5); ok {
+		t.Errorf("quote without rates")
+	}
+	if _, ok := domain.CheapestRental(domain.RentalRates{Day: 100}, 0); ok {
+		t.Errorf("quote for zero days")
+	}
+}
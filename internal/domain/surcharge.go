@@ -2,7 +2,12 @@ package domain
 
 // EffectiveSurcharge calculates the applicable surcharge for a line item
 // based on the job's surcharge mode and the category hierarchy.
+// Items exempt from surcharge always return 0, as do credits unless
+// job.SurchargeCredits is set.
 func EffectiveSurcharge(li *LineItem, job *Job, categoryChain []*Category) float64 {
+	if li.ExemptFromSurcharge || (li.IsCredit && !job.SurchargeCredits) {
+		return 0
+	}
 	if job.SurchargeMode == SurchargeModeOverride {
 		return effectiveSurchargeOverride(li, job, categoryChain)
 	}
@@ -64,12 +69,18 @@ type CategoryTotal struct {
 
 // JobTotal calculates the complete job totals.
 type JobTotal struct {
-	Subtotal          float64 `json:"subtotal"`           // Sum of all base prices
-	SurchargeTotal    float64 `json:"surcharge_total"`    // Total surcharges applied
-	GrandTotal        float64 `json:"grand_total"`        // Final total
-	MaterialSubtotal  float64 `json:"material_subtotal"`  // Materials only
-	LaborSubtotal     float64 `json:"labor_subtotal"`     // Labor only
-	EquipmentSubtotal float64 `json:"equipment_subtotal"` // Equipment only
+	Subtotal            float64 `json:"subtotal"`             // Sum of all base prices
+	SurchargeTotal      float64 `json:"surcharge_total"`      // Total surcharges applied
+	GrandTotal          float64 `json:"grand_total"`          // Final total
+	MaterialSubtotal    float64 `json:"material_subtotal"`    // Materials only
+	LaborSubtotal       float64 `json:"labor_subtotal"`       // Labor only
+	EquipmentSubtotal   float64 `json:"equipment_subtotal"`   // Equipment only
+	SubcontractSubtotal float64 `json:"subcontract_subtotal"` // Subcontracts only
+	FeeSubtotal         float64 `json:"fee_subtotal"`         // Fees only
+	// The tax fields are only filled in when the job has a tax rate.
+	TaxableBase float64 `json:"taxable_base,omitempty"` // Final prices of taxable items
+	ExemptBase  float64 `json:"exempt_base,omitempty"`  // Final prices of exempt items
+	TaxTotal    float64 `json:"tax_total,omitempty"`    // Tax on the taxable base
 }
 
 // CalculateJobTotal computes all totals for a job.
@@ -109,11 +120,27 @@ func CalculateJobTotal(job *Job, categories []*Category, lineItems []*LineItem)
 			result.LaborSubtotal += finalPrice
 		case LineItemTypeEquipment:
 			result.EquipmentSubtotal += finalPrice
+		case LineItemTypeSubcontract:
+			result.SubcontractSubtotal += finalPrice
+		case LineItemTypeFee:
+			result.FeeSubtotal += finalPrice
+		}
+
+		if job.TaxPercent != 0 {
+			if EffectiveTaxTreatment(li, chain) == TaxTreatmentExempt {
+				result.ExemptBase += finalPrice
+			} else {
+				result.TaxableBase += finalPrice
+			}
 		}
 	}
 
 	result.SurchargeTotal = result.GrandTotal - result.Subtotal
 
+	// Tax is added after the surcharge total so it isn't counted as surcharge
+	result.TaxTotal = result.TaxableBase * job.TaxPercent / 100
+	result.GrandTotal += result.TaxTotal
+
 	return result
 }
 
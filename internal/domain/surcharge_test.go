@@ -76,6 +76,21 @@ func TestEffectiveSurcharge_StackingMode(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "exempt item ignores the chain",
+			job: &domain.Job{
+				SurchargePercent: 10,
+				SurchargeMode:    domain.SurchargeModeStacking,
+			},
+			categoryChain: []*domain.Category{
+				{SurchargePercent: floatPtr(5)},
+			},
+			lineItem: &domain.LineItem{
+				SurchargePercent:    floatPtr(2),
+				ExemptFromSurcharge: true,
+			},
+			want: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -155,6 +170,21 @@ func TestEffectiveSurcharge_OverrideMode(t *testing.T) {
 			},
 			want: 10, // Top level category wins
 		},
+		{
+			name: "exempt item ignores the chain",
+			job: &domain.Job{
+				SurchargePercent: 15,
+				SurchargeMode:    domain.SurchargeModeOverride,
+			},
+			categoryChain: []*domain.Category{
+				{SurchargePercent: floatPtr(10)},
+			},
+			lineItem: &domain.LineItem{
+				SurchargePercent:    floatPtr(5),
+				ExemptFromSurcharge: true,
+			},
+			want: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -282,6 +312,75 @@ func TestCalculateJobTotal(t *testing.T) {
 	}
 }
 
+func TestCalculateJobTotal_SubcontractAndFeeSubtotals(t *testing.T) {
+	job := makeJob("job-1", 10, domain.SurchargeModeStacking)
+
+	categories := []*domain.Category{
+		makeCategory("cat-1", "job-1", nil, nil),
+	}
+
+	lineItems := []*domain.LineItem{
+		// Base 100, Final 110
+		makeLineItem("item-m", "cat-1", domain.LineItemTypeMaterial, 1, 100),
+		// Base 2000, Final 2200
+		makeLineItem("item-s", "cat-1", domain.LineItemTypeSubcontract, 1, 2000),
+		// Base 2 x 150 = 300, Final 330
+		makeLineItem("item-f", "cat-1", domain.LineItemTypeFee, 2, 150),
+	}
+
+	result := domain.CalculateJobTotal(job, categories, lineItems)
+
+	if !floatEquals(result.SubcontractSubtotal, 2200) {
+		t.Errorf("SubcontractSubtotal = %v, want 2200", result.SubcontractSubtotal)
+	}
+
+	if !floatEquals(result.FeeSubtotal, 330) {
+		t.Errorf("FeeSubtotal = %v, want 330", result.FeeSubtotal)
+	}
+
+	// Subcontracts and fees must not leak into the material subtotal
+	if !floatEquals(result.MaterialSubtotal, 110) {
+		t.Errorf("MaterialSubtotal = %v, want 110", result.MaterialSubtotal)
+	}
+
+	if !floatEquals(result.GrandTotal, 2640) {
+		t.Errorf("GrandTotal = %v, want 2640", result.GrandTotal)
+	}
+}
+
+func TestCalculateJobTotal_ExemptItemsAddNoSurcharge(t *testing.T) {
+	for _, mode := range []domain.SurchargeMode{domain.SurchargeModeStacking, domain.SurchargeModeOverride} {
+		t.Run(string(mode), func(t *testing.T) {
+			job := makeJob("job-1", 10, mode)
+
+			categories := []*domain.Category{
+				makeCategory("cat-1", "job-1", nil, floatPtr(5)),
+			}
+
+			permit := makeLineItem("item-permit", "cat-1", domain.LineItemTypeFee, 1, 400)
+			permit.ExemptFromSurcharge = true
+
+			lineItems := []*domain.LineItem{
+				makeLineItem("item-m", "cat-1", domain.LineItemTypeMaterial, 1, 100),
+				permit,
+			}
+
+			result := domain.CalculateJobTotal(job, categories, lineItems)
+			materialSurcharge := result.MaterialSubtotal - 100
+
+			// Only the material contributes surcharge
+			if !floatEquals(result.SurchargeTotal, materialSurcharge) {
+				t.Errorf("SurchargeTotal = %v, want %v", result.SurchargeTotal, materialSurcharge)
+			}
+
+			// The permit is billed at cost
+			if !floatEquals(result.FeeSubtotal, 400) {
+				t.Errorf("FeeSubtotal = %v, want 400", result.FeeSubtotal)
+			}
+		})
+	}
+}
+
 // Test helper functions for cleaner test setup
 func stringPtr(s string) *string {
 	return &s
@@ -558,6 +657,89 @@ func TestCalculateJobTotal_CategoryRemoval(t *testing.T) {
 	}
 }
 
+func TestCalculateJobTotal_CreditsExceedCategory(t *testing.T) {
+	job := makeJob("job-1", 10, domain.SurchargeModeStacking)
+	categories := []*domain.Category{
+		makeCategory("cat-fixtures", "job-1", nil, nil),
+		makeCategory("cat-labor", "job-1", nil, nil),
+	}
+
+	credit := makeLineItem("item-credit", "cat-fixtures", domain.LineItemTypeMaterial, 1, -250)
+	credit.IsCredit = true
+
+	lineItems := []*domain.LineItem{
+		// Base 100, Final 110
+		makeLineItem("item-fixture", "cat-fixtures", domain.LineItemTypeMaterial, 1, 100),
+		// Base -250, no surcharge on the credit
+		credit,
+		// Base 200, Final 220
+		makeLineItem("item-labor", "cat-labor", domain.LineItemTypeLabor, 2, 100),
+	}
+
+	category := domain.CalculateCategoryTotal("cat-fixtures", job, categories, lineItems)
+	if !floatEquals(category.Subtotal, -150) {
+		t.Errorf("category Subtotal = %v, want -150", category.Subtotal)
+	}
+	if !floatEquals(category.Total, -140) {
+		t.Errorf("category Total = %v, want -140", category.Total)
+	}
+
+	result := domain.CalculateJobTotal(job, categories, lineItems)
+	if !floatEquals(result.Subtotal, 50) {
+		t.Errorf("Subtotal = %v, want 50", result.Subtotal)
+	}
+	if !floatEquals(result.SurchargeTotal, 30) {
+		t.Errorf("SurchargeTotal = %v, want 30", result.SurchargeTotal)
+	}
+	if !floatEquals(result.GrandTotal, 80) {
+		t.Errorf("GrandTotal = %v, want 80", result.GrandTotal)
+	}
+	if !floatEquals(result.MaterialSubtotal, -140) {
+		t.Errorf("MaterialSubtotal = %v, want -140", result.MaterialSubtotal)
+	}
+	if !floatEquals(result.LaborSubtotal, 220) {
+		t.Errorf("LaborSubtotal = %v, want 220", result.LaborSubtotal)
+	}
+
+	// With markup applied to credits, the credit reduces the total by 275.
+	job.SurchargeCredits = true
+	result = domain.CalculateJobTotal(job, categories, lineItems)
+	if !floatEquals(result.GrandTotal, 55) {
+		t.Errorf("GrandTotal with surcharged credit = %v, want 55", result.GrandTotal)
+	}
+}
+
+func TestEffectiveSurcharge_CreditsSetting(t *testing.T) {
+	job := makeJob("job-1", 10, domain.SurchargeModeStacking)
+	chain := []*domain.Category{makeCategory("cat-1", "job-1", nil, floatPtr(5))}
+	credit := makeLineItem("item-credit", "cat-1", domain.LineItemTypeMaterial, 1, -100)
+	credit.IsCredit = true
+
+	if got := domain.EffectiveSurcharge(credit, job, chain); got != 0 {
+		t.Errorf("credit surcharge with setting off = %v, want 0", got)
+	}
+
+	// Turning the setting on marks up credits that already exist.
+	job.SurchargeCredits = true
+	if got := domain.EffectiveSurcharge(credit, job, chain); got != 15 {
+		t.Errorf("credit surcharge with setting on = %v, want 15", got)
+	}
+
+	// A credit the user exempted stays exempt either way.
+	credit.ExemptFromSurcharge = true
+	if got := domain.EffectiveSurcharge(credit, job, chain); got != 0 {
+		t.Errorf("exempt credit surcharge = %v, want 0", got)
+	}
+
+	// Editing a credit back into a normal item marks it up again.
+	credit.ExemptFromSurcharge = false
+	credit.IsCredit = false
+	job.SurchargeCredits = false
+	if got := domain.EffectiveSurcharge(credit, job, chain); got != 15 {
+		t.Errorf("former credit surcharge = %v, want 15", got)
+	}
+}
+
 func TestCalculateCategoryTotal(t *testing.T) {
 	job := makeJob("job-1", 10, domain.SurchargeModeStacking)
 
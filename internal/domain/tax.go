@@ -0,0 +1,28 @@
+package domain
+
+// EffectiveTaxTreatment resolves whether a line item is taxed. The most
+// specific explicit treatment wins: LineItem > deepest Category > ... >
+// shallowest Category. Items with no explicit treatment anywhere in the
+// chain are taxable.
+//
+// The chain resolves the same way in both surcharge modes; tax is charged on
+// the surcharged final price, so the mode only changes the taxable amount.
+func EffectiveTaxTreatment(li *LineItem, categoryChain []*Category) TaxTreatment {
+	if isExplicitTaxTreatment(li.TaxTreatment) {
+		return li.TaxTreatment
+	}
+
+	// Walk category chain from deepest to shallowest
+	for i := len(categoryChain) - 1; i >= 0; i-- {
+		if isExplicitTaxTreatment(categoryChain[i].TaxTreatment) {
+			return categoryChain[i].TaxTreatment
+		}
+	}
+
+	return TaxTreatmentTaxable
+}
+
+// isExplicitTaxTreatment reports whether t overrides the inherited treatment.
+func isExplicitTaxTreatment(t TaxTreatment) bool {
+	return t == TaxTreatmentTaxable || t == TaxTreatmentExempt
+}
@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+)
+
+func TestEffectiveTaxTreatment(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   domain.TaxTreatment
+		parent domain.TaxTreatment
+		child  domain.TaxTreatment
+		want   domain.TaxTreatment
+	}{
+		{"nothing set", domain.TaxTreatmentDefault, domain.TaxTreatmentDefault, domain.TaxTreatmentDefault, domain.TaxTreatmentTaxable},
+		{"empty values", "", "", "", domain.TaxTreatmentTaxable},
+		{"item exempt", domain.TaxTreatmentExempt, domain.TaxTreatmentDefault, domain.TaxTreatmentDefault, domain.TaxTreatmentExempt},
+		{"item taxable in exempt category", domain.TaxTreatmentTaxable, domain.TaxTreatmentExempt, domain.TaxTreatmentExempt, domain.TaxTreatmentTaxable},
+		{"item exempt in taxable category", domain.TaxTreatmentExempt, domain.TaxTreatmentTaxable, domain.TaxTreatmentTaxable, domain.TaxTreatmentExempt},
+		{"inherits parent", domain.TaxTreatmentDefault, domain.TaxTreatmentExempt, domain.TaxTreatmentDefault, domain.TaxTreatmentExempt},
+		{"inherits child", domain.TaxTreatmentDefault, domain.TaxTreatmentDefault, domain.TaxTreatmentExempt, domain.TaxTreatmentExempt},
+		{"child overrides parent", domain.TaxTreatmentDefault, domain.TaxTreatmentExempt, domain.TaxTreatmentTaxable, domain.TaxTreatmentTaxable},
+		{"child exempt under taxable parent", domain.TaxTreatmentDefault, domain.TaxTreatmentTaxable, domain.TaxTreatmentExempt, domain.TaxTreatmentExempt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := []*domain.Category{
+				{TaxTreatment: tt.parent},
+				{TaxTreatment: tt.child},
+			}
+			got := domain.EffectiveTaxTreatment(&domain.LineItem{TaxTreatment: tt.item}, chain)
+			if got != tt.want {
+				t.Errorf("EffectiveTaxTreatment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateJobTotal_Tax(t *testing.T) {
+	// Parent category: +10% surcharge, exempt. Child category: taxable.
+	categories := []*domain.Category{
+		{ID: "parent", SurchargePercent: floatPtr(10), TaxTreatment: domain.TaxTreatmentExempt},
+		{ID: "child", ParentID: stringPtr("parent"), TaxTreatment: domain.TaxTreatmentTaxable},
+	}
+
+	tests := []struct {
+		name        string
+		mode        domain.SurchargeMode
+		item        domain.TaxTreatment
+		category    string
+		wantTaxable float64
+		wantExempt  float64
+	}{
+		// Stacking: 20% job + 10% parent = 30%, so 100 becomes 130.
+		{"stacking, inherits exempt parent", domain.SurchargeModeStacking, domain.TaxTreatmentDefault, "parent", 0, 130},
+		{"stacking, item taxable in exempt parent", domain.SurchargeModeStacking, domain.TaxTreatmentTaxable, "parent", 130, 0},
+		{"stacking, inherits taxable child", domain.SurchargeModeStacking, domain.TaxTreatmentDefault, "child", 130, 0},
+		{"stacking, item exempt in taxable child", domain.SurchargeModeStacking, domain.TaxTreatmentExempt, "child", 0, 130},
+		// Override: the parent's 10% wins over the job's 20%, so 100 becomes 110.
+		{"override, inherits exempt parent", domain.SurchargeModeOverride, domain.TaxTreatmentDefault, "parent", 0, 110},
+		{"override, item taxable in exempt parent", domain.SurchargeModeOverride, domain.TaxTreatmentTaxable, "parent", 110, 0},
+		{"override, inherits taxable child", domain.SurchargeModeOverride, domain.TaxTreatmentDefault, "child", 110, 0},
+		{"override, item exempt in taxable child", domain.SurchargeModeOverride, domain.TaxTreatmentExempt, "child", 0, 110},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &domain.Job{SurchargePercent: 20, SurchargeMode: tt.mode, TaxPercent: 5}
+			items := []*domain.LineItem{
+				{CategoryID: tt.category, Type: domain.LineItemTypeMaterial, Quantity: 1, UnitPrice: 100, TaxTreatment: tt.item},
+			}
+
+			got := domain.CalculateJobTotal(job, categories, items)
+			if !floatEquals(got.TaxableBase, tt.wantTaxable) || !floatEquals(got.ExemptBase, tt.wantExempt) {
+				t.Errorf("bases = taxable %v, exempt %v; want %v, %v", got.TaxableBase, got.ExemptBase, tt.wantTaxable, tt.wantExempt)
+			}
+			wantTax := tt.wantTaxable * 0.05
+			if !floatEquals(got.TaxTotal, wantTax) {
+				t.Errorf("TaxTotal = %v, want %v", got.TaxTotal, wantTax)
+			}
+			if !floatEquals(got.GrandTotal, tt.wantTaxable+tt.wantExempt+wantTax) {
+				t.Errorf("GrandTotal = %v, want %v", got.GrandTotal, tt.wantTaxable+tt.wantExempt+wantTax)
+			}
+			if !floatEquals(got.SurchargeTotal, tt.wantTaxable+tt.wantExempt-100) {
+				t.Errorf("SurchargeTotal = %v includes tax", got.SurchargeTotal)
+			}
+		})
+	}
+}
+
+func TestCalculateJobTotal_NoTaxRate(t *testing.T) {
+	job := &domain.Job{SurchargeMode: domain.SurchargeModeStacking}
+	items := []*domain.LineItem{
+		{CategoryID: "cat", Type: domain.LineItemTypeMaterial, Quantity: 1, UnitPrice: 100, TaxTreatment: domain.TaxTreatmentExempt},
+		{CategoryID: "cat", Type: domain.LineItemTypeMaterial, Quantity: 1, UnitPrice: 50},
+	}
+
+	got := domain.CalculateJobTotal(job, []*domain.Category{{ID: "cat"}}, items)
+	if got.TaxableBase != 0 || got.ExemptBase != 0 || got.TaxTotal != 0 {
+		t.Errorf("tax fields = %v, %v, %v, want zero without a tax rate", got.TaxableBase, got.ExemptBase, got.TaxTotal)
+	}
+	if got.GrandTotal != 150 {
+		t.Errorf("GrandTotal = %v, want 150", got.GrandTotal)
+	}
+}
@@ -10,15 +10,57 @@ const (
 	SurchargeModeOverride SurchargeMode = "override"
 )
 
-// LineItemType distinguishes materials, labor, and equipment.
+// LineItemType distinguishes materials, labor, equipment, subcontracts, and fees.
 type LineItemType string
 
 const (
-	LineItemTypeMaterial  LineItemType = "material"
-	LineItemTypeLabor     LineItemType = "labor"
-	LineItemTypeEquipment LineItemType = "equipment"
+	LineItemTypeMaterial    LineItemType = "material"
+	LineItemTypeLabor       LineItemType = "labor"
+	LineItemTypeEquipment   LineItemType = "equipment"
+	LineItemTypeSubcontract LineItemType = "subcontract"
+	LineItemTypeFee         LineItemType = "fee"
 )
 
+// LineItemTypes lists every valid line item type in display order.
+var LineItemTypes = []LineItemType{
+	LineItemTypeMaterial,
+	LineItemTypeLabor,
+	LineItemTypeEquipment,
+	LineItemTypeSubcontract,
+	LineItemTypeFee,
+}
+
+// Valid reports whether t is a known line item type.
+func (t LineItemType) Valid() bool {
+	for _, v := range LineItemTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Orderable reports whether items of this type are physical goods that belong
+// on order lists and site material reports.
+func (t LineItemType) Orderable() bool {
+	return t == LineItemTypeMaterial || t == LineItemTypeEquipment
+}
+
+// TaxTreatment decides whether a line item is taxed. TaxTreatmentDefault
+// inherits the treatment of the item's category chain.
+type TaxTreatment string
+
+const (
+	TaxTreatmentDefault TaxTreatment = "default"
+	TaxTreatmentTaxable TaxTreatment = "taxable"
+	TaxTreatmentExempt  TaxTreatment = "exempt"
+)
+
+// Valid reports whether t is a known tax treatment.
+func (t TaxTreatment) Valid() bool {
+	return t == TaxTreatmentDefault || t == TaxTreatmentTaxable || t == TaxTreatmentExempt
+}
+
 // Settings holds application-wide defaults.
 type Settings struct {
 	ID                      string        `json:"id"`
@@ -33,43 +75,56 @@ type Job struct {
 	CustomerName     *string       `json:"customer_name,omitempty"`
 	SurchargePercent float64       `json:"surcharge_percent"`
 	SurchargeMode    SurchargeMode `json:"surcharge_mode"`
+	TaxPercent       float64       `json:"tax_percent"`
 	CreatedAt        time.Time     `json:"created_at"`
+	// SurchargeCredits marks up credits like any other item. It comes from
+	// the app settings rather than the job.
+	SurchargeCredits bool `json:"-"`
 }
 
 // Category represents an organizational grouping within a job.
 type Category struct {
-	ID               string   `json:"id"`
-	JobID            string   `json:"job_id"`
-	ParentID         *string  `json:"parent_id,omitempty"`
-	Name             string   `json:"name"`
-	SurchargePercent *float64 `json:"surcharge_percent,omitempty"`
-	SortOrder        int      `json:"sort_order"`
-}
-
-// LineItem represents an individual material or labor entry.
-type LineItem struct {
 	ID               string       `json:"id"`
-	CategoryID       string       `json:"category_id"`
-	Type             LineItemType `json:"type"`
+	JobID            string       `json:"job_id"`
+	ParentID         *string      `json:"parent_id,omitempty"`
 	Name             string       `json:"name"`
-	Description      *string      `json:"description,omitempty"`
-	Quantity         float64      `json:"quantity"`
-	Unit             string       `json:"unit"`
-	UnitPrice        float64      `json:"unit_price"`
 	SurchargePercent *float64     `json:"surcharge_percent,omitempty"`
+	TaxTreatment     TaxTreatment `json:"tax_treatment"`
 	SortOrder        int          `json:"sort_order"`
 }
 
-// BasePrice calculates quantity * unit_price.
+// LineItem represents an individual material or labor entry.
+type LineItem struct {
+	ID                  string       `json:"id"`
+	CategoryID          string       `json:"category_id"`
+	Type                LineItemType `json:"type"`
+	Name                string       `json:"name"`
+	Description         *string      `json:"description,omitempty"`
+	Quantity            float64      `json:"quantity"`
+	Unit                string       `json:"unit"`
+	UnitPrice           float64      `json:"unit_price"`
+	SurchargePercent    *float64     `json:"surcharge_percent,omitempty"`
+	SortOrder           int          `json:"sort_order"`
+	ExemptFromSurcharge bool         `json:"exempt_from_surcharge"`
+	IsCredit            bool         `json:"is_credit"`
+	TaxTreatment        TaxTreatment `json:"tax_treatment"`
+}
+
+// BasePrice calculates quantity * unit_price. Credits have a negative unit
+// price, so their base price is negative too.
 func (li *LineItem) BasePrice() float64 {
 	return li.Quantity * li.UnitPrice
 }
 
 // CommonUnits returns suggested units for the UI.
 var CommonUnits = struct {
-	Material []string
-	Labor    []string
+	Material    []string
+	Labor       []string
+	Subcontract []string
+	Fee         []string
 }{
-	Material: []string{"ea", "sqft", "lnft", "bundle", "box", "bag", "gal", "sheet"},
-	Labor:    []string{"hr", "day", "job", "sqft"},
+	Material:    []string{"ea", "sqft", "lnft", "bundle", "box", "bag", "gal", "sheet"},
+	Labor:       []string{"hr", "day", "job", "sqft"},
+	Subcontract: []string{"job", "sqft", "lnft"},
+	Fee:         []string{"ea", "job"},
 }
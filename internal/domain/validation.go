@@ -86,15 +86,18 @@ func ValidateCategoryDepth(parentDepth int) *ValidationError {
 
 // LineItemInput represents input for creating or updating a line item.
 type LineItemInput struct {
-	CategoryID       string       `json:"category_id"`
-	Type             LineItemType `json:"type"`
-	Name             string       `json:"name"`
-	Description      *string      `json:"description"`
-	Quantity         float64      `json:"quantity"`
-	Unit             string       `json:"unit"`
-	UnitPrice        float64      `json:"unit_price"`
-	SurchargePercent *float64     `json:"surcharge_percent"`
-	SortOrder        int          `json:"sort_order"`
+	CategoryID          string       `json:"category_id"`
+	Type                LineItemType `json:"type"`
+	Name                string       `json:"name"`
+	Description         *string      `json:"description"`
+	Quantity            float64      `json:"quantity"`
+	Unit                string       `json:"unit"`
+	UnitPrice           float64      `json:"unit_price"`
+	SurchargePercent    *float64     `json:"surcharge_percent"`
+	SortOrder           int          `json:"sort_order"`
+	ExemptFromSurcharge bool         `json:"exempt_from_surcharge"`
+	IsCredit            bool         `json:"is_credit"`
+	TaxTreatment        TaxTreatment `json:"tax_treatment"`
 }
 
 // Validate checks the line item input for errors.
@@ -113,10 +116,10 @@ func (i *LineItemInput) Validate() []ValidationError {
 		})
 	}
 
-	if i.Type != LineItemTypeMaterial && i.Type != LineItemTypeLabor {
+	if !i.Type.Valid() {
 		errors = append(errors, ValidationError{
 			Field:   "type",
-			Message: "Type must be 'material' or 'labor'",
+			Message: "Type must be 'material', 'labor', 'equipment', 'subcontract', or 'fee'",
 		})
 	}
 
@@ -134,10 +137,23 @@ func (i *LineItemInput) Validate() []ValidationError {
 		})
 	}
 
-	if i.UnitPrice < 0 {
+	if i.TaxTreatment != "" && !i.TaxTreatment.Valid() {
+		errors = append(errors, ValidationError{
+			Field:   "tax_treatment",
+			Message: "Tax treatment must be 'default', 'taxable', or 'exempt'",
+		})
+	}
+
+	// Credits carry a negative unit price; anything else must be positive.
+	if i.IsCredit && i.UnitPrice > 0 {
+		errors = append(errors, ValidationError{
+			Field:   "unit_price",
+			Message: "Credit unit price cannot be positive",
+		})
+	} else if !i.IsCredit && i.UnitPrice < 0 {
 		errors = append(errors, ValidationError{
 			Field:   "unit_price",
-			Message: "Unit price cannot be negative",
+			Message: "Unit price cannot be negative unless the item is a credit",
 		})
 	}
 
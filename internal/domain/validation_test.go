@@ -196,6 +196,39 @@ func TestLineItemInput_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid equipment",
+			input: domain.LineItemInput{
+				Type:      domain.LineItemTypeEquipment,
+				Name:      "Skid Steer",
+				Quantity:  2,
+				Unit:      "day",
+				UnitPrice: 350,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid subcontract",
+			input: domain.LineItemInput{
+				Type:      domain.LineItemTypeSubcontract,
+				Name:      "Drywall Sub",
+				Quantity:  1,
+				Unit:      "job",
+				UnitPrice: 4200,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid fee",
+			input: domain.LineItemInput{
+				Type:      domain.LineItemTypeFee,
+				Name:      "Building Permit",
+				Quantity:  1,
+				Unit:      "ea",
+				UnitPrice: 250,
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty name",
 			input: domain.LineItemInput{
@@ -268,6 +301,31 @@ func TestLineItemInput_Validate(t *testing.T) {
 			wantErr:   true,
 			wantField: "unit_price",
 		},
+		{
+			name: "credit with negative price",
+			input: domain.LineItemInput{
+				Type:      domain.LineItemTypeMaterial,
+				Name:      "Owner-supplied fixtures",
+				Quantity:  1,
+				Unit:      "ea",
+				UnitPrice: -150,
+				IsCredit:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "credit with positive price",
+			input: domain.LineItemInput{
+				Type:      domain.LineItemTypeMaterial,
+				Name:      "Owner-supplied fixtures",
+				Quantity:  1,
+				Unit:      "ea",
+				UnitPrice: 150,
+				IsCredit:  true,
+			},
+			wantErr:   true,
+			wantField: "unit_price",
+		},
 		{
 			name: "zero price allowed",
 			input: domain.LineItemInput{
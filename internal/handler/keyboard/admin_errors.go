@@ -0,0 +1,80 @@
+package keyboard
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+// errorLogSize is how many failed requests /admin/errors keeps.
+const errorLogSize = 100
+
+// requireAdmin checks the admin token and writes the error response when it
+// is missing or wrong. The admin pages don't exist until ADMIN_TOKEN is set.
+// The token is sent as a bearer token, as the password of HTTP basic auth so
+// a browser can prompt for it, or as the token field of a posted form; never
+// in the query string, which ends up in access logs and browser history.
+func (h *Handler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.config.AdminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	token := r.PostFormValue("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	} else if _, password, ok := r.BasicAuth(); ok {
+		token = password
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.config.AdminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Skalkaho admin"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// ListErrors shows the most recent panics and server errors with their
+// request IDs. The ref query parameter narrows the list to the requests whose
+// ID starts with it, so an error ref quoted by a user can be looked up.
+func (h *Handler) ListErrors(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	ref := strings.TrimSpace(r.URL.Query().Get("ref"))
+	entries := h.errorLog.Entries()
+	if ref != "" {
+		matching := make([]middleware.ErrorEntry, 0)
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.RequestID, ref) {
+				matching = append(matching, entry)
+			}
+		}
+		entries = matching
+	}
+
+	data := map[string]interface{}{
+		"Errors": entries,
+		"Ref":    ref,
+	}
+
+	if err := h.renderer.Render(w, "admin_errors", data); err != nil {
+		logger.Error("failed to render template", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// Metrics serves the app's counters, such as job_total_failures, as JSON.
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	expvar.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,70 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+func TestListErrors(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{AdminToken: "s3cret"})
+	errs := app.handler.ErrorLog()
+	errs.Record(middleware.ErrorEntry{RequestID: "7f3a-first", Method: "GET", Path: "/jobs/job-1", Status: 500, Message: "Failed to load job", Stack: "goroutine 1 [running]"})
+	errs.Record(middleware.ErrorEntry{RequestID: "b2c4-second", Method: "PUT", Path: "/settings", Status: 500})
+
+	if rec := app.get(t, "/admin/errors"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+
+	if rec := app.get(t, "/admin/errors?token=s3cret"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with token in the query string = %d, want 401", rec.Code)
+	}
+
+	body := adminGet(t, app, "/admin/errors", "s3cret").Body.String()
+	for _, want := range []string{"7f3a-first", "b2c4-second", "Failed to load job", "goroutine 1 [running]"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("errors page missing %q", want)
+		}
+	}
+
+	body = adminGet(t, app, "/admin/errors?ref=7f3a", "s3cret").Body.String()
+	if !strings.Contains(body, "7f3a-first") || strings.Contains(body, "b2c4-second") {
+		t.Errorf("ref filter should show only the matching error")
+	}
+}
+
+// adminGet requests an admin page the way a browser does after prompting for
+// the token: as the password of HTTP basic auth.
+func adminGet(t *testing.T, app *testApp, target, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.SetBasicAuth("admin", token)
+	return app.do(req)
+}
+
+func TestAdminPages_OffWithoutToken(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, target := range []string{"/admin/errors", "/admin/metrics"} {
+		if rec := app.get(t, target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404", target, rec.Code)
+		}
+	}
+	if rec := app.postForm(t, http.MethodPost, "/admin/import-budget/override", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("override status = %d, want 404", rec.Code)
+	}
+}
+
+func TestMetrics_BearerToken(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{AdminToken: "s3cret"})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/metrics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	if rec := app.do(req); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
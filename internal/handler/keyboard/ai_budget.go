@@ -0,0 +1,154 @@
+package keyboard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/claude"
+)
+
+// budgetWarnPercent is how much of the monthly import budget is used before
+// the price import page warns about it.
+const budgetWarnPercent = 80
+
+// Units the monthly import budget can be set in.
+const (
+	budgetDollars = "dollars"
+	budgetTokens  = "tokens"
+)
+
+// AIBudget is this month's price import API spend against the budget in
+// settings.
+type AIBudget struct {
+	Limit     float64 // 0 when there is no budget
+	Unit      string  // budgetDollars or budgetTokens
+	Used      float64
+	Percent   float64
+	ResetsOn  time.Time
+	Override  bool // an admin has let the next upload through
+	Warn      bool // at least budgetWarnPercent used
+	Exhausted bool
+}
+
+// Describe formats an amount in the budget's unit.
+func (b AIBudget) Describe(amount float64) string {
+	if b.Unit == budgetTokens {
+		return fmt.Sprintf("%.0f tokens", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// budgetLocation is the zone the budget month is counted in.
+func (h *Handler) budgetLocation() *time.Location {
+	if h.config.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(h.config.Timezone)
+	if err != nil {
+		h.logger.Warn("unknown timezone, using local time", "timezone", h.config.Timezone, "error", err)
+		return time.Local
+	}
+	return loc
+}
+
+// aiBudget totals the price import usage recorded since the start of the
+// month and compares it with the budget.
+func (h *Handler) aiBudget(ctx context.Context, now time.Time) (AIBudget, error) {
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		return AIBudget{}, err
+	}
+	start, next := domain.BudgetPeriod(now, h.budgetLocation())
+	budget := AIBudget{
+		Limit:    settings.AiBudget,
+		Unit:     settings.AiBudgetUnit,
+		ResetsOn: next,
+		Override: settings.AiBudgetOverride,
+	}
+	if budget.Limit <= 0 {
+		return budget, nil
+	}
+
+	// created_at is stored in UTC by SQLite's datetime('now').
+	row, err := h.queries.GetImportUsageSince(ctx, start.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return AIBudget{}, err
+	}
+	usage := claude.Usage{InputTokens: row.InputTokens, OutputTokens: row.OutputTokens}
+	if budget.Unit == budgetTokens {
+		budget.Used = float64(usage.Tokens())
+	} else {
+		budget.Used = usage.Dollars()
+	}
+	budget.Percent = budget.Used / budget.Limit * 100
+	budget.Warn = budget.Percent >= budgetWarnPercent
+	budget.Exhausted = budget.Used >= budget.Limit
+	return budget, nil
+}
+
+// importAllowed reports whether a new price import may call the API. Once
+// the month's budget is used up only an admin override lets one more upload
+// through, and that upload uses the override up. Anything that starts
+// imports checks this before creating the import records.
+func (h *Handler) importAllowed(ctx context.Context, now time.Time) (bool, AIBudget, error) {
+	budget, err := h.aiBudget(ctx, now)
+	if err != nil || !budget.Exhausted {
+		return err == nil, budget, err
+	}
+	n, err := h.queries.UseBudgetOverride(ctx)
+	if err != nil {
+		return false, budget, err
+	}
+	return n > 0, budget, nil
+}
+
+// budgetBlockedMessage explains why an upload was refused.
+func budgetBlockedMessage(b AIBudget) string {
+	return fmt.Sprintf("This month's price import budget of %s is used up, so the file was not imported. It resets on %s. An admin can allow one more import.",
+		b.Describe(b.Limit), b.ResetsOn.Format("January 2"))
+}
+
+// recordImportUsage stores the tokens an import's API call used. A failure is
+// logged rather than failing the import.
+func (h *Handler) recordImportUsage(ctx context.Context, importID string, usage claude.Usage, logger *slog.Logger) {
+	if usage.Tokens() == 0 {
+		return
+	}
+	if err := h.queries.CreateImportUsage(ctx, repository.CreateImportUsageParams{
+		ImportID:     importID,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+	}); err != nil {
+		logger.Error("failed to record import usage", "error", err, "import_id", importID)
+	}
+}
+
+// AllowBudgetOverride lets one more price import through after the monthly
+// budget is used up.
+func (h *Handler) AllowBudgetOverride(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if err := h.queries.AllowBudgetOverride(ctx); err != nil {
+		logger.Error("failed to allow budget override", "error", err)
+		http.Error(w, "Failed to allow import", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("allowed one price import over budget")
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/price-import")
+		return
+	}
+	http.Redirect(w, r, "/price-import", http.StatusSeeOther)
+}
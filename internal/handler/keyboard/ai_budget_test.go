@@ -0,0 +1,136 @@
+package keyboard_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+)
+
+// uploadPriceFiles posts files to the price import upload. Text files fail
+// validation, so an upload of two of them creates failed import records
+// without calling the API.
+func uploadPriceFiles(t *testing.T, app *testApp, filenames ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range filenames {
+		part, err := mw.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatalf("creating form file: %v", err)
+		}
+		_, _ = part.Write([]byte("not a workbook"))
+	}
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/price-import/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return app.do(req)
+}
+
+func TestImportBudget(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{AnthropicAPIKey: "test-key", Timezone: "UTC", AdminToken: "secret"})
+	app.exec(t, `UPDATE settings SET ai_budget = 10, ai_budget_unit = 'dollars'`)
+
+	// $6 spent earlier this month, plus usage from before the month that no
+	// longer counts: 1M input tokens at $3 and 200k output tokens at $15.
+	app.exec(t, `INSERT INTO import_usage (import_id, input_tokens, output_tokens, created_at) VALUES
+		('imp-1', 1000000, 200000, datetime('now')),
+		('imp-old', 5000000, 1000000, '2000-01-15 12:00:00')`)
+	if body := app.get(t, "/price-import").Body.String(); strings.Contains(body, `id="import-budget"`) {
+		t.Errorf("warning shown at 60%% of the budget")
+	}
+
+	// Crossing 80% mid-month warns but still allows uploads.
+	app.exec(t, `INSERT INTO import_usage (import_id, input_tokens, output_tokens) VALUES ('imp-2', 500000, 100000)`)
+	body := app.get(t, "/price-import").Body.String()
+	if !strings.Contains(body, `id="import-budget"`) || !strings.Contains(body, "90% of this month's import budget is used") || !strings.Contains(body, "$9.00 of $10.00") {
+		t.Errorf("budget warning missing")
+	}
+	if rec := uploadPriceFiles(t, app, "a.txt", "b.txt"); rec.Code != http.StatusSeeOther {
+		t.Fatalf("upload under budget status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports`); n != 2 {
+		t.Fatalf("imports = %d, want 2", n)
+	}
+
+	// Once the budget is used up, uploads are refused before any import record
+	// is created.
+	app.exec(t, `INSERT INTO import_usage (import_id, input_tokens, output_tokens) VALUES ('imp-3', 500000, 100000)`)
+	rec := uploadPriceFiles(t, app, "c.txt", "d.txt")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("blocked upload status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "budget of $10.00 is used up") {
+		t.Errorf("blocked upload missing the budget message")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports`); n != 2 {
+		t.Errorf("blocked upload created imports")
+	}
+
+	// An admin override lets exactly one more upload through.
+	if rec := app.postForm(t, http.MethodPost, "/admin/import-budget/override", url.Values{"token": {"secret"}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("override status = %d, want 303", rec.Code)
+	}
+	if body := app.get(t, "/price-import").Body.String(); !strings.Contains(body, "The next upload is allowed by an admin") {
+		t.Errorf("override not shown")
+	}
+	if rec := uploadPriceFiles(t, app, "e.txt", "f.txt"); rec.Code != http.StatusSeeOther {
+		t.Fatalf("overridden upload status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports`); n != 4 {
+		t.Errorf("overridden upload imports = %d, want 4", n)
+	}
+	if rec := uploadPriceFiles(t, app, "g.txt", "h.txt"); !strings.Contains(rec.Body.String(), "is used up") {
+		t.Errorf("override allowed a second upload")
+	}
+
+	// Raising the budget, or counting it in tokens, is picked up right away.
+	app.exec(t, `UPDATE settings SET ai_budget = 10000000, ai_budget_unit = 'tokens'`)
+	if body := app.get(t, "/price-import").Body.String(); strings.Contains(body, `id="import-budget"`) {
+		t.Errorf("warning shown at 24%% of a token budget")
+	}
+}
+
+func TestImportBudget_OverrideNeedsAdmin(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{AdminToken: "secret"})
+
+	if rec := app.postForm(t, http.MethodPost, "/admin/import-budget/override", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPost, "/admin/import-budget/override?token=secret", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with token in the query string = %d, want 401", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPost, "/admin/import-budget/override", url.Values{"token": {"secret"}}); rec.Code != http.StatusSeeOther {
+		t.Errorf("status with token = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM settings WHERE ai_budget_override = 1`); n != 1 {
+		t.Errorf("override not stored")
+	}
+}
+
+func TestUpdateSettings_ImportBudget(t *testing.T) {
+	app := newTestApp(t)
+
+	form := url.Values{"default_surcharge_mode": {"stacking"}, "ai_budget": {"25"}, "ai_budget_unit": {"tokens"}}
+	app.postForm(t, http.MethodPut, "/settings", form)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM settings WHERE ai_budget = 25 AND ai_budget_unit = 'tokens'`); n != 1 {
+		t.Errorf("budget not saved")
+	}
+
+	form.Set("ai_budget_unit", "euros")
+	if rec := app.postForm(t, http.MethodPut, "/settings", form); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad unit status = %d, want 400", rec.Code)
+	}
+	form.Set("ai_budget_unit", "dollars")
+	form.Set("ai_budget", "-1")
+	if rec := app.postForm(t, http.MethodPut, "/settings", form); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative budget status = %d, want 400", rec.Code)
+	}
+}
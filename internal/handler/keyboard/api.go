@@ -0,0 +1,264 @@
+package keyboard
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// APIJobTotals is the response body of GET /api/v1/jobs/{id}/totals.
+// Field names are a contract with external dashboards; don't rename them.
+type APIJobTotals struct {
+	JobID      string `json:"job_id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	ClientName string `json:"client_name"`
+	UpdatedAt  string `json:"updated_at"`
+	// CustomFields maps each filled-in job field's label to its value.
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	domain.JobTotal
+}
+
+// APITotalsSummary is the response body of GET /api/v1/totals/summary.
+// Field names are a contract with external dashboards; don't rename them.
+type APITotalsSummary struct {
+	Status   string `json:"status"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	JobCount int    `json:"job_count"`
+	domain.JobTotal
+}
+
+// checkAPIAuth checks the API token, sent either as a bearer token or as the
+// token query parameter for spreadsheet fetches that can't set headers. The
+// API is off until API_TOKEN is set, so every request is refused without one.
+func (h *Handler) checkAPIAuth(r *http.Request) bool {
+	if h.config.APIToken == "" {
+		return false
+	}
+
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+
+	// Use constant-time comparison to prevent timing attacks
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.config.APIToken)) == 1
+}
+
+// GetAPIJobTotals returns a job's totals as JSON.
+func (h *Handler) GetAPIJobTotals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	if !h.checkAPIAuth(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	var clientName string
+	if job.ClientID.Valid {
+		if client, err := h.queries.GetClient(ctx, job.ClientID.String); err == nil {
+			clientName = client.Name
+		}
+	} else if job.CustomerName.Valid {
+		clientName = job.CustomerName.String
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	// The client name and settings are not covered by the job's updated_at.
+	etag := apiETag(job.ID, job.UpdatedAt, clientName, totalsSettingsTag(settings))
+	if notModified(w, r, etag) {
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	fields, err := h.queries.ListJobCustomFieldValues(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list job custom fields", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	totals := APIJobTotals{
+		JobID:      job.ID,
+		Name:       job.Name,
+		Status:     job.Status,
+		ClientName: clientName,
+		UpdatedAt:  job.UpdatedAt,
+		JobTotal:   h.calculateTotals(job, settings, categories, lineItems),
+	}
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		if totals.CustomFields == nil {
+			totals.CustomFields = make(map[string]string)
+		}
+		totals.CustomFields[field.Label] = field.Value
+	}
+
+	writeJSON(w, totals)
+}
+
+// GetAPITotalsSummary returns the summed totals of every job matching the
+// status filter that was created between from and to (inclusive dates in
+// YYYY-MM-DD form). All filters are optional.
+func (h *Handler) GetAPITotalsSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if !h.checkAPIAuth(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	var toDate string
+	if from != "" {
+		if _, err := time.Parse(dateLayout, from); err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if to != "" {
+		end, err := time.Parse(dateLayout, to)
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		toDate = end.AddDate(0, 0, 1).Format(dateLayout)
+	}
+
+	jobs, err := h.queries.ListJobsCreatedBetween(ctx, repository.ListJobsCreatedBetweenParams{
+		Status:   status,
+		FromDate: from,
+		ToDate:   toDate,
+	})
+	if err != nil {
+		logger.Error("failed to list jobs", "error", err)
+		http.Error(w, "Failed to load jobs", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	parts := []string{status, from, to, totalsSettingsTag(settings)}
+	for _, job := range jobs {
+		parts = append(parts, job.ID, job.UpdatedAt)
+	}
+	etag := apiETag(parts...)
+	if notModified(w, r, etag) {
+		return
+	}
+
+	summary := APITotalsSummary{Status: status, From: from, To: to, JobCount: len(jobs)}
+	for _, job := range jobs {
+		categories, err := h.queries.ListCategoriesByJob(ctx, job.ID)
+		if err != nil {
+			logger.Error("failed to list categories", "error", err)
+			http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+			return
+		}
+		lineItems, err := h.queries.ListLineItemsByJob(ctx, job.ID)
+		if err != nil {
+			logger.Error("failed to list line items", "error", err)
+			http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+			return
+		}
+
+		totals := h.calculateTotals(job, settings, categories, lineItems)
+		summary.Subtotal += totals.Subtotal
+		summary.SurchargeTotal += totals.SurchargeTotal
+		summary.GrandTotal += totals.GrandTotal
+		summary.MaterialSubtotal += totals.MaterialSubtotal
+		summary.LaborSubtotal += totals.LaborSubtotal
+		summary.EquipmentSubtotal += totals.EquipmentSubtotal
+		summary.SubcontractSubtotal += totals.SubcontractSubtotal
+		summary.FeeSubtotal += totals.FeeSubtotal
+		summary.TaxableBase += totals.TaxableBase
+		summary.ExemptBase += totals.ExemptBase
+		summary.TaxTotal += totals.TaxTotal
+	}
+
+	writeJSON(w, summary)
+}
+
+// totalsSettingsTag names the settings that change totals, for ETags built
+// from a job's updated_at, which settings changes don't touch.
+func totalsSettingsTag(settings repository.Setting) string {
+	return "surcharge_credits=" + strconv.FormatBool(settings.SurchargeCredits)
+}
+
+// apiETag builds a strong ETag from the values a response depends on.
+func apiETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the ETag and caching headers and, if the client already
+// has this version, responds 304 and reports true.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,185 @@
+package keyboard_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+)
+
+func decodeJSONObject(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func seedAPIJobs(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('client-1', 'Acme Builders')`)
+	app.exec(t, `INSERT INTO jobs (id, name, status, surcharge_percent, client_id, created_at) VALUES
+		('job-1', 'Garage', 'accepted', 10, 'client-1', '2026-03-05 09:00:00'),
+		('job-2', 'Deck', 'accepted', 0, NULL, '2026-04-10 09:00:00'),
+		('job-3', 'Shed', 'draft', 0, NULL, '2026-03-06 09:00:00')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing'), ('cat-2', 'job-2', 'Decking'), ('cat-3', 'job-3', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 100, 'ea', 3),
+		('item-2', 'cat-1', 'labor', 'Framer', 10, 'hr', 50),
+		('item-3', 'cat-2', 'material', 'Deck Board', 10, 'ea', 20),
+		('item-4', 'cat-3', 'material', 'Siding', 10, 'ea', 99)`)
+}
+
+func TestAPIJobTotals_Shape(t *testing.T) {
+	app := newTestApp(t)
+	seedAPIJobs(t, app)
+
+	rec := app.apiGet(t, "/api/v1/jobs/job-1/totals")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := decodeJSONObject(t, rec)
+
+	// These field names are a contract with external dashboards.
+	want := []string{
+		"client_name", "equipment_subtotal", "fee_subtotal", "grand_total", "job_id",
+		"labor_subtotal", "material_subtotal", "name", "status", "subcontract_subtotal",
+		"subtotal", "surcharge_total", "updated_at",
+	}
+	if got := sortedKeys(body); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+
+	checks := map[string]interface{}{
+		"job_id":            "job-1",
+		"name":              "Garage",
+		"status":            "accepted",
+		"client_name":       "Acme Builders",
+		"subtotal":          800.0,
+		"surcharge_total":   80.0,
+		"grand_total":       880.0,
+		"material_subtotal": 330.0,
+		"labor_subtotal":    550.0,
+	}
+	for field, want := range checks {
+		if body[field] != want {
+			t.Errorf("%s = %v, want %v", field, body[field], want)
+		}
+	}
+}
+
+func TestAPITotalsSummary_Shape(t *testing.T) {
+	app := newTestApp(t)
+	seedAPIJobs(t, app)
+
+	rec := app.apiGet(t, "/api/v1/totals/summary?status=accepted&from=2026-03-01&to=2026-03-31")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := decodeJSONObject(t, rec)
+
+	want := []string{
+		"equipment_subtotal", "fee_subtotal", "from", "grand_total", "job_count",
+		"labor_subtotal", "material_subtotal", "status", "subcontract_subtotal",
+		"subtotal", "surcharge_total", "to",
+	}
+	if got := sortedKeys(body); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+	if body["job_count"] != 1.0 || body["grand_total"] != 880.0 {
+		t.Errorf("job_count = %v, grand_total = %v, want 1 and 880", body["job_count"], body["grand_total"])
+	}
+
+	rec = app.apiGet(t, "/api/v1/totals/summary?status=accepted")
+	body = decodeJSONObject(t, rec)
+	if body["job_count"] != 2.0 || body["grand_total"] != 1080.0 {
+		t.Errorf("all accepted: job_count = %v, grand_total = %v, want 2 and 1080", body["job_count"], body["grand_total"])
+	}
+
+	if rec := app.apiGet(t, "/api/v1/totals/summary?from=March"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid date status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAPIJobTotals_ETag(t *testing.T) {
+	app := newTestApp(t)
+	seedAPIJobs(t, app)
+	// Pin updated_at well before the edit below, so the edit always moves it.
+	app.exec(t, `UPDATE jobs SET updated_at = '2026-03-05 09:00:00.000' WHERE id = 'job-1'`)
+
+	rec := app.apiGet(t, "/api/v1/jobs/job-1/totals")
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/totals", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	req.Header.Set("If-None-Match", etag)
+	if rec := app.do(req); rec.Code != http.StatusNotModified {
+		t.Fatalf("unchanged job status = %d, want 304", rec.Code)
+	}
+
+	app.exec(t, `UPDATE line_items SET quantity = 200 WHERE id = 'item-1'`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/totals", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	req.Header.Set("If-None-Match", etag)
+	rec = app.do(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("edited job status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Error("ETag did not change after an item was edited")
+	}
+}
+
+func TestAPI_RequiresToken(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{APIToken: "s3cret"})
+	seedAPIJobs(t, app)
+
+	if rec := app.get(t, "/api/v1/jobs/job-1/totals"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token status = %d, want 401", rec.Code)
+	}
+	if rec := app.get(t, "/api/v1/totals/summary?token=wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token status = %d, want 401", rec.Code)
+	}
+	if rec := app.get(t, "/api/v1/totals/summary?token=s3cret"); rec.Code != http.StatusOK {
+		t.Errorf("query token status = %d, want 200", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/totals", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	if rec := app.do(req); rec.Code != http.StatusOK {
+		t.Errorf("bearer token status = %d, want 200", rec.Code)
+	}
+}
+
+func TestAPI_DisabledWithoutToken(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{})
+	seedAPIJobs(t, app)
+
+	if rec := app.get(t, "/api/v1/jobs/job-1/totals"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("job totals status = %d, want 401", rec.Code)
+	}
+	if rec := app.get(t, "/api/v1/totals/summary?token="); rec.Code != http.StatusUnauthorized {
+		t.Errorf("summary status = %d, want 401", rec.Code)
+	}
+}
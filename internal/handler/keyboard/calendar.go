@@ -0,0 +1,170 @@
+package keyboard
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// dateLayout is the storage format for job dates such as expires_at and follow_up_at.
+const dateLayout = "2006-01-02"
+
+// CalendarEntry is a job plotted on a calendar day.
+type CalendarEntry struct {
+	Job  repository.Job
+	Kind string // "expires" or "follow_up"
+}
+
+// CalendarDay is a single cell in the month grid.
+type CalendarDay struct {
+	Date    time.Time
+	InMonth bool
+	IsToday bool
+	Entries []CalendarEntry
+}
+
+// GetCalendar shows a month grid of quotes plotted by expiry and follow-up dates.
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	status := r.URL.Query().Get("status")
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local)
+	if m, err := time.ParseInLocation("2006-01", r.URL.Query().Get("month"), time.Local); err == nil {
+		month = m
+	}
+
+	// The grid runs from the Sunday on or before the 1st to the Saturday on or after the last day.
+	gridStart := month.AddDate(0, 0, -int(month.Weekday()))
+	monthEnd := month.AddDate(0, 1, 0)
+	gridEnd := monthEnd.AddDate(0, 0, (7-int(monthEnd.Weekday()))%7)
+
+	start := sql.NullString{String: gridStart.Format(dateLayout), Valid: true}
+	end := sql.NullString{String: gridEnd.Format(dateLayout), Valid: true}
+
+	expiring, err := h.queries.ListJobsExpiringBetween(ctx, repository.ListJobsExpiringBetweenParams{
+		StartDate: start,
+		EndDate:   end,
+		Status:    status,
+	})
+	if err != nil {
+		logger.Error("failed to list expiring jobs", "error", err)
+		http.Error(w, "Failed to load calendar", http.StatusInternalServerError)
+		return
+	}
+
+	followUps, err := h.queries.ListJobsFollowUpBetween(ctx, repository.ListJobsFollowUpBetweenParams{
+		StartDate: start,
+		EndDate:   end,
+		Status:    status,
+	})
+	if err != nil {
+		logger.Error("failed to list follow-ups", "error", err)
+		http.Error(w, "Failed to load calendar", http.StatusInternalServerError)
+		return
+	}
+
+	overdue, err := h.queries.ListOverdueFollowUps(ctx, repository.ListOverdueFollowUpsParams{
+		Today:  sql.NullString{String: today.Format(dateLayout), Valid: true},
+		Status: status,
+	})
+	if err != nil {
+		logger.Error("failed to list overdue follow-ups", "error", err)
+		http.Error(w, "Failed to load calendar", http.StatusInternalServerError)
+		return
+	}
+
+	entries := make(map[string][]CalendarEntry)
+	for _, job := range followUps {
+		key := dateKey(job.FollowUpAt.String)
+		entries[key] = append(entries[key], CalendarEntry{Job: job, Kind: "follow_up"})
+	}
+	for _, job := range expiring {
+		key := dateKey(job.ExpiresAt.String)
+		entries[key] = append(entries[key], CalendarEntry{Job: job, Kind: "expires"})
+	}
+
+	var weeks [][]CalendarDay
+	for day := gridStart; day.Before(gridEnd); day = day.AddDate(0, 0, 7) {
+		week := make([]CalendarDay, 7)
+		for i := range week {
+			d := day.AddDate(0, 0, i)
+			week[i] = CalendarDay{
+				Date:    d,
+				InMonth: d.Month() == month.Month(),
+				IsToday: d.Equal(today),
+				Entries: entries[d.Format(dateLayout)],
+			}
+		}
+		weeks = append(weeks, week)
+	}
+
+	data := map[string]interface{}{
+		"Month":     month,
+		"PrevMonth": month.AddDate(0, -1, 0).Format("2006-01"),
+		"NextMonth": month.AddDate(0, 1, 0).Format("2006-01"),
+		"Weeks":     weeks,
+		"Overdue":   overdue,
+		"Status":    status,
+	}
+
+	if err := h.renderer.Render(w, "calendar", data); err != nil {
+		logger.Error("failed to render calendar", "error", err)
+	}
+}
+
+// UpdateJobFollowUp sets or clears a job's follow-up date.
+func (h *Handler) UpdateJobFollowUp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	followUpAt := sql.NullString{}
+	if fu := r.FormValue("follow_up_at"); fu != "" {
+		if _, err := time.Parse(dateLayout, fu); err != nil {
+			http.Error(w, "Invalid follow-up date", http.StatusBadRequest)
+			return
+		}
+		followUpAt = sql.NullString{String: fu, Valid: true}
+	}
+
+	if _, err := h.queries.UpdateJobFollowUp(ctx, repository.UpdateJobFollowUpParams{
+		FollowUpAt: followUpAt,
+		ID:         jobID,
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to update follow-up date", "error", err)
+		http.Error(w, "Failed to update follow-up date", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Follow-up date saved", "type": "success"}}`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
+}
+
+// dateKey trims a stored date or timestamp down to its YYYY-MM-DD day.
+func dateKey(s string) string {
+	if len(s) > len(dateLayout) {
+		return s[:len(dateLayout)]
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCalendar_PlotsFollowUpsAndExpiry(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, status, expires_at) VALUES ('job-1', 'Deck Rebuild', 'sent', '2026-03-20')`)
+
+	rec := app.postForm(t, http.MethodPut, "/jobs/job-1/follow-up", url.Values{"follow_up_at": {"2026-03-05"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("update follow-up status = %d, want 303", rec.Code)
+	}
+
+	rec = app.get(t, "/calendar?month=2026-03&status=sent")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("calendar status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+
+	if got := strings.Count(body, `href="/jobs/job-1"`); got < 2 {
+		t.Errorf("job plotted %d times, want follow-up and expiry entries", got)
+	}
+	if !strings.Contains(body, "month=2026-04&status=sent") {
+		t.Errorf("next month link does not preserve the status filter")
+	}
+
+	rec = app.get(t, "/calendar?month=2026-03&status=draft")
+	if strings.Contains(rec.Body.String(), `href="/jobs/job-1"`) {
+		t.Errorf("status filter did not exclude the sent job")
+	}
+}
+
+func TestUpdateJobFollowUp_RejectsInvalidDate(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Deck Rebuild')`)
+
+	rec := app.postForm(t, http.MethodPut, "/jobs/job-1/follow-up", url.Values{"follow_up_at": {"next tuesday"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -3,9 +3,12 @@ package keyboard
 import (
 	"bytes"
 	"database/sql"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/dukerupert/skalkaho/internal/domain"
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
 	"github.com/google/uuid"
@@ -24,8 +27,22 @@ func (h *Handler) GetCategoryMarkupForm(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	phases, err := h.jobPhases(ctx, category.JobID)
+	if err != nil {
+		logger.Error("failed to list job phases", "error", err)
+	}
+
+	inherited, err := h.categoryItemDefaults(ctx, category.ParentID.String)
+	if err != nil {
+		logger.Error("failed to load parent item defaults", "error", err)
+	}
+	inheritedType, inheritedUnit := inherited.Resolve("")
+
 	data := map[string]interface{}{
-		"Category": category,
+		"Category":      category,
+		"Phases":        phases,
+		"InheritedType": inheritedType,
+		"InheritedUnit": inheritedUnit,
 	}
 
 	var buf bytes.Buffer
@@ -110,7 +127,8 @@ func (h *Handler) UpdateCategoryName(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/categories/"+categoryID, http.StatusSeeOther)
 }
 
-// UpdateCategoryMarkup updates a category's markup percentage.
+// UpdateCategoryMarkup updates a category's markup percentage and, when sent,
+// its default tax treatment and phase.
 func (h *Handler) UpdateCategoryMarkup(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
@@ -128,13 +146,31 @@ func (h *Handler) UpdateCategoryMarkup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	surchargeStr := r.FormValue("surcharge_percent")
 	var surchargePercent sql.NullFloat64
-	if surchargeStr != "" {
-		val, _ := strconv.ParseFloat(surchargeStr, 64)
+	if strings.TrimSpace(r.FormValue("surcharge_percent")) != "" {
+		val, err := h.formPercent(r, "surcharge_percent", "Markup")
+		if err != nil {
+			h.numberError(w, r, err)
+			return
+		}
 		surchargePercent = sql.NullFloat64{Float64: val, Valid: true}
 	}
 
+	taxTreatment := domain.TaxTreatment(category.TaxTreatment)
+	if t := r.FormValue("tax_treatment"); t != "" {
+		taxTreatment = domain.TaxTreatment(t)
+		if !taxTreatment.Valid() {
+			http.Error(w, "Invalid tax treatment", http.StatusBadRequest)
+			return
+		}
+	}
+
+	defaults, hasDefaults := formItemDefaults(r)
+	if hasDefaults && defaults.Type.Valid && !domain.LineItemType(defaults.Type.String).Valid() {
+		http.Error(w, "Invalid item type", http.StatusBadRequest)
+		return
+	}
+
 	_, err = h.queries.UpdateCategory(ctx, repository.UpdateCategoryParams{
 		ID:               categoryID,
 		Name:             category.Name,
@@ -147,6 +183,40 @@ func (h *Handler) UpdateCategoryMarkup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if string(taxTreatment) != category.TaxTreatment {
+		if err := h.queries.UpdateCategoryTaxTreatment(ctx, repository.UpdateCategoryTaxTreatmentParams{
+			ID:           categoryID,
+			TaxTreatment: string(taxTreatment),
+		}); err != nil {
+			logger.Error("failed to update category tax treatment", "error", err)
+			http.Error(w, "Failed to update tax treatment", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if phase, ok := formPhase(r); ok && phase != category.Phase {
+		if err := h.queries.UpdateCategoryPhase(ctx, repository.UpdateCategoryPhaseParams{
+			ID:    categoryID,
+			Phase: phase,
+		}); err != nil {
+			logger.Error("failed to update category phase", "error", err)
+			http.Error(w, "Failed to update phase", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if hasDefaults && (defaults.Type != category.DefaultItemType || defaults.Unit != category.DefaultUnit) {
+		if err := h.queries.UpdateCategoryDefaults(ctx, repository.UpdateCategoryDefaultsParams{
+			ID:              categoryID,
+			DefaultItemType: defaults.Type,
+			DefaultUnit:     defaults.Unit,
+		}); err != nil {
+			logger.Error("failed to update category item defaults", "error", err)
+			http.Error(w, "Failed to update item defaults", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/categories/"+categoryID)
 		return
@@ -168,8 +238,14 @@ func (h *Handler) GetEditForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	phases, err := h.categoryPhases(ctx, item.CategoryID)
+	if err != nil {
+		logger.Error("failed to list job phases", "error", err)
+	}
+
 	data := map[string]interface{}{
-		"Item": item,
+		"Item":   item,
+		"Phases": phases,
 	}
 
 	var buf bytes.Buffer
@@ -201,12 +277,20 @@ func (h *Handler) UpdateLineItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	quantity, _ := strconv.ParseFloat(r.FormValue("quantity"), 64)
+	quantity, err := h.formQuantity(r, "quantity", "Quantity")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 	if quantity <= 0 {
 		quantity = 1
 	}
 
-	unitPrice, _ := strconv.ParseFloat(r.FormValue("unit_price"), 64)
+	unitPrice, err := h.formMoney(r, "unit_price", "Price")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 
 	name := r.FormValue("name")
 	if name == "" {
@@ -218,22 +302,52 @@ func (h *Handler) UpdateLineItem(w http.ResponseWriter, r *http.Request) {
 		unit = item.Unit
 	}
 
+	pricing := lineItemPricing(r, domain.LineItemInput{
+		Type:         domain.LineItemType(item.Type),
+		Name:         name,
+		Quantity:     quantity,
+		Unit:         unit,
+		UnitPrice:    unitPrice,
+		TaxTreatment: domain.TaxTreatment(item.TaxTreatment),
+	})
+	if errs := pricing.Validate(); len(errs) > 0 {
+		http.Error(w, errs[0].Message, http.StatusBadRequest)
+		return
+	}
+
+	phase := item.Phase
+	if p, ok := formPhase(r); ok {
+		phase = p
+	}
+
+	watch, err := h.watchTotal(ctx, item.CategoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
 	_, err = h.queries.UpdateLineItem(ctx, repository.UpdateLineItemParams{
-		ID:               itemID,
-		Type:             item.Type,
-		Name:             name,
-		Description:      item.Description,
-		Quantity:         quantity,
-		Unit:             unit,
-		UnitPrice:        unitPrice,
-		SurchargePercent: item.SurchargePercent,
-		SortOrder:        item.SortOrder,
+		ID:                  itemID,
+		Type:                item.Type,
+		Name:                name,
+		Description:         item.Description,
+		Quantity:            quantity,
+		Unit:                unit,
+		UnitPrice:           pricing.UnitPrice,
+		SurchargePercent:    item.SurchargePercent,
+		SortOrder:           item.SortOrder,
+		ExemptFromSurcharge: pricing.ExemptFromSurcharge,
+		IsCredit:            pricing.IsCredit,
+		TaxTreatment:        string(pricing.TaxTreatment),
+		Phase:               phase,
 	})
 	if err != nil {
 		logger.Error("failed to update line item", "error", err)
 		http.Error(w, "Failed to update line item", http.StatusInternalServerError)
 		return
 	}
+	if err := h.checkTotal(ctx, watch, totalAlertUpdate, itemID, &item); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/categories/"+item.CategoryID)
@@ -251,23 +365,30 @@ func (h *Handler) SearchItems(w http.ResponseWriter, r *http.Request) {
 	itemType := r.URL.Query().Get("type")
 	query := r.URL.Query().Get("q")
 
-	if query == "" {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		return
+	// An empty query still renders the partial so the swap clears any
+	// results left over from the previous keystroke.
+	var items []repository.ItemTemplate
+	if query != "" {
+		var err error
+		items, err = h.queries.SearchItemTemplatesByType(ctx, repository.SearchItemTemplatesByTypeParams{
+			Type:    itemType,
+			Column2: sql.NullString{String: query, Valid: true},
+		})
+		if err != nil {
+			logger.Error("failed to search items", "error", err)
+			http.Error(w, "Search failed", http.StatusInternalServerError)
+			return
+		}
 	}
 
-	items, err := h.queries.SearchItemTemplatesByType(ctx, repository.SearchItemTemplatesByTypeParams{
-		Type:    itemType,
-		Column2: sql.NullString{String: query, Valid: true},
-	})
-	if err != nil {
-		logger.Error("failed to search items", "error", err)
-		http.Error(w, "Search failed", http.StatusInternalServerError)
-		return
+	data := map[string]interface{}{
+		"Items": items,
+		"Query": query,
+		"Type":  itemType,
 	}
 
 	var buf bytes.Buffer
-	if err := h.renderer.RenderPartial(&buf, "search_results", items); err != nil {
+	if err := h.renderer.RenderPartial(&buf, "search_results", data); err != nil {
 		logger.Error("failed to render search results", "error", err)
 		http.Error(w, "Failed to render results", http.StatusInternalServerError)
 		return
@@ -323,12 +444,77 @@ func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get line items for this category only
-	categoryItems := make([]repository.LineItem, 0)
-	for _, item := range lineItems {
-		if item.CategoryID == categoryID {
-			categoryItems = append(categoryItems, item)
+	// Get line items for this category only, with their templates' current prices
+	itemRows, err := h.queries.ListLineItemsByCategoryWithTemplatePrice(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to list category items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+	categoryItems := newCategoryItems(itemRows, settings.CostMarginPercent)
+
+	belowCostCount := 0
+	for _, item := range categoryItems {
+		if item.BelowCost {
+			belowCostCount++
+		}
+	}
+
+	outOfDateCount := 0
+	for _, item := range categoryItems {
+		if item.OutOfDate {
+			outOfDateCount++
+		}
+	}
+
+	showOutOfDate := r.URL.Query().Get("stale") == "1"
+	if showOutOfDate {
+		filtered := make([]CategoryItem, 0, outOfDateCount)
+		for _, item := range categoryItems {
+			if item.OutOfDate {
+				filtered = append(filtered, item)
+			}
 		}
+		categoryItems = filtered
+	}
+
+	// Type chips list the types present among this category's own items.
+	typeCounts := make(map[string]int)
+	for _, item := range categoryItems {
+		typeCounts[item.Type]++
+	}
+	typeFilter := r.URL.Query().Get("type")
+	if !domain.LineItemType(typeFilter).Valid() {
+		typeFilter = ""
+	}
+	var typeFilterTotal domain.CategoryTotal
+	if typeFilter != "" {
+		filtered := make([]CategoryItem, 0, typeCounts[typeFilter])
+		for _, item := range categoryItems {
+			if item.Type == typeFilter {
+				filtered = append(filtered, item)
+			}
+		}
+		categoryItems = filtered
+
+		// Total the shown items with the same markup the category total uses.
+		shownIDs := make(map[string]bool, len(categoryItems))
+		for _, item := range categoryItems {
+			shownIDs[item.ID] = true
+		}
+		shown := make([]repository.LineItem, 0, len(categoryItems))
+		for _, item := range lineItems {
+			if shownIDs[item.ID] {
+				shown = append(shown, item)
+			}
+		}
+		typeFilterTotal = h.calculateCategoryTotal(categoryID, job, settings, categories, shown)
 	}
 
 	// Calculate depth and breadcrumbs
@@ -336,7 +522,12 @@ func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
 	breadcrumbs := h.getBreadcrumbs(categories, categoryID, job)
 
 	// Calculate category total
-	catTotal := h.calculateCategoryTotal(categoryID, job, categories, lineItems)
+	catTotal := h.calculateCategoryTotal(categoryID, job, settings, categories, lineItems)
+
+	// Items are added and edited here, so the minimum job total warning is
+	// shown here as well as on the job page.
+	jobTotals := h.calculateTotals(job, settings, categories, lineItems)
+	warning := minimumWarning(settings, jobTotals.GrandTotal, lineItems)
 
 	// Calculate totals for subcategories
 	type SubcategoryWithTotal struct {
@@ -345,27 +536,49 @@ func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
 	}
 	subcatsWithTotals := make([]SubcategoryWithTotal, len(subcategories))
 	for i, sub := range subcategories {
-		subTotal := h.calculateCategoryTotal(sub.ID, job, categories, lineItems)
+		subTotal := h.calculateCategoryTotal(sub.ID, job, settings, categories, lineItems)
 		subcatsWithTotals[i] = SubcategoryWithTotal{
 			Category: sub,
 			Total:    subTotal.Total,
 		}
 	}
 
-	// Build category tree for sidebar navigation
-	categoryTree := buildCategoryTree(categories)
+	totalAlert, err := h.openTotalAlert(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to get total change alert", "error", err)
+	}
+
+	shares, err := h.queries.ListCategoryShares(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to list category shares", "error", err)
+	}
+
+	commentCounts, err := h.openCommentCounts(ctx, job.ID, categories)
+	if err != nil {
+		logger.Error("failed to count item comments", "error", err)
+	}
 
 	data := map[string]interface{}{
 		"Job":               job,
 		"Category":          category,
 		"Subcategories":     subcatsWithTotals,
 		"Items":             categoryItems,
+		"OutOfDateCount":    outOfDateCount,
+		"BelowCostCount":    belowCostCount,
+		"CostMargin":        settings.CostMarginPercent,
+		"CommentCounts":     commentCounts,
+		"ShowOutOfDate":     showOutOfDate,
+		"TypeCounts":        typeCounts,
+		"TypeFilter":        typeFilter,
+		"TypeFilterTotal":   typeFilterTotal,
 		"Breadcrumbs":       breadcrumbs,
 		"Depth":             depth,
 		"CanAddSubcategory": canAddSubcategory(depth),
 		"CategoryTotal":     catTotal,
+		"TotalAlert":        totalAlert,
+		"MinimumWarning":    warning,
+		"Shares":            shares,
 		"SelectedIndex":     0,
-		"CategoryTree":      categoryTree,
 		"CurrentCategoryID": categoryID,
 	}
 
@@ -508,45 +721,116 @@ func (h *Handler) CreateLineItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	quantity, _ := strconv.ParseFloat(r.FormValue("quantity"), 64)
+	quantity, err := h.formQuantity(r, "quantity", "Quantity")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 	if quantity <= 0 {
 		quantity = 1
 	}
 
-	unitPrice, _ := strconv.ParseFloat(r.FormValue("unit_price"), 64)
+	unitPrice, err := h.formMoney(r, "unit_price", "Price")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 
 	name := r.FormValue("name")
 	if name == "" {
 		name = "New Item"
 	}
 
+	defaults, err := h.categoryItemDefaults(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to load item defaults", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	itemType, defaultUnit := defaults.Resolve(r.FormValue("type"))
 	unit := r.FormValue("unit")
 	if unit == "" {
-		unit = "ea"
+		unit = defaultUnit
 	}
 
-	itemType := r.FormValue("type")
-	if itemType == "" {
-		itemType = "material"
+	// Items picked from the autocomplete remember their template
+	var templateID sql.NullInt64
+	if id, err := strconv.ParseInt(r.FormValue("template_id"), 10, 64); err == nil {
+		templateID = sql.NullInt64{Int64: id, Valid: true}
 	}
 
-	_, err := h.queries.CreateLineItem(ctx, repository.CreateLineItemParams{
-		ID:               uuid.New().String(),
-		CategoryID:       categoryID,
-		Type:             itemType,
-		Name:             name,
-		Description:      sql.NullString{},
-		Quantity:         quantity,
-		Unit:             unit,
-		UnitPrice:        unitPrice,
-		SurchargePercent: sql.NullFloat64{},
-		SortOrder:        0,
+	// Equipment rented for a number of days is priced from its template's
+	// rate tiers, if it has any, with the periods billed as the description
+	var description sql.NullString
+	if itemType == string(domain.LineItemTypeEquipment) && templateID.Valid {
+		days, err := formRentalDays(r)
+		if err != nil {
+			h.numberError(w, r, err)
+			return
+		}
+		quote, rates, ok, err := h.rentalQuote(ctx, templateID.Int64, days)
+		if err != nil {
+			logger.Error("failed to get item template", "error", err)
+			http.Error(w, "Failed to load template", http.StatusInternalServerError)
+			return
+		}
+		if ok {
+			unitPrice = quote.Total
+			unit = rentalUnit
+			description = sql.NullString{String: quote.Breakdown(rates), Valid: true}
+		}
+	}
+
+	pricing := lineItemPricing(r, domain.LineItemInput{
+		CategoryID: categoryID,
+		Type:       domain.LineItemType(itemType),
+		Name:       name,
+		Quantity:   quantity,
+		Unit:       unit,
+		UnitPrice:  unitPrice,
+	})
+	if errs := pricing.Validate(); len(errs) > 0 {
+		http.Error(w, errs[0].Message, http.StatusBadRequest)
+		return
+	}
+
+	phase, _ := formPhase(r)
+
+	watch, err := h.watchTotal(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
+	created, err := h.queries.CreateLineItem(ctx, repository.CreateLineItemParams{
+		ID:                  uuid.New().String(),
+		CategoryID:          categoryID,
+		Type:                itemType,
+		Name:                name,
+		Description:         description,
+		Quantity:            quantity,
+		Unit:                unit,
+		UnitPrice:           pricing.UnitPrice,
+		SurchargePercent:    sql.NullFloat64{},
+		SortOrder:           0,
+		ExemptFromSurcharge: pricing.ExemptFromSurcharge,
+		TemplateID:          templateID,
+		IsCredit:            pricing.IsCredit,
+		TaxTreatment:        string(pricing.TaxTreatment),
+		Phase:               phase,
 	})
 	if err != nil {
 		logger.Error("failed to create line item", "error", err)
 		http.Error(w, "Failed to create line item", http.StatusInternalServerError)
 		return
 	}
+	if err := h.checkTotal(ctx, watch, totalAlertCreate, created.ID, nil); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/categories/"+categoryID)
@@ -556,6 +840,26 @@ func (h *Handler) CreateLineItem(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/categories/"+categoryID, http.StatusSeeOther)
 }
 
+// lineItemPricing applies the credit and markup exemption checkboxes and the
+// tax treatment select to a line item input. Credits are stored with a
+// negative unit price; whether they are marked up follows the
+// surcharge_credits setting when totals are calculated. A missing
+// tax_treatment keeps the input's treatment.
+func lineItemPricing(r *http.Request, input domain.LineItemInput) domain.LineItemInput {
+	input.IsCredit = r.FormValue("is_credit") == "true"
+	input.ExemptFromSurcharge = r.FormValue("exempt_from_surcharge") == "true"
+	if treatment := r.FormValue("tax_treatment"); treatment != "" {
+		input.TaxTreatment = domain.TaxTreatment(treatment)
+	}
+	if input.TaxTreatment == "" {
+		input.TaxTreatment = domain.TaxTreatmentDefault
+	}
+	if input.IsCredit {
+		input.UnitPrice = -math.Abs(input.UnitPrice)
+	}
+	return input
+}
+
 // DeleteLineItem deletes a line item.
 func (h *Handler) DeleteLineItem(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -569,11 +873,19 @@ func (h *Handler) DeleteLineItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	watch, err := h.watchTotal(ctx, item.CategoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
 	if err := h.queries.DeleteLineItem(ctx, itemID); err != nil {
 		logger.Error("failed to delete line item", "error", err)
 		http.Error(w, "Failed to delete line item", http.StatusInternalServerError)
 		return
 	}
+	if err := h.checkTotal(ctx, watch, totalAlertDelete, itemID, &item); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/categories/"+item.CategoryID)
@@ -588,25 +900,29 @@ func (h *Handler) GetInlineForm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
 	categoryID := r.PathValue("categoryID")
-	itemType := r.URL.Query().Get("type")
 
-	if itemType == "" {
-		itemType = "material"
+	defaults, err := h.categoryItemDefaults(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to load item defaults", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
 	}
+	itemType, defaultUnit := defaults.Resolve(r.URL.Query().Get("type"))
 
-	// Default units based on type
-	defaultUnit := "ea"
-	switch itemType {
-	case "labor":
-		defaultUnit = "hr"
-	case "equipment":
-		defaultUnit = "day"
+	phases, err := h.categoryPhases(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to list job phases", "error", err)
 	}
 
 	data := map[string]interface{}{
 		"CategoryID":  categoryID,
 		"Type":        itemType,
 		"DefaultUnit": defaultUnit,
+		"Phases":      phases,
 	}
 
 	var buf bytes.Buffer
@@ -0,0 +1,190 @@
+package keyboard
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// Share modes decide whether a shared category shows prices.
+const (
+	shareModeCrew     = "crew"     // scope only, for subs confirming the work
+	shareModeCustomer = "customer" // scope with prices and totals
+)
+
+// shareExpired reports whether a shared link has lapsed with its job: the
+// quote was marked expired or its expiry date has passed.
+func shareExpired(job repository.Job, now time.Time) bool {
+	if job.Status == "expired" {
+		return true
+	}
+	return job.ExpiresAt.Valid && dateKey(job.ExpiresAt.String) < now.Format(dateLayout)
+}
+
+// CreateCategoryShare creates a read-only link to a category and its
+// subcategories. The mode is fixed when the link is made; crew links never
+// show prices.
+func (h *Handler) CreateCategoryShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	categoryID := r.PathValue("id")
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	mode := r.FormValue("mode")
+	if mode == "" {
+		mode = shareModeCrew
+	}
+	if mode != shareModeCrew && mode != shareModeCustomer {
+		http.Error(w, "Invalid share mode", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetCategory(ctx, categoryID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	_, err := h.queries.CreateCategoryShare(ctx, repository.CreateCategoryShareParams{
+		Token:      rand.Text(),
+		CategoryID: categoryID,
+		Mode:       mode,
+	})
+	if err != nil {
+		logger.Error("failed to create category share", "error", err)
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/categories/"+categoryID)
+		return
+	}
+
+	http.Redirect(w, r, "/categories/"+categoryID, http.StatusSeeOther)
+}
+
+// RevokeCategoryShare turns off a category's share link.
+func (h *Handler) RevokeCategoryShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	categoryID := r.PathValue("id")
+
+	n, err := h.queries.RevokeCategoryShare(ctx, repository.RevokeCategoryShareParams{
+		Token:      r.PathValue("token"),
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		logger.Error("failed to revoke category share", "error", err)
+		http.Error(w, "Failed to revoke share link", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Share link not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/categories/"+categoryID)
+		return
+	}
+
+	http.Redirect(w, r, "/categories/"+categoryID, http.StatusSeeOther)
+}
+
+// GetSharedCategory is the public, read-only view behind a category share
+// link. It shows the category's items and subcategories without anything
+// else from the quote, with prices only on customer links, and counts the
+// view.
+func (h *Handler) GetSharedCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	share, err := h.queries.GetCategoryShare(ctx, r.PathValue("token"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get category share", "error", err)
+		http.Error(w, "Failed to load link", http.StatusInternalServerError)
+		return
+	}
+	if share.RevokedAt.Valid {
+		http.Error(w, "Link not found", http.StatusNotFound)
+		return
+	}
+
+	category, err := h.queries.GetCategory(ctx, share.CategoryID)
+	if err != nil {
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, category.JobID)
+	if err != nil {
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	if shareExpired(job, time.Now()) {
+		http.Error(w, "This link has expired", http.StatusGone)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	node, ok := findCategoryNode(buildCategoryTree(categories), category.ID)
+	if !ok {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.queries.RecordCategoryShareView(ctx, share.Token); err != nil {
+		logger.Error("failed to record category share view", "error", err)
+	}
+
+	data := map[string]interface{}{
+		"Job":        job,
+		"Settings":   settings,
+		"Category":   h.buildWorkbookCategory(node, job, settings, categories, lineItems, groupItemsByCategory(lineItems)),
+		"ShowPrices": share.Mode == shareModeCustomer,
+	}
+
+	if err := h.renderer.Render(w, "category_share", data); err != nil {
+		logger.Error("failed to render template", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,127 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedShareJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, status) VALUES ('job-share', 'Bathroom Remodel', 'sent')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES
+		('cat-plumbing', 'job-share', NULL, 'Plumbing'),
+		('cat-rough-in', 'job-share', 'cat-plumbing', 'Rough-in'),
+		('cat-tile', 'job-share', NULL, 'Tile')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-heater', 'cat-plumbing', 'material', 'Water heater', 1, 'ea', 1234.56),
+		('item-pex', 'cat-rough-in', 'material', 'PEX tubing', 100, 'ft', 0.87),
+		('item-tile', 'cat-tile', 'material', 'Porcelain tile', 80, 'sqft', 6.25)`)
+}
+
+// createShare makes a share link for the category and returns its token.
+func createShare(t *testing.T, app *testApp, categoryID, mode string) string {
+	t.Helper()
+	rec := app.postForm(t, http.MethodPost, "/categories/"+categoryID+"/share", url.Values{"mode": {mode}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create share status = %d, want 303", rec.Code)
+	}
+	var token string
+	if err := app.db.QueryRow(`SELECT token FROM category_shares WHERE category_id = ? AND mode = ?`, categoryID, mode).Scan(&token); err != nil {
+		t.Fatalf("select token: %v", err)
+	}
+	return token
+}
+
+func TestSharedCategory_CrewMode(t *testing.T) {
+	app := newTestApp(t)
+	seedShareJob(t, app)
+	token := createShare(t, app, "cat-plumbing", "crew")
+
+	rec := app.get(t, "/c/"+token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<html lang="en" class="system">`) || !strings.Contains(body, "/static/theme.css") {
+		t.Errorf("shared view ignores the color theme")
+	}
+	for _, want := range []string{"Plumbing", "Water heater", "Rough-in", "PEX tubing"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("shared view missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Porcelain tile", "Tile", "$", "1234.56", "Unit Price"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("crew view shows %q", unwanted)
+		}
+	}
+
+	app.get(t, "/c/"+token)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM category_shares WHERE view_count = 2 AND last_viewed_at IS NOT NULL`); n != 1 {
+		t.Errorf("views not tracked")
+	}
+	if body := app.get(t, "/categories/cat-plumbing").Body.String(); !strings.Contains(body, "/c/"+token) || !strings.Contains(body, "2 views") {
+		t.Errorf("category page missing share link")
+	}
+}
+
+func TestSharedCategory_CustomerMode(t *testing.T) {
+	app := newTestApp(t)
+	seedShareJob(t, app)
+	token := createShare(t, app, "cat-plumbing", "customer")
+
+	body := app.get(t, "/c/"+token).Body.String()
+	for _, want := range []string{"Water heater", "$1234.56", "$87.00", "Plumbing Total", "$1321.56"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("customer view missing %q", want)
+		}
+	}
+	if strings.Contains(body, "Porcelain tile") {
+		t.Errorf("customer view shows another category")
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-plumbing/share", url.Values{"mode": {"admin"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad mode status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSharedCategory_RevokeAndExpire(t *testing.T) {
+	app := newTestApp(t)
+	seedShareJob(t, app)
+	token := createShare(t, app, "cat-plumbing", "crew")
+
+	if rec := app.get(t, "/c/not-a-token"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token status = %d, want 404", rec.Code)
+	}
+
+	app.exec(t, `UPDATE jobs SET expires_at = '2020-01-01' WHERE id = 'job-share'`)
+	if rec := app.get(t, "/c/"+token); rec.Code != http.StatusGone {
+		t.Errorf("expired job status = %d, want 410", rec.Code)
+	}
+	app.exec(t, `UPDATE jobs SET expires_at = NULL, status = 'expired' WHERE id = 'job-share'`)
+	if rec := app.get(t, "/c/"+token); rec.Code != http.StatusGone {
+		t.Errorf("expired status = %d, want 410", rec.Code)
+	}
+	app.exec(t, `UPDATE jobs SET status = 'sent' WHERE id = 'job-share'`)
+
+	if rec := app.postForm(t, http.MethodDelete, "/categories/cat-tile/share/"+token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("revoke from another category status = %d, want 404", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodDelete, "/categories/cat-plumbing/share/"+token, nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("revoke status = %d, want 303", rec.Code)
+	}
+	if rec := app.get(t, "/c/"+token); rec.Code != http.StatusNotFound {
+		t.Errorf("revoked link status = %d, want 404", rec.Code)
+	}
+	if body := app.get(t, "/categories/cat-plumbing").Body.String(); strings.Contains(body, "/c/"+token) {
+		t.Errorf("revoked link still listed")
+	}
+
+	// Deleting the job removes its links.
+	app.exec(t, `DELETE FROM jobs WHERE id = 'job-share'`)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM category_shares`); n != 0 {
+		t.Errorf("links left after the job was deleted")
+	}
+}
@@ -0,0 +1,144 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/clientlink"
+	"github.com/google/uuid"
+)
+
+// GetClientLinkReview shows how jobs that only have a free-text customer name
+// would be linked to clients, grouped by normalized name.
+func (h *Handler) GetClientLinkReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	jobs, err := h.queries.ListUnlinkedCustomerJobs(ctx)
+	if err != nil {
+		logger.Error("failed to list unlinked jobs", "error", err)
+		http.Error(w, "Failed to load jobs", http.StatusInternalServerError)
+		return
+	}
+
+	clients, err := h.queries.ListClients(ctx)
+	if err != nil {
+		logger.Error("failed to list clients", "error", err)
+		http.Error(w, "Failed to load clients", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Groups": clientlink.Plan(jobs, clients),
+	}
+
+	if err := h.renderer.Render(w, "client_link", data); err != nil {
+		logger.Error("failed to render client link page", "error", err)
+	}
+}
+
+// ApplyClientLinks creates and links clients for the groups selected on the
+// review page, then shows how many clients were created and jobs linked.
+func (h *Handler) ApplyClientLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, key := range r.Form["group"] {
+		selected[key] = true
+	}
+
+	summary, err := h.applyClientLinks(ctx, selected)
+	if err != nil {
+		logger.Error("failed to link clients", "error", err)
+		http.Error(w, "Failed to link clients", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("linked jobs to clients",
+		"created", summary.Created,
+		"linked", summary.Linked,
+		"skipped", summary.Skipped,
+	)
+
+	data := map[string]interface{}{
+		"Summary": summary,
+	}
+
+	if err := h.renderer.Render(w, "client_link", data); err != nil {
+		logger.Error("failed to render client link page", "error", err)
+	}
+}
+
+// applyClientLinks re-plans against the current jobs and clients and applies
+// the selected groups. Either every selected group is applied or none are.
+func (h *Handler) applyClientLinks(ctx context.Context, selected map[string]bool) (clientlink.Summary, error) {
+	var summary clientlink.Summary
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	jobs, err := qtx.ListUnlinkedCustomerJobs(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("listing unlinked jobs: %w", err)
+	}
+	clients, err := qtx.ListClients(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("listing clients: %w", err)
+	}
+
+	for _, group := range clientlink.Plan(jobs, clients) {
+		if group.Action == clientlink.ActionSkip || !selected[group.Key] {
+			summary.Skipped += len(group.Jobs)
+			continue
+		}
+
+		var clientID string
+		switch group.Action {
+		case clientlink.ActionLink:
+			clientID = group.Client.ID
+		case clientlink.ActionCreate:
+			client, err := qtx.CreateClient(ctx, repository.CreateClientParams{
+				ID:   uuid.New().String(),
+				Name: group.ProposedName,
+			})
+			if err != nil {
+				return summary, fmt.Errorf("creating client %q: %w", group.ProposedName, err)
+			}
+			clientID = client.ID
+			summary.Created++
+		default:
+			return summary, fmt.Errorf("unknown action %q for %q", group.Action, group.Key)
+		}
+
+		for _, job := range group.Jobs {
+			n, err := qtx.LinkJobClient(ctx, repository.LinkJobClientParams{
+				ClientID: sql.NullString{String: clientID, Valid: true},
+				ID:       job.ID,
+			})
+			if err != nil {
+				return summary, fmt.Errorf("linking job %s: %w", job.ID, err)
+			}
+			summary.Linked += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, fmt.Errorf("committing transaction: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,83 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedUnlinkedJobs(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('c-acme', 'Acme Builders'), ('c-smith', 'Smith Homes'), ('c-smyth', 'Smyth Homes')`)
+	app.exec(t, `INSERT INTO jobs (id, name, customer_name) VALUES
+		('j-1', 'Deck', 'ACME Builders, Inc.'),
+		('j-2', 'Fence', 'acme builders'),
+		('j-3', 'Porch', 'Jane Doe'),
+		('j-4', 'Shed', 'Jane  Doe'),
+		('j-5', 'Roof', 'Rivera Co'),
+		('j-6', 'Siding', 'Rivera LLC'),
+		('j-7', 'Patio', 'Smoth Homes'),
+		('j-8', 'Linked', 'Whoever')`)
+	app.exec(t, `UPDATE jobs SET client_id = 'c-acme' WHERE id = 'j-8'`)
+}
+
+func TestClientLinkReview(t *testing.T) {
+	app := newTestApp(t)
+	seedUnlinkedJobs(t, app)
+
+	rec := app.get(t, "/clients/link")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`href="/clients/c-acme"`,
+		`New client &ldquo;Jane Doe&rdquo;`,
+		"different business suffixes",
+		"Matches 2 existing clients",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("review page missing %q", want)
+		}
+	}
+	if strings.Contains(body, "Whoever") {
+		t.Errorf("review page lists a job that already has a client")
+	}
+}
+
+func TestApplyClientLinks(t *testing.T) {
+	app := newTestApp(t)
+	seedUnlinkedJobs(t, app)
+
+	rec := app.postForm(t, http.MethodPost, "/clients/link", url.Values{
+		"group": {"acme builders", "jane doe", "rivera"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs WHERE id IN ('j-1', 'j-2') AND client_id = 'c-acme'`); n != 2 {
+		t.Errorf("acme jobs linked = %d, want 2", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs j JOIN clients c ON c.id = j.client_id WHERE j.id IN ('j-3', 'j-4') AND c.name = 'Jane Doe'`); n != 2 {
+		t.Errorf("jane doe jobs linked to a new client = %d, want 2", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs WHERE id IN ('j-5', 'j-6', 'j-7') AND client_id IS NOT NULL`); n != 0 {
+		t.Errorf("ambiguous jobs were linked")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM clients`); n != 4 {
+		t.Errorf("clients = %d, want 4", n)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<div class="text-2xl font-bold text-forest-700">1</div>`,
+		`<div class="text-2xl font-bold text-blue-700">4</div>`,
+		`<div class="text-2xl font-bold text-slate-700">3</div>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary missing %s", want)
+		}
+	}
+}
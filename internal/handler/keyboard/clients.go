@@ -2,9 +2,10 @@ package keyboard
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"encoding/json"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/dukerupert/skalkaho/internal/middleware"
@@ -12,8 +13,6 @@ import (
 	"github.com/google/uuid"
 )
 
-const clientsPageSize = 20
-
 // ListClients shows the clients management page with search and pagination.
 func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -21,15 +20,6 @@ func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query params
 	search := r.URL.Query().Get("q")
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	offset := int64((page - 1) * clientsPageSize)
 
 	// Get total count for pagination
 	totalCount, err := h.queries.CountClients(ctx, search)
@@ -39,16 +29,13 @@ func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	totalPages := int(totalCount+int64(clientsPageSize)-1) / clientsPageSize
-	if totalPages < 1 {
-		totalPages = 1
-	}
+	pagination := newPagination(r, totalCount, h.pageSize(w, r))
 
 	// Get paginated clients
 	clients, err := h.queries.ListClientsPaginated(ctx, repository.ListClientsPaginatedParams{
 		Search: search,
-		Offset: offset,
-		Limit:  int64(clientsPageSize),
+		Offset: int64(pagination.Offset),
+		Limit:  int64(pagination.PerPage),
 	})
 	if err != nil {
 		logger.Error("failed to list clients", "error", err)
@@ -56,14 +43,6 @@ func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pagination := PaginationData{
-		CurrentPage: page,
-		TotalPages:  totalPages,
-		TotalItems:  totalCount,
-		HasPrev:     page > 1,
-		HasNext:     page < totalPages,
-	}
-
 	data := map[string]interface{}{
 		"Clients":    clients,
 		"Search":     search,
@@ -126,8 +105,15 @@ func (h *Handler) GetClientForm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
 
+	// name prefills the form, e.g. from a search with no matches. modal
+	// opens it over a job's client picker instead.
+	data := map[string]interface{}{
+		"Name":  r.URL.Query().Get("name"),
+		"Modal": r.URL.Query().Get("modal") == "1",
+	}
+
 	var buf bytes.Buffer
-	if err := h.renderer.RenderPartial(&buf, "client_form", nil); err != nil {
+	if err := h.renderer.RenderPartial(&buf, "client_form", data); err != nil {
 		logger.Error("failed to render client form", "error", err)
 		http.Error(w, "Failed to render form", http.StatusInternalServerError)
 		return
@@ -137,27 +123,23 @@ func (h *Handler) GetClientForm(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(buf.Bytes())
 }
 
-// CreateClient creates a new client.
-func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	logger := middleware.LoggerFromContext(ctx)
-
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
-		return
-	}
+// clientFormProblem is why a submitted client form was rejected.
+type clientFormProblem struct {
+	Status  int
+	Message string
+}
 
+// createClientFromForm saves the client described by a parsed client form. A
+// missing or taken name is returned as a problem for the form to show.
+func (h *Handler) createClientFromForm(ctx context.Context, r *http.Request) (repository.Client, *clientFormProblem, error) {
 	name := strings.TrimSpace(r.FormValue("name"))
 	if name == "" {
-		http.Error(w, "Name is required", http.StatusBadRequest)
-		return
+		return repository.Client{}, &clientFormProblem{http.StatusBadRequest, "Name is required"}, nil
 	}
 
 	// Check for duplicate name
-	_, err := h.queries.GetClientByName(ctx, name)
-	if err == nil {
-		http.Error(w, "A client with this name already exists", http.StatusConflict)
-		return
+	if _, err := h.queries.GetClientByName(ctx, name); err == nil {
+		return repository.Client{}, &clientFormProblem{http.StatusConflict, "A client with this name already exists"}, nil
 	}
 
 	client, err := h.queries.CreateClient(ctx, repository.CreateClientParams{
@@ -173,11 +155,29 @@ func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
 		TaxID:   toNullString(r.FormValue("tax_id")),
 		Notes:   toNullString(r.FormValue("notes")),
 	})
+	return client, nil, err
+}
+
+// CreateClient creates a new client.
+func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	client, problem, err := h.createClientFromForm(ctx, r)
 	if err != nil {
 		logger.Error("failed to create client", "error", err)
 		http.Error(w, "Failed to create client", http.StatusInternalServerError)
 		return
 	}
+	if problem != nil {
+		http.Error(w, problem.Message, problem.Status)
+		return
+	}
 
 	// Redirect to client detail page
 	if r.Header.Get("HX-Request") == "true" {
@@ -189,6 +189,57 @@ func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/clients/"+client.ID, http.StatusSeeOther)
 }
 
+// CreateClientInline creates a client from the modal opened in a job's client
+// picker. Instead of leaving the page it closes the modal and fires a
+// clientCreated event with the new client's ID and name, which selects it in
+// the picker. A rejected form is shown again in the modal with its error.
+func (h *Handler) CreateClientInline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	client, problem, err := h.createClientFromForm(ctx, r)
+	if err != nil {
+		logger.Error("failed to create client", "error", err)
+		http.Error(w, "Failed to create client", http.StatusInternalServerError)
+		return
+	}
+	if problem != nil {
+		data := map[string]interface{}{
+			"Modal":   true,
+			"Error":   problem.Message,
+			"Name":    r.FormValue("name"),
+			"Company": r.FormValue("company"),
+			"Email":   r.FormValue("email"),
+			"Phone":   r.FormValue("phone"),
+		}
+		var buf bytes.Buffer
+		if err := h.renderer.RenderPartial(&buf, "client_form", data); err != nil {
+			logger.Error("failed to render client form", "error", err)
+			http.Error(w, "Failed to render form", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	trigger, err := json.Marshal(map[string]interface{}{
+		"clientCreated": map[string]string{"id": client.ID, "name": client.Name},
+	})
+	if err != nil {
+		logger.Error("failed to encode client trigger", "error", err)
+		http.Error(w, "Failed to create client", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("HX-Trigger", string(trigger))
+	w.WriteHeader(http.StatusOK)
+}
+
 // GetClientEditForm returns the inline form for editing a client.
 func (h *Handler) GetClientEditForm(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -0,0 +1,85 @@
+package keyboard_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func postClientInline(app *testApp, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/clients/inline", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	return app.do(req)
+}
+
+func TestCreateClientInline(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Deck')`)
+
+	if body := app.get(t, "/jobs/job-1/client").Body.String(); !strings.Contains(body, `hx-get="/client-form?modal=1"`) {
+		t.Errorf("client picker missing the new client option")
+	}
+	if body := app.get(t, "/client-form?modal=1").Body.String(); !strings.Contains(body, `hx-post="/clients/inline"`) || !strings.Contains(body, "data-client-modal") {
+		t.Errorf("modal form = %q", body)
+	}
+
+	rec := postClientInline(app, url.Values{"name": {"Ana Lopez"}, "email": {"ana@example.com"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("HX-Redirect") != "" || rec.Body.Len() != 0 {
+		t.Errorf("inline create should stay on the page, got redirect %q body %q", rec.Header().Get("HX-Redirect"), rec.Body.String())
+	}
+
+	var trigger struct {
+		ClientCreated struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"clientCreated"`
+	}
+	if err := json.Unmarshal([]byte(rec.Header().Get("HX-Trigger")), &trigger); err != nil {
+		t.Fatalf("HX-Trigger = %q: %v", rec.Header().Get("HX-Trigger"), err)
+	}
+	var id string
+	if err := app.db.QueryRow(`SELECT id FROM clients WHERE name = 'Ana Lopez' AND email = 'ana@example.com'`).Scan(&id); err != nil {
+		t.Fatalf("client not created: %v", err)
+	}
+	if trigger.ClientCreated.ID != id || trigger.ClientCreated.Name != "Ana Lopez" {
+		t.Errorf("trigger = %+v, want id %s", trigger.ClientCreated, id)
+	}
+}
+
+func TestCreateClientInline_Rejected(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('client-1', 'Ana Lopez')`)
+
+	rec := postClientInline(app, url.Values{"name": {"Ana Lopez"}, "company": {"Lopez Builders"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 so the modal is swapped", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"A client with this name already exists", `value="Lopez Builders"`, `hx-post="/clients/inline"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("modal missing %q", want)
+		}
+	}
+	if rec.Header().Get("HX-Trigger") != "" {
+		t.Errorf("rejected form fired %q", rec.Header().Get("HX-Trigger"))
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM clients`); n != 1 {
+		t.Errorf("clients = %d, want 1", n)
+	}
+
+	if body := postClientInline(app, url.Values{"name": {"  "}}).Body.String(); !strings.Contains(body, "Name is required") {
+		t.Errorf("blank name not reported in the modal")
+	}
+
+	// The clients page keeps its status codes.
+	if rec := app.postForm(t, http.MethodPost, "/clients", url.Values{"name": {"Ana Lopez"}}); rec.Code != http.StatusConflict {
+		t.Errorf("POST /clients duplicate status = %d, want 409", rec.Code)
+	}
+}
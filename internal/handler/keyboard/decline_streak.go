@@ -0,0 +1,41 @@
+package keyboard
+
+import (
+	"context"
+
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// DeclineWarning describes a client whose most recent quotes were all rejected.
+type DeclineWarning struct {
+	ClientName string
+	Quotes     []repository.Job
+}
+
+// declineWarning returns a warning when a draft job's client has rejected
+// their last settings.DeclineStreak quotes, or nil otherwise. Drafts are not
+// counted as quotes, so an abandoned draft doesn't break the streak.
+func (h *Handler) declineWarning(ctx context.Context, settings repository.Setting, job repository.Job, client *repository.Client) (*DeclineWarning, error) {
+	if !settings.DeclineWarning || settings.DeclineStreak <= 0 || job.Status != "draft" || client == nil {
+		return nil, nil
+	}
+
+	quotes, err := h.queries.ListRecentClientQuotes(ctx, repository.ListRecentClientQuotesParams{
+		ClientID: job.ClientID,
+		ID:       job.ID,
+		Limit:    settings.DeclineStreak,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(quotes)) < settings.DeclineStreak {
+		return nil, nil
+	}
+	for _, quote := range quotes {
+		if quote.Status != "rejected" {
+			return nil, nil
+		}
+	}
+
+	return &DeclineWarning{ClientName: client.Name, Quotes: quotes}, nil
+}
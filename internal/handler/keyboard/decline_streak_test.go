@@ -0,0 +1,56 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedDeclinedQuotes(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('client-1', 'Acme Builders')`)
+	app.exec(t, `INSERT INTO jobs (id, name, status, client_id, created_at) VALUES
+		('old-1', 'Deck', 'accepted', 'client-1', datetime('now', '-90 days')),
+		('old-2', 'Fence', 'rejected', 'client-1', datetime('now', '-60 days')),
+		('old-3', 'Shed', 'rejected', 'client-1', datetime('now', '-30 days')),
+		('old-4', 'Abandoned', 'draft', 'client-1', datetime('now', '-20 days')),
+		('old-5', 'Porch', 'rejected', 'client-1', datetime('now', '-10 days'))`)
+}
+
+func newJobPage(t *testing.T, app *testApp) string {
+	t.Helper()
+	rec := app.postForm(t, http.MethodPost, "/jobs", url.Values{"client_id": {"client-1"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status = %d, want 303", rec.Code)
+	}
+	return app.get(t, rec.Header().Get("Location")).Body.String()
+}
+
+func TestCreateJob_DeclineStreakWarning(t *testing.T) {
+	app := newTestApp(t)
+	seedDeclinedQuotes(t, app)
+
+	body := newJobPage(t, app)
+	if !strings.Contains(body, `id="decline-warning"`) {
+		t.Fatalf("expected decline warning after three rejected quotes")
+	}
+	for _, link := range []string{`href="/jobs/old-2"`, `href="/jobs/old-3"`, `href="/jobs/old-5"`} {
+		if !strings.Contains(body, link) {
+			t.Errorf("warning missing link %s", link)
+		}
+	}
+	if strings.Contains(body, `href="/jobs/old-1"`) {
+		t.Errorf("warning links to an accepted quote")
+	}
+
+	app.exec(t, `UPDATE settings SET decline_streak = 4`)
+	if body := newJobPage(t, app); strings.Contains(body, `id="decline-warning"`) {
+		t.Errorf("warning shown although the streak is broken by an accepted quote")
+	}
+
+	app.exec(t, `UPDATE settings SET decline_streak = 3, decline_warning = 0`)
+	if body := newJobPage(t, app); strings.Contains(body, `id="decline-warning"`) {
+		t.Errorf("warning shown while disabled in settings")
+	}
+}
@@ -0,0 +1,68 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSearchItems_EmptyState(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.get(t, "/items/search?type=material&q=zzzgadget")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "data-empty-state") || !strings.Contains(body, "No matches for &#39;zzzgadget&#39;") {
+		t.Errorf("no-hit search missing empty state: %s", body)
+	}
+	if !strings.Contains(body, "/items?new=true&amp;type=material&amp;name=zzzgadget") {
+		t.Errorf("empty state missing prefilled create link: %s", body)
+	}
+
+	rec = app.get(t, "/items/search?type=material&q=")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("empty query status = %d, want 200", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "autocomplete-results") || strings.TrimSpace(body) != "" {
+		t.Errorf("empty query did not clear the results: %q", body)
+	}
+}
+
+func TestListItemTemplates_EmptyState(t *testing.T) {
+	app := newTestApp(t)
+
+	body := app.get(t, "/items?q=zzzgadget&type=labor").Body.String()
+	if !strings.Contains(body, "No matches for &#39;zzzgadget&#39;") {
+		t.Errorf("no-hit filter missing empty state")
+	}
+	if !strings.Contains(body, `hx-get="/items/new?type=labor&amp;name=zzzgadget"`) {
+		t.Errorf("empty state missing prefilled create button")
+	}
+
+	form := app.get(t, "/items/new?type=labor&name=zzzgadget").Body.String()
+	if !strings.Contains(form, `value="zzzgadget"`) || !strings.Contains(form, `<option value="labor" selected>`) {
+		t.Errorf("create form not prefilled: %s", form)
+	}
+}
+
+func TestListClients_EmptyState(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('c-1', 'Acme Homes')`)
+
+	body := app.get(t, "/clients?q=Zenith").Body.String()
+	if !strings.Contains(body, "No matches for &#39;Zenith&#39;") {
+		t.Errorf("no-hit search missing empty state")
+	}
+	if !strings.Contains(body, `hx-get="/client-form?name=Zenith"`) {
+		t.Errorf("empty state missing prefilled create button")
+	}
+	if strings.Contains(body, "Acme Homes") {
+		t.Errorf("non-matching client listed")
+	}
+
+	if form := app.get(t, "/client-form?name=Zenith").Body.String(); !strings.Contains(form, `value="Zenith"`) {
+		t.Errorf("client form not prefilled")
+	}
+}
@@ -1,46 +1,80 @@
 package keyboard
 
 import (
+	"database/sql"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/dukerupert/skalkaho/internal/config"
 	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
 	"github.com/dukerupert/skalkaho/internal/service/claude"
+	"github.com/dukerupert/skalkaho/internal/service/cleanup"
 	"github.com/dukerupert/skalkaho/internal/templates/keyboard"
 )
 
+const (
+	// idempotencyCacheSize is how many keyed submissions are remembered.
+	idempotencyCacheSize = 1000
+	// idempotencyWindow is how long a keyed submission is remembered.
+	idempotencyWindow = 10 * time.Minute
+)
+
 // Handler handles keyboard-centric UI HTTP requests.
 type Handler struct {
-	queries  *repository.Queries
-	renderer *keyboard.Renderer
-	logger   *slog.Logger
-	matcher  *claude.Matcher
-	config   *config.Config
+	db          *sql.DB
+	queries     *repository.Queries
+	renderer    *keyboard.Renderer
+	logger      *slog.Logger
+	matcher     *claude.Matcher
+	cleanup     *cleanup.Runner
+	config      *config.Config
+	errorLog    *middleware.ErrorLog
+	idempotency *middleware.IdempotencyCache
 }
 
 // NewHandler creates a new keyboard UI handler.
-func NewHandler(queries *repository.Queries, renderer *keyboard.Renderer, logger *slog.Logger, cfg *config.Config) *Handler {
+func NewHandler(db *sql.DB, queries *repository.Queries, renderer *keyboard.Renderer, logger *slog.Logger, cfg *config.Config) *Handler {
 	var matcher *claude.Matcher
 	if cfg.AnthropicAPIKey != "" {
 		matcher = claude.NewMatcher(cfg.AnthropicAPIKey)
 	}
 	return &Handler{
-		queries:  queries,
-		renderer: renderer,
-		logger:   logger,
-		matcher:  matcher,
-		config:   cfg,
+		db:          db,
+		queries:     queries,
+		renderer:    renderer,
+		logger:      logger,
+		matcher:     matcher,
+		cleanup:     cleanup.NewRunner(db, queries, logger),
+		config:      cfg,
+		errorLog:    middleware.NewErrorLog(errorLogSize),
+		idempotency: middleware.NewIdempotencyCache(idempotencyCacheSize, idempotencyWindow),
 	}
 }
 
-// calculateTotals computes job totals from repository types.
-func (h *Handler) calculateTotals(job repository.Job, categories []repository.Category, lineItems []repository.LineItem) domain.JobTotal {
+// Idempotent wraps a create handler so a double-submitted form creates one
+// row; see middleware.Idempotent.
+func (h *Handler) Idempotent(next http.HandlerFunc) http.Handler {
+	return middleware.Idempotent(h.idempotency)(next)
+}
+
+// ErrorLog returns the log of failed requests shown on /admin/errors.
+func (h *Handler) ErrorLog() *middleware.ErrorLog {
+	return h.errorLog
+}
+
+// calculateTotals computes job totals from repository types. Settings decide
+// whether credits are marked up.
+func (h *Handler) calculateTotals(job repository.Job, settings repository.Setting, categories []repository.Category, lineItems []repository.LineItem) domain.JobTotal {
 	// Convert to domain types
 	domainJob := &domain.Job{
 		ID:               job.ID,
 		SurchargePercent: job.SurchargePercent,
 		SurchargeMode:    domain.SurchargeMode(job.SurchargeMode),
+		TaxPercent:       job.TaxPercent,
+		SurchargeCredits: settings.SurchargeCredits,
 	}
 
 	domainCategories := make([]*domain.Category, len(categories))
@@ -58,6 +92,7 @@ func (h *Handler) calculateTotals(job repository.Job, categories []repository.Ca
 			JobID:            cat.JobID,
 			ParentID:         parentID,
 			SurchargePercent: surcharge,
+			TaxTreatment:     domain.TaxTreatment(cat.TaxTreatment),
 		}
 	}
 
@@ -68,12 +103,15 @@ func (h *Handler) calculateTotals(job repository.Job, categories []repository.Ca
 			surcharge = &item.SurchargePercent.Float64
 		}
 		domainLineItems[i] = &domain.LineItem{
-			ID:               item.ID,
-			CategoryID:       item.CategoryID,
-			Type:             domain.LineItemType(item.Type),
-			Quantity:         item.Quantity,
-			UnitPrice:        item.UnitPrice,
-			SurchargePercent: surcharge,
+			ID:                  item.ID,
+			CategoryID:          item.CategoryID,
+			Type:                domain.LineItemType(item.Type),
+			Quantity:            item.Quantity,
+			UnitPrice:           item.UnitPrice,
+			SurchargePercent:    surcharge,
+			ExemptFromSurcharge: item.ExemptFromSurcharge,
+			IsCredit:            item.IsCredit,
+			TaxTreatment:        domain.TaxTreatment(item.TaxTreatment),
 		}
 	}
 
@@ -81,11 +119,13 @@ func (h *Handler) calculateTotals(job repository.Job, categories []repository.Ca
 }
 
 // calculateCategoryTotal computes totals for a single category.
-func (h *Handler) calculateCategoryTotal(categoryID string, job repository.Job, categories []repository.Category, lineItems []repository.LineItem) domain.CategoryTotal {
+func (h *Handler) calculateCategoryTotal(categoryID string, job repository.Job, settings repository.Setting, categories []repository.Category, lineItems []repository.LineItem) domain.CategoryTotal {
 	domainJob := &domain.Job{
 		ID:               job.ID,
 		SurchargePercent: job.SurchargePercent,
 		SurchargeMode:    domain.SurchargeMode(job.SurchargeMode),
+		TaxPercent:       job.TaxPercent,
+		SurchargeCredits: settings.SurchargeCredits,
 	}
 
 	domainCategories := make([]*domain.Category, len(categories))
@@ -103,6 +143,7 @@ func (h *Handler) calculateCategoryTotal(categoryID string, job repository.Job,
 			JobID:            cat.JobID,
 			ParentID:         parentID,
 			SurchargePercent: surcharge,
+			TaxTreatment:     domain.TaxTreatment(cat.TaxTreatment),
 		}
 	}
 
@@ -113,12 +154,15 @@ func (h *Handler) calculateCategoryTotal(categoryID string, job repository.Job,
 			surcharge = &item.SurchargePercent.Float64
 		}
 		domainLineItems[i] = &domain.LineItem{
-			ID:               item.ID,
-			CategoryID:       item.CategoryID,
-			Type:             domain.LineItemType(item.Type),
-			Quantity:         item.Quantity,
-			UnitPrice:        item.UnitPrice,
-			SurchargePercent: surcharge,
+			ID:                  item.ID,
+			CategoryID:          item.CategoryID,
+			Type:                domain.LineItemType(item.Type),
+			Quantity:            item.Quantity,
+			UnitPrice:           item.UnitPrice,
+			SurchargePercent:    surcharge,
+			ExemptFromSurcharge: item.ExemptFromSurcharge,
+			IsCredit:            item.IsCredit,
+			TaxTreatment:        domain.TaxTreatment(item.TaxTreatment),
 		}
 	}
 
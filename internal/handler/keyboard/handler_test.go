@@ -0,0 +1,111 @@
+package keyboard_test
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+	"github.com/dukerupert/skalkaho/internal/handler/keyboard"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/router"
+	keyboardtemplates "github.com/dukerupert/skalkaho/internal/templates/keyboard"
+)
+
+// testApp wires a handler to a fresh in-memory database with all migrations applied.
+type testApp struct {
+	db      *sql.DB
+	queries *repository.Queries
+	handler *keyboard.Handler
+	mux     *http.ServeMux
+}
+
+// testAPIToken is the API token newTestApp configures; see apiGet.
+const testAPIToken = "test-api-token"
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	return newTestAppWithConfig(t, &config.Config{APIToken: testAPIToken})
+}
+
+// newTestAppWithConfig is newTestApp with a custom configuration.
+func newTestAppWithConfig(t *testing.T, cfg *config.Config) *testApp {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	// Every connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	goose.SetBaseFS(os.DirFS("../../../migrations"))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatalf("setting dialect: %v", err)
+	}
+	if err := goose.Up(db, "."); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+
+	renderer, err := keyboardtemplates.NewRenderer()
+	if err != nil {
+		t.Fatalf("creating renderer: %v", err)
+	}
+
+	queries := repository.New(db)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := keyboard.NewHandler(db, queries, renderer, logger, cfg)
+
+	mux := http.NewServeMux()
+	router.Register(mux, h)
+
+	return &testApp{db: db, queries: queries, handler: h, mux: mux}
+}
+
+// do sends a request through the router and returns the recorded response.
+func (a *testApp) do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	a.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// get sends a GET request.
+func (a *testApp) get(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	return a.do(httptest.NewRequest(http.MethodGet, target, nil))
+}
+
+// apiGet sends a GET request to the JSON API with the test API token.
+func (a *testApp) apiGet(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	return a.do(req)
+}
+
+// postForm sends a url-encoded form with the given method.
+func (a *testApp) postForm(t *testing.T, method, target string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return a.do(req)
+}
+
+// exec runs a statement directly against the test database.
+func (a *testApp) exec(t *testing.T, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := a.db.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
@@ -0,0 +1,63 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// postKeyed sends an HTMX form post carrying an idempotency key.
+func postKeyed(app *testApp, target, key string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("X-Idempotency-Key", key)
+	return app.do(req)
+}
+
+func TestIdempotentCreates(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Addition')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+
+	tests := []struct {
+		name   string
+		target string
+		form   url.Values
+		count  string
+	}{
+		{"job", "/jobs", url.Values{"name": {"Deck"}}, `SELECT COUNT(*) FROM jobs WHERE name = 'Deck'`},
+		{"category", "/jobs/job-1/categories", url.Values{"name": {"Roofing"}}, `SELECT COUNT(*) FROM categories WHERE name = 'Roofing'`},
+		{"subcategory", "/categories/cat-1/subcategories", url.Values{"name": {"Walls"}}, `SELECT COUNT(*) FROM categories WHERE name = 'Walls'`},
+		{"line item", "/categories/cat-1/items", url.Values{
+			"type": {"material"}, "name": {"2x4"}, "quantity": {"10"}, "unit": {"ea"}, "unit_price": {"3.50"},
+		}, `SELECT COUNT(*) FROM line_items WHERE name = '2x4'`},
+		{"quick add", "/quick-add", url.Values{
+			"category_id": {"cat-1"}, "type": {"labor"}, "name": {"Framer"}, "quantity": {"8"}, "unit": {"hr"}, "unit_price": {"50"},
+		}, `SELECT COUNT(*) FROM line_items WHERE name = 'Framer'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := postKeyed(app, tt.target, "key-"+tt.name, tt.form)
+			second := postKeyed(app, tt.target, "key-"+tt.name, tt.form)
+
+			if n := countRows(t, app, tt.count); n != 1 {
+				t.Errorf("rows after duplicate submit = %d, want 1", n)
+			}
+			if second.Code != first.Code || second.Header().Get("HX-Redirect") != first.Header().Get("HX-Redirect") {
+				t.Errorf("duplicate got %d %q, want replay of %d %q",
+					second.Code, second.Header().Get("HX-Redirect"), first.Code, first.Header().Get("HX-Redirect"))
+			}
+			if second.Header().Get("Idempotent-Replayed") != "true" {
+				t.Errorf("duplicate was not marked as replayed")
+			}
+
+			postKeyed(app, tt.target, "other-key-"+tt.name, tt.form)
+			if n := countRows(t, app, tt.count); n != 2 {
+				t.Errorf("rows after a new submit = %d, want 2", n)
+			}
+		})
+	}
+}
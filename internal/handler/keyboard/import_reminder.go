@@ -0,0 +1,47 @@
+package keyboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+// ImportReminder counts price imports that have waited too long for review.
+type ImportReminder struct {
+	Days    int64 // Reminder window from the settings
+	Overdue int64 // Ready imports older than Days
+	Stale   int64 // Imports the cleanup marked stale
+}
+
+// importReminder returns the reminder banner for the price import page and
+// the dashboard, or nil when reminders are off or nothing is waiting. Errors
+// are logged and hide the banner rather than failing the page.
+func (h *Handler) importReminder(ctx context.Context) *ImportReminder {
+	logger := middleware.LoggerFromContext(ctx)
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to load settings", "error", err)
+		return nil
+	}
+	if settings.ImportReminderDays <= 0 {
+		return nil
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -int(settings.ImportReminderDays)).Format("2006-01-02 15:04:05")
+	counts, err := h.queries.GetImportReminder(ctx, since)
+	if err != nil {
+		logger.Error("failed to count overdue imports", "error", err)
+		return nil
+	}
+	if counts.Overdue == 0 && counts.Stale == 0 {
+		return nil
+	}
+
+	return &ImportReminder{
+		Days:    settings.ImportReminderDays,
+		Overdue: counts.Overdue,
+		Stale:   counts.Stale,
+	}
+}
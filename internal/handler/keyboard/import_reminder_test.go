@@ -0,0 +1,98 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedReminderImports(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO price_imports (id, filename, status, created_at) VALUES
+		('imp-applied', 'applied.xlsx', 'applied', datetime('now', '-1 days')),
+		('imp-old', 'old.xlsx', 'ready', datetime('now', '-20 days')),
+		('imp-new', 'new.xlsx', 'ready', datetime('now', '-2 days')),
+		('imp-pending', 'pending.xlsx', 'ready', datetime('now', '-3 days'))`)
+	app.exec(t, `INSERT INTO price_import_matches (import_id, row_number, source_name, source_price, status) VALUES
+		('imp-pending', 1, 'STUD', 5.50, 'pending')`)
+}
+
+func TestImportReminder_Banner(t *testing.T) {
+	app := newTestApp(t)
+
+	if body := app.get(t, "/").Body.String(); strings.Contains(body, `id="import-reminder"`) {
+		t.Fatalf("reminder shown with no imports")
+	}
+
+	seedReminderImports(t, app)
+
+	for _, target := range []string{"/", "/price-import"} {
+		body := app.get(t, target).Body.String()
+		if !strings.Contains(body, "1 price import has been waiting for review more than 14 days.") {
+			t.Errorf("%s missing reminder banner", target)
+		}
+	}
+
+	app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_import_days":  {"30"},
+		"import_reminder_days": {"0"},
+	})
+	if body := app.get(t, "/").Body.String(); strings.Contains(body, `id="import-reminder"`) {
+		t.Errorf("reminder shown after turning reminders off")
+	}
+}
+
+func TestRunCleanup_ExpiresImports(t *testing.T) {
+	app := newTestApp(t)
+	seedReminderImports(t, app)
+
+	rec := app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_import_days":  {"30"},
+		"import_reminder_days": {"14"},
+		"import_stale_days":    {"10"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("save status = %d, want 303", rec.Code)
+	}
+	app.postForm(t, http.MethodPost, "/settings/cleanup/run", nil)
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE status = 'stale'`); n != 1 {
+		t.Fatalf("stale imports = %d, want 1", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE id = 'imp-old' AND status = 'stale'`); n != 1 {
+		t.Errorf("old ready import was not marked stale")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM cleanup_runs WHERE expired_imports = 1`); n != 1 {
+		t.Errorf("expired imports not recorded")
+	}
+
+	body := app.get(t, "/price-import").Body.String()
+	if !strings.Contains(body, "1 stale import can no longer be applied") {
+		t.Errorf("price import page missing stale reminder")
+	}
+
+	// Stale imports sort first, then ready imports with pending matches.
+	old := strings.Index(body, "old.xlsx")
+	pending := strings.Index(body, "pending.xlsx")
+	applied := strings.Index(body, "applied.xlsx")
+	newer := strings.Index(body, "new.xlsx")
+	if old < 0 || !(old < pending && pending < applied && applied < newer) {
+		t.Errorf("imports out of order: stale %d, pending %d, applied %d, new %d", old, pending, applied, newer)
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/price-import/imp-old/apply", nil); rec.Code != http.StatusConflict {
+		t.Errorf("apply stale status = %d, want 409", rec.Code)
+	}
+}
+
+func TestUpdateCleanupSettings_RejectsNegativeReminder(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"import_reminder_days": {"-1"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,234 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// commentAuthorCookie remembers the name last used on a comment so the next
+// one is signed the same way.
+const commentAuthorCookie = "comment_author"
+
+// CommentCounts are a job's unresolved line item comments, by item and by
+// category. A category's count includes its subcategories.
+type CommentCounts struct {
+	Items      map[string]int64
+	Categories map[string]int64
+	Total      int64
+}
+
+// openCommentCounts counts a job's unresolved comments and rolls them up
+// through the category tree.
+func (h *Handler) openCommentCounts(ctx context.Context, jobID string, categories []repository.Category) (CommentCounts, error) {
+	rows, err := h.queries.CountOpenItemCommentsByJob(ctx, jobID)
+	if err != nil {
+		return CommentCounts{}, err
+	}
+
+	parents := make(map[string]string, len(categories))
+	for _, cat := range categories {
+		if cat.ParentID.Valid {
+			parents[cat.ID] = cat.ParentID.String
+		}
+	}
+
+	counts := CommentCounts{
+		Items:      make(map[string]int64, len(rows)),
+		Categories: make(map[string]int64),
+	}
+	for _, row := range rows {
+		counts.Items[row.ItemID] = row.OpenCount
+		counts.Total += row.OpenCount
+		for id := row.CategoryID; id != ""; id = parents[id] {
+			counts.Categories[id] += row.OpenCount
+		}
+	}
+	return counts, nil
+}
+
+// commentAuthor returns the name remembered from the last comment.
+func commentAuthor(r *http.Request) string {
+	if cookie, err := r.Cookie(commentAuthorCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// activityActor names who made a change for the job activity log. There are
+// no user accounts, so it is the name remembered from the last comment, or
+// "system" when none has been given.
+func activityActor(r *http.Request) string {
+	if author := strings.TrimSpace(commentAuthor(r)); author != "" {
+		return author
+	}
+	return "system"
+}
+
+// GetItemComments renders the comment thread under a line item's row.
+func (h *Handler) GetItemComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	item, err := h.queries.GetLineItem(ctx, r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Line item not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get line item", "error", err)
+		http.Error(w, "Failed to load line item", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderItemComments(w, r, item.ID, commentAuthor(r))
+}
+
+// CreateItemComment adds a comment to a line item and remembers the author's
+// name for next time.
+func (h *Handler) CreateItemComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	author := strings.TrimSpace(r.FormValue("author"))
+	body := strings.TrimSpace(r.FormValue("body"))
+	if author == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	if body == "" {
+		http.Error(w, "Comment is required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.queries.GetLineItem(ctx, r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Line item not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get line item", "error", err)
+		http.Error(w, "Failed to load line item", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.queries.CreateItemComment(ctx, repository.CreateItemCommentParams{
+		ItemID: item.ID,
+		Author: author,
+		Body:   body,
+	}); err != nil {
+		logger.Error("failed to create item comment", "error", err)
+		http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     commentAuthorCookie,
+		Value:    author,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.renderItemComments(w, r, item.ID, author)
+		return
+	}
+	http.Redirect(w, r, "/categories/"+item.CategoryID, http.StatusSeeOther)
+}
+
+// ResolveItemComment marks a comment as dealt with. Resolved comments stay in
+// the thread but no longer count as open.
+func (h *Handler) ResolveItemComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+
+	comment, err := h.queries.GetItemComment(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get item comment", "error", err)
+		http.Error(w, "Failed to load comment", http.StatusInternalServerError)
+		return
+	}
+
+	n, err := h.queries.ResolveItemComment(ctx, id)
+	if err != nil {
+		logger.Error("failed to resolve item comment", "error", err)
+		http.Error(w, "Failed to resolve comment", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Comment is already resolved", http.StatusConflict)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.renderItemComments(w, r, comment.ItemID, commentAuthor(r))
+		return
+	}
+
+	item, err := h.queries.GetLineItem(ctx, comment.ItemID)
+	if err != nil {
+		logger.Error("failed to get line item", "error", err)
+		http.Error(w, "Failed to load line item", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/categories/"+item.CategoryID, http.StatusSeeOther)
+}
+
+// renderItemComments writes an item's comment thread, with the row's comment
+// badge swapped in out of band so its count stays current.
+func (h *Handler) renderItemComments(w http.ResponseWriter, r *http.Request, itemID, author string) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	comments, err := h.queries.ListItemComments(ctx, itemID)
+	if err != nil {
+		logger.Error("failed to list item comments", "error", err)
+		http.Error(w, "Failed to load comments", http.StatusInternalServerError)
+		return
+	}
+
+	var open int64
+	for _, c := range comments {
+		if !c.Resolved {
+			open++
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "item_comments", map[string]interface{}{
+		"ItemID":    itemID,
+		"Comments":  comments,
+		"OpenCount": open,
+		"Author":    author,
+		"OOB":       true,
+	}); err != nil {
+		logger.Error("failed to render item comments", "error", err)
+		http.Error(w, "Failed to render comments", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,124 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedCommentJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, customer_name, expires_at) VALUES ('job-c', 'Kitchen', 'Pat', '2099-01-01')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES
+		('cat-c', 'job-c', NULL, 'Cabinets'),
+		('cat-c-sub', 'job-c', 'cat-c', 'Hardware')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-box', 'cat-c', 'material', 'Base cabinet', 4, 'ea', 320),
+		('item-pull', 'cat-c-sub', 'material', 'Drawer pull', 12, 'ea', 6)`)
+}
+
+func TestCreateItemComment(t *testing.T) {
+	app := newTestApp(t)
+	seedCommentJob(t, app)
+
+	req := httptest.NewRequest(http.MethodPost, "/items/item-pull/comments", strings.NewReader(url.Values{
+		"author": {"Sam"}, "body": {"Confirm finish with client"},
+	}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec := app.do(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Confirm finish with client") || !strings.Contains(body, `id="comment-badge-item-pull"`) {
+		t.Errorf("thread = %q", body)
+	}
+	if !strings.Contains(rec.Header().Get("Set-Cookie"), "comment_author=Sam") {
+		t.Errorf("author not remembered")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_comments WHERE item_id = 'item-pull' AND author = 'Sam' AND resolved = 0`); n != 1 {
+		t.Errorf("comments = %d, want 1", n)
+	}
+
+	for _, form := range []url.Values{
+		{"author": {"Sam"}, "body": {"  "}},
+		{"author": {""}, "body": {"Missing name"}},
+	} {
+		if rec := app.postForm(t, http.MethodPost, "/items/item-pull/comments", form); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", form, rec.Code)
+		}
+	}
+	if rec := app.postForm(t, http.MethodPost, "/items/missing/comments", url.Values{"author": {"Sam"}, "body": {"Hi"}}); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown item status = %d, want 404", rec.Code)
+	}
+}
+
+func TestResolveItemComment(t *testing.T) {
+	app := newTestApp(t)
+	seedCommentJob(t, app)
+	app.exec(t, `INSERT INTO item_comments (id, item_id, author, body) VALUES (1, 'item-box', 'Sam', 'Check the width')`)
+
+	rec := app.do(httptest.NewRequest(http.MethodPost, "/comments/1/resolve", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/categories/cat-c" {
+		t.Fatalf("status = %d, location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_comments WHERE id = 1 AND resolved = 1`); n != 1 {
+		t.Errorf("comment not resolved")
+	}
+
+	if rec := app.do(httptest.NewRequest(http.MethodPost, "/comments/1/resolve", nil)); rec.Code != http.StatusConflict {
+		t.Errorf("second resolve status = %d, want 409", rec.Code)
+	}
+	if rec := app.do(httptest.NewRequest(http.MethodPost, "/comments/99/resolve", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("missing comment status = %d, want 404", rec.Code)
+	}
+
+	// Resolved comments stay in the thread.
+	body := app.get(t, "/items/item-box/comments").Body.String()
+	if !strings.Contains(body, "Check the width") || !strings.Contains(body, "data-resolved") {
+		t.Errorf("thread = %q", body)
+	}
+}
+
+func TestItemComments_Counts(t *testing.T) {
+	app := newTestApp(t)
+	seedCommentJob(t, app)
+	app.exec(t, `INSERT INTO item_comments (item_id, author, body, resolved) VALUES
+		('item-box', 'Sam', 'Check the width', 0),
+		('item-pull', 'Sam', 'Confirm finish', 0),
+		('item-pull', 'Sam', 'Count looks high', 0),
+		('item-pull', 'Sam', 'Already ordered', 1)`)
+
+	// The parent category counts its subcategory's comments.
+	body := app.get(t, "/categories/cat-c").Body.String()
+	for _, want := range []string{"3 open comments", "2 open comments", `hx-get="/items/item-box/comments"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("category page missing %q", want)
+		}
+	}
+	body = app.get(t, "/categories/cat-c-sub").Body.String()
+	if !strings.Contains(body, "2 open comments") || strings.Contains(body, "3 open comments") {
+		t.Errorf("subcategory page has the wrong count")
+	}
+
+	body = app.get(t, "/jobs/job-c").Body.String()
+	if !strings.Contains(body, "3 open comments") {
+		t.Errorf("job page missing the comment count")
+	}
+
+	body = app.get(t, "/jobs/job-c/preflight").Body.String()
+	for _, want := range []string{"Unresolved comments", "Base cabinet has 1 unresolved comment", "Drawer pull has 2 unresolved comments"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("checklist missing %q", want)
+		}
+	}
+
+	// Comments stay internal.
+	body = app.get(t, "/jobs/job-c/print").Body.String()
+	if strings.Contains(body, "Confirm finish") || strings.Contains(body, "open comment") {
+		t.Errorf("print view shows comments")
+	}
+}
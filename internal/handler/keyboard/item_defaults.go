@@ -0,0 +1,79 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+)
+
+// ItemDefaults is the item type and unit a category's new line items start
+// with, each taken from the category or else its nearest ancestor that sets
+// one.
+type ItemDefaults struct {
+	Type sql.NullString
+	Unit sql.NullString
+}
+
+// typeDefaultUnit is the unit new items of a type start with when no
+// category sets one.
+func typeDefaultUnit(itemType string) string {
+	switch itemType {
+	case "labor":
+		return "hr"
+	case "equipment":
+		return "day"
+	case "subcontract", "fee":
+		return "job"
+	}
+	return "ea"
+}
+
+// Resolve returns the type and unit for a new item. An explicit type wins
+// over the category's; the category's unit only applies to items of its
+// default type, so pressing "m" in a labor category still starts at "ea".
+func (d ItemDefaults) Resolve(itemType string) (string, string) {
+	if itemType == "" {
+		itemType = "material"
+		if d.Type.Valid {
+			itemType = d.Type.String
+		}
+	}
+	if d.Unit.Valid && (!d.Type.Valid || d.Type.String == itemType) {
+		return itemType, d.Unit.String
+	}
+	return itemType, typeDefaultUnit(itemType)
+}
+
+// categoryItemDefaults walks up from a category to find its item defaults.
+func (h *Handler) categoryItemDefaults(ctx context.Context, categoryID string) (ItemDefaults, error) {
+	var defaults ItemDefaults
+	for id := categoryID; id != "" && !(defaults.Type.Valid && defaults.Unit.Valid); {
+		category, err := h.queries.GetCategory(ctx, id)
+		if err != nil {
+			return defaults, err
+		}
+		if !defaults.Type.Valid {
+			defaults.Type = category.DefaultItemType
+		}
+		if !defaults.Unit.Valid {
+			defaults.Unit = category.DefaultUnit
+		}
+		id = category.ParentID.String
+	}
+	return defaults, nil
+}
+
+// formItemDefaults reads the default_item_type and default_unit fields of a
+// submitted form, where empty means inherit. The second result is false when
+// the form has no such fields, so callers can keep the old values.
+func formItemDefaults(r *http.Request) (ItemDefaults, bool) {
+	_, hasType := r.Form["default_item_type"]
+	_, hasUnit := r.Form["default_unit"]
+	if !hasType && !hasUnit {
+		return ItemDefaults{}, false
+	}
+	return ItemDefaults{
+		Type: toNullString(r.FormValue("default_item_type")),
+		Unit: toNullString(r.FormValue("default_unit")),
+	}, true
+}
@@ -0,0 +1,105 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// seedItemDefaultsJob builds Framing Labor (labor/hr) > Walls (no defaults) >
+// Sheathing (unit sqft), plus an unrelated category with no defaults.
+func seedItemDefaultsJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-defaults', 'Addition')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name, default_item_type, default_unit) VALUES
+		('cat-labor', 'job-defaults', NULL, 'Framing Labor', 'labor', 'hr'),
+		('cat-walls', 'job-defaults', 'cat-labor', 'Walls', NULL, NULL),
+		('cat-sheathing', 'job-defaults', 'cat-walls', 'Sheathing', NULL, 'sqft'),
+		('cat-plain', 'job-defaults', NULL, 'Misc', NULL, NULL)`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-existing', 'cat-walls', 'material', 'Studs', 10, 'ea', 4)`)
+}
+
+func TestInlineForm_CategoryDefaults(t *testing.T) {
+	app := newTestApp(t)
+	seedItemDefaultsJob(t, app)
+
+	tests := []struct {
+		target   string
+		wantType string
+		wantUnit string
+	}{
+		{"/categories/cat-labor/form", "labor", "hr"},
+		{"/categories/cat-walls/form", "labor", "hr"},       // both inherited from the parent
+		{"/categories/cat-sheathing/form", "labor", "sqft"}, // own unit, type from the grandparent
+		{"/categories/cat-plain/form", "material", "ea"},    // global defaults
+		{"/categories/cat-walls/form?type=material", "material", "ea"},
+		{"/categories/cat-walls/form?type=equipment", "equipment", "day"},
+	}
+	for _, tt := range tests {
+		body := app.get(t, tt.target).Body.String()
+		if !strings.Contains(body, `name="type" value="`+tt.wantType+`"`) {
+			t.Errorf("%s: want type %s", tt.target, tt.wantType)
+		}
+		if !strings.Contains(body, `value="`+tt.wantUnit+`"`) {
+			t.Errorf("%s: want unit %s", tt.target, tt.wantUnit)
+		}
+	}
+}
+
+func TestCreateLineItem_CategoryDefaults(t *testing.T) {
+	app := newTestApp(t)
+	seedItemDefaultsJob(t, app)
+
+	app.postForm(t, http.MethodPost, "/categories/cat-walls/items", url.Values{"name": {"Wall framing"}, "quantity": {"16"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Wall framing' AND type = 'labor' AND unit = 'hr'`); n != 1 {
+		t.Errorf("item without type or unit did not use inherited defaults")
+	}
+
+	app.postForm(t, http.MethodPost, "/categories/cat-plain/items", url.Values{"name": {"Dumpster"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Dumpster' AND type = 'material' AND unit = 'ea'`); n != 1 {
+		t.Errorf("item in category without defaults did not use global defaults")
+	}
+
+	app.postForm(t, http.MethodPost, "/categories/cat-walls/items", url.Values{"name": {"Nails"}, "type": {"material"}, "unit": {"box"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Nails' AND type = 'material' AND unit = 'box'`); n != 1 {
+		t.Errorf("explicit type and unit were overridden")
+	}
+}
+
+func TestUpdateCategoryMarkup_ItemDefaults(t *testing.T) {
+	app := newTestApp(t)
+	seedItemDefaultsJob(t, app)
+
+	body := app.get(t, "/categories/cat-walls/markup").Body.String()
+	if !strings.Contains(body, "Inherit (labor)") || !strings.Contains(body, `placeholder="hr"`) {
+		t.Errorf("markup form does not show inherited defaults")
+	}
+
+	rec := app.postForm(t, http.MethodPut, "/categories/cat-walls/markup", url.Values{
+		"surcharge_percent": {""}, "default_item_type": {"equipment"}, "default_unit": {"week"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM categories WHERE id = 'cat-walls' AND default_item_type = 'equipment' AND default_unit = 'week'`); n != 1 {
+		t.Errorf("category defaults not saved")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-existing' AND type = 'material' AND unit = 'ea'`); n != 1 {
+		t.Errorf("changing category defaults altered an existing item")
+	}
+
+	// Clearing the fields goes back to inheriting.
+	app.postForm(t, http.MethodPut, "/categories/cat-walls/markup", url.Values{
+		"surcharge_percent": {""}, "default_item_type": {""}, "default_unit": {""},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM categories WHERE id = 'cat-walls' AND default_item_type IS NULL AND default_unit IS NULL`); n != 1 {
+		t.Errorf("category defaults not cleared")
+	}
+
+	rec = app.postForm(t, http.MethodPut, "/categories/cat-walls/markup", url.Values{"default_item_type": {"widget"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid type status = %d, want 400", rec.Code)
+	}
+}
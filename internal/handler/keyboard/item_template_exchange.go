@@ -0,0 +1,254 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/pricebook"
+	"github.com/dukerupert/skalkaho/internal/upload"
+)
+
+// ExportItemTemplates downloads all item templates as a price book JSON document.
+// Prices are included unless the prices query parameter is "false" or "0".
+func (h *Handler) ExportItemTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	includePrices := true
+	switch r.URL.Query().Get("prices") {
+	case "false", "0", "no":
+		includePrices = false
+	}
+
+	items, err := h.queries.ListItemTemplates(ctx)
+	if err != nil {
+		logger.Error("failed to list item templates", "error", err)
+		http.Error(w, "Failed to load item templates", http.StatusInternalServerError)
+		return
+	}
+
+	doc := &pricebook.Document{
+		SchemaVersion:  pricebook.SchemaVersion,
+		IncludesPrices: includePrices,
+		Templates:      toPricebookTemplates(items, includePrices),
+	}
+
+	var buf bytes.Buffer
+	if err := pricebook.Encode(&buf, doc); err != nil {
+		logger.Error("failed to encode item templates", "error", err)
+		http.Error(w, "Failed to export item templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="item-templates.json"`)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// ImportItemTemplates merges a price book JSON document into the item templates.
+// An uploaded file renders a conflict-resolution preview; submitting the preview
+// with apply=true performs the merge in a single transaction and shows a summary.
+func (h *Handler) ImportItemTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	var payload []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, err := upload.FromRequest(w, r, "file", upload.JSON)
+		if err != nil {
+			var uploadErr *upload.Error
+			if errors.As(err, &uploadErr) {
+				logger.Warn("rejected item template upload", "code", uploadErr.Code, "error", uploadErr.Message)
+				data := map[string]interface{}{
+					"Error": uploadErr.Message,
+				}
+				if err := h.renderer.Render(w, "item_templates_import", data); err != nil {
+					logger.Error("failed to render item templates import page", "error", err)
+				}
+				return
+			}
+			logger.Error("failed to read file", "error", err)
+			http.Error(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+		payload = file.Data
+	} else {
+		payload = []byte(r.FormValue("payload"))
+	}
+
+	doc, err := pricebook.Decode(bytes.NewReader(payload))
+	if err != nil {
+		http.Error(w, "Invalid price book: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("apply") != "true" {
+		items, err := h.queries.ListItemTemplates(ctx)
+		if err != nil {
+			logger.Error("failed to list item templates", "error", err)
+			http.Error(w, "Failed to load item templates", http.StatusInternalServerError)
+			return
+		}
+
+		data := map[string]interface{}{
+			"Changes":        pricebook.Plan(toPricebookTemplates(items, true), doc.Templates, doc.IncludesPrices),
+			"IncludesPrices": doc.IncludesPrices,
+			"Payload":        string(payload),
+		}
+
+		if err := h.renderer.Render(w, "item_templates_import", data); err != nil {
+			logger.Error("failed to render item templates import page", "error", err)
+		}
+		return
+	}
+
+	summary, err := h.applyItemTemplateImport(ctx, doc, r)
+	if err != nil {
+		logger.Error("failed to import item templates", "error", err)
+		http.Error(w, "Failed to import item templates", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("imported item templates",
+		"created", summary.Created,
+		"updated", summary.Updated,
+		"kept", summary.Kept,
+		"skipped", summary.Skipped,
+	)
+
+	data := map[string]interface{}{
+		"Summary": summary,
+	}
+
+	if err := h.renderer.Render(w, "item_templates_import", data); err != nil {
+		logger.Error("failed to render item templates import page", "error", err)
+	}
+}
+
+// applyItemTemplateImport re-plans the import against the current templates and
+// applies each change according to the resolution submitted for its index.
+// Either every change is applied or none are.
+func (h *Handler) applyItemTemplateImport(ctx context.Context, doc *pricebook.Document, r *http.Request) (pricebook.Summary, error) {
+	var summary pricebook.Summary
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	items, err := qtx.ListItemTemplates(ctx)
+	if err != nil {
+		return summary, fmt.Errorf("listing item templates: %w", err)
+	}
+
+	idsByName := make(map[string]int64, len(items))
+	pricesByID := make(map[int64]float64, len(items))
+	for _, item := range items {
+		if _, ok := idsByName[item.Name]; !ok {
+			idsByName[item.Name] = item.ID
+		}
+		pricesByID[item.ID] = item.DefaultPrice
+	}
+
+	for _, change := range pricebook.Plan(toPricebookTemplates(items, true), doc.Templates, doc.IncludesPrices) {
+		resolution := pricebook.Resolution(r.FormValue("resolution_" + strconv.Itoa(change.Index)))
+		if resolution == "" {
+			resolution = change.DefaultResolution()
+		}
+
+		switch change.Action {
+		case pricebook.ActionInvalid:
+			summary.Invalid++
+			continue
+		case pricebook.ActionUnchanged:
+			summary.Unchanged++
+			continue
+		}
+
+		switch resolution {
+		case pricebook.ResolutionSkip:
+			summary.Skipped++
+			continue
+		case pricebook.ResolutionKeepMine:
+			if change.Action == pricebook.ActionConflict {
+				summary.Kept++
+			} else {
+				summary.Skipped++
+			}
+			continue
+		case pricebook.ResolutionTakeIncoming:
+		default:
+			return summary, fmt.Errorf("unknown resolution %q for %q", resolution, change.Incoming.Name)
+		}
+
+		in := change.Incoming
+		price := 0.0
+		if in.DefaultPrice != nil {
+			price = *in.DefaultPrice
+		}
+
+		if change.Action == pricebook.ActionNew {
+			if _, err := qtx.CreateItemTemplate(ctx, repository.CreateItemTemplateParams{
+				Type:         in.Type,
+				Category:     in.Category,
+				Name:         in.Name,
+				DefaultUnit:  in.DefaultUnit,
+				DefaultPrice: price,
+			}); err != nil {
+				return summary, fmt.Errorf("creating item template %q: %w", in.Name, err)
+			}
+			summary.Created++
+			continue
+		}
+
+		id := idsByName[in.Name]
+		if in.DefaultPrice == nil {
+			// A price-less document only shares structure; keep our price.
+			price = pricesByID[id]
+		}
+		if _, err := updateTemplate(ctx, qtx, repository.UpdateItemTemplateParams{
+			ID:           id,
+			Type:         in.Type,
+			Category:     in.Category,
+			Name:         in.Name,
+			DefaultUnit:  in.DefaultUnit,
+			DefaultPrice: price,
+		}, pricesByID[id], priceChange{Source: priceSourceTemplateImport}); err != nil {
+			return summary, fmt.Errorf("updating item template %q: %w", in.Name, err)
+		}
+		summary.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, fmt.Errorf("committing transaction: %w", err)
+	}
+	return summary, nil
+}
+
+// toPricebookTemplates converts item templates to the exchange format.
+func toPricebookTemplates(items []repository.ItemTemplate, includePrices bool) []pricebook.Template {
+	templates := make([]pricebook.Template, len(items))
+	for i, item := range items {
+		templates[i] = pricebook.Template{
+			Type:        item.Type,
+			Category:    item.Category,
+			Name:        item.Name,
+			DefaultUnit: item.DefaultUnit,
+		}
+		if includePrices {
+			price := item.DefaultPrice
+			templates[i].DefaultPrice = &price
+		}
+	}
+	return templates
+}
@@ -0,0 +1,131 @@
+package keyboard_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func uploadPriceBook(t *testing.T, app *testApp, payload []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "item-templates.json")
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	_, _ = part.Write(payload)
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/items/import.json", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return app.do(req)
+}
+
+func TestItemTemplateExport_RoundTrip(t *testing.T) {
+	for _, target := range []string{"/items/export.json", "/items/export.json?prices=false"} {
+		t.Run(target, func(t *testing.T) {
+			source := newTestApp(t)
+			source.exec(t, `INSERT INTO item_templates (type, category, name, default_unit, default_price) VALUES ('labor', 'Crew', 'Foreman "lead"', 'hr', 72.125)`)
+
+			rec := source.get(t, target)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("export status = %d, want 200", rec.Code)
+			}
+			exported := rec.Body.Bytes()
+
+			// Import into an install with no templates of its own.
+			dest := newTestApp(t)
+			dest.exec(t, "DELETE FROM item_templates")
+
+			rec = uploadPriceBook(t, dest, exported)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("preview status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+
+			rec = dest.postForm(t, http.MethodPost, "/items/import.json", url.Values{
+				"apply":   {"true"},
+				"payload": {string(exported)},
+			})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("apply status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+
+			rec = dest.get(t, target)
+			if !bytes.Equal(rec.Body.Bytes(), exported) {
+				t.Errorf("round-tripped export differs from original\noriginal: %.200s\nround-trip: %.200s", exported, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestItemTemplateImport_Resolutions(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, "DELETE FROM item_templates")
+	app.exec(t, `INSERT INTO item_templates (type, category, name, default_unit, default_price) VALUES
+		('material', 'Lumber', 'Stud', 'ea', 4.00),
+		('material', 'Lumber', 'Plate', 'ea', 5.00),
+		('material', 'Lumber', 'Header', 'ea', 9.00)`)
+
+	payload := `{
+  "schema_version": 1,
+  "includes_prices": true,
+  "templates": [
+    {"type": "material", "category": "Lumber", "name": "Stud", "default_unit": "ea", "default_price": 4.5},
+    {"type": "material", "category": "Lumber", "name": "Plate", "default_unit": "ea", "default_price": 6},
+    {"type": "material", "category": "Lumber", "name": "Header", "default_unit": "ea", "default_price": 11},
+    {"type": "material", "category": "Lumber", "name": "Joist", "default_unit": "ea", "default_price": 12},
+    {"type": "material", "category": "Lumber", "name": "Blocking", "default_unit": "ea", "default_price": 2},
+    {"type": "widget", "category": "Lumber", "name": "Bad", "default_unit": "ea"}
+  ]
+}`
+
+	rec := uploadPriceBook(t, app, []byte(payload))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `name="resolution_0"`) {
+		t.Errorf("preview is missing conflict resolution controls")
+	}
+
+	rec = app.postForm(t, http.MethodPost, "/items/import.json", url.Values{
+		"apply":        {"true"},
+		"payload":      {payload},
+		"resolution_0": {"take_incoming"},
+		"resolution_1": {"keep_mine"},
+		"resolution_2": {"skip"},
+		"resolution_3": {"take_incoming"},
+		"resolution_4": {"skip"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	want := map[string]float64{"Stud": 4.5, "Plate": 5, "Header": 9, "Joist": 12}
+	items, err := app.queries.ListItemTemplates(t.Context())
+	if err != nil {
+		t.Fatalf("listing templates: %v", err)
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d templates, want %d", len(items), len(want))
+	}
+	for _, item := range items {
+		if price, ok := want[item.Name]; !ok || item.DefaultPrice != price {
+			t.Errorf("%s price = %v, want %v", item.Name, item.DefaultPrice, price)
+		}
+	}
+}
+
+func TestItemTemplateImport_RejectsUnknownSchemaVersion(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := uploadPriceBook(t, app, []byte(`{"schema_version": 99, "templates": []}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
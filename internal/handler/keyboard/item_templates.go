@@ -2,8 +2,11 @@ package keyboard
 
 import (
 	"bytes"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
@@ -46,12 +49,16 @@ func (h *Handler) ListItemTemplates(w http.ResponseWriter, r *http.Request) {
 		items = allItems
 	}
 
+	pagination := newPagination(r, int64(len(items)), h.pageSize(w, r))
+	items = items[pagination.Offset:min(pagination.Offset+pagination.PerPage, len(items))]
+
 	data := map[string]interface{}{
 		"Items":          items,
 		"Categories":     categories,
 		"Query":          query,
 		"TypeFilter":     typeFilter,
 		"CategoryFilter": categoryFilter,
+		"Pagination":     pagination,
 	}
 
 	// For HTMX partial requests, return just the items list
@@ -147,8 +154,11 @@ func (h *Handler) GetItemTemplateForm(w http.ResponseWriter, r *http.Request) {
 		categories = append(categories, cat)
 	}
 
+	// name and type prefill the form, e.g. from a search with no matches.
 	data := map[string]interface{}{
 		"Categories": categories,
+		"Name":       r.URL.Query().Get("name"),
+		"Type":       r.URL.Query().Get("type"),
 	}
 
 	var buf bytes.Buffer
@@ -193,9 +203,13 @@ func (h *Handler) CreateItemTemplate(w http.ResponseWriter, r *http.Request) {
 		defaultUnit = "ea"
 	}
 
-	defaultPrice, _ := strconv.ParseFloat(r.FormValue("default_price"), 64)
+	defaultPrice, err := h.formMoney(r, "default_price", "Default price")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 
-	_, err := h.queries.CreateItemTemplate(ctx, repository.CreateItemTemplateParams{
+	_, err = h.queries.CreateItemTemplate(ctx, repository.CreateItemTemplateParams{
 		Type:         itemType,
 		Category:     category,
 		Name:         name,
@@ -257,6 +271,13 @@ func (h *Handler) GetItemTemplateEditForm(w http.ResponseWriter, r *http.Request
 		"Categories": categories,
 	}
 
+	change, err := h.queries.GetLatestItemTemplatePriceChange(ctx, id)
+	if err == nil {
+		data["PriceChange"] = change
+	} else if err != sql.ErrNoRows {
+		logger.Error("failed to get price history", "error", err)
+	}
+
 	var buf bytes.Buffer
 	if err := h.renderer.RenderPartial(&buf, "item_template_edit_form", data); err != nil {
 		logger.Error("failed to render item template edit form", "error", err)
@@ -306,22 +327,80 @@ func (h *Handler) UpdateItemTemplate(w http.ResponseWriter, r *http.Request) {
 		defaultUnit = "ea"
 	}
 
-	defaultPrice, _ := strconv.ParseFloat(r.FormValue("default_price"), 64)
+	defaultPrice, err := h.formMoney(r, "default_price", "Default price")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
+
+	rates, hasRates, problem := formRentalRates(r, h.decimalSeparator())
+	if problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to update item template", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err = h.queries.UpdateItemTemplate(ctx, repository.UpdateItemTemplateParams{
+	qtx := h.queries.WithTx(tx)
+
+	existing, err := qtx.GetItemTemplate(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Item template not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get item template", "error", err)
+		http.Error(w, "Failed to update item template", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = updateTemplate(ctx, qtx, repository.UpdateItemTemplateParams{
 		ID:           id,
 		Type:         itemType,
 		Category:     category,
 		Name:         name,
 		DefaultUnit:  defaultUnit,
 		DefaultPrice: defaultPrice,
-	})
+	}, existing.DefaultPrice, priceChange{Source: priceSourceManual})
 	if err != nil {
 		logger.Error("failed to update item template", "error", err)
 		http.Error(w, "Failed to update item template", http.StatusInternalServerError)
 		return
 	}
 
+	// Forms without a SKU field leave the template's SKU alone
+	if _, ok := r.Form["sku"]; ok {
+		if err := qtx.UpdateItemTemplateSku(ctx, repository.UpdateItemTemplateSkuParams{
+			Sku: toNullString(r.FormValue("sku")),
+			ID:  id,
+		}); err != nil {
+			logger.Error("failed to update item template sku", "error", err)
+			http.Error(w, "Failed to update item template", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if hasRates {
+		rates.ID = id
+		if err := qtx.UpdateItemTemplateRentalRates(ctx, rates); err != nil {
+			logger.Error("failed to update item template rental rates", "error", err)
+			http.Error(w, "Failed to update item template", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", "error", err)
+		http.Error(w, "Failed to update item template", http.StatusInternalServerError)
+		return
+	}
+
 	// Redirect back to the items page
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/items")
@@ -343,13 +422,66 @@ func (h *Handler) DeleteItemTemplate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid item ID", http.StatusBadRequest)
 		return
 	}
+	templateID := sql.NullInt64{Int64: id, Valid: true}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	// Matches in imports that haven't been applied yet would be skipped
+	// silently once the template is gone, so refuse unless the caller asks
+	// for them to be rejected along with the delete. The check runs in the
+	// transaction so an import can't match the template between the check
+	// and the delete.
+	rejectMatches := r.URL.Query().Get("reject_matches") == "true"
+	if !rejectMatches {
+		imports, err := qtx.ListUnappliedTemplateMatchImports(ctx, templateID)
+		if err != nil {
+			logger.Error("failed to check price import matches", "error", err)
+			http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
+			return
+		}
+		if len(imports) > 0 {
+			http.Error(w, unappliedMatchesMessage(imports), http.StatusConflict)
+			return
+		}
+	} else {
+		rejected, err := qtx.RejectUnappliedTemplateMatches(ctx, templateID)
+		if err != nil {
+			logger.Error("failed to reject price import matches", "error", err)
+			http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
+			return
+		}
+		if rejected > 0 {
+			logger.Info("rejected price import matches for deleted template", "template_id", id, "matches", rejected)
+		}
+	}
+
+	// Applied and rejected matches keep their history without the template.
+	if err := qtx.ClearMatchedTemplate(ctx, templateID); err != nil {
+		logger.Error("failed to clear matched template", "error", err)
+		http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
+		return
+	}
 
-	if err := h.queries.DeleteItemTemplate(ctx, id); err != nil {
+	if err := qtx.DeleteItemTemplate(ctx, id); err != nil {
 		logger.Error("failed to delete item template", "error", err)
 		http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit template delete", "error", err)
+		http.Error(w, "Failed to delete item template", http.StatusInternalServerError)
+		return
+	}
+
 	// Redirect back to the items page
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/items")
@@ -359,3 +491,17 @@ func (h *Handler) DeleteItemTemplate(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/items", http.StatusSeeOther)
 }
+
+// unappliedMatchesMessage explains which imports block a template delete.
+func unappliedMatchesMessage(imports []repository.ListUnappliedTemplateMatchImportsRow) string {
+	parts := make([]string, len(imports))
+	for i, imp := range imports {
+		noun := "matches"
+		if imp.MatchCount == 1 {
+			noun = "match"
+		}
+		parts[i] = fmt.Sprintf("%s (%d %s)", imp.Filename, imp.MatchCount, noun)
+	}
+	return "This template is matched in price imports that haven't been applied: " +
+		strings.Join(parts, ", ") + ". Apply or review those imports first, or reject the matches."
+}
@@ -0,0 +1,79 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func seedTemplateMatches(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', '2x4x8', 'ea', 3.50)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES
+		('imp-ready', 'march.xlsx', 'ready'),
+		('imp-applied', 'february.xlsx', 'applied')`)
+	app.exec(t, `INSERT INTO price_import_matches (id, import_id, row_number, source_name, source_price, matched_template_id, status) VALUES
+		(1, 'imp-ready', 1, '2X4 8FT', 3.75, 9001, 'approved'),
+		(2, 'imp-ready', 2, '2X4 8 STUD', 3.80, 9001, 'pending'),
+		(3, 'imp-applied', 1, '2X4 8FT', 3.60, 9001, 'approved')`)
+}
+
+func TestDeleteItemTemplate_BlockedByUnappliedMatches(t *testing.T) {
+	app := newTestApp(t)
+	seedTemplateMatches(t, app)
+
+	rec := app.postForm(t, http.MethodDelete, "/item-templates/9001", nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "march.xlsx (2 matches)") {
+		t.Errorf("message does not list the affected import: %s", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "february.xlsx") {
+		t.Errorf("message lists an applied import")
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9001`); n != 1 {
+		t.Errorf("template was deleted")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_import_matches WHERE status = 'rejected'`); n != 0 {
+		t.Errorf("matches were rejected without being asked")
+	}
+}
+
+func TestDeleteItemTemplate_RejectMatches(t *testing.T) {
+	app := newTestApp(t)
+	seedTemplateMatches(t, app)
+
+	rec := app.postForm(t, http.MethodDelete, "/item-templates/9001?reject_matches=true", nil)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9001`); n != 0 {
+		t.Errorf("template was not deleted")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_import_matches WHERE import_id = 'imp-ready' AND status = 'rejected'`); n != 2 {
+		t.Errorf("rejected matches = %d, want 2", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_import_matches WHERE id = 3 AND status = 'approved'`); n != 1 {
+		t.Errorf("applied import history was changed")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_import_matches WHERE matched_template_id IS NOT NULL`); n != 0 {
+		t.Errorf("matches still reference the deleted template")
+	}
+}
+
+func TestDeleteItemTemplate_AppliedHistoryOnly(t *testing.T) {
+	app := newTestApp(t)
+	seedTemplateMatches(t, app)
+	app.exec(t, `DELETE FROM price_import_matches WHERE import_id = 'imp-ready'`)
+
+	if rec := app.postForm(t, http.MethodDelete, "/item-templates/9001", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9001`); n != 0 {
+		t.Errorf("template was not deleted")
+	}
+}
@@ -0,0 +1,74 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedDeleteJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-del', 'Smith Kitchen'), ('job-keep', 'Other')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES
+		('cat-del', 'job-del', NULL, 'Cabinets'),
+		('cat-del-sub', 'job-del', 'cat-del', 'Hardware'),
+		('cat-keep', 'job-keep', NULL, 'Cabinets')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-del', 'cat-del-sub', 'material', 'Drawer pull', 12, 'ea', 6),
+		('item-keep', 'cat-keep', 'material', 'Drawer pull', 12, 'ea', 6)`)
+	app.exec(t, `INSERT INTO item_comments (item_id, author, body) VALUES ('item-del', 'Sam', 'Finish?')`)
+}
+
+func deleteJob(app *testApp, id, name string) *httptest.ResponseRecorder {
+	return app.do(httptest.NewRequest(http.MethodDelete, "/jobs/"+id+"?"+url.Values{"confirm_name": {name}}.Encode(), nil))
+}
+
+func TestGetJobDeleteForm(t *testing.T) {
+	app := newTestApp(t)
+	seedDeleteJob(t, app)
+
+	body := app.get(t, "/jobs/job-del/delete").Body.String()
+	for _, want := range []string{"Smith Kitchen", "2 categories", "1 line item,", `name="confirm_name"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("form missing %q", want)
+		}
+	}
+	if rec := app.get(t, "/jobs/missing/delete"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing job status = %d, want 404", rec.Code)
+	}
+}
+
+func TestDeleteJob_RequiresName(t *testing.T) {
+	app := newTestApp(t)
+	seedDeleteJob(t, app)
+
+	for _, name := range []string{"", "Smith", "Smith Kitchen 2"} {
+		if rec := deleteJob(app, "job-del", name); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", name, rec.Code)
+		}
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs WHERE id = 'job-del'`); n != 1 {
+		t.Fatalf("job deleted without a matching name")
+	}
+
+	if rec := deleteJob(app, "missing", "Smith Kitchen"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing job status = %d, want 404", rec.Code)
+	}
+
+	// Case and surrounding space don't matter.
+	if rec := deleteJob(app, "job-del", "  smith KITCHEN "); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	for query, want := range map[string]int{
+		`SELECT COUNT(*) FROM jobs`:          1,
+		`SELECT COUNT(*) FROM categories`:    1,
+		`SELECT COUNT(*) FROM line_items`:    1,
+		`SELECT COUNT(*) FROM item_comments`: 0,
+	} {
+		if n := countRows(t, app, query); n != want {
+			t.Errorf("%s = %d, want %d", query, n, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package keyboard
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/excel"
+)
+
+// exportFilenameUnsafe matches characters replaced in download file names.
+var exportFilenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportJobWorkbook downloads a job as an Excel workbook with a Summary sheet
+// and one sheet per top-level category.
+func (h *Handler) ExportJobWorkbook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	customer := job.CustomerName.String
+	if job.ClientID.Valid {
+		if client, err := h.queries.GetClient(ctx, job.ClientID.String); err == nil {
+			customer = client.Name
+		}
+	}
+
+	fields, err := h.queries.ListJobCustomFieldValues(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list job custom fields", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	workbook := h.buildJobWorkbook(job, settings, customer, categories, lineItems)
+	for _, field := range fields {
+		if field.Value != "" {
+			workbook.Fields = append(workbook.Fields, excel.WorkbookField{Label: field.Label, Value: field.Value})
+		}
+	}
+
+	filename := strings.Trim(exportFilenameUnsafe.ReplaceAllString(job.Name, "-"), "-")
+	if filename == "" {
+		filename = "job"
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.xlsx"`)
+	// Stream straight to the response so large jobs aren't buffered in memory.
+	if err := excel.WriteJobWorkbook(w, workbook); err != nil {
+		logger.Error("failed to write job workbook", "error", err)
+	}
+}
+
+// buildJobWorkbook lays out a job's totals and items for the workbook export.
+func (h *Handler) buildJobWorkbook(job repository.Job, settings repository.Setting, customer string, categories []repository.Category, lineItems []repository.LineItem) excel.JobWorkbook {
+	totals := h.calculateTotals(job, settings, categories, lineItems)
+	itemsByCategory := groupItemsByCategory(lineItems)
+
+	workbook := excel.JobWorkbook{
+		SourceJobID:      job.ID,
+		ClientID:         job.ClientID.String,
+		Name:             job.Name,
+		Customer:         customer,
+		Status:           job.Status,
+		CreatedAt:        job.CreatedAt,
+		SurchargePercent: job.SurchargePercent,
+		SurchargeMode:    job.SurchargeMode,
+		TypeTotals: []excel.WorkbookTotal{
+			{Label: "Materials", Amount: totals.MaterialSubtotal},
+			{Label: "Labor", Amount: totals.LaborSubtotal},
+			{Label: "Equipment", Amount: totals.EquipmentSubtotal},
+			{Label: "Subcontracts", Amount: totals.SubcontractSubtotal},
+			{Label: "Fees", Amount: totals.FeeSubtotal},
+		},
+		Subtotal:       totals.Subtotal,
+		SurchargeTotal: totals.SurchargeTotal,
+		GrandTotal:     totals.GrandTotal,
+		TaxPercent:     job.TaxPercent,
+		TaxableBase:    totals.TaxableBase,
+		ExemptBase:     totals.ExemptBase,
+		TaxTotal:       totals.TaxTotal,
+	}
+
+	for _, node := range buildCategoryTree(categories) {
+		workbook.Categories = append(workbook.Categories, h.buildWorkbookCategory(node, job, settings, categories, lineItems, itemsByCategory))
+	}
+
+	return workbook
+}
+
+// buildWorkbookCategory flattens a category and its subcategories into one
+// section with the category's totals.
+func (h *Handler) buildWorkbookCategory(node CategoryTreeNode, job repository.Job, settings repository.Setting, categories []repository.Category, lineItems []repository.LineItem, itemsByCategory map[string][]repository.LineItem) excel.WorkbookCategory {
+	catTotal := h.calculateCategoryTotal(node.ID, job, settings, categories, lineItems)
+	section := excel.WorkbookCategory{
+		Name:           node.Name,
+		Subtotal:       catTotal.Subtotal,
+		SurchargeTotal: catTotal.SurchargeTotal,
+		Total:          catTotal.Total,
+	}
+	collectWorkbookItems(node, "", itemsByCategory, &section.Items)
+	return section
+}
+
+// collectWorkbookItems walks a category depth-first so subcategory items
+// follow their parent's items, labelled with their path below the category.
+func collectWorkbookItems(node CategoryTreeNode, section string, itemsByCategory map[string][]repository.LineItem, items *[]excel.WorkbookItem) {
+	for _, item := range itemsByCategory[node.ID] {
+		*items = append(*items, excel.WorkbookItem{
+			Section:     section,
+			Type:        item.Type,
+			Name:        item.Name,
+			Description: item.Description.String,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+	for _, child := range node.Children {
+		childSection := child.Name
+		if section != "" {
+			childSection = section + " / " + child.Name
+		}
+		collectWorkbookItems(child, childSection, itemsByCategory, items)
+	}
+}
+
+// groupItemsByCategory indexes line items by their category ID.
+func groupItemsByCategory(lineItems []repository.LineItem) map[string][]repository.LineItem {
+	itemsByCategory := make(map[string][]repository.LineItem)
+	for _, item := range lineItems {
+		itemsByCategory[item.CategoryID] = append(itemsByCategory[item.CategoryID], item)
+	}
+	return itemsByCategory
+}
@@ -0,0 +1,81 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportJobWorkbook(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage Addition', 10)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name, sort_order) VALUES
+		('cat-1', 'job-1', 'Framing', 0),
+		('cat-2', 'job-1', 'Electrical: Rough-in and Finish for Main House', 1),
+		('cat-3', 'job-1', 'framing', 2)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES ('cat-1a', 'job-1', 'cat-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 3.50, 0),
+		('item-2', 'cat-1a', 'labor', 'Framer', 8, 'hr', 50, 1),
+		('item-3', 'cat-2', 'material', 'Romex 12/2', 2, 'roll', 100, 0)`)
+
+	rec := app.get(t, "/jobs/job-1/export.xlsx")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), "Garage-Addition.xlsx") {
+		t.Errorf("Content-Disposition = %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	f, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+
+	wantSheets := []string{"Summary", "Framing", "Electrical- Rough-in and Finish", "framing (2)", "_meta"}
+	sheets := f.GetSheetList()
+	if strings.Join(sheets, "|") != strings.Join(wantSheets, "|") {
+		t.Fatalf("sheets = %q, want %q", sheets, wantSheets)
+	}
+	if visible, _ := f.GetSheetVisible("_meta"); visible {
+		t.Errorf("metadata sheet should be hidden")
+	}
+
+	cells := []struct {
+		sheet, cell, want string
+	}{
+		{"Summary", "B1", "Garage Addition"},
+		{"Summary", "A8", "Materials"},
+		{"Summary", "B8", "$258.50"},
+		{"Summary", "B9", "$440.00"},
+		{"Summary", "A15", "Grand Total"},
+		{"Summary", "B15", "$698.50"},
+		{"Summary", "A18", "Framing"},
+		{"Summary", "D18", "$478.50"},
+		{"Framing", "C1", "Item"},
+		{"Framing", "C2", "2x4x8"},
+		{"Framing", "A3", "Walls"},
+		{"Framing", "H3", "$400.00"},
+		{"Framing", "H7", "$478.50"},
+	}
+	for _, c := range cells {
+		got, err := f.GetCellValue(c.sheet, c.cell)
+		if err != nil {
+			t.Fatalf("read %s!%s: %v", c.sheet, c.cell, err)
+		}
+		if got != c.want {
+			t.Errorf("%s!%s = %q, want %q", c.sheet, c.cell, got, c.want)
+		}
+	}
+
+	panes, err := f.GetPanes("Framing")
+	if err != nil {
+		t.Fatalf("get panes: %v", err)
+	}
+	if !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("header row is not frozen: %+v", panes)
+	}
+}
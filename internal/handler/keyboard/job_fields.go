@@ -0,0 +1,238 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// Job custom field types.
+const (
+	jobFieldText   = "text"
+	jobFieldNumber = "number"
+	jobFieldDate   = "date"
+)
+
+// JobFieldInput is a custom field on the job form with its current value and
+// any validation error for it.
+type JobFieldInput struct {
+	repository.ListJobCustomFieldValuesRow
+	Error string
+}
+
+// validateJobField returns a description of the problem with value for a
+// field of the given type, or "" if it is valid. Empty values are allowed.
+func validateJobField(fieldType, value string) string {
+	if value == "" {
+		return ""
+	}
+	switch fieldType {
+	case jobFieldNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "Must be a number"
+		}
+	case jobFieldDate:
+		if _, err := time.Parse(dateLayout, value); err != nil {
+			return "Must be a date (YYYY-MM-DD)"
+		}
+	}
+	return ""
+}
+
+// jobFieldInputs returns the job's active custom fields with their values.
+func (h *Handler) jobFieldInputs(ctx context.Context, jobID string) ([]JobFieldInput, error) {
+	rows, err := h.queries.ListJobCustomFieldValues(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]JobFieldInput, len(rows))
+	for i, row := range rows {
+		inputs[i] = JobFieldInput{ListJobCustomFieldValuesRow: row}
+	}
+	return inputs, nil
+}
+
+// quoteFields returns the filled-in custom fields flagged to show on the quote.
+func (h *Handler) quoteFields(ctx context.Context, jobID string) ([]repository.ListJobCustomFieldValuesRow, error) {
+	rows, err := h.queries.ListJobCustomFieldValues(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]repository.ListJobCustomFieldValuesRow, 0, len(rows))
+	for _, row := range rows {
+		if row.ShowOnQuote && row.Value != "" {
+			fields = append(fields, row)
+		}
+	}
+	return fields, nil
+}
+
+// CreateJobCustomField defines a new custom field for jobs.
+func (h *Handler) CreateJobCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	label := strings.TrimSpace(r.FormValue("label"))
+	if label == "" {
+		http.Error(w, "Label is required", http.StatusBadRequest)
+		return
+	}
+	fieldType := r.FormValue("field_type")
+	if fieldType != jobFieldText && fieldType != jobFieldNumber && fieldType != jobFieldDate {
+		http.Error(w, "Type must be 'text', 'number', or 'date'", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.CreateJobCustomField(ctx, repository.CreateJobCustomFieldParams{
+		Label:       label,
+		FieldType:   fieldType,
+		ShowOnQuote: r.FormValue("show_on_quote") == "true",
+	}); err != nil {
+		logger.Error("failed to create job custom field", "error", err)
+		http.Error(w, "Failed to create field", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// ArchiveJobCustomField removes a custom field from job forms and quotes.
+// Values already entered are kept.
+func (h *Handler) ArchiveJobCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid field ID", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.queries.ArchiveJobCustomField(ctx, id)
+	if err != nil {
+		logger.Error("failed to archive job custom field", "error", err)
+		http.Error(w, "Failed to delete field", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Field not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// UpdateJobCustomFields saves a job's custom field values. Invalid values
+// re-render the form with an error under each offending field.
+func (h *Handler) UpdateJobCustomFields(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetJob(ctx, jobID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	fields, err := h.jobFieldInputs(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list job custom fields", "error", err)
+		http.Error(w, "Failed to load fields", http.StatusInternalServerError)
+		return
+	}
+
+	valid := true
+	for i := range fields {
+		fields[i].Value = strings.TrimSpace(r.FormValue(fmt.Sprintf("field_%d", fields[i].FieldID)))
+		fields[i].Error = validateJobField(fields[i].FieldType, fields[i].Value)
+		if fields[i].Error != "" {
+			valid = false
+		}
+	}
+
+	// Errors are returned with 200 so HTMX swaps the form with them in place.
+	if valid {
+		if err := h.saveJobCustomFields(ctx, jobID, fields); err != nil {
+			logger.Error("failed to save job custom fields", "error", err)
+			http.Error(w, "Failed to save fields", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Details saved", "type": "success"}}`)
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "job_fields_form", map[string]interface{}{
+		"JobID":  jobID,
+		"Fields": fields,
+	}); err != nil {
+		logger.Error("failed to render job fields form", "error", err)
+		http.Error(w, "Failed to render form", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// saveJobCustomFields stores each field's value, removing cleared ones.
+func (h *Handler) saveJobCustomFields(ctx context.Context, jobID string, fields []JobFieldInput) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+	for _, field := range fields {
+		if field.Value == "" {
+			err = qtx.DeleteJobCustomFieldValue(ctx, repository.DeleteJobCustomFieldValueParams{
+				JobID:   jobID,
+				FieldID: field.FieldID,
+			})
+		} else {
+			err = qtx.SetJobCustomFieldValue(ctx, repository.SetJobCustomFieldValueParams{
+				JobID:   jobID,
+				FieldID: field.FieldID,
+				Value:   field.Value,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("saving field %q: %w", field.Label, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
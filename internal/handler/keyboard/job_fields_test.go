@@ -0,0 +1,112 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedJobFields(t *testing.T, app *testApp) {
+	t.Helper()
+	for _, field := range []url.Values{
+		{"label": {"Permit Number"}, "field_type": {"text"}, "show_on_quote": {"true"}},
+		{"label": {"Lot Size"}, "field_type": {"number"}},
+		{"label": {"Inspection"}, "field_type": {"date"}},
+	} {
+		if rec := app.postForm(t, http.MethodPost, "/settings/job-fields", field); rec.Code != http.StatusSeeOther {
+			t.Fatalf("create field status = %d, want 303", rec.Code)
+		}
+	}
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+}
+
+func TestUpdateJobCustomFields(t *testing.T) {
+	app := newTestApp(t)
+	seedJobFields(t, app)
+
+	if body := app.get(t, "/jobs/job-1").Body.String(); !strings.Contains(body, `name="field_1"`) || !strings.Contains(body, `type="date"`) {
+		t.Fatalf("job page missing custom field inputs")
+	}
+
+	rec := app.postForm(t, http.MethodPut, "/jobs/job-1/fields", url.Values{
+		"field_1": {"BP-2024-118"},
+		"field_2": {"a quarter acre"},
+		"field_3": {"next tuesday"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Must be a number") || !strings.Contains(body, "Must be a date") {
+		t.Errorf("form missing inline errors: %s", body)
+	}
+	if !strings.Contains(body, `value="a quarter acre"`) {
+		t.Errorf("form did not keep the submitted value")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_custom_field_values`); n != 0 {
+		t.Errorf("values saved despite errors: %d", n)
+	}
+
+	rec = app.postForm(t, http.MethodPut, "/jobs/job-1/fields", url.Values{
+		"field_1": {"BP-2024-118"},
+		"field_2": {"0.25"},
+		"field_3": {""},
+	})
+	if strings.Contains(rec.Body.String(), "data-field-error") {
+		t.Fatalf("valid values rejected: %s", rec.Body.String())
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_custom_field_values WHERE job_id = 'job-1'`); n != 2 {
+		t.Errorf("saved values = %d, want 2", n)
+	}
+
+	printed := app.get(t, "/jobs/job-1/print").Body.String()
+	if !strings.Contains(printed, "Permit Number: BP-2024-118") {
+		t.Errorf("quote missing field flagged to show on it")
+	}
+	if strings.Contains(printed, "Lot Size") {
+		t.Errorf("quote shows field not flagged to show on it")
+	}
+
+	api := decodeJSONObject(t, app.apiGet(t, "/api/v1/jobs/job-1/totals"))
+	fields, _ := api["custom_fields"].(map[string]interface{})
+	if fields["Permit Number"] != "BP-2024-118" || fields["Lot Size"] != "0.25" {
+		t.Errorf("api custom_fields = %v", api["custom_fields"])
+	}
+}
+
+func TestArchiveJobCustomField_KeepsValues(t *testing.T) {
+	app := newTestApp(t)
+	seedJobFields(t, app)
+	app.exec(t, `INSERT INTO job_custom_field_values (job_id, field_id, value) VALUES ('job-1', 1, 'BP-2024-118')`)
+
+	rec := app.postForm(t, http.MethodDelete, "/settings/job-fields/1", nil)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_custom_field_values WHERE field_id = 1`); n != 1 {
+		t.Errorf("archiving a field dropped its values")
+	}
+	if body := app.get(t, "/jobs/job-1").Body.String(); strings.Contains(body, `name="field_1"`) {
+		t.Errorf("archived field still on the job form")
+	}
+	if body := app.get(t, "/settings").Body.String(); strings.Contains(body, "Permit Number") {
+		t.Errorf("archived field still listed in settings")
+	}
+
+	if rec := app.postForm(t, http.MethodDelete, "/settings/job-fields/1", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("second archive status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCreateJobCustomField_Validation(t *testing.T) {
+	app := newTestApp(t)
+
+	if rec := app.postForm(t, http.MethodPost, "/settings/job-fields", url.Values{"label": {" "}, "field_type": {"text"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("blank label status = %d, want 400", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPost, "/settings/job-fields", url.Values{"label": {"PO"}, "field_type": {"money"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad type status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,228 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/excel"
+	"github.com/dukerupert/skalkaho/internal/upload"
+	"github.com/google/uuid"
+)
+
+// ImportJobWorkbook creates a new job from a workbook produced by
+// ExportJobWorkbook. Malformed item rows are skipped and listed in a report;
+// workbooks in any other layout are rejected outright.
+func (h *Handler) ImportJobWorkbook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	file, err := upload.FromRequest(w, r, "file", upload.XLSX)
+	if err != nil {
+		var uploadErr *upload.Error
+		if errors.As(err, &uploadErr) {
+			h.renderJobImportError(w, r, uploadErr.Message)
+			return
+		}
+		logger.Error("failed to read file", "error", err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	workbook, problems, err := excel.ReadJobWorkbook(bytes.NewReader(file.Data))
+	if err != nil {
+		if errors.Is(err, excel.ErrNotJobWorkbook) {
+			h.renderJobImportError(w, r, "This workbook is not in the quote export layout ("+err.Error()+"). Export a quote with \"Export Workbook\", edit that file, and upload it again.")
+			return
+		}
+		logger.Error("failed to read job workbook", "error", err)
+		http.Error(w, "Failed to read workbook", http.StatusInternalServerError)
+		return
+	}
+
+	jobInput := domain.JobInput{
+		Name:             workbook.Name,
+		SurchargePercent: workbook.SurchargePercent,
+		SurchargeMode:    domain.SurchargeMode(workbook.SurchargeMode),
+	}
+	if errs := jobInput.Validate(); len(errs) > 0 {
+		h.renderJobImportError(w, r, "This workbook is not in the quote export layout: "+errs[0].Message)
+		return
+	}
+
+	job, rowErrors, err := h.createJobFromWorkbook(ctx, workbook)
+	if err != nil {
+		logger.Error("failed to import job workbook", "error", err)
+		http.Error(w, "Failed to import workbook", http.StatusInternalServerError)
+		return
+	}
+	problems = append(problems, rowErrors...)
+
+	logger.Info("imported job workbook", "job_id", job.ID, "source_job_id", workbook.SourceJobID, "skipped_rows", len(problems))
+
+	if len(problems) == 0 {
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", "/jobs/"+job.ID)
+			return
+		}
+		http.Redirect(w, r, "/jobs/"+job.ID, http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Job":      job,
+		"Filename": file.Name,
+		"Problems": problems,
+	}
+
+	if err := h.renderer.Render(w, "job_import", data); err != nil {
+		logger.Error("failed to render job import report", "error", err)
+	}
+}
+
+// renderJobImportError shows why an uploaded workbook was rejected.
+func (h *Handler) renderJobImportError(w http.ResponseWriter, r *http.Request, message string) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	data := map[string]interface{}{
+		"Error": message,
+	}
+
+	if err := h.renderer.Render(w, "job_import", data); err != nil {
+		logger.Error("failed to render job import report", "error", err)
+	}
+}
+
+// createJobFromWorkbook creates the job, its categories, and every valid item
+// in one transaction. Items that fail validation are returned as row errors.
+func (h *Handler) createJobFromWorkbook(ctx context.Context, workbook *excel.JobWorkbook) (repository.Job, []excel.RowError, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.Job{}, nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	// Keep the client link only if the client still exists.
+	var clientID sql.NullString
+	if workbook.ClientID != "" {
+		if _, err := qtx.GetClient(ctx, workbook.ClientID); err == nil {
+			clientID = toNullString(workbook.ClientID)
+		}
+	}
+
+	job, err := qtx.CreateJob(ctx, repository.CreateJobParams{
+		ID:               uuid.New().String(),
+		Name:             workbook.Name + " (revised)",
+		CustomerName:     sql.NullString{},
+		SurchargePercent: workbook.SurchargePercent,
+		SurchargeMode:    workbook.SurchargeMode,
+		Status:           "draft",
+		ExpiresAt:        sql.NullString{},
+		ClientID:         clientID,
+	})
+	if err != nil {
+		return repository.Job{}, nil, fmt.Errorf("creating job: %w", err)
+	}
+
+	var problems []excel.RowError
+	for catOrder, cat := range workbook.Categories {
+		// Sections are "Parent / Child" paths below the sheet's category.
+		categoryIDs := map[string]string{}
+		createCategory := func(path, parentID, name string, sortOrder int) (string, error) {
+			if id, ok := categoryIDs[path]; ok {
+				return id, nil
+			}
+			created, err := qtx.CreateCategory(ctx, repository.CreateCategoryParams{
+				ID:               uuid.New().String(),
+				JobID:            job.ID,
+				ParentID:         toNullString(parentID),
+				Name:             name,
+				SurchargePercent: sql.NullFloat64{},
+				SortOrder:        int64(sortOrder),
+			})
+			if err != nil {
+				return "", fmt.Errorf("creating category %q: %w", name, err)
+			}
+			categoryIDs[path] = created.ID
+			return created.ID, nil
+		}
+
+		topID, err := createCategory("", "", cat.Name, catOrder)
+		if err != nil {
+			return repository.Job{}, nil, err
+		}
+
+		for itemOrder, item := range cat.Items {
+			categoryID := topID
+			if item.Section != "" {
+				parts := strings.Split(item.Section, "/")
+				if len(parts)+1 > 3 {
+					problems = append(problems, excel.RowError{Sheet: cat.Sheet, Row: item.Row, Message: "Section is nested deeper than 3 levels"})
+					continue
+				}
+				path := ""
+				for _, part := range parts {
+					name := strings.TrimSpace(part)
+					if name == "" {
+						continue
+					}
+					path += "/" + name
+					categoryID, err = createCategory(path, categoryID, name, len(categoryIDs))
+					if err != nil {
+						return repository.Job{}, nil, err
+					}
+				}
+			}
+
+			// Exported credits come back as negative unit prices.
+			input := domain.LineItemInput{
+				CategoryID: categoryID,
+				Type:       domain.LineItemType(item.Type),
+				Name:       item.Name,
+				Quantity:   item.Quantity,
+				Unit:       item.Unit,
+				UnitPrice:  item.UnitPrice,
+				IsCredit:   item.UnitPrice < 0,
+			}
+			if errs := input.Validate(); len(errs) > 0 {
+				for _, e := range errs {
+					problems = append(problems, excel.RowError{Sheet: cat.Sheet, Row: item.Row, Message: e.Message})
+				}
+				continue
+			}
+
+			if _, err := qtx.CreateLineItem(ctx, repository.CreateLineItemParams{
+				ID:                  uuid.New().String(),
+				CategoryID:          categoryID,
+				Type:                item.Type,
+				Name:                item.Name,
+				Description:         toNullString(item.Description),
+				Quantity:            item.Quantity,
+				Unit:                item.Unit,
+				UnitPrice:           item.UnitPrice,
+				SurchargePercent:    sql.NullFloat64{},
+				SortOrder:           int64(itemOrder),
+				ExemptFromSurcharge: input.ExemptFromSurcharge,
+				TemplateID:          sql.NullInt64{},
+				IsCredit:            input.IsCredit,
+				TaxTreatment:        string(domain.TaxTreatmentDefault),
+			}); err != nil {
+				return repository.Job{}, nil, fmt.Errorf("creating item on %s row %d: %w", cat.Sheet, item.Row, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repository.Job{}, nil, fmt.Errorf("committing import: %w", err)
+	}
+	return job, problems, nil
+}
@@ -0,0 +1,156 @@
+package keyboard_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func uploadJobWorkbook(t *testing.T, app *testApp, filename string, payload []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	_, _ = part.Write(payload)
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/jobs/import.xlsx", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return app.do(req)
+}
+
+func TestImportJobWorkbook_RoundTripWithEdits(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent, surcharge_mode) VALUES ('job-1', 'Garage', 15, 'override')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES ('cat-1a', 'job-1', 'cat-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 3.50),
+		('item-2', 'cat-1a', 'labor', 'Framer', 8, 'hr', 50)`)
+
+	rec := app.get(t, "/jobs/job-1/export.xlsx")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", rec.Code)
+	}
+
+	// Edit on site: bump a quantity and add a row with a bad quantity.
+	f, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	_ = f.SetCellValue("Framing", "E2", 12)
+	_ = f.InsertRows("Framing", 4, 1)
+	_ = f.SetSheetRow("Framing", "A4", &[]interface{}{"", "material", "Sheathing", "", "lots", "sheet", 40})
+	var edited bytes.Buffer
+	if err := f.Write(&edited); err != nil {
+		t.Fatalf("write edited workbook: %v", err)
+	}
+	f.Close()
+
+	rec = uploadJobWorkbook(t, app, "garage.xlsx", edited.Bytes())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Qty &#34;lots&#34; is not a number") {
+		t.Errorf("report missing malformed row: %s", body)
+	}
+
+	var jobID, mode string
+	var percent float64
+	if err := app.db.QueryRow(`SELECT id, surcharge_percent, surcharge_mode FROM jobs WHERE name = 'Garage (revised)'`).Scan(&jobID, &percent, &mode); err != nil {
+		t.Fatalf("revised job not created: %v", err)
+	}
+	if percent != 15 || mode != "override" {
+		t.Errorf("markup = %v %s, want 15 override", percent, mode)
+	}
+
+	var qty float64
+	if err := app.db.QueryRow(`SELECT li.quantity FROM line_items li JOIN categories c ON li.category_id = c.id
+		WHERE c.job_id = ? AND li.name = '2x4x8'`, jobID).Scan(&qty); err != nil {
+		t.Fatalf("query 2x4x8: %v", err)
+	}
+	if qty != 12 {
+		t.Errorf("2x4x8 quantity = %v, want 12", qty)
+	}
+
+	var parent string
+	if err := app.db.QueryRow(`SELECT p.name FROM line_items li
+		JOIN categories c ON li.category_id = c.id
+		JOIN categories p ON c.parent_id = p.id
+		WHERE c.job_id = ? AND li.name = 'Framer' AND c.name = 'Walls'`, jobID).Scan(&parent); err != nil {
+		t.Fatalf("Framer not recreated under Framing / Walls: %v", err)
+	}
+
+	var original float64
+	if err := app.db.QueryRow(`SELECT quantity FROM line_items WHERE id = 'item-1'`).Scan(&original); err != nil || original != 10 {
+		t.Errorf("original job was modified: quantity = %v, err = %v", original, err)
+	}
+}
+
+func TestImportJobWorkbook_RejectsOtherLayouts(t *testing.T) {
+	app := newTestApp(t)
+
+	f := excelize.NewFile()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"Name", "Qty", "Price"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"2x4x8", 10, 3.5})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("write workbook: %v", err)
+	}
+	f.Close()
+
+	rec := uploadJobWorkbook(t, app, "supplier.xlsx", buf.Bytes())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "not in the quote export layout") {
+		t.Errorf("unexpected message: %s", rec.Body.String())
+	}
+
+	var count int
+	if err := app.db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("jobs created = %d, want 0", count)
+	}
+}
+
+func TestImportJobWorkbook_RejectsMislabelledFiles(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name     string
+		filename string
+		payload  []byte
+		want     string
+	}{
+		{"csv renamed to xlsx", "quote.xlsx", []byte("Name,Qty\n2x4x8,10\n"), "quote.xlsx does not contain an Excel workbook"},
+		{"wrong extension", "quote.csv", []byte("Name,Qty\n"), "quote.csv is not an accepted file type"},
+		{"path in name", "../../etc/quote.xlsx", []byte("PK\x03\x04junk"), "quote.xlsx does not contain an Excel workbook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := uploadJobWorkbook(t, app, tt.filename, tt.payload)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body missing %q: %s", tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	var count int
+	if err := app.db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("jobs created = %d, want 0", count)
+	}
+}
@@ -2,33 +2,85 @@ package keyboard
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"expvar"
+	"fmt"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
 
+	"github.com/dukerupert/skalkaho/internal/domain"
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
 	"github.com/google/uuid"
 )
 
-const pageSize = 20
+// jobTotalFailures counts jobs list rows whose total couldn't be calculated.
+var jobTotalFailures = expvar.NewInt("job_total_failures")
 
-// JobWithTotal wraps a Job with its calculated grand total and client info.
+// jobClientFailures counts jobs list rows whose client couldn't be loaded.
+var jobClientFailures = expvar.NewInt("job_client_failures")
+
+// JobWithTotal wraps a Job with its calculated grand total, client info, and
+// how many categories and line items it has.
 type JobWithTotal struct {
 	repository.Job
-	GrandTotal float64
-	ClientName string
+	GrandTotal    float64
+	TotalFailed   bool // the total couldn't be calculated, so GrandTotal isn't real
+	ClientName    string
+	ClientFailed  bool // the linked client couldn't be loaded, so ClientName is empty
+	CategoryCount int64
+	ItemCount     int64
+}
+
+// listedJobContents loads the categories and line items of every listed job
+// in one query each, grouped by job ID.
+func (h *Handler) listedJobContents(ctx context.Context, jobIDs []string) (map[string][]repository.Category, map[string][]repository.LineItem, error) {
+	categories, err := h.queries.ListCategoriesByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing categories: %w", err)
+	}
+	lineItems, err := h.queries.ListLineItemsByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing line items: %w", err)
+	}
+
+	categoriesByJob := make(map[string][]repository.Category, len(jobIDs))
+	jobByCategory := make(map[string]string, len(categories))
+	for _, cat := range categories {
+		categoriesByJob[cat.JobID] = append(categoriesByJob[cat.JobID], cat)
+		jobByCategory[cat.ID] = cat.JobID
+	}
+	lineItemsByJob := make(map[string][]repository.LineItem, len(jobIDs))
+	for _, item := range lineItems {
+		jobID := jobByCategory[item.CategoryID]
+		lineItemsByJob[jobID] = append(lineItemsByJob[jobID], item)
+	}
+	return categoriesByJob, lineItemsByJob, nil
 }
 
-// PaginationData holds pagination state for templates.
-type PaginationData struct {
-	CurrentPage int
-	TotalPages  int
-	TotalItems  int64
-	HasPrev     bool
-	HasNext     bool
+// listedClientNames loads the names of the clients linked to the listed jobs
+// in one query, keyed by client ID.
+func (h *Handler) listedClientNames(ctx context.Context, jobs []repository.Job) (map[string]string, error) {
+	var clientIDs []string
+	for _, job := range jobs {
+		if job.ClientID.Valid {
+			clientIDs = append(clientIDs, job.ClientID.String)
+		}
+	}
+	names := make(map[string]string, len(clientIDs))
+	if len(clientIDs) == 0 {
+		return names, nil
+	}
+	clients, err := h.queries.ListClientsByIDs(ctx, clientIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, client := range clients {
+		names[client.ID] = client.Name
+	}
+	return names, nil
 }
 
 // ListJobs shows the keyboard-centric jobs list with pagination and filtering.
@@ -36,21 +88,12 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
 
-	// Parse query parameters
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-		page = p
-	}
-
 	status := r.URL.Query().Get("status")
 	sortBy := r.URL.Query().Get("sort")
 	if sortBy == "" {
 		sortBy = "newest"
 	}
 
-	offset := int64((page - 1) * pageSize)
-
 	// Get total count for pagination
 	totalItems, err := h.queries.CountJobs(ctx, status)
 	if err != nil {
@@ -59,17 +102,16 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	totalPages := int(totalItems+pageSize-1) / pageSize
-	if totalPages < 1 {
-		totalPages = 1
-	}
+	pagination := newPagination(r, totalItems, h.pageSize(w, r))
+	offset := int64(pagination.Offset)
+	limit := int64(pagination.PerPage)
 
 	// Get jobs based on sort order
 	var jobs []repository.Job
 	params := repository.ListJobsPaginatedParams{
 		Status: status,
 		Offset: offset,
-		Limit:  pageSize,
+		Limit:  limit,
 	}
 
 	switch sortBy {
@@ -77,19 +119,19 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		jobs, err = h.queries.ListJobsPaginatedOldest(ctx, repository.ListJobsPaginatedOldestParams{
 			Status: status,
 			Offset: offset,
-			Limit:  pageSize,
+			Limit:  limit,
 		})
 	case "name_asc":
 		jobs, err = h.queries.ListJobsPaginatedByName(ctx, repository.ListJobsPaginatedByNameParams{
 			Status: status,
 			Offset: offset,
-			Limit:  pageSize,
+			Limit:  limit,
 		})
 	case "name_desc":
 		jobs, err = h.queries.ListJobsPaginatedByNameDesc(ctx, repository.ListJobsPaginatedByNameDescParams{
 			Status: status,
 			Offset: offset,
-			Limit:  pageSize,
+			Limit:  limit,
 		})
 	default: // newest
 		jobs, err = h.queries.ListJobsPaginated(ctx, params)
@@ -101,43 +143,71 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate totals for each job and get client names
-	jobsWithTotals := make([]JobWithTotal, len(jobs))
+	jobIDs := make([]string, len(jobs))
 	for i, job := range jobs {
-		categories, _ := h.queries.ListCategoriesByJob(ctx, job.ID)
-		lineItems, _ := h.queries.ListLineItemsByJob(ctx, job.ID)
-		totals := h.calculateTotals(job, categories, lineItems)
+		jobIDs[i] = job.ID
+	}
+	contents, err := h.queries.CountJobContents(ctx, jobIDs)
+	if err != nil {
+		logger.Error("failed to count job contents", "error", err)
+		http.Error(w, "Failed to load jobs", http.StatusInternalServerError)
+		return
+	}
+	counts := make(map[string]repository.CountJobContentsRow, len(contents))
+	for _, c := range contents {
+		counts[c.JobID] = c
+	}
 
-		var clientName string
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	// Totals and client names are loaded for the whole page at once. If a
+	// load fails, the affected rows are shown without that value rather than
+	// as $0.00 or an unlinked job.
+	categoriesByJob, lineItemsByJob, totalsErr := h.listedJobContents(ctx, jobIDs)
+	if totalsErr != nil {
+		logger.Error("failed to load job totals", "error", totalsErr, "job_ids", jobIDs)
+		jobTotalFailures.Add(int64(len(jobs)))
+	}
+	clientNames, clientsErr := h.listedClientNames(ctx, jobs)
+	if clientsErr != nil {
+		logger.Error("failed to load job clients", "error", clientsErr, "job_ids", jobIDs)
+	}
+
+	jobsWithTotals := make([]JobWithTotal, len(jobs))
+	for i, job := range jobs {
+		row := JobWithTotal{
+			Job:           job,
+			TotalFailed:   totalsErr != nil,
+			CategoryCount: counts[job.ID].CategoryCount,
+			ItemCount:     counts[job.ID].ItemCount,
+		}
+		if totalsErr == nil {
+			row.GrandTotal = h.calculateTotals(job, settings, categoriesByJob[job.ID], lineItemsByJob[job.ID]).GrandTotal
+		}
 		if job.ClientID.Valid {
-			if client, err := h.queries.GetClient(ctx, job.ClientID.String); err == nil {
-				clientName = client.Name
+			row.ClientName = clientNames[job.ClientID.String]
+			if clientsErr != nil {
+				row.ClientFailed = true
+				jobClientFailures.Add(1)
 			}
 		} else if job.CustomerName.Valid {
-			clientName = job.CustomerName.String
-		}
-
-		jobsWithTotals[i] = JobWithTotal{
-			Job:        job,
-			GrandTotal: totals.GrandTotal,
-			ClientName: clientName,
+			row.ClientName = job.CustomerName.String
 		}
-	}
-
-	pagination := PaginationData{
-		CurrentPage: page,
-		TotalPages:  totalPages,
-		TotalItems:  totalItems,
-		HasPrev:     page > 1,
-		HasNext:     page < totalPages,
+		jobsWithTotals[i] = row
 	}
 
 	data := map[string]interface{}{
-		"Jobs":          jobsWithTotals,
-		"SelectedIndex": 0,
-		"Pagination":    pagination,
-		"Status":        status,
-		"Sort":          sortBy,
+		"Jobs":           jobsWithTotals,
+		"SelectedIndex":  0,
+		"Pagination":     pagination,
+		"Status":         status,
+		"Sort":           sortBy,
+		"ImportReminder": h.importReminder(ctx),
 	}
 
 	if err := h.renderer.Render(w, "jobs_list", data); err != nil {
@@ -176,6 +246,13 @@ func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
 	// Get only top-level categories
 	topLevelCategories := make([]repository.Category, 0)
 	for _, cat := range categories {
@@ -192,17 +269,14 @@ func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 
 	categoriesWithTotals := make([]CategoryWithTotal, len(topLevelCategories))
 	for i, cat := range topLevelCategories {
-		catTotal := h.calculateCategoryTotal(cat.ID, job, categories, lineItems)
+		catTotal := h.calculateCategoryTotal(cat.ID, job, settings, categories, lineItems)
 		categoriesWithTotals[i] = CategoryWithTotal{
 			Category: cat,
 			Total:    catTotal.Total,
 		}
 	}
 
-	totals := h.calculateTotals(job, categories, lineItems)
-
-	// Build category tree for sidebar navigation
-	categoryTree := buildCategoryTree(categories)
+	totals := h.calculateTotals(job, settings, categories, lineItems)
 
 	// Get client if associated
 	var client *repository.Client
@@ -213,14 +287,56 @@ func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Warn when the quote is below the configured minimum job total, or when
+	// a new quote's client has declined their recent quotes
+	warning := minimumWarning(settings, totals.GrandTotal, lineItems)
+	declined, err := h.declineWarning(ctx, settings, job, client)
+	if err != nil {
+		logger.Error("failed to list client quotes", "error", err)
+	}
+
+	fields, err := h.jobFieldInputs(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list job custom fields", "error", err)
+	}
+
+	activity, err := h.queries.ListJobActivity(ctx, repository.ListJobActivityParams{
+		JobID: jobID,
+		Limit: 10,
+	})
+	if err != nil {
+		logger.Error("failed to list job activity", "error", err)
+	}
+
+	preflight, err := h.preflight(ctx, job, categories, lineItems)
+	if err != nil {
+		logger.Error("failed to run preflight checks", "error", err)
+	}
+
+	totalAlert, err := h.openTotalAlert(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to get total change alert", "error", err)
+	}
+
+	commentCounts, err := h.openCommentCounts(ctx, job.ID, categories)
+	if err != nil {
+		logger.Error("failed to count item comments", "error", err)
+	}
+
 	data := map[string]interface{}{
 		"Job":               job,
 		"Categories":        categoriesWithTotals,
 		"Totals":            totals,
 		"SelectedIndex":     0,
-		"CategoryTree":      categoryTree,
 		"CurrentCategoryID": "",
 		"Client":            client,
+		"MinimumWarning":    warning,
+		"DeclineWarning":    declined,
+		"TotalAlert":        totalAlert,
+		"JobFields":         fields,
+		"Activity":          activity,
+		"Preflight":         preflight,
+		"CommentCounts":     commentCounts,
 	}
 
 	if err := h.renderer.Render(w, "job", data); err != nil {
@@ -287,7 +403,11 @@ func (h *Handler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	surchargePercent, _ := strconv.ParseFloat(r.FormValue("surcharge_percent"), 64)
+	surchargePercent, err := h.formPercent(r, "surcharge_percent", "Markup")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
 
 	customerName := sql.NullString{}
 	if cn := r.FormValue("customer_name"); cn != "" {
@@ -344,17 +464,104 @@ func (h *Handler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
 }
 
-// DeleteJob deletes a job.
+// confirmsJobName reports whether typed matches the job's name, ignoring case
+// and surrounding space.
+func confirmsJobName(typed, name string) bool {
+	return strings.EqualFold(strings.TrimSpace(typed), strings.TrimSpace(name))
+}
+
+// GetJobDeleteForm shows what deleting a job destroys and asks for its name
+// to be typed before the delete is allowed.
+func (h *Handler) GetJobDeleteForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	job, err := h.queries.GetJob(ctx, r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	counts, err := h.queries.CountJobContents(ctx, []string{job.ID})
+	if err != nil {
+		logger.Error("failed to count job contents", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	var contents repository.CountJobContentsRow
+	if len(counts) > 0 {
+		contents = counts[0]
+	}
+
+	data := map[string]interface{}{
+		"Job":      job,
+		"Contents": contents,
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "job_delete_form", data); err != nil {
+		logger.Error("failed to render job delete form", "error", err)
+		http.Error(w, "Failed to render form", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// DeleteJob permanently deletes a job with its categories, line items, and
+// everything attached to them. The job's name must be sent as confirm_name.
 func (h *Handler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
 	jobID := r.PathValue("id")
 
-	if err := h.queries.DeleteJob(ctx, jobID); err != nil {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to delete job", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	job, err := qtx.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	if !confirmsJobName(r.FormValue("confirm_name"), job.Name) {
+		http.Error(w, "Type the quote name to confirm deletion", http.StatusBadRequest)
+		return
+	}
+
+	// Categories, line items, and the rest of the job's records cascade.
+	if err := qtx.DeleteJob(ctx, jobID); err != nil {
 		logger.Error("failed to delete job", "error", err)
 		http.Error(w, "Failed to delete job", http.StatusInternalServerError)
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit job delete", "error", err)
+		http.Error(w, "Failed to delete job", http.StatusInternalServerError)
+		return
+	}
 
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/")
@@ -494,7 +701,7 @@ func (h *Handler) UpdateJobName(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
 }
 
-// UpdateMarkup updates a job's markup percentage.
+// UpdateMarkup updates a job's markup percentage and, when sent, its tax rate.
 func (h *Handler) UpdateMarkup(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
@@ -512,7 +719,21 @@ func (h *Handler) UpdateMarkup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	surchargePercent, _ := strconv.ParseFloat(r.FormValue("surcharge_percent"), 64)
+	surchargePercent, err := h.formPercent(r, "surcharge_percent", "Markup")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
+
+	taxPercent := job.TaxPercent
+	if taxStr := r.FormValue("tax_percent"); taxStr != "" {
+		val, err := parsePercent(taxStr, h.decimalSeparator())
+		if err != nil || val < 0 {
+			http.Error(w, "Tax rate must be zero or more", http.StatusBadRequest)
+			return
+		}
+		taxPercent = val
+	}
 
 	_, err = h.queries.UpdateJob(ctx, repository.UpdateJobParams{
 		ID:               jobID,
@@ -530,6 +751,17 @@ func (h *Handler) UpdateMarkup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if taxPercent != job.TaxPercent {
+		if err := h.queries.UpdateJobTaxPercent(ctx, repository.UpdateJobTaxPercentParams{
+			ID:         jobID,
+			TaxPercent: taxPercent,
+		}); err != nil {
+			logger.Error("failed to update job tax rate", "error", err)
+			http.Error(w, "Failed to update tax rate", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Redirect", "/jobs/"+jobID)
 		return
@@ -543,6 +775,7 @@ type ReportItem struct {
 	Name     string
 	Quantity float64
 	Unit     string
+	SKU      string // supplier SKU from the item's template; order list only
 }
 
 // CategoryReport represents a category with its items for the site materials report.
@@ -564,28 +797,48 @@ func (h *Handler) GetOrderList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	rows, err := h.queries.ListOrderItemsByJob(ctx, jobID)
 	if err != nil {
 		logger.Error("failed to list line items", "error", err)
 		http.Error(w, "Failed to load items", http.StatusInternalServerError)
 		return
 	}
+	items := orderListItems(rows)
 
-	// Aggregate materials and equipment by name+unit
+	suppliers, err := h.queries.ListSupplierExportMappings(ctx)
+	if err != nil {
+		logger.Error("failed to list supplier export mappings", "error", err)
+	}
+
+	data := map[string]interface{}{
+		"Job":       job,
+		"Items":     items,
+		"Suppliers": suppliers,
+	}
+
+	if err := h.renderer.Render(w, "order_list", data); err != nil {
+		logger.Error("failed to render order list", "error", err)
+	}
+}
+
+// orderListItems aggregates a job's materials and equipment by name and unit,
+// sorted by name. An item takes the SKU of the first of its lines that came
+// from a template with one.
+func orderListItems(rows []repository.ListOrderItemsByJobRow) []ReportItem {
 	itemMap := make(map[string]*ReportItem)
-	for _, li := range lineItems {
-		if li.Type != "material" && li.Type != "equipment" {
+	for _, row := range rows {
+		if !domain.LineItemType(row.Type).Orderable() {
 			continue
 		}
-		key := li.Name + "|" + li.Unit
-		if existing, ok := itemMap[key]; ok {
-			existing.Quantity += li.Quantity
-		} else {
-			itemMap[key] = &ReportItem{
-				Name:     li.Name,
-				Quantity: li.Quantity,
-				Unit:     li.Unit,
-			}
+		key := row.Name + "|" + row.Unit
+		existing, ok := itemMap[key]
+		if !ok {
+			existing = &ReportItem{Name: row.Name, Unit: row.Unit}
+			itemMap[key] = existing
+		}
+		existing.Quantity += row.Quantity
+		if existing.SKU == "" {
+			existing.SKU = row.Sku.String
 		}
 	}
 
@@ -597,15 +850,7 @@ func (h *Handler) GetOrderList(w http.ResponseWriter, r *http.Request) {
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].Name < items[j].Name
 	})
-
-	data := map[string]interface{}{
-		"Job":   job,
-		"Items": items,
-	}
-
-	if err := h.renderer.Render(w, "order_list", data); err != nil {
-		logger.Error("failed to render order list", "error", err)
-	}
+	return items
 }
 
 // GetJobClientForm returns an inline form for changing the job's client.
@@ -702,7 +947,8 @@ func (h *Handler) UpdateJobClient(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
 }
 
-// GetSiteMaterials shows materials and equipment broken down by category.
+// GetSiteMaterials shows materials and equipment broken down by category, or
+// by delivery phase with group=phase.
 func (h *Handler) GetSiteMaterials(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
@@ -729,6 +975,18 @@ func (h *Handler) GetSiteMaterials(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("group") == "phase" {
+		data := map[string]interface{}{
+			"Job":        job,
+			"Categories": phaseReports(categories, lineItems),
+			"GroupBy":    "phase",
+		}
+		if err := h.renderer.Render(w, "site_materials", data); err != nil {
+			logger.Error("failed to render site materials", "error", err)
+		}
+		return
+	}
+
 	// Build category name lookup (with full path)
 	categoryNames := make(map[string]string)
 	categoryParents := make(map[string]string)
@@ -755,7 +1013,7 @@ func (h *Handler) GetSiteMaterials(w http.ResponseWriter, r *http.Request) {
 	// Group items by category
 	categoryItems := make(map[string][]ReportItem)
 	for _, li := range lineItems {
-		if li.Type != "material" && li.Type != "equipment" {
+		if !domain.LineItemType(li.Type).Orderable() {
 			continue
 		}
 		categoryItems[li.CategoryID] = append(categoryItems[li.CategoryID], ReportItem{
@@ -790,6 +1048,7 @@ func (h *Handler) GetSiteMaterials(w http.ResponseWriter, r *http.Request) {
 	data := map[string]interface{}{
 		"Job":        job,
 		"Categories": reports,
+		"GroupBy":    "category",
 	}
 
 	if err := h.renderer.Render(w, "site_materials", data); err != nil {
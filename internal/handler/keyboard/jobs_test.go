@@ -0,0 +1,166 @@
+package keyboard_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+	"github.com/dukerupert/skalkaho/internal/handler/keyboard"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/router"
+	keyboardtemplates "github.com/dukerupert/skalkaho/internal/templates/keyboard"
+)
+
+// failingDB fails the named query when one of its arguments is arg.
+type failingDB struct {
+	repository.DBTX
+	query string
+	arg   string
+}
+
+func (f failingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if strings.Contains(query, "-- name: "+f.query+" ") {
+		for _, a := range args {
+			if a == f.arg {
+				return nil, errors.New("injected failure")
+			}
+		}
+	}
+	return f.DBTX.QueryContext(ctx, query, args...)
+}
+
+// countingDB counts the queries run through it.
+type countingDB struct {
+	repository.DBTX
+	queries *int
+}
+
+func (c countingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	*c.queries++
+	return c.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (c countingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	*c.queries++
+	return c.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+// withDB returns a copy of the app whose handler runs its queries through db.
+func (a *testApp) withDB(t *testing.T, db repository.DBTX) *testApp {
+	t.Helper()
+	renderer, err := keyboardtemplates.NewRenderer()
+	if err != nil {
+		t.Fatalf("creating renderer: %v", err)
+	}
+	queries := repository.New(db)
+	h := keyboard.NewHandler(a.db, queries, renderer, slog.New(slog.NewTextHandler(io.Discard, nil)), &config.Config{})
+	mux := http.NewServeMux()
+	router.Register(mux, h)
+	return &testApp{db: a.db, queries: queries, handler: h, mux: mux}
+}
+
+func TestListJobs_TotalFailure(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-ok', 'Deck'), ('job-bad', 'Fence')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-ok', 'job-ok', 'Framing'), ('cat-bad', 'job-bad', 'Posts')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-ok', 'cat-ok', 'material', 'Joist', 10, 'ea', 12),
+		('item-bad', 'cat-bad', 'material', 'Post', 8, 'ea', 25)`)
+
+	body := app.get(t, "/").Body.String()
+	if !strings.Contains(body, "$120.00") || !strings.Contains(body, "$200.00") {
+		t.Fatalf("jobs list missing totals")
+	}
+
+	failures := expvar.Get("job_total_failures").(*expvar.Int)
+	before := failures.Value()
+
+	broken := app.withDB(t, failingDB{DBTX: app.db, query: "ListLineItemsByJobIDs", arg: "job-bad"})
+	rec := broken.get(t, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body = rec.Body.String()
+	if strings.Count(body, "data-total-failed") != 2 {
+		t.Errorf("want every row on the page without a total")
+	}
+	if strings.Contains(body, "$0.00") || strings.Contains(body, "$120.00") || strings.Contains(body, "$200.00") {
+		t.Errorf("failed page shown with made-up totals")
+	}
+	if got := failures.Value() - before; got != 2 {
+		t.Errorf("job_total_failures grew by %d, want 2", got)
+	}
+
+	admin := newTestAppWithConfig(t, &config.Config{AdminToken: "s3cret"})
+	if body := adminGet(t, admin, "/admin/metrics", "s3cret").Body.String(); !strings.Contains(body, `"job_total_failures"`) {
+		t.Errorf("metrics missing the failure counter")
+	}
+}
+
+func TestListJobs_ClientFailure(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('client-1', 'Acme Builders')`)
+	app.exec(t, `INSERT INTO jobs (id, name, client_id, customer_name) VALUES
+		('job-linked', 'Deck', 'client-1', NULL),
+		('job-walkin', 'Fence', NULL, 'Dana Walsh')`)
+
+	if body := app.get(t, "/").Body.String(); !strings.Contains(body, "Acme Builders") {
+		t.Fatalf("jobs list missing client name")
+	}
+
+	failures := expvar.Get("job_client_failures").(*expvar.Int)
+	before := failures.Value()
+
+	broken := app.withDB(t, failingDB{DBTX: app.db, query: "ListClientsByIDs", arg: "client-1"})
+	rec := broken.get(t, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "data-client-failed") != 1 {
+		t.Errorf("want exactly the linked row marked as missing its client")
+	}
+	if !strings.Contains(body, "Dana Walsh") {
+		t.Errorf("walk-in customer name lost")
+	}
+	if strings.Contains(body, "data-total-failed") {
+		t.Errorf("client failure hid the totals")
+	}
+	if got := failures.Value() - before; got != 1 {
+		t.Errorf("job_client_failures grew by %d, want 1", got)
+	}
+}
+
+func TestListJobs_QueriesDontGrowWithJobs(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO clients (id, name) VALUES ('client-1', 'Acme Builders')`)
+
+	pageQueries := func() int {
+		var n int
+		app.withDB(t, countingDB{DBTX: app.db, queries: &n}).get(t, "/")
+		return n
+	}
+
+	app.exec(t, `INSERT INTO jobs (id, name, client_id) VALUES ('job-1', 'Deck', 'client-1')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES ('item-1', 'cat-1', 'material', 'Joist', 10, 'ea', 12)`)
+	one := pageQueries()
+
+	app.exec(t, `INSERT INTO jobs (id, name, client_id) VALUES ('job-2', 'Fence', 'client-1'), ('job-3', 'Porch', 'client-1')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-2', 'job-2', 'Posts'), ('cat-3', 'job-3', 'Decking')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-2', 'cat-2', 'material', 'Post', 8, 'ea', 25),
+		('item-3', 'cat-3', 'material', 'Board', 20, 'ea', 9)`)
+	three := pageQueries()
+
+	if three != one {
+		t.Errorf("jobs list ran %d queries for 3 jobs and %d for 1, want the same", three, one)
+	}
+}
@@ -0,0 +1,278 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCreateLineItem_ExemptFromSurcharge(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Addition', 20)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Permits')`)
+
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", url.Values{
+		"type":                  {"fee"},
+		"name":                  {"Building Permit"},
+		"quantity":              {"1"},
+		"unit":                  {"ea"},
+		"unit_price":            {"250"},
+		"exempt_from_surcharge": {"true"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status = %d, want 303", rec.Code)
+	}
+
+	if body := app.get(t, "/categories/cat-1").Body.String(); !strings.Contains(body, "at cost") {
+		t.Errorf("category page missing at cost badge for exempt item")
+	}
+
+	// 20% job markup must not apply to the permit
+	if body := app.get(t, "/jobs/job-1").Body.String(); strings.Contains(body, "$300.00") || !strings.Contains(body, "$250.00") {
+		t.Errorf("job total should bill the exempt permit at $250.00")
+	}
+}
+
+func TestCreateLineItem_Credit(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Bath Remodel', 10)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Fixtures')`)
+
+	item := url.Values{
+		"type":       {"material"},
+		"name":       {"Vanity"},
+		"quantity":   {"1"},
+		"unit":       {"ea"},
+		"unit_price": {"100"},
+	}
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", item); rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status = %d, want 303", rec.Code)
+	}
+
+	// The client supplies their own fixtures, worth more than what we sell.
+	credit := url.Values{
+		"type":       {"material"},
+		"name":       {"Owner-supplied fixtures"},
+		"quantity":   {"1"},
+		"unit":       {"ea"},
+		"unit_price": {"250"},
+		"is_credit":  {"true"},
+	}
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", credit); rec.Code != http.StatusSeeOther {
+		t.Fatalf("credit status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE is_credit = 1 AND unit_price = -250 AND exempt_from_surcharge = 0`); n != 1 {
+		t.Fatalf("credit not stored as a negative price with the exemption left as entered")
+	}
+
+	body := app.get(t, "/categories/cat-1").Body.String()
+	if !strings.Contains(body, "($250.00)") || !strings.Contains(body, "data-credit") {
+		t.Errorf("category page does not render the credit in parentheses")
+	}
+
+	// 110 for the marked-up vanity, less the 250 credit
+	totals := decodeJSONObject(t, app.apiGet(t, "/api/v1/jobs/job-1/totals"))
+	if totals["grand_total"] != -140.0 || totals["material_subtotal"] != -140.0 {
+		t.Errorf("totals = %v, want grand and material totals of -140", totals)
+	}
+
+	// Turning on markup for credits applies to the existing credit: 110 less 275.
+	app.exec(t, `UPDATE settings SET surcharge_credits = 1`)
+	totals = decodeJSONObject(t, app.apiGet(t, "/api/v1/jobs/job-1/totals"))
+	if totals["grand_total"] != -165.0 {
+		t.Errorf("grand_total with marked-up credits = %v, want -165", totals["grand_total"])
+	}
+
+	// Negative prices are only allowed on credits.
+	delete(credit, "is_credit")
+	credit.Set("unit_price", "-250")
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", credit); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative non-credit status = %d, want 400", rec.Code)
+	}
+}
+
+func TestCategoryPage_TypeFilter(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage', 10)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES
+		('cat-1', 'job-1', NULL, 'Framing'),
+		('cat-2', 'job-1', 'cat-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 100, 'ea', 4, 0),
+		('item-2', 'cat-1', 'material', 'Sheathing', 10, 'sheet', 30, 1),
+		('item-3', 'cat-1', 'labor', 'Framer', 20, 'hr', 50, 2),
+		('item-4', 'cat-2', 'labor', 'Framer helper', 10, 'hr', 30, 0)`)
+
+	body := app.get(t, "/categories/cat-1").Body.String()
+	if !strings.Contains(body, "data-type-filters") || !strings.Contains(body, "labor (1)") || !strings.Contains(body, "material (2)") {
+		t.Errorf("type chips missing")
+	}
+
+	body = app.get(t, "/categories/cat-1?type=labor").Body.String()
+	if strings.Contains(body, "Sheathing") || !strings.Contains(body, "Framer") {
+		t.Errorf("labor filter should show only labor rows")
+	}
+	// 1000 labor + 700 material + 300 subcategory labor, all with 10% markup
+	if !strings.Contains(body, "Showing 1 labor item totaling") || !strings.Contains(body, "$1100.00") {
+		t.Errorf("filtered subtotal missing")
+	}
+	if strings.Count(body, "$2200.00") != 3 {
+		t.Errorf("category total in the header, summary, and total card should ignore the filter")
+	}
+
+	if body := app.get(t, "/categories/cat-1?type=bogus").Body.String(); !strings.Contains(body, "Sheathing") || strings.Contains(body, "data-type-filter-summary") {
+		t.Errorf("unknown type should be ignored")
+	}
+}
+
+func TestCategoryPage_PriceDrift(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', '2x4x8', 'ea', 4.20),
+		(9002, 'material', 'Lumber', '2x6x8', 'ea', 6.00)`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order, template_id) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 3.50, 0, 9001),
+		('item-2', 'cat-1', 'material', '2x6x8', 10, 'ea', 6.01, 1, 9002),
+		('item-3', 'cat-1', 'labor', 'Framer', 8, 'hr', 50, 2, NULL)`)
+
+	body := app.get(t, "/categories/cat-1").Body.String()
+	if strings.Count(body, `class="price-drift`) != 2 {
+		t.Errorf("want drift indicator on the 2x4 only (mobile and desktop rows)")
+	}
+	if !strings.Contains(body, "&uarr;20.0%") {
+		t.Errorf("drift indicator missing direction and percentage")
+	}
+	if !strings.Contains(body, "Show only out-of-date (1)") {
+		t.Errorf("out-of-date filter toggle missing")
+	}
+
+	body = app.get(t, "/categories/cat-1?stale=1").Body.String()
+	if !strings.Contains(body, `id="item-row-item-1"`) || strings.Contains(body, `id="item-row-item-2"`) || strings.Contains(body, `id="item-row-item-3"`) {
+		t.Errorf("out-of-date filter should show only the 2x4")
+	}
+
+	rec := app.postForm(t, http.MethodPost, "/items/item-1/refresh-price", nil)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("refresh status = %d, want 303", rec.Code)
+	}
+	var price float64
+	if err := app.db.QueryRow(`SELECT unit_price FROM line_items WHERE id = 'item-1'`).Scan(&price); err != nil || price != 4.20 {
+		t.Errorf("refreshed price = %v, err = %v, want 4.20", price, err)
+	}
+	if body := app.get(t, "/categories/cat-1").Body.String(); strings.Contains(body, `class="price-drift`) {
+		t.Errorf("refreshed item still flagged as out of date")
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/items/item-3/refresh-price", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("refresh without template status = %d, want 400", rec.Code)
+	}
+}
+
+func TestCategoryPage_BelowCost(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', '2x4x8', 'ea', 4.00),
+		(9002, 'material', 'Lumber', '2x6x8', 'ea', 6.00)`)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage', 50)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order, template_id, is_credit) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 3.50, 0, 9001, 0),
+		('item-2', 'cat-1', 'material', '2x6x8', 10, 'ea', 6.00, 1, 9002, 0),
+		('item-3', 'cat-1', 'material', '2x6x8 return', 2, 'ea', -6.00, 2, 9002, 1)`)
+	app.exec(t, `UPDATE settings SET cost_margin_percent = 15`)
+
+	// The job's markup would cover the 2x4, but cost is compared before markup.
+	body := app.get(t, "/categories/cat-1").Body.String()
+	if strings.Count(body, `class="below-cost`) != 2 {
+		t.Errorf("want below-cost flag on the 2x4 only (mobile and desktop rows)")
+	}
+	if !strings.Contains(body, "1 below cost") || !strings.Contains(body, `hx-post="/items/item-1/raise-to-cost"`) {
+		t.Errorf("below-cost count or quick action missing")
+	}
+	if !strings.Contains(body, "Raise to cost + 15.0% ($4.60)") {
+		t.Errorf("quick action missing the margin and new price")
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/items/item-2/raise-to-cost", nil); rec.Code != http.StatusConflict {
+		t.Errorf("raise at cost status = %d, want 409", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPost, "/items/item-3/raise-to-cost", nil); rec.Code != http.StatusConflict {
+		t.Errorf("raise credit status = %d, want 409", rec.Code)
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/items/item-1/raise-to-cost", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("raise status = %d, want 303", rec.Code)
+	}
+	var price float64
+	if err := app.db.QueryRow(`SELECT unit_price FROM line_items WHERE id = 'item-1'`).Scan(&price); err != nil || price != 4.60 {
+		t.Errorf("raised price = %v, err = %v, want 4.60", price, err)
+	}
+	if body := app.get(t, "/categories/cat-1").Body.String(); strings.Contains(body, `class="below-cost`) {
+		t.Errorf("raised item still flagged as below cost")
+	}
+}
+
+func TestTaxTreatment(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Kitchen', 25)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Cabinets'), ('cat-2', 'job-1', 'Install')`)
+
+	if rec := app.postForm(t, http.MethodPut, "/jobs/job-1/markup", url.Values{
+		"surcharge_percent": {"25"},
+		"tax_percent":       {"8"},
+	}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("markup status = %d, want 303", rec.Code)
+	}
+	// Labor is untaxed here, so the whole category is exempt by default
+	if rec := app.postForm(t, http.MethodPut, "/categories/cat-2/markup", url.Values{
+		"tax_treatment": {"exempt"},
+	}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("category markup status = %d, want 303", rec.Code)
+	}
+
+	items := []url.Values{
+		{"type": {"material"}, "name": {"Base cabinet"}, "quantity": {"1"}, "unit": {"ea"}, "unit_price": {"100"}},
+		{"type": {"labor"}, "name": {"Install"}, "quantity": {"1"}, "unit": {"hr"}, "unit_price": {"200"}},
+	}
+	for i, category := range []string{"cat-1", "cat-2"} {
+		if rec := app.postForm(t, http.MethodPost, "/categories/"+category+"/items", items[i]); rec.Code != http.StatusSeeOther {
+			t.Fatalf("create status = %d, want 303", rec.Code)
+		}
+	}
+	// Hardware sold with the install is taxable despite its category
+	hardware := url.Values{"type": {"material"}, "name": {"Hinges"}, "quantity": {"1"}, "unit": {"ea"}, "unit_price": {"50"}, "tax_treatment": {"taxable"}}
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-2/items", hardware); rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status = %d, want 303", rec.Code)
+	}
+
+	// Taxable: 125 + 62.50; exempt: 250; tax: 8% of 187.50
+	totals := decodeJSONObject(t, app.apiGet(t, "/api/v1/jobs/job-1/totals"))
+	if totals["taxable_base"] != 187.5 || totals["exempt_base"] != 250.0 || totals["tax_total"] != 15.0 {
+		t.Errorf("totals = %v, want taxable 187.5, exempt 250, tax 15", totals)
+	}
+	if totals["grand_total"] != 452.5 || totals["surcharge_total"] != 87.5 {
+		t.Errorf("totals = %v, want grand total 452.5 and surcharge 87.5", totals)
+	}
+
+	if body := app.get(t, "/categories/cat-2/markup").Body.String(); !strings.Contains(body, `value="exempt" selected`) {
+		t.Errorf("category markup form does not show the exempt default")
+	}
+	if body := app.get(t, "/jobs/job-1/markup").Body.String(); !strings.Contains(body, `name="tax_percent"`) || !strings.Contains(body, `value="8.00"`) {
+		t.Errorf("job markup form missing the tax rate")
+	}
+
+	body := app.get(t, "/jobs/job-1").Body.String()
+	if !strings.Contains(body, `id="tax-totals"`) || !strings.Contains(body, "$15.00") {
+		t.Errorf("job page missing tax totals")
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", url.Values{
+		"type": {"material"}, "name": {"Pulls"}, "quantity": {"1"}, "unit": {"ea"}, "unit_price": {"5"}, "tax_treatment": {"zero-rated"},
+	}); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid treatment status = %d, want 400", rec.Code)
+	}
+}
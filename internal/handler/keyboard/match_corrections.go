@@ -0,0 +1,175 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/claude"
+)
+
+// matchCorrections returns the learned supplier name mappings, most recent
+// first, for the matcher.
+func (h *Handler) matchCorrections(ctx context.Context) ([]claude.Correction, error) {
+	rows, err := h.queries.ListMatchCorrections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	corrections := make([]claude.Correction, len(rows))
+	for i, row := range rows {
+		corrections[i] = claude.Correction{
+			SourceName:   row.SourceName,
+			TemplateID:   row.TemplateID,
+			TemplateName: row.TemplateName,
+		}
+	}
+	return corrections, nil
+}
+
+// learnCorrection remembers that a supplier item name belongs to a template,
+// replacing anything learned for the name before. A failure is logged rather
+// than failing the review action that taught it.
+func (h *Handler) learnCorrection(ctx context.Context, sourceName string, templateID int64) {
+	sourceName = strings.Join(strings.Fields(sourceName), " ")
+	if sourceName == "" {
+		return
+	}
+	err := h.queries.UpsertMatchCorrection(ctx, repository.UpsertMatchCorrectionParams{
+		SourceName: sourceName,
+		TemplateID: templateID,
+	})
+	if err != nil {
+		middleware.LoggerFromContext(ctx).Error("failed to record match correction", "error", err, "source_name", sourceName)
+	}
+}
+
+// RematchImportMatch points a pending import row at a different template
+// picked by hand, approves it, and learns the mapping for later imports.
+func (h *Handler) RematchImportMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	templateID, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("template_id")), 10, 64)
+	if err != nil {
+		http.Error(w, "Choose a template", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.queries.GetItemTemplate(ctx, templateID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Template not found", http.StatusBadRequest)
+			return
+		}
+		logger.Error("failed to get item template", "error", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := h.queries.GetPriceImportMatch(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Match not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get match", "error", err)
+		http.Error(w, "Failed to load match", http.StatusInternalServerError)
+		return
+	}
+	if existing.Status != "pending" {
+		http.Error(w, "Only pending rows can be re-matched", http.StatusConflict)
+		return
+	}
+
+	match, err := h.queries.RematchPriceImportMatch(ctx, repository.RematchPriceImportMatchParams{
+		MatchedTemplateID: sql.NullInt64{Int64: templateID, Valid: true},
+		ID:                id,
+	})
+	if err != nil {
+		logger.Error("failed to re-match import row", "error", err)
+		http.Error(w, "Failed to update match", http.StatusInternalServerError)
+		return
+	}
+	h.learnCorrection(ctx, match.SourceName, templateID)
+
+	if r.Header.Get("HX-Request") == "true" {
+		var buf bytes.Buffer
+		if err := h.renderer.RenderPartial(&buf, "match_row", match); err != nil {
+			logger.Error("failed to render match row", "error", err)
+			http.Error(w, "Failed to render", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	http.Redirect(w, r, "/price-import/"+match.ImportID+"/review", http.StatusSeeOther)
+}
+
+// GetMatchCorrections lists the supplier names the matcher has learned from
+// review corrections.
+func (h *Handler) GetMatchCorrections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	corrections, err := h.queries.ListMatchCorrections(ctx)
+	if err != nil {
+		logger.Error("failed to list match corrections", "error", err)
+		http.Error(w, "Failed to load learned matches", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Corrections": corrections,
+	}
+
+	if err := h.renderer.Render(w, "match_corrections", data); err != nil {
+		logger.Error("failed to render match corrections page", "error", err)
+	}
+}
+
+// DeleteMatchCorrection forgets a learned mapping, so the name goes back to
+// being matched by the AI.
+func (h *Handler) DeleteMatchCorrection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Learned match not found", http.StatusNotFound)
+		return
+	}
+
+	n, err := h.queries.DeleteMatchCorrection(ctx, id)
+	if err != nil {
+		logger.Error("failed to delete match correction", "error", err)
+		http.Error(w, "Failed to delete learned match", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Learned match not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/price-import/corrections")
+		return
+	}
+
+	http.Redirect(w, r, "/price-import/corrections", http.StatusSeeOther)
+}
@@ -0,0 +1,109 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedCorrectionImport(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9401, 'material', 'Lumber', '2x4x8 Stud', 'ea', 3.50),
+		(9402, 'material', 'Lumber', '2x6x8', 'ea', 5.25)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES ('imp-learn', 'april.xlsx', 'ready')`)
+	app.exec(t, `INSERT INTO price_import_matches (id, import_id, row_number, source_name, source_price, matched_template_id, confidence, status) VALUES
+		(41, 'imp-learn', 1, 'STUD  2X4 8FT', 3.75, 9402, 0.4, 'pending'),
+		(42, 'imp-learn', 2, '2X6 8FT', 5.40, 9402, 0.9, 'pending'),
+		(43, 'imp-learn', 3, 'OSB 7/16', 14.10, NULL, 0, 'pending')`)
+}
+
+func TestRematchImportMatch(t *testing.T) {
+	app := newTestApp(t)
+	seedCorrectionImport(t, app)
+
+	rec := app.postForm(t, http.MethodPut, "/price-import/matches/41/template", url.Values{"template_id": {"9401"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_import_matches WHERE id = 41
+		AND matched_template_id = 9401 AND status = 'approved' AND confidence = 1.0`); n != 1 {
+		t.Errorf("row not re-matched and approved")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections WHERE source_name = 'STUD 2X4 8FT' AND template_id = 9401`); n != 1 {
+		t.Errorf("correction not recorded")
+	}
+
+	// Only pending rows can be re-matched.
+	if rec := app.postForm(t, http.MethodPut, "/price-import/matches/41/template", url.Values{"template_id": {"9402"}}); rec.Code != http.StatusConflict {
+		t.Errorf("approved row status = %d, want 409", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPut, "/price-import/matches/42/template", url.Values{"template_id": {"9999"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown template status = %d, want 400", rec.Code)
+	}
+	if rec := app.postForm(t, http.MethodPut, "/price-import/matches/42/template", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing template status = %d, want 400", rec.Code)
+	}
+
+	// Re-matching the same name again replaces what was learned.
+	app.exec(t, `UPDATE price_import_matches SET status = 'pending' WHERE id = 41`)
+	app.postForm(t, http.MethodPut, "/price-import/matches/41/template", url.Values{"template_id": {"9402"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections WHERE source_name = 'stud 2x4 8ft' AND template_id = 9402`); n != 1 {
+		t.Errorf("correction not replaced")
+	}
+}
+
+func TestReviewCorrections_Learned(t *testing.T) {
+	app := newTestApp(t)
+	seedCorrectionImport(t, app)
+
+	// Approving as a match records nothing; approving with a rename does.
+	app.postForm(t, http.MethodPut, "/price-import/matches/42", url.Values{"status": {"approved"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections`); n != 0 {
+		t.Errorf("plain approval recorded a correction")
+	}
+	app.exec(t, `UPDATE price_import_matches SET status = 'pending' WHERE id = 42`)
+	app.postForm(t, http.MethodPut, "/price-import/matches/42", url.Values{"status": {"approved"}, "new_name": {"2x6x8 SPF"}})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections WHERE source_name = '2X6 8FT' AND template_id = 9402`); n != 1 {
+		t.Errorf("rename not recorded")
+	}
+
+	app.postForm(t, http.MethodPost, "/price-import/matches/43/create-template", url.Values{
+		"name": {"OSB 7/16 4x8"}, "unit": {"sheet"}, "category": {"Sheathing"}, "type": {"material"}, "price": {"14.10"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections c JOIN item_templates t ON t.id = c.template_id
+		WHERE c.source_name = 'OSB 7/16' AND t.name = 'OSB 7/16 4x8'`); n != 1 {
+		t.Errorf("created template not recorded")
+	}
+
+	body := app.get(t, "/price-import/corrections").Body.String()
+	if !strings.Contains(body, "2X6 8FT") || !strings.Contains(body, "OSB 7/16 4x8") {
+		t.Errorf("corrections page missing learned matches")
+	}
+}
+
+func TestDeleteMatchCorrection(t *testing.T) {
+	app := newTestApp(t)
+	seedCorrectionImport(t, app)
+	app.exec(t, `INSERT INTO match_corrections (id, source_name, template_id) VALUES (7, '2X6 8FT', 9402)`)
+
+	if rec := app.postForm(t, http.MethodDelete, "/price-import/corrections/7", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections`); n != 0 {
+		t.Errorf("correction not deleted")
+	}
+	if rec := app.postForm(t, http.MethodDelete, "/price-import/corrections/7", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+
+	// Deleting a template forgets what was learned for it.
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9403, 'material', 'Lumber', '2x6x10', 'ea', 6.75)`)
+	app.exec(t, `INSERT INTO match_corrections (source_name, template_id) VALUES ('2X6 10FT', 9403)`)
+	app.exec(t, `DELETE FROM item_templates WHERE id = 9403`)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM match_corrections`); n != 0 {
+		t.Errorf("correction outlived its template")
+	}
+}
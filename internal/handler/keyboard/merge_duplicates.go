@@ -0,0 +1,237 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// DuplicateMerge combines line items that differ only in quantity into the
+// earliest of them.
+type DuplicateMerge struct {
+	Keep     repository.LineItem
+	Remove   []repository.LineItem
+	Quantity float64 // Summed quantity of every item in the merge
+}
+
+// SkippedDuplicates are line items that look like duplicates but carry
+// details a merge would lose.
+type SkippedDuplicates struct {
+	Items  []repository.LineItem
+	Reason string
+}
+
+// duplicateKey groups line items by normalized name, unit, unit price and type.
+func duplicateKey(item repository.LineItem) string {
+	name := strings.Join(strings.Fields(strings.ToLower(item.Name)), " ")
+	unit := strings.ToLower(strings.TrimSpace(item.Unit))
+	return fmt.Sprintf("%s\x00%s\x00%v\x00%s", name, unit, item.UnitPrice, item.Type)
+}
+
+// planDuplicateMerges finds the merges for a category's items, which must be
+// in sort order so the earliest item of each group is kept.
+func planDuplicateMerges(items []repository.LineItem) ([]DuplicateMerge, []SkippedDuplicates) {
+	var keys []string
+	groups := make(map[string][]repository.LineItem)
+	for _, item := range items {
+		key := duplicateKey(item)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	var merges []DuplicateMerge
+	var skipped []SkippedDuplicates
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		if reason := mergeConflict(group); reason != "" {
+			skipped = append(skipped, SkippedDuplicates{Items: group, Reason: reason})
+			continue
+		}
+		merge := DuplicateMerge{Keep: group[0], Remove: group[1:]}
+		for _, item := range group {
+			merge.Quantity += item.Quantity
+		}
+		merges = append(merges, merge)
+	}
+	return merges, skipped
+}
+
+// mergeConflict explains why a group of duplicates can't be merged without
+// losing a detail or changing the totals, or returns "" if it can.
+func mergeConflict(group []repository.LineItem) string {
+	first := group[0]
+	for _, item := range group[1:] {
+		switch {
+		case item.SurchargePercent != first.SurchargePercent:
+			return "Different surcharges"
+		case strings.TrimSpace(item.Description.String) != strings.TrimSpace(first.Description.String):
+			return "Different descriptions"
+		case item.ExemptFromSurcharge != first.ExemptFromSurcharge || item.IsCredit != first.IsCredit:
+			return "Different surcharge settings"
+		case item.TaxTreatment != first.TaxTreatment:
+			return "Different tax treatment"
+		case item.Phase != first.Phase:
+			return "Different phases"
+		}
+	}
+	return ""
+}
+
+// MergeCategoryDuplicates previews merging a category's duplicate line items,
+// or applies the merges when the form includes apply=true.
+func (h *Handler) MergeCategoryDuplicates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	categoryID := r.PathValue("id")
+
+	category, err := h.queries.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, category.JobID)
+	if err != nil {
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	items, err := h.queries.ListLineItemsByCategory(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to list category items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	merges, skipped := planDuplicateMerges(items)
+	redirectURL := "/categories/" + categoryID
+
+	if r.Method == http.MethodPost && r.FormValue("apply") == "true" {
+		if len(merges) == 0 {
+			http.Error(w, "No duplicates to merge", http.StatusBadRequest)
+			return
+		}
+
+		removed := 0
+		for _, m := range merges {
+			removed += len(m.Remove)
+		}
+		detail := fmt.Sprintf("Merged %d duplicate items into %d in %s", removed+len(merges), len(merges), category.Name)
+		if err := h.applyDuplicateMerges(ctx, job.ID, merges, detail, activityActor(r)); err != nil {
+			logger.Error("failed to merge duplicates", "error", err)
+			http.Error(w, "Failed to merge duplicates", http.StatusInternalServerError)
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", redirectURL)
+			return
+		}
+
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Job":               job,
+		"Category":          category,
+		"BackURL":           redirectURL,
+		"Merges":            merges,
+		"Skipped":           skipped,
+		"CategoryTotal":     h.calculateCategoryTotal(categoryID, job, settings, categories, lineItems),
+		"CurrentCategoryID": categoryID,
+	}
+
+	if err := h.renderer.Render(w, "merge_duplicates", data); err != nil {
+		logger.Error("failed to render duplicate merge preview", "error", err)
+	}
+}
+
+// applyDuplicateMerges folds each merge into its kept item, moving the removed
+// items' comments with it, and records an activity entry by actor in one
+// transaction.
+func (h *Handler) applyDuplicateMerges(ctx context.Context, jobID string, merges []DuplicateMerge, detail, actor string) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	for _, m := range merges {
+		if err := qtx.UpdateLineItemQuantity(ctx, repository.UpdateLineItemQuantityParams{
+			Quantity: m.Quantity,
+			ID:       m.Keep.ID,
+		}); err != nil {
+			return fmt.Errorf("updating quantity for %s: %w", m.Keep.ID, err)
+		}
+		for _, item := range m.Remove {
+			// Comments are deleted along with their item, so keep them on the
+			// merged one.
+			if err := qtx.MoveItemComments(ctx, repository.MoveItemCommentsParams{
+				ToItemID:   m.Keep.ID,
+				FromItemID: item.ID,
+			}); err != nil {
+				return fmt.Errorf("moving comments from %s: %w", item.ID, err)
+			}
+			if err := qtx.DeleteLineItem(ctx, item.ID); err != nil {
+				return fmt.Errorf("deleting %s: %w", item.ID, err)
+			}
+		}
+	}
+
+	// An earlier single-item edit can't be undone on its own once a bulk
+	// change has touched the job.
+	if err := qtx.DismissOpenTotalChangeAlerts(ctx, jobID); err != nil {
+		return fmt.Errorf("dismissing total change alerts: %w", err)
+	}
+
+	if _, err := qtx.CreateJobActivity(ctx, repository.CreateJobActivityParams{
+		JobID:  jobID,
+		Action: "merge_duplicates",
+		Detail: detail,
+		Actor:  actor,
+	}); err != nil {
+		return fmt.Errorf("recording activity: %w", err)
+	}
+
+	return tx.Commit()
+}
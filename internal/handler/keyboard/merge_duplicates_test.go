@@ -0,0 +1,113 @@
+package keyboard_test
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedDuplicateItems(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage', 12.5)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-framing', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order) VALUES
+		('stud-1', 'cat-framing', 'material', '2x4x8 Stud', 'KD fir', 10, 'ea', 3.27, NULL, 0),
+		('stud-2', 'cat-framing', 'material', '2x4x8  stud', 'KD fir', 12, 'EA', 3.27, NULL, 1),
+		('stud-3', 'cat-framing', 'material', '2x4x8 Stud', 'KD fir', 5, 'ea', 3.27, NULL, 2),
+		('stud-labor', 'cat-framing', 'labor', '2x4x8 Stud', 'KD fir', 2, 'ea', 3.27, NULL, 3),
+		('nails-1', 'cat-framing', 'material', 'Framing nails', NULL, 1, 'box', 42, NULL, 4),
+		('nails-2', 'cat-framing', 'material', 'Framing nails', NULL, 2, 'box', 42, 5, 5),
+		('screws-1', 'cat-framing', 'material', 'Screws', 'Deck', 1, 'box', 20, NULL, 6),
+		('screws-2', 'cat-framing', 'material', 'Screws', 'Drywall', 1, 'box', 20, NULL, 7)`)
+}
+
+func categoryItemTotal(t *testing.T, app *testApp) float64 {
+	t.Helper()
+	var total float64
+	if err := app.db.QueryRow(`SELECT SUM(quantity * unit_price) FROM line_items`).Scan(&total); err != nil {
+		t.Fatalf("sum items: %v", err)
+	}
+	return total
+}
+
+func TestMergeCategoryDuplicates_Preview(t *testing.T) {
+	app := newTestApp(t)
+	seedDuplicateItems(t, app)
+
+	rec := app.get(t, "/categories/cat-framing/merge-duplicates")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if got := strings.Count(body, `class="merge-row`); got != 1 {
+		t.Errorf("merge rows = %d, want 1", got)
+	}
+	if !strings.Contains(body, "27.00 ea") {
+		t.Errorf("preview missing the summed quantity")
+	}
+	for _, want := range []string{"Different surcharges", "Different descriptions"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("preview missing skipped reason %q", want)
+		}
+	}
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items`); got != 8 {
+		t.Errorf("preview changed items: %d rows", got)
+	}
+}
+
+func TestMergeCategoryDuplicates_Apply(t *testing.T) {
+	app := newTestApp(t)
+	seedDuplicateItems(t, app)
+	before := categoryItemTotal(t, app)
+
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-framing/merge-duplicates", url.Values{"apply": {"true"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	var quantity float64
+	var description string
+	if err := app.db.QueryRow(`SELECT quantity, description FROM line_items WHERE id = 'stud-1'`).Scan(&quantity, &description); err != nil {
+		t.Fatalf("kept item: %v", err)
+	}
+	if quantity != 27 || description != "KD fir" {
+		t.Errorf("kept item = %v %q, want 27 \"KD fir\"", quantity, description)
+	}
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id IN ('stud-2', 'stud-3')`); got != 0 {
+		t.Errorf("merged items not removed")
+	}
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items`); got != 6 {
+		t.Errorf("items = %d, want 6 with skipped groups untouched", got)
+	}
+	if after := categoryItemTotal(t, app); math.Abs(after-before) > 0.005 {
+		t.Errorf("total changed from %.2f to %.2f", before, after)
+	}
+	if got := countRows(t, app, `SELECT COUNT(*) FROM job_activity WHERE action = 'merge_duplicates'`); got != 1 {
+		t.Errorf("activity entries = %d, want 1", got)
+	}
+
+	// Nothing left to merge.
+	rec = app.postForm(t, http.MethodPost, "/categories/cat-framing/merge-duplicates", url.Values{"apply": {"true"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("second apply status = %d, want 400", rec.Code)
+	}
+}
+
+func TestMergeCategoryDuplicates_KeepsComments(t *testing.T) {
+	app := newTestApp(t)
+	seedDuplicateItems(t, app)
+	app.exec(t, `INSERT INTO item_comments (item_id, author, body) VALUES
+		('stud-1', 'Dana', 'Check the lumber yard price'),
+		('stud-2', 'Sam', 'Customer wants these kiln dried'),
+		('stud-3', 'Sam', 'Two are for the shed')`)
+
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-framing/merge-duplicates", url.Values{"apply": {"true"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+	if got := countRows(t, app, `SELECT COUNT(*) FROM item_comments WHERE item_id = 'stud-1'`); got != 3 {
+		t.Errorf("comments on kept item = %d, want 3", got)
+	}
+}
@@ -0,0 +1,178 @@
+package keyboard
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// generalCategoryName is the top-level category that receives the mobilization fee.
+	generalCategoryName = "General"
+	// mobilizationFeeName identifies the mobilization fee line item on a job.
+	mobilizationFeeName = "Mobilization"
+)
+
+// MinimumWarning describes a job whose grand total falls short of the configured minimum.
+type MinimumWarning struct {
+	MinimumJobTotal float64
+	Shortfall       float64
+	MobilizationFee float64
+}
+
+// minimumWarning returns a warning when the job is below the minimum total and
+// does not already carry a mobilization fee, or nil otherwise.
+func minimumWarning(settings repository.Setting, grandTotal float64, lineItems []repository.LineItem) *MinimumWarning {
+	if settings.MinimumJobTotal <= 0 || grandTotal >= settings.MinimumJobTotal {
+		return nil
+	}
+	if hasMobilizationFee(lineItems) {
+		return nil
+	}
+	return &MinimumWarning{
+		MinimumJobTotal: settings.MinimumJobTotal,
+		Shortfall:       settings.MinimumJobTotal - grandTotal,
+		MobilizationFee: settings.MobilizationFee,
+	}
+}
+
+// hasMobilizationFee reports whether any line item is a mobilization fee.
+func hasMobilizationFee(lineItems []repository.LineItem) bool {
+	for _, item := range lineItems {
+		if strings.EqualFold(item.Name, mobilizationFeeName) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddMobilizationFee adds the configured mobilization fee to a job as a flat
+// line item in its General category, creating the category if needed.
+func (h *Handler) AddMobilizationFee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	if _, err := h.queries.GetJob(ctx, jobID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+	if settings.MobilizationFee <= 0 {
+		http.Error(w, "No mobilization fee is configured", http.StatusBadRequest)
+		return
+	}
+
+	watch, err := h.watchJobTotal(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
+	// The check for an existing fee, the General category, and the fee itself
+	// go in one transaction, so a failed insert leaves no empty category and
+	// two requests can't both add a fee.
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to add mobilization fee", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := h.queries.WithTx(tx)
+
+	lineItems, err := qtx.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+	if hasMobilizationFee(lineItems) {
+		http.Error(w, "Mobilization fee already added", http.StatusConflict)
+		return
+	}
+
+	categories, err := qtx.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	var categoryID string
+	for _, cat := range categories {
+		if !cat.ParentID.Valid && strings.EqualFold(cat.Name, generalCategoryName) {
+			categoryID = cat.ID
+			break
+		}
+	}
+
+	if categoryID == "" {
+		category, err := qtx.CreateCategory(ctx, repository.CreateCategoryParams{
+			ID:               uuid.New().String(),
+			JobID:            jobID,
+			ParentID:         sql.NullString{},
+			Name:             generalCategoryName,
+			SurchargePercent: sql.NullFloat64{},
+			SortOrder:        0,
+		})
+		if err != nil {
+			logger.Error("failed to create general category", "error", err)
+			http.Error(w, "Failed to create category", http.StatusInternalServerError)
+			return
+		}
+		categoryID = category.ID
+	}
+
+	// Flat pricing: a single "job" unit at the full fee.
+	item, err := qtx.CreateLineItem(ctx, repository.CreateLineItemParams{
+		ID:               uuid.New().String(),
+		CategoryID:       categoryID,
+		Type:             string(domain.LineItemTypeFee),
+		Name:             mobilizationFeeName,
+		Description:      sql.NullString{},
+		Quantity:         1,
+		Unit:             "job",
+		UnitPrice:        settings.MobilizationFee,
+		SurchargePercent: sql.NullFloat64{},
+		SortOrder:        0,
+		TaxTreatment:     string(domain.TaxTreatmentDefault),
+	})
+	if err != nil {
+		logger.Error("failed to create mobilization fee", "error", err)
+		http.Error(w, "Failed to add mobilization fee", http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", "error", err)
+		http.Error(w, "Failed to add mobilization fee", http.StatusInternalServerError)
+		return
+	}
+	if err := h.checkTotal(ctx, watch, totalAlertCreate, item.ID, nil); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
+
+	// The warning is shown on the job and category pages; reload whichever
+	// the fee was added from.
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+
+	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
+}
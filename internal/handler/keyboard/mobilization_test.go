@@ -0,0 +1,114 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMobilizationFee_WarnsAndAddsFlatItem(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `UPDATE settings SET minimum_job_total = 500, mobilization_fee = 150`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Fence Repair')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Fencing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES ('item-1', 'cat-1', 'material', 'Post', 4, 'ea', 25)`)
+
+	rec := app.get(t, "/jobs/job-1")
+	if !strings.Contains(rec.Body.String(), `id="minimum-warning"`) {
+		t.Fatalf("expected minimum total warning on job below minimum")
+	}
+
+	rec = app.postForm(t, http.MethodPost, "/jobs/job-1/mobilization", url.Values{})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("add mobilization status = %d, want 303", rec.Code)
+	}
+
+	var category, unit string
+	var quantity, price float64
+	err := app.db.QueryRow(`SELECT c.name, li.quantity, li.unit, li.unit_price
+		FROM line_items li JOIN categories c ON c.id = li.category_id
+		WHERE li.name = 'Mobilization'`).Scan(&category, &quantity, &unit, &price)
+	if err != nil {
+		t.Fatalf("query mobilization item: %v", err)
+	}
+	if category != "General" || quantity != 1 || unit != "job" || price != 150 {
+		t.Errorf("mobilization item = (%s, %v, %s, %v), want (General, 1, job, 150)", category, quantity, unit, price)
+	}
+
+	rec = app.get(t, "/jobs/job-1")
+	if strings.Contains(rec.Body.String(), `id="minimum-warning"`) {
+		t.Errorf("warning still shown after the mobilization fee was added")
+	}
+
+	rec = app.postForm(t, http.MethodPost, "/jobs/job-1/mobilization", url.Values{})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second add status = %d, want 409", rec.Code)
+	}
+}
+
+func TestMobilizationFee_NoWarningAboveMinimum(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `UPDATE settings SET minimum_job_total = 50, mobilization_fee = 150`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Fence Repair')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Fencing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES ('item-1', 'cat-1', 'material', 'Post', 4, 'ea', 25)`)
+
+	rec := app.get(t, "/jobs/job-1")
+	if strings.Contains(rec.Body.String(), `id="minimum-warning"`) {
+		t.Errorf("warning shown for a job above the minimum")
+	}
+}
+
+func TestMobilizationFee_WarningOnCategoryPage(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `UPDATE settings SET minimum_job_total = 500, mobilization_fee = 150`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Fence Repair')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Fencing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES ('item-1', 'cat-1', 'material', 'Post', 4, 'ea', 25)`)
+
+	if body := app.get(t, "/categories/cat-1").Body.String(); !strings.Contains(body, `id="minimum-warning"`) {
+		t.Fatalf("category page missing minimum total warning")
+	}
+
+	app.postForm(t, http.MethodPut, "/items/item-1", url.Values{
+		"name": {"Post"}, "quantity": {"40"}, "unit": {"ea"}, "unit_price": {"25"},
+	})
+	if body := app.get(t, "/categories/cat-1").Body.String(); strings.Contains(body, `id="minimum-warning"`) {
+		t.Errorf("warning still shown once the edit brought the job over the minimum")
+	}
+}
+
+func TestMobilizationFee_FailedInsertLeavesNoCategory(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `UPDATE settings SET minimum_job_total = 500, mobilization_fee = 150`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Fence Repair')`)
+	app.exec(t, `CREATE TRIGGER fail_fee BEFORE INSERT ON line_items WHEN NEW.name = 'Mobilization'
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END`)
+
+	if rec := app.postForm(t, http.MethodPost, "/jobs/job-1/mobilization", url.Values{}); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM categories WHERE job_id = 'job-1'`); n != 0 {
+		t.Errorf("General category left behind by a failed fee")
+	}
+}
+
+func TestMobilizationFee_DoubleSubmit(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `UPDATE settings SET minimum_job_total = 500, mobilization_fee = 150`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Fence Repair')`)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/jobs/job-1/mobilization", nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("X-Idempotency-Key", "fee-click")
+		if rec := app.do(req); rec.Code != http.StatusOK {
+			t.Errorf("submit %d status = %d, want 200", i+1, rec.Code)
+		}
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Mobilization'`); n != 1 {
+		t.Errorf("mobilization fees = %d, want 1", n)
+	}
+}
@@ -0,0 +1,172 @@
+package keyboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+// errNotANumber is returned by the number parsers for text that isn't a
+// number in the expected format.
+var errNotANumber = errors.New("not a number")
+
+// NumberError is a form field whose value couldn't be read as a number. Its
+// message is meant to be shown next to the form.
+type NumberError struct {
+	Field   string // the form field's name
+	Label   string // the field's name as shown on the form
+	Value   string
+	Example string // how the field should be typed, such as "1,200.50"
+}
+
+func (e *NumberError) Error() string {
+	return fmt.Sprintf("%s %q isn't a number; enter it like %s", e.Label, e.Value, e.Example)
+}
+
+// parseNumber reads a number typed into a form, with decimal as the decimal
+// separator and the other of '.' and ',' allowed as a thousands separator
+// between groups of three digits. Blank is zero. "12,5" is rejected when the
+// decimal separator is '.', rather than read as 125.
+func parseNumber(value string, decimal rune) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	thousands := ','
+	if decimal == ',' {
+		thousands = '.'
+	}
+
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(value, string(decimal))
+	if hasFraction && (fraction == "" || !allDigits(fraction)) {
+		return 0, errNotANumber
+	}
+
+	if strings.ContainsRune(whole, thousands) {
+		groups := strings.Split(whole, string(thousands))
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return 0, errNotANumber
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return 0, errNotANumber
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	if (whole == "" && !hasFraction) || !allDigits(whole) {
+		return 0, errNotANumber
+	}
+
+	n := sign + whole
+	if hasFraction {
+		n += "." + fraction
+	}
+	return strconv.ParseFloat(n, 64)
+}
+
+// parseMoney reads an amount such as "$1,200.50" or "-$45"; see parseNumber.
+// A dollar sign on its own isn't an amount.
+func parseMoney(value string, decimal rune) (float64, error) {
+	value = strings.TrimSpace(value)
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", strings.TrimSpace(value[1:])
+	}
+	if amount, ok := strings.CutPrefix(value, "$"); ok {
+		if amount == "" {
+			return 0, errNotANumber
+		}
+		value = amount
+	}
+	return parseNumber(sign+value, decimal)
+}
+
+// parsePercent reads a percentage such as "15" or "12.5%"; see parseNumber.
+func parsePercent(value string, decimal rune) (float64, error) {
+	return parseNumber(strings.TrimSuffix(strings.TrimSpace(value), "%"), decimal)
+}
+
+func allDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// decimalSeparator is the decimal separator amounts are typed with.
+func (h *Handler) decimalSeparator() rune {
+	if h.config.DecimalSeparator == "," {
+		return ','
+	}
+	return '.'
+}
+
+// numberExample shows how a number is typed with the configured separators.
+func (h *Handler) numberExample() string {
+	if h.decimalSeparator() == ',' {
+		return "1.200,50"
+	}
+	return "1,200.50"
+}
+
+// formNumber reads the named form field with parse, wrapping a bad value in a
+// NumberError labelled for the form.
+func (h *Handler) formNumber(r *http.Request, field, label string, parse func(string, rune) (float64, error)) (float64, error) {
+	value := r.FormValue(field)
+	n, err := parse(value, h.decimalSeparator())
+	if err != nil {
+		return 0, &NumberError{Field: field, Label: label, Value: value, Example: h.numberExample()}
+	}
+	return n, nil
+}
+
+// formMoney reads an amount field; see parseMoney.
+func (h *Handler) formMoney(r *http.Request, field, label string) (float64, error) {
+	return h.formNumber(r, field, label, parseMoney)
+}
+
+// formPercent reads a percentage field; see parsePercent.
+func (h *Handler) formPercent(r *http.Request, field, label string) (float64, error) {
+	return h.formNumber(r, field, label, parsePercent)
+}
+
+// formQuantity reads a quantity field; see parseNumber.
+func (h *Handler) formQuantity(r *http.Request, field, label string) (float64, error) {
+	return h.formNumber(r, field, label, parseNumber)
+}
+
+// numberError answers a form with a field that isn't a number. HTMX forms get
+// a field error fragment, which base.html places under the named field; other
+// requests get the message as text.
+func (h *Handler) numberError(w http.ResponseWriter, r *http.Request, err error) {
+	var numErr *NumberError
+	if r.Header.Get("HX-Request") != "true" || !errors.As(err, &numErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "field_error", map[string]interface{}{
+		"Field":   numErr.Field,
+		"Message": numErr.Error(),
+	}); err != nil {
+		middleware.LoggerFromContext(r.Context()).Error("failed to render field error", "error", err)
+		http.Error(w, numErr.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(buf.Bytes())
+}
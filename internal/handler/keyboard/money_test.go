@@ -0,0 +1,235 @@
+package keyboard_test
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+)
+
+func seedMoneyJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-money', 'Deck', 10)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name, surcharge_percent) VALUES ('cat-money', 'job-money', 'Framing', 5)`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-money', 'cat-money', 'material', 'Joist hanger', 4, 'ea', 3.25)`)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9401, 'material', 'Lumber', '2x8 PT', 'ea', 12.5)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES ('imp-money', 'lumber.xlsx', 'ready')`)
+	app.exec(t, `INSERT INTO price_import_matches (import_id, row_number, source_name, source_price, matched_template_id, confidence, status) VALUES
+		('imp-money', 1, '2X8 PT', 13, 9401, 0.95, 'pending')`)
+}
+
+// TestMalformedNumbersRejected posts numbers that ParseFloat used to turn
+// into zero to every form that takes a price, quantity, or percentage.
+func TestMalformedNumbersRejected(t *testing.T) {
+	forms := []struct {
+		name   string
+		method string
+		target string
+		field  string
+		form   url.Values
+		stored string // query for the value the field is saved to
+		want   float64
+	}{
+		{"new line item price", http.MethodPost, "/categories/cat-money/items", "unit_price",
+			url.Values{"name": {"Post base"}, "quantity": {"1"}},
+			`SELECT COUNT(*) FROM line_items WHERE name = 'Post base'`, 0},
+		{"new line item quantity", http.MethodPost, "/categories/cat-money/items", "quantity",
+			url.Values{"name": {"Post base"}, "unit_price": {"9"}},
+			`SELECT COUNT(*) FROM line_items WHERE name = 'Post base'`, 0},
+		{"line item price", http.MethodPut, "/items/item-money", "unit_price",
+			url.Values{"name": {"Joist hanger"}, "quantity": {"4"}},
+			`SELECT unit_price FROM line_items WHERE id = 'item-money'`, 3.25},
+		{"line item quantity", http.MethodPut, "/items/item-money", "quantity",
+			url.Values{"name": {"Joist hanger"}, "unit_price": {"3.25"}},
+			`SELECT quantity FROM line_items WHERE id = 'item-money'`, 4},
+		{"category markup", http.MethodPut, "/categories/cat-money/markup", "surcharge_percent",
+			url.Values{},
+			`SELECT surcharge_percent FROM categories WHERE id = 'cat-money'`, 5},
+		{"job markup", http.MethodPut, "/jobs/job-money/markup", "surcharge_percent",
+			url.Values{},
+			`SELECT surcharge_percent FROM jobs WHERE id = 'job-money'`, 10},
+		{"job details", http.MethodPut, "/jobs/job-money", "surcharge_percent",
+			url.Values{"name": {"Deck"}},
+			`SELECT surcharge_percent FROM jobs WHERE id = 'job-money'`, 10},
+		{"new item template", http.MethodPost, "/items", "default_price",
+			url.Values{"name": {"Post cap"}},
+			`SELECT COUNT(*) FROM item_templates WHERE name = 'Post cap'`, 0},
+		{"item template", http.MethodPut, "/item-templates/9401", "default_price",
+			url.Values{"name": {"2x8 PT"}},
+			`SELECT default_price FROM item_templates WHERE id = 9401`, 12.5},
+		{"settings markup", http.MethodPut, "/settings", "default_surcharge_percent",
+			url.Values{"default_surcharge_mode": {"stacking"}},
+			`SELECT default_surcharge_percent FROM settings`, 0},
+		{"settings minimum", http.MethodPut, "/settings", "minimum_job_total",
+			url.Values{"default_surcharge_mode": {"stacking"}},
+			`SELECT minimum_job_total FROM settings`, 0},
+		{"price adjustment", http.MethodPost, "/jobs/job-money/adjust-prices", "percent",
+			url.Values{},
+			`SELECT unit_price FROM line_items WHERE id = 'item-money'`, 3.25},
+		{"bulk approve threshold", http.MethodPost, "/price-import/imp-money/bulk-approve", "threshold",
+			url.Values{},
+			`SELECT COUNT(*) FROM price_import_matches WHERE status = 'auto_approved'`, 0},
+	}
+
+	for _, f := range forms {
+		for _, bad := range []string{"12,5", "abc", "1,2,3", "$", "4.5.6"} {
+			t.Run(f.name+" "+bad, func(t *testing.T) {
+				app := newTestApp(t)
+				seedMoneyJob(t, app)
+				var before float64
+				if err := app.db.QueryRow(f.stored).Scan(&before); err != nil {
+					t.Fatal(err)
+				}
+
+				form := url.Values{f.field: {bad}}
+				for k, v := range f.form {
+					form[k] = v
+				}
+				rec := app.postForm(t, f.method, f.target, form)
+				if rec.Code != http.StatusBadRequest {
+					t.Fatalf("status = %d, want 400", rec.Code)
+				}
+
+				var after float64
+				if err := app.db.QueryRow(f.stored).Scan(&after); err != nil {
+					t.Fatal(err)
+				}
+				if after != f.want {
+					t.Errorf("stored %v, want %v left alone", after, f.want)
+				}
+			})
+		}
+	}
+}
+
+func TestMalformedNumberMessage(t *testing.T) {
+	app := newTestApp(t)
+	seedMoneyJob(t, app)
+
+	rec := app.postForm(t, http.MethodPut, "/items/item-money", url.Values{
+		"name":       {"Joist hanger"},
+		"quantity":   {"4"},
+		"unit_price": {"12,5"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `Price "12,5" isn't a number`) || !strings.Contains(body, "1,200.50") {
+		t.Errorf("message = %q, want the field, value, and an example", body)
+	}
+}
+
+// TestMalformedNumberFieldError checks that HTMX forms get the message as a
+// fragment naming the field, which the page shows under it.
+func TestMalformedNumberFieldError(t *testing.T) {
+	forms := []struct {
+		method string
+		target string
+		field  string
+		form   url.Values
+	}{
+		{http.MethodPost, "/categories/cat-money/items", "unit_price", url.Values{"name": {"Post base"}, "quantity": {"1"}}},
+		{http.MethodPut, "/items/item-money", "quantity", url.Values{"name": {"Joist hanger"}, "unit_price": {"3.25"}}},
+		{http.MethodPut, "/categories/cat-money/markup", "surcharge_percent", url.Values{}},
+		{http.MethodPut, "/settings", "minimum_job_total", url.Values{"default_surcharge_mode": {"stacking"}}},
+	}
+	for _, f := range forms {
+		t.Run(f.target+" "+f.field, func(t *testing.T) {
+			app := newTestApp(t)
+			seedMoneyJob(t, app)
+
+			form := url.Values{f.field: {"12,5"}}
+			for k, v := range f.form {
+				form[k] = v
+			}
+			req := httptest.NewRequest(f.method, f.target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("HX-Request", "true")
+			rec := app.do(req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			body := html.UnescapeString(rec.Body.String())
+			if !strings.Contains(body, `data-field-error="`+f.field+`"`) || !strings.Contains(body, `"12,5" isn't a number`) {
+				t.Errorf("fragment = %q, want the message for %s", body, f.field)
+			}
+		})
+	}
+}
+
+func TestFormattedNumbersAccepted(t *testing.T) {
+	tests := []struct {
+		decimal string
+		price   string
+		percent string
+		want    float64
+		wantPct float64
+	}{
+		{"", "$1,200.50", "12.5%", 1200.5, 12.5},
+		{"", " $45 ", "15", 45, 15},
+		{"", "1200", "0", 1200, 0},
+		{",", "1.200,50", "12,5", 1200.5, 12.5},
+		{",", "$45,75", "15%", 45.75, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decimal+tt.price, func(t *testing.T) {
+			app := newTestAppWithConfig(t, &config.Config{DecimalSeparator: tt.decimal})
+			seedMoneyJob(t, app)
+
+			rec := app.postForm(t, http.MethodPut, "/items/item-money", url.Values{
+				"name":       {"Joist hanger"},
+				"quantity":   {"4"},
+				"unit_price": {tt.price},
+			})
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("item status = %d, want 303: %s", rec.Code, rec.Body.String())
+			}
+			var price float64
+			if err := app.db.QueryRow(`SELECT unit_price FROM line_items WHERE id = 'item-money'`).Scan(&price); err != nil {
+				t.Fatal(err)
+			}
+			if price != tt.want {
+				t.Errorf("unit_price = %v, want %v", price, tt.want)
+			}
+
+			rec = app.postForm(t, http.MethodPut, "/jobs/job-money/markup", url.Values{
+				"surcharge_percent": {tt.percent},
+			})
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("markup status = %d, want 303: %s", rec.Code, rec.Body.String())
+			}
+			var percent float64
+			if err := app.db.QueryRow(`SELECT surcharge_percent FROM jobs WHERE id = 'job-money'`).Scan(&percent); err != nil {
+				t.Fatal(err)
+			}
+			if percent != tt.wantPct {
+				t.Errorf("surcharge_percent = %v, want %v", percent, tt.wantPct)
+			}
+		})
+	}
+}
+
+func TestCreateQuickAddItem_MalformedPrice(t *testing.T) {
+	app := newTestApp(t)
+	seedQuickAddJobs(t, app)
+
+	rec := app.do(quickAddRequest(url.Values{
+		"category_id": {"cat-open"},
+		"type":        {"material"},
+		"name":        {"Base cabinet"},
+		"quantity":    {"3"},
+		"unit_price":  {"1,80"},
+	}))
+	if body := rec.Body.String(); !strings.Contains(body, "isn&#39;t a number") {
+		t.Errorf("expected the form back with the price error, got %q", body)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Base cabinet'`); n != 0 {
+		t.Errorf("line items created = %d, want 0", n)
+	}
+}
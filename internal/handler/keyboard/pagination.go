@@ -0,0 +1,138 @@
+package keyboard
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Page size bounds for paginated lists.
+const (
+	defaultPageSize = 20
+	minPageSize     = 10
+	maxPageSize     = 200
+)
+
+// pageSizeCookie remembers the per_page choice between visits.
+const pageSizeCookie = "per_page"
+
+// pageSizeOptions are the page sizes offered below paginated lists.
+var pageSizeOptions = []int{20, 50, 100, 200}
+
+// PaginationData holds pagination state for templates.
+type PaginationData struct {
+	CurrentPage int
+	TotalPages  int
+	TotalItems  int64
+	PerPage     int
+	Offset      int
+	HasPrev     bool
+	HasNext     bool
+	PrevURL     string
+	NextURL     string
+	SizeOptions []PageSizeOption
+}
+
+// PageSizeOption is a per_page link shown below a paginated list.
+type PageSizeOption struct {
+	Size     int
+	URL      string
+	Selected bool
+}
+
+// clampPageSize limits a page size to the supported bounds.
+func clampPageSize(size int) int {
+	if size < minPageSize {
+		return minPageSize
+	}
+	if size > maxPageSize {
+		return maxPageSize
+	}
+	return size
+}
+
+// pageSize resolves the page size for a list request. An explicit per_page
+// query parameter wins and is remembered in a cookie; otherwise the cookie is
+// used, then the page size from settings.
+func (h *Handler) pageSize(w http.ResponseWriter, r *http.Request) int {
+	if perPage, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil {
+		size := clampPageSize(perPage)
+		http.SetCookie(w, &http.Cookie{
+			Name:     pageSizeCookie,
+			Value:    strconv.Itoa(size),
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return size
+	}
+
+	if cookie, err := r.Cookie(pageSizeCookie); err == nil {
+		if size, err := strconv.Atoi(cookie.Value); err == nil {
+			return clampPageSize(size)
+		}
+	}
+
+	if settings, err := h.queries.GetSettings(r.Context()); err == nil {
+		return clampPageSize(int(settings.PageSize))
+	}
+	return defaultPageSize
+}
+
+// newPagination works out the page to show for a list of totalItems rows.
+// Pages are 1-based; a page past the end shows the last page. Links keep the
+// request's other query parameters.
+func newPagination(r *http.Request, totalItems int64, perPage int) PaginationData {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	totalPages := int((totalItems + int64(perPage) - 1) / int64(perPage))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	p := PaginationData{
+		CurrentPage: page,
+		TotalPages:  totalPages,
+		TotalItems:  totalItems,
+		PerPage:     perPage,
+		Offset:      (page - 1) * perPage,
+		HasPrev:     page > 1,
+		HasNext:     page < totalPages,
+	}
+	if p.HasPrev {
+		p.PrevURL = pageURL(r.URL, page-1, 0)
+	}
+	if p.HasNext {
+		p.NextURL = pageURL(r.URL, page+1, 0)
+	}
+	for _, size := range pageSizeOptions {
+		p.SizeOptions = append(p.SizeOptions, PageSizeOption{
+			Size:     size,
+			URL:      pageURL(r.URL, 1, size),
+			Selected: size == perPage,
+		})
+	}
+	return p
+}
+
+// pageURL returns u with the page number set and, when perPage is non-zero,
+// the per_page parameter. HTMX partial flags are dropped so links load the
+// full page.
+func pageURL(u *url.URL, page, perPage int) string {
+	query := u.Query()
+	query.Del("partial")
+	query.Set("page", strconv.Itoa(page))
+	if perPage > 0 {
+		query.Set("per_page", strconv.Itoa(perPage))
+	} else {
+		query.Del("per_page")
+	}
+	return u.Path + "?" + query.Encode()
+}
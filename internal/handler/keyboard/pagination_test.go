@@ -0,0 +1,123 @@
+package keyboard_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedClients(t *testing.T, app *testApp, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		app.exec(t, `INSERT INTO clients (id, name) VALUES (?, ?)`, fmt.Sprintf("client-%03d", i), fmt.Sprintf("Client %03d", i))
+	}
+}
+
+func TestPagination(t *testing.T) {
+	app := newTestApp(t)
+	seedClients(t, app, 45)
+
+	tests := []struct {
+		name     string
+		target   string
+		page     string
+		first    string
+		prevLink string
+		nextLink string
+	}{
+		{"first page", "/clients", "Page 1 of 3", "Client 001", "", "/clients?page=2"},
+		{"last page", "/clients?page=3", "Page 3 of 3", "Client 041", "/clients?page=2", ""},
+		{"past the end shows last page", "/clients?page=9", "Page 3 of 3", "Client 041", "/clients?page=2", ""},
+		{"invalid page", "/clients?page=abc", "Page 1 of 3", "Client 001", "", "/clients?page=2"},
+		{"keeps search", "/clients?q=Client&page=2", "Page 2 of 3", "Client 021", "/clients?page=1&amp;q=Client", "/clients?page=3&amp;q=Client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := app.get(t, tt.target).Body.String()
+			if !strings.Contains(body, tt.page) {
+				t.Errorf("missing %q", tt.page)
+			}
+			if !strings.Contains(body, tt.first) {
+				t.Errorf("page does not start with %q", tt.first)
+			}
+			if tt.prevLink != "" && !strings.Contains(body, `href="`+tt.prevLink+`"`) {
+				t.Errorf("missing prev link %q", tt.prevLink)
+			}
+			if tt.nextLink != "" && !strings.Contains(body, `href="`+tt.nextLink+`"`) {
+				t.Errorf("missing next link %q", tt.nextLink)
+			}
+		})
+	}
+}
+
+func TestPagination_PerPage(t *testing.T) {
+	app := newTestApp(t)
+	seedClients(t, app, 45)
+
+	rec := app.get(t, "/clients?per_page=50")
+	if body := rec.Body.String(); strings.Contains(body, "Page 1 of") || !strings.Contains(body, "Client 045") {
+		t.Errorf("per_page=50 should show every client on one page")
+	}
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "per_page" {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != "50" {
+		t.Fatalf("per_page cookie = %v, want 50", cookie)
+	}
+
+	// The cookie carries the choice to later requests
+	req := httptest.NewRequest(http.MethodGet, "/clients", nil)
+	req.AddCookie(cookie)
+	if body := app.do(req).Body.String(); !strings.Contains(body, "Client 045") {
+		t.Errorf("per_page cookie not applied")
+	}
+
+	// Out-of-range values are clamped to 10..200
+	if body := app.get(t, "/clients?per_page=1").Body.String(); !strings.Contains(body, "Page 1 of 5") {
+		t.Errorf("per_page=1 should be clamped to 10")
+	}
+}
+
+func TestPagination_DefaultFromSettings(t *testing.T) {
+	app := newTestApp(t)
+	seedClients(t, app, 45)
+
+	rec := app.postForm(t, http.MethodPut, "/settings", url.Values{
+		"default_surcharge_mode": {"stacking"},
+		"page_size":              {"15"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("save status = %d, want 303", rec.Code)
+	}
+	if body := app.get(t, "/clients").Body.String(); !strings.Contains(body, "Page 1 of 3") || strings.Contains(body, "Client 016") {
+		t.Errorf("settings page size not applied")
+	}
+
+	rec = app.postForm(t, http.MethodPut, "/settings", url.Values{
+		"default_surcharge_mode": {"stacking"},
+		"page_size":              {"500"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("out of range page size status = %d, want 400", rec.Code)
+	}
+}
+
+func TestPagination_ItemTemplates(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `DELETE FROM item_templates`)
+	for i := 1; i <= 25; i++ {
+		app.exec(t, `INSERT INTO item_templates (type, category, name, default_unit, default_price) VALUES ('material', 'Lumber', ?, 'ea', 1)`, fmt.Sprintf("Board %03d", i))
+	}
+
+	body := app.get(t, "/items?page=2").Body.String()
+	if !strings.Contains(body, "Page 2 of 2") || !strings.Contains(body, "Board 021") || strings.Contains(body, "Board 020") {
+		t.Errorf("item templates not paginated")
+	}
+}
@@ -0,0 +1,137 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// unphased is the site materials group for items with no phase anywhere in
+// their category chain.
+const unphased = "Unphased"
+
+// formPhase reads the phase field of a submitted form. The second result is
+// false when the form has no phase field, so callers can keep the old value.
+func formPhase(r *http.Request) (sql.NullString, bool) {
+	if _, ok := r.Form["phase"]; !ok {
+		return sql.NullString{}, false
+	}
+	return toNullString(r.FormValue("phase")), true
+}
+
+// jobPhases lists the phases already used in a job, for autocomplete,
+// ignoring differences in case and surrounding spaces.
+func (h *Handler) jobPhases(ctx context.Context, jobID string) ([]string, error) {
+	rows, err := h.queries.ListJobPhases(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	phases := make([]string, 0, len(rows))
+	for _, row := range rows {
+		phase := strings.TrimSpace(row.String)
+		if key := strings.ToLower(phase); !seen[key] {
+			seen[key] = true
+			phases = append(phases, phase)
+		}
+	}
+	return phases, nil
+}
+
+// categoryPhases lists the phases used in the job a category belongs to.
+func (h *Handler) categoryPhases(ctx context.Context, categoryID string) ([]string, error) {
+	category, err := h.queries.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return h.jobPhases(ctx, category.JobID)
+}
+
+// itemPhase returns the phase a line item is delivered in: its own, or else
+// the nearest one set on its category chain.
+func itemPhase(item repository.LineItem, categories map[string]repository.Category) string {
+	if item.Phase.Valid {
+		return item.Phase.String
+	}
+	for id := item.CategoryID; id != ""; {
+		cat, ok := categories[id]
+		if !ok {
+			break
+		}
+		if cat.Phase.Valid {
+			return cat.Phase.String
+		}
+		id = cat.ParentID.String
+	}
+	return ""
+}
+
+// phaseReports groups a job's orderable items by phase, summing quantities
+// of the same item across categories. Phases are sorted by name, with
+// untagged items last under "Unphased".
+func phaseReports(categories []repository.Category, lineItems []repository.LineItem) []CategoryReport {
+	byID := make(map[string]repository.Category, len(categories))
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+	}
+
+	// Phases are free text, so "Rough-in" and "rough-in " are one phase,
+	// shown as first written.
+	names := make(map[string]string)
+	items := make(map[string]map[string]*ReportItem)
+	for _, li := range lineItems {
+		if !domain.LineItemType(li.Type).Orderable() {
+			continue
+		}
+		phase := strings.TrimSpace(itemPhase(li, byID))
+		key := strings.ToLower(phase)
+		if _, ok := names[key]; !ok {
+			names[key] = phase
+			items[key] = make(map[string]*ReportItem)
+		}
+		itemKey := li.Name + "|" + li.Unit
+		if existing, ok := items[key][itemKey]; ok {
+			existing.Quantity += li.Quantity
+		} else {
+			items[key][itemKey] = &ReportItem{
+				Name:     li.Name,
+				Quantity: li.Quantity,
+				Unit:     li.Unit,
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return names[keys[i]] < names[keys[j]]
+	})
+	if _, ok := items[""]; ok {
+		keys = append(keys, "")
+	}
+
+	reports := make([]CategoryReport, 0, len(keys))
+	for _, key := range keys {
+		report := CategoryReport{Name: names[key]}
+		if key == "" {
+			report.Name = unphased
+		}
+		for _, item := range items[key] {
+			report.Items = append(report.Items, *item)
+		}
+		sort.Slice(report.Items, func(i, j int) bool {
+			return report.Items[i].Name < report.Items[j].Name
+		})
+		reports = append(reports, report)
+	}
+	return reports
+}
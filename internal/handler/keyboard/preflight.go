@@ -0,0 +1,228 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// preflightLabels are the checklist headings for each pre-send rule.
+var preflightLabels = map[domain.PreflightRule]string{
+	domain.PreflightMissingClient: "Missing client",
+	domain.PreflightMissingExpiry: "Missing expiration date",
+	domain.PreflightEmptyCategory: "Empty categories",
+	domain.PreflightZeroPrice:     "Items with a $0 price",
+	domain.PreflightBelowCost:     "Items priced below cost",
+	domain.PreflightAreaQuantity:  "Area items quoted as 1",
+	domain.PreflightOpenComments:  "Unresolved comments",
+}
+
+// PreflightGroup is one rule's findings on the pre-send checklist.
+type PreflightGroup struct {
+	Rule     domain.PreflightRule
+	Label    string
+	Findings []domain.PreflightFinding
+}
+
+// PreflightResult is the outcome of the pre-send checks for a job.
+type PreflightResult struct {
+	Groups    []PreflightGroup
+	Count     int
+	Dismissed []PreflightGroup // Dismissed rules, without findings
+}
+
+// preflight runs the pre-send checks on a job, skipping the rules dismissed
+// for it.
+func (h *Handler) preflight(ctx context.Context, job repository.Job, categories []repository.Category, lineItems []repository.LineItem) (PreflightResult, error) {
+	rules, err := h.queries.ListPreflightDismissals(ctx, job.ID)
+	if err != nil {
+		return PreflightResult{}, fmt.Errorf("listing preflight dismissals: %w", err)
+	}
+	dismissed := make(map[domain.PreflightRule]bool, len(rules))
+	for _, rule := range rules {
+		dismissed[domain.PreflightRule(rule)] = true
+	}
+
+	prices, err := h.queries.ListTemplatePricesByJob(ctx, job.ID)
+	if err != nil {
+		return PreflightResult{}, fmt.Errorf("listing template prices: %w", err)
+	}
+
+	comments, err := h.openCommentCounts(ctx, job.ID, categories)
+	if err != nil {
+		return PreflightResult{}, fmt.Errorf("counting open comments: %w", err)
+	}
+
+	quote := domain.PreflightQuote{
+		HasClient:  job.ClientID.Valid || job.CustomerName.Valid,
+		HasExpiry:  job.ExpiresAt.Valid,
+		Categories: make([]*domain.Category, len(categories)),
+		LineItems:  make([]*domain.LineItem, len(lineItems)),
+		Costs:      make(map[string]float64, len(prices)),
+		Comments:   comments.Items,
+	}
+	// A rental's price covers the whole rental, so it isn't compared with
+	// the template's price.
+	rentals := make(map[string]bool)
+	for _, item := range lineItems {
+		if item.Unit == rentalUnit {
+			rentals[item.ID] = true
+		}
+	}
+	for _, p := range prices {
+		if !rentals[p.ID] {
+			quote.Costs[p.ID] = p.DefaultPrice
+		}
+	}
+	for i, cat := range categories {
+		var parentID *string
+		if cat.ParentID.Valid {
+			parentID = &cat.ParentID.String
+		}
+		quote.Categories[i] = &domain.Category{
+			ID:       cat.ID,
+			JobID:    cat.JobID,
+			ParentID: parentID,
+			Name:     cat.Name,
+		}
+	}
+	for i, item := range lineItems {
+		var description *string
+		if item.Description.Valid {
+			description = &item.Description.String
+		}
+		quote.LineItems[i] = &domain.LineItem{
+			ID:          item.ID,
+			CategoryID:  item.CategoryID,
+			Type:        domain.LineItemType(item.Type),
+			Name:        item.Name,
+			Description: description,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			UnitPrice:   item.UnitPrice,
+			IsCredit:    item.IsCredit,
+		}
+	}
+
+	findings := domain.Preflight(quote, dismissed)
+	result := PreflightResult{Count: len(findings)}
+	for _, rule := range domain.PreflightRules {
+		if dismissed[rule] {
+			result.Dismissed = append(result.Dismissed, PreflightGroup{Rule: rule, Label: preflightLabels[rule]})
+			continue
+		}
+		group := PreflightGroup{Rule: rule, Label: preflightLabels[rule]}
+		for _, f := range findings {
+			if f.Rule == rule {
+				group.Findings = append(group.Findings, f)
+			}
+		}
+		if len(group.Findings) > 0 {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	return result, nil
+}
+
+// GetJobPreflight renders the pre-send checklist for a job.
+func (h *Handler) GetJobPreflight(w http.ResponseWriter, r *http.Request) {
+	h.renderPreflight(w, r, r.PathValue("id"))
+}
+
+// DismissJobPreflightRule stops a rule from being checked on a job.
+func (h *Handler) DismissJobPreflightRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	rule := domain.PreflightRule(r.PathValue("rule"))
+	if !rule.Valid() {
+		http.Error(w, "Unknown check", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.queries.DismissPreflightRule(ctx, repository.DismissPreflightRuleParams{
+		JobID: jobID,
+		Rule:  string(rule),
+	}); err != nil {
+		logger.Error("failed to dismiss preflight rule", "error", err)
+		http.Error(w, "Failed to dismiss check", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderPreflight(w, r, jobID)
+}
+
+// RestoreJobPreflightRule turns a dismissed rule back on for a job.
+func (h *Handler) RestoreJobPreflightRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	if err := h.queries.RestorePreflightRule(ctx, repository.RestorePreflightRuleParams{
+		JobID: jobID,
+		Rule:  r.PathValue("rule"),
+	}); err != nil {
+		logger.Error("failed to restore preflight rule", "error", err)
+		http.Error(w, "Failed to restore check", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderPreflight(w, r, jobID)
+}
+
+// renderPreflight runs the checks on a job and writes the checklist partial.
+func (h *Handler) renderPreflight(w http.ResponseWriter, r *http.Request, jobID string) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := h.preflight(ctx, job, categories, lineItems)
+	if err != nil {
+		logger.Error("failed to run preflight checks", "error", err)
+		http.Error(w, "Failed to check quote", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "preflight_checklist", map[string]interface{}{
+		"Job":       job,
+		"Preflight": result,
+	}); err != nil {
+		logger.Error("failed to render preflight checklist", "error", err)
+		http.Error(w, "Failed to render checklist", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,97 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func seedPreflightJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Bathroom')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES
+		('cat-tile', 'job-1', 'Tile'),
+		('cat-paint', 'job-1', 'Paint')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-floor', 'cat-tile', 'material', 'Floor tile', 1, 'sqft', 8),
+		('item-grout', 'cat-tile', 'material', 'Grout', 2, 'bag', 0)`)
+}
+
+func TestGetJobPreflight(t *testing.T) {
+	app := newTestApp(t)
+	seedPreflightJob(t, app)
+
+	rec := app.get(t, "/jobs/job-1/preflight")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"5 warnings",
+		"No client is assigned",
+		"The quote has no expiration date",
+		"Paint has no items",
+		"Grout has a $0 price",
+		"Floor tile is quoted as 1 sqft",
+		`href="/categories/cat-paint"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("checklist missing %q", want)
+		}
+	}
+
+	if body := app.get(t, "/jobs/job-1").Body.String(); !strings.Contains(body, "5 warnings") {
+		t.Errorf("job page missing the warnings indicator")
+	}
+}
+
+func TestDismissJobPreflightRule(t *testing.T) {
+	app := newTestApp(t)
+	seedPreflightJob(t, app)
+
+	rec := app.do(httptest.NewRequest(http.MethodPost, "/jobs/job-1/preflight/zero_price/dismiss", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "Grout has a $0 price") {
+		t.Errorf("dismissed rule still reported")
+	}
+	if !strings.Contains(body, "4 warnings") || !strings.Contains(body, `hx-delete="/jobs/job-1/preflight/zero_price/dismiss"`) {
+		t.Errorf("expected the dismissed rule listed for restoring: %s", body)
+	}
+
+	// Dismissals are stored per job.
+	if body := app.get(t, "/jobs/job-1/preflight").Body.String(); strings.Contains(body, "Grout has a $0 price") {
+		t.Errorf("dismissal not remembered")
+	}
+
+	rec = app.do(httptest.NewRequest(http.MethodDelete, "/jobs/job-1/preflight/zero_price/dismiss", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "Grout has a $0 price") {
+		t.Errorf("restored rule not reported")
+	}
+
+	rec = app.do(httptest.NewRequest(http.MethodPost, "/jobs/job-1/preflight/bogus/dismiss", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown rule status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGetJobPreflight_BelowCost(t *testing.T) {
+	app := newTestApp(t)
+	seedPreflightJob(t, app)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Paint', 'Primer', 'gal', 32)`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, template_id) VALUES
+		('item-primer', 'cat-paint', 'material', 'Primer', 2, 'gal', 28, 9001)`)
+
+	body := app.get(t, "/jobs/job-1/preflight").Body.String()
+	if !strings.Contains(body, "Items priced below cost") || !strings.Contains(body, "Primer is priced at $28.00, below its $32.00 cost") {
+		t.Errorf("below-cost item not reported: %s", body)
+	}
+	// The paint category now has an item, which offsets the new warning.
+	if !strings.Contains(body, "5 warnings") {
+		t.Errorf("warning count should include the below-cost item")
+	}
+}
@@ -0,0 +1,245 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// PriceAdjustment is a single line item's price change within a bulk adjustment.
+type PriceAdjustment struct {
+	Item     repository.LineItem
+	NewPrice float64
+}
+
+// adjustPrice applies a percentage change to a unit price, rounded to cents.
+func adjustPrice(price, percent float64) float64 {
+	return math.Round(price*(1+percent/100)*100) / 100
+}
+
+// descendantCategoryIDs returns the IDs of rootID and every category nested beneath it.
+func descendantCategoryIDs(categories []repository.Category, rootID string) map[string]bool {
+	ids := map[string]bool{rootID: true}
+	for changed := true; changed; {
+		changed = false
+		for _, cat := range categories {
+			if cat.ParentID.Valid && ids[cat.ParentID.String] && !ids[cat.ID] {
+				ids[cat.ID] = true
+				changed = true
+			}
+		}
+	}
+	return ids
+}
+
+// AdjustJobPrices previews or applies a percentage change to every line item price in a job.
+func (h *Handler) AdjustJobPrices(w http.ResponseWriter, r *http.Request) {
+	h.adjustPrices(w, r, r.PathValue("id"), nil)
+}
+
+// AdjustCategoryPrices previews or applies a percentage change to every line item
+// price in a category and its subcategories.
+func (h *Handler) AdjustCategoryPrices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	category, err := h.queries.GetCategory(ctx, r.PathValue("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	h.adjustPrices(w, r, category.JobID, &category)
+}
+
+// adjustPrices renders a preview of the adjustment, or applies it when the
+// form includes apply=true. A nil category scopes the adjustment to the whole job.
+func (h *Handler) adjustPrices(w http.ResponseWriter, r *http.Request, jobID string, category *repository.Category) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	percent, err := parsePercent(r.FormValue("percent"), h.decimalSeparator())
+	if err != nil || percent == 0 || percent <= -100 {
+		http.Error(w, "Percent must be a non-zero number greater than -100", http.StatusBadRequest)
+		return
+	}
+
+	itemType := r.FormValue("type")
+	if itemType != "" && !domain.LineItemType(itemType).Valid() {
+		http.Error(w, "Invalid item type", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	var scope map[string]bool
+	if category != nil {
+		scope = descendantCategoryIDs(categories, category.ID)
+	}
+
+	// Build the adjustments alongside a projected copy of the job's items so the
+	// preview totals come from the same calculation the job page uses.
+	var adjustments []PriceAdjustment
+	projected := make([]repository.LineItem, len(lineItems))
+	for i, item := range lineItems {
+		projected[i] = item
+		if scope != nil && !scope[item.CategoryID] {
+			continue
+		}
+		if itemType != "" && item.Type != itemType {
+			continue
+		}
+		// Credits are fixed deductions, not prices that track the market.
+		if item.IsCredit {
+			continue
+		}
+		newPrice := adjustPrice(item.UnitPrice, percent)
+		adjustments = append(adjustments, PriceAdjustment{Item: item, NewPrice: newPrice})
+		projected[i].UnitPrice = newPrice
+	}
+
+	redirectURL := "/jobs/" + jobID
+	action := "/jobs/" + jobID + "/adjust-prices"
+	scopeName := job.Name
+	if category != nil {
+		redirectURL = "/categories/" + category.ID
+		action = "/categories/" + category.ID + "/adjust-prices"
+		scopeName = category.Name
+	}
+
+	if r.FormValue("apply") == "true" {
+		if len(adjustments) == 0 {
+			http.Error(w, "No items to adjust", http.StatusBadRequest)
+			return
+		}
+
+		detail := fmt.Sprintf("Adjusted %d %sprices in %s by %+.2f%%", len(adjustments), typePrefix(itemType), scopeName, percent)
+		if err := h.applyPriceAdjustments(ctx, jobID, adjustments, detail, activityActor(r)); err != nil {
+			logger.Error("failed to apply price adjustment", "error", err)
+			http.Error(w, "Failed to adjust prices", http.StatusInternalServerError)
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", redirectURL)
+			return
+		}
+
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Job":               job,
+		"Category":          category,
+		"ScopeName":         scopeName,
+		"Action":            action,
+		"BackURL":           redirectURL,
+		"Percent":           percent,
+		"Type":              itemType,
+		"Adjustments":       adjustments,
+		"CurrentTotals":     h.calculateTotals(job, settings, categories, lineItems),
+		"ProjectedTotals":   h.calculateTotals(job, settings, categories, projected),
+		"CurrentCategoryID": "",
+	}
+	if category != nil {
+		data["CurrentCategoryTotal"] = h.calculateCategoryTotal(category.ID, job, settings, categories, lineItems)
+		data["ProjectedCategoryTotal"] = h.calculateCategoryTotal(category.ID, job, settings, categories, projected)
+	}
+
+	if err := h.renderer.Render(w, "adjust_prices", data); err != nil {
+		logger.Error("failed to render price adjustment preview", "error", err)
+	}
+}
+
+// applyPriceAdjustments writes the new prices and an activity entry by actor in
+// one transaction.
+func (h *Handler) applyPriceAdjustments(ctx context.Context, jobID string, adjustments []PriceAdjustment, detail, actor string) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+
+	for _, adj := range adjustments {
+		if err := qtx.UpdateLineItemPrice(ctx, repository.UpdateLineItemPriceParams{
+			UnitPrice: adj.NewPrice,
+			ID:        adj.Item.ID,
+		}); err != nil {
+			return fmt.Errorf("updating price for %s: %w", adj.Item.ID, err)
+		}
+	}
+
+	// An earlier single-item edit can't be undone on its own once a bulk
+	// change has touched the job.
+	if err := qtx.DismissOpenTotalChangeAlerts(ctx, jobID); err != nil {
+		return fmt.Errorf("dismissing total change alerts: %w", err)
+	}
+
+	if _, err := qtx.CreateJobActivity(ctx, repository.CreateJobActivityParams{
+		JobID:  jobID,
+		Action: "price_adjustment",
+		Detail: detail,
+		Actor:  actor,
+	}); err != nil {
+		return fmt.Errorf("recording activity: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// typePrefix returns "material " style wording for an optional item type filter.
+func typePrefix(itemType string) string {
+	if itemType == "" {
+		return ""
+	}
+	return itemType + " "
+}
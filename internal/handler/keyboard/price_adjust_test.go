@@ -0,0 +1,118 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var grandTotalPattern = regexp.MustCompile(`id="projected-grand-total"[^>]*>([^<]+)<`)
+
+func seedAdjustableJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage', 12.5)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-framing', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name, surcharge_percent) VALUES ('cat-walls', 'job-1', 'cat-framing', 'Walls', 3)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-roof', 'job-1', 'Roofing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-1', 'cat-framing', 'material', '2x4x8', 37, 'ea', 3.27),
+		('item-2', 'cat-walls', 'material', '2x4x104 5/8"', 13, 'ea', 4.62),
+		('item-3', 'cat-framing', 'labor', 'Framer', 16.5, 'hr', 47.15),
+		('item-4', 'cat-roof', 'material', 'Shingles', 22, 'bundle', 36.99)`)
+}
+
+func TestAdjustCategoryPrices_PreviewMatchesApply(t *testing.T) {
+	app := newTestApp(t)
+	seedAdjustableJob(t, app)
+
+	form := url.Values{"percent": {"8"}, "type": {"material"}}
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-framing/adjust-prices", form)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview status = %d, want 200", rec.Code)
+	}
+	m := grandTotalPattern.FindStringSubmatch(rec.Body.String())
+	if m == nil {
+		t.Fatalf("preview has no projected grand total")
+	}
+	projected := m[1]
+
+	form.Set("apply", "true")
+	rec = app.postForm(t, http.MethodPost, "/categories/cat-framing/adjust-prices", form)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("apply status = %d, want 303", rec.Code)
+	}
+
+	prices := map[string]float64{}
+	rows, err := app.db.Query(`SELECT id, unit_price FROM line_items`)
+	if err != nil {
+		t.Fatalf("query prices: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var price float64
+		if err := rows.Scan(&id, &price); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		prices[id] = price
+	}
+
+	want := map[string]float64{
+		"item-1": 3.53,  // 3.27 * 1.08 = 3.5316
+		"item-2": 4.99,  // 4.62 * 1.08 = 4.9896
+		"item-3": 47.15, // labor excluded by the type filter
+		"item-4": 36.99, // outside the category
+	}
+	for id, price := range want {
+		if prices[id] != price {
+			t.Errorf("%s unit_price = %v, want %v", id, prices[id], price)
+		}
+	}
+
+	body := app.get(t, "/jobs/job-1").Body.String()
+	if !strings.Contains(body, projected) {
+		t.Errorf("job page does not show the previewed grand total %s", projected)
+	}
+	if !strings.Contains(body, "Adjusted 2 material prices in Framing") {
+		t.Errorf("job page missing price adjustment activity entry")
+	}
+}
+
+func TestAdjustJobPrices_RejectsInvalidPercent(t *testing.T) {
+	app := newTestApp(t)
+	seedAdjustableJob(t, app)
+
+	for _, percent := range []string{"", "0", "-100", "abc"} {
+		rec := app.postForm(t, http.MethodPost, "/jobs/job-1/adjust-prices", url.Values{"percent": {percent}})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("percent %q: status = %d, want 400", percent, rec.Code)
+		}
+	}
+}
+
+func TestAdjustCategoryPrices_RecordsActor(t *testing.T) {
+	app := newTestApp(t)
+	seedAdjustableJob(t, app)
+	form := url.Values{"percent": {"8"}, "apply": {"true"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/categories/cat-framing/adjust-prices", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "comment_author", Value: "Dana"})
+	if rec := app.do(req); rec.Code != http.StatusSeeOther {
+		t.Fatalf("apply status = %d, want 303", rec.Code)
+	}
+	app.postForm(t, http.MethodPost, "/categories/cat-roof/adjust-prices", form)
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_activity WHERE actor = 'Dana' AND detail LIKE '%Framing%'`); n != 1 {
+		t.Errorf("adjustment not attributed to the remembered name")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_activity WHERE actor = 'system' AND detail LIKE '%Roofing%'`); n != 1 {
+		t.Errorf("adjustment without a name not attributed to the system")
+	}
+	if body := app.get(t, "/jobs/job-1").Body.String(); !strings.Contains(body, "Dana &middot; ") || strings.Contains(body, "system &middot;") {
+		t.Errorf("job page activity doesn't name who made each change")
+	}
+}
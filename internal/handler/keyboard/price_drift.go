@@ -0,0 +1,207 @@
+package keyboard
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// priceDriftTolerance is the percentage difference from the template price
+// below which an item is not flagged as out of date.
+const priceDriftTolerance = 0.5
+
+// CategoryItem is a line item on the category page along with how far its
+// price has drifted from the template it was created from.
+type CategoryItem struct {
+	repository.ListLineItemsByCategoryWithTemplatePriceRow
+	DriftPercent float64 // template price relative to the item price; positive when the template went up
+	OutOfDate    bool
+	BelowCost    bool    // priced under the template price, before markup
+	CostPrice    float64 // template price plus the cost margin, for raising a below-cost item
+}
+
+// newCategoryItems compares each item's price to its template's current price,
+// flagging items priced below it. costMargin is the percentage over the
+// template price offered as the fix. Rentals are priced for the whole rental
+// from the template's rate tiers, so they aren't compared.
+func newCategoryItems(rows []repository.ListLineItemsByCategoryWithTemplatePriceRow, costMargin float64) []CategoryItem {
+	items := make([]CategoryItem, len(rows))
+	for i, row := range rows {
+		items[i] = CategoryItem{ListLineItemsByCategoryWithTemplatePriceRow: row}
+		if !row.TemplatePrice.Valid || row.Unit == rentalUnit {
+			continue
+		}
+		// Credits store the template price negated.
+		items[i].DriftPercent, items[i].OutOfDate = priceDrift(math.Abs(row.UnitPrice), row.TemplatePrice.Float64)
+		if !row.IsCredit && domain.BelowCost(row.UnitPrice, row.TemplatePrice.Float64) {
+			items[i].BelowCost = true
+			items[i].CostPrice = adjustPrice(row.TemplatePrice.Float64, costMargin)
+		}
+	}
+	return items
+}
+
+// priceDrift returns the percentage change from price to templatePrice and
+// whether it exceeds priceDriftTolerance. A zero price that now has a
+// template price counts as a 100% increase.
+func priceDrift(price, templatePrice float64) (float64, bool) {
+	if price == 0 {
+		if math.Abs(templatePrice) < 0.005 {
+			return 0, false
+		}
+		return 100, true
+	}
+	percent := (templatePrice - price) / math.Abs(price) * 100
+	return percent, math.Abs(percent) >= priceDriftTolerance
+}
+
+// RefreshLineItemPrice sets a line item's unit price to the current default
+// price of the template it was created from.
+func (h *Handler) RefreshLineItemPrice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	itemID := r.PathValue("id")
+
+	item, err := h.queries.GetLineItem(ctx, itemID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Line item not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get line item", "error", err)
+		http.Error(w, "Failed to load line item", http.StatusInternalServerError)
+		return
+	}
+
+	if !item.TemplateID.Valid {
+		http.Error(w, "Line item was not created from a template", http.StatusBadRequest)
+		return
+	}
+
+	if item.Unit == rentalUnit {
+		http.Error(w, "Rental items are priced from the template's rental rates", http.StatusConflict)
+		return
+	}
+
+	template, err := h.queries.GetItemTemplate(ctx, item.TemplateID.Int64)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get item template", "error", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+
+	price := template.DefaultPrice
+	if item.IsCredit {
+		price = -price
+	}
+
+	watch, err := h.watchTotal(ctx, item.CategoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
+	if err := h.queries.UpdateLineItemPrice(ctx, repository.UpdateLineItemPriceParams{
+		UnitPrice: price,
+		ID:        item.ID,
+	}); err != nil {
+		logger.Error("failed to update line item price", "error", err)
+		http.Error(w, "Failed to update price", http.StatusInternalServerError)
+		return
+	}
+	if err := h.checkTotal(ctx, watch, totalAlertUpdate, item.ID, &item); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
+
+	// Refresh rather than redirect so the out-of-date filter stays applied.
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+
+	http.Redirect(w, r, "/categories/"+item.CategoryID, http.StatusSeeOther)
+}
+
+// RaiseLineItemToCost raises a line item priced below its template's price to
+// that price plus the cost margin from settings.
+func (h *Handler) RaiseLineItemToCost(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	itemID := r.PathValue("id")
+
+	item, err := h.queries.GetLineItem(ctx, itemID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Line item not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get line item", "error", err)
+		http.Error(w, "Failed to load line item", http.StatusInternalServerError)
+		return
+	}
+
+	if !item.TemplateID.Valid {
+		http.Error(w, "Line item was not created from a template", http.StatusBadRequest)
+		return
+	}
+
+	if item.Unit == rentalUnit {
+		http.Error(w, "Rental items are priced from the template's rental rates", http.StatusConflict)
+		return
+	}
+
+	template, err := h.queries.GetItemTemplate(ctx, item.TemplateID.Int64)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get item template", "error", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+
+	if item.IsCredit || !domain.BelowCost(item.UnitPrice, template.DefaultPrice) {
+		http.Error(w, "Line item is not priced below cost", http.StatusConflict)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	watch, err := h.watchTotal(ctx, item.CategoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
+	if err := h.queries.UpdateLineItemPrice(ctx, repository.UpdateLineItemPriceParams{
+		UnitPrice: adjustPrice(template.DefaultPrice, settings.CostMarginPercent),
+		ID:        item.ID,
+	}); err != nil {
+		logger.Error("failed to update line item price", "error", err)
+		http.Error(w, "Failed to update price", http.StatusInternalServerError)
+		return
+	}
+	if err := h.checkTotal(ctx, watch, totalAlertUpdate, item.ID, &item); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
+
+	// Refresh rather than redirect so the page's filters stay applied.
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+
+	http.Redirect(w, r, "/categories/"+item.CategoryID, http.StatusSeeOther)
+}
@@ -0,0 +1,74 @@
+package keyboard
+
+import (
+	"context"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// Sources of an item template price change, as stored in
+// item_template_price_history.
+const (
+	priceSourceManual         = "manual"
+	priceSourcePriceImport    = "price_import"
+	priceSourceTemplateImport = "template_import"
+)
+
+// priceChange describes where a template price change came from.
+type priceChange struct {
+	Source         string
+	ImportID       string
+	ImportFilename string
+}
+
+// Every query that writes item_templates.default_price on an existing
+// template must only be called from this file, so that no price change skips
+// the history. TestPriceUpdatesRecordHistory enforces this.
+
+// updateTemplate saves a template and records its price change, if any.
+func updateTemplate(ctx context.Context, q *repository.Queries, arg repository.UpdateItemTemplateParams, oldPrice float64, change priceChange) (repository.ItemTemplate, error) {
+	template, err := q.UpdateItemTemplate(ctx, arg)
+	if err != nil {
+		return template, err
+	}
+	return template, recordPriceChange(ctx, q, arg.ID, oldPrice, arg.DefaultPrice, change)
+}
+
+// setTemplatePrice changes a template's price, and its name when name is not
+// empty, and records the price change.
+func setTemplatePrice(ctx context.Context, q *repository.Queries, id int64, oldPrice, newPrice float64, name string, change priceChange) error {
+	var err error
+	if name != "" {
+		err = q.UpdateItemTemplatePriceAndName(ctx, repository.UpdateItemTemplatePriceAndNameParams{
+			ID:           id,
+			DefaultPrice: newPrice,
+			Name:         name,
+		})
+	} else {
+		err = q.UpdateItemTemplatePrice(ctx, repository.UpdateItemTemplatePriceParams{
+			ID:           id,
+			DefaultPrice: newPrice,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return recordPriceChange(ctx, q, id, oldPrice, newPrice, change)
+}
+
+// recordPriceChange writes a history entry unless the price is unchanged.
+func recordPriceChange(ctx context.Context, q *repository.Queries, id int64, oldPrice, newPrice float64, change priceChange) error {
+	if oldPrice == newPrice {
+		return nil
+	}
+	return q.CreateItemTemplatePriceHistory(ctx, repository.CreateItemTemplatePriceHistoryParams{
+		TemplateID:     id,
+		OldPrice:       oldPrice,
+		NewPrice:       newPrice,
+		Source:         change.Source,
+		ImportID:       toNullString(change.ImportID),
+		ImportFilename: toNullString(change.ImportFilename),
+		RequestID:      toNullString(middleware.RequestIDFromContext(ctx)),
+	})
+}
@@ -0,0 +1,139 @@
+package keyboard_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+// priceWriteQuery matches generated queries that change the price of an
+// existing item template.
+var priceWriteQuery = regexp.MustCompile("(?s)-- name: (\\w+) :\\w+\\nUPDATE item_templates[^`]*default_price")
+
+// generatedFile matches the header Go tools put on generated files.
+var generatedFile = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// TestPriceUpdatesRecordHistory fails when a query that changes a template's
+// price is called anywhere but price_history.go, which records the history.
+func TestPriceUpdatesRecordHistory(t *testing.T) {
+	generated, err := filepath.Glob("../../repository/*.sql.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []string
+	for _, path := range generated {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range priceWriteQuery.FindAllStringSubmatch(string(src), -1) {
+			queries = append(queries, m[1])
+		}
+	}
+	if len(queries) < 3 {
+		t.Fatalf("found price update queries %v, expected at least 3", queries)
+	}
+
+	// Walk every hand-written Go file in the module, so services and
+	// commands are held to the rule as well as handlers.
+	for _, root := range []string{"../..", "../../../cmd"} {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			if filepath.ToSlash(path) == "../../handler/keyboard/price_history.go" {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if generatedFile.Match(src) {
+				return nil
+			}
+			for _, query := range queries {
+				if strings.Contains(string(src), "."+query+"(") {
+					t.Errorf("%s calls %s directly; use updateTemplate or setTemplatePrice so the change is recorded", path, query)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUpdateItemTemplate_RecordsPriceHistory(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', '2x4x8', 'ea', 4.10)`)
+
+	form := url.Values{
+		"type":          {"material"},
+		"category":      {"Lumber"},
+		"name":          {"2x4x8"},
+		"default_unit":  {"ea"},
+		"default_price": {"4.35"},
+	}
+	req := httptest.NewRequest(http.MethodPut, "/item-templates/9001", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(middleware.RequestIDHeader, "req-abc123")
+	rec := httptest.NewRecorder()
+	middleware.RequestID(app.mux).ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_template_price_history
+		WHERE template_id = 9001 AND old_price = 4.10 AND new_price = 4.35 AND source = 'manual' AND request_id = 'req-abc123'`); n != 1 {
+		t.Fatalf("manual price change not recorded")
+	}
+
+	// Saving without a price change adds no history.
+	app.postForm(t, http.MethodPut, "/item-templates/9001", form)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_template_price_history`); n != 1 {
+		t.Errorf("history entries = %d, want 1", n)
+	}
+
+	body := app.get(t, "/item-templates/9001/edit").Body.String()
+	for _, want := range []string{"data-price-provenance", "manually", "(was $4.10)", "req-abc123"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("edit form missing %q", want)
+		}
+	}
+}
+
+func TestApplyPriceUpdates_RecordsPriceHistory(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', '2x4x8', 'ea', 4.10)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES ('imp-nov', 'acme-nov.xlsx', 'ready')`)
+	app.exec(t, `INSERT INTO price_import_matches (import_id, row_number, source_name, source_price, matched_template_id, status) VALUES
+		('imp-nov', 1, '2X4 8FT', 4.45, 9001, 'approved')`)
+
+	if rec := app.postForm(t, http.MethodPost, "/price-import/imp-nov/apply", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_template_price_history
+		WHERE template_id = 9001 AND old_price = 4.10 AND new_price = 4.45 AND source = 'price_import'
+		AND import_id = 'imp-nov' AND import_filename = 'acme-nov.xlsx'`); n != 1 {
+		t.Fatalf("import price change not recorded")
+	}
+
+	body := app.get(t, "/item-templates/9001/edit").Body.String()
+	for _, want := range []string{`href="/price-import/imp-nov/review"`, "acme-nov.xlsx", "(was $4.10)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("edit form missing %q", want)
+		}
+	}
+}
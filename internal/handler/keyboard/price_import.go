@@ -5,21 +5,27 @@ import (
 	"context"
 	"crypto/subtle"
 	"database/sql"
-	"io"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
 	"github.com/dukerupert/skalkaho/internal/service/excel"
+	"github.com/dukerupert/skalkaho/internal/upload"
 	"github.com/google/uuid"
 )
 
 const priceImportCookieName = "price_import_auth"
 
+// maxImportFiles caps how many workbooks one upload can hold.
+const maxImportFiles = 10
+
 // checkPriceImportAuth checks if the user has valid authentication for price import.
 func (h *Handler) checkPriceImportAuth(r *http.Request) bool {
 	// If no token is configured, allow access (for development)
@@ -38,6 +44,12 @@ func (h *Handler) checkPriceImportAuth(r *http.Request) bool {
 
 // GetPriceImportPage renders the price import upload page.
 func (h *Handler) GetPriceImportPage(w http.ResponseWriter, r *http.Request) {
+	h.renderPriceImportPage(w, r, "")
+}
+
+// renderPriceImportPage renders the upload page, with an optional error shown
+// above the upload form.
+func (h *Handler) renderPriceImportPage(w http.ResponseWriter, r *http.Request, uploadError string) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
 
@@ -49,9 +61,15 @@ func (h *Handler) GetPriceImportPage(w http.ResponseWriter, r *http.Request) {
 	hasAPI := h.matcher != nil
 
 	// Get list of imports
+	totalImports, err := h.queries.CountPriceImports(ctx)
+	if err != nil {
+		logger.Error("failed to count imports", "error", err)
+	}
+	pagination := newPagination(r, totalImports, h.pageSize(w, r))
+
 	imports, err := h.queries.ListPriceImports(ctx, repository.ListPriceImportsParams{
-		Limit:  20,
-		Offset: 0,
+		Limit:  int64(pagination.PerPage),
+		Offset: int64(pagination.Offset),
 	})
 	if err != nil {
 		logger.Error("failed to list imports", "error", err)
@@ -67,6 +85,24 @@ func (h *Handler) GetPriceImportPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Summarise the batches on this page so their files can be grouped
+	var batchIDs []string
+	for _, imp := range imports {
+		if imp.BatchID.Valid {
+			batchIDs = append(batchIDs, imp.BatchID.String)
+		}
+	}
+	batches := make(map[string]repository.CountBatchImportsRow)
+	if len(batchIDs) > 0 {
+		rows, err := h.queries.CountBatchImports(ctx, batchIDs)
+		if err != nil {
+			logger.Error("failed to count batch imports", "error", err)
+		}
+		for _, row := range rows {
+			batches[row.ID] = row
+		}
+	}
+
 	// Check for success message
 	successCount := r.URL.Query().Get("success")
 
@@ -75,8 +111,19 @@ func (h *Handler) GetPriceImportPage(w http.ResponseWriter, r *http.Request) {
 		"RequiresToken":   requiresToken,
 		"IsAuthenticated": isAuthenticated,
 		"Imports":         imports,
+		"Batches":         batches,
+		"Pagination":      pagination,
 		"HasProcessing":   hasProcessing,
 		"SuccessCount":    successCount,
+		"UploadError":     uploadError,
+		"ImportReminder":  h.importReminder(ctx),
+		"AdminTokenSet":   h.config.AdminToken != "",
+	}
+
+	if budget, err := h.aiBudget(ctx, time.Now()); err != nil {
+		logger.Error("failed to check import budget", "error", err)
+	} else if budget.Warn {
+		data["AIBudget"] = budget
 	}
 
 	if err := h.renderer.Render(w, "price_import", data); err != nil {
@@ -151,55 +198,82 @@ func (h *Handler) UploadPriceFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse multipart form (10MB max)
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		logger.Error("failed to parse multipart form", "error", err)
-		http.Error(w, "File too large (max 10MB)", http.StatusBadRequest)
-		return
+	// Read the whole files into memory so we can process in background
+	parts, err := upload.FilesFromRequest(w, r, "file", maxImportFiles, upload.XLSX)
+	if err == nil && len(parts) == 1 && parts[0].Err != nil {
+		// A lone bad file is shown on the form rather than as a failed import
+		err = parts[0].Err
 	}
-
-	file, header, err := r.FormFile("file")
 	if err != nil {
-		logger.Error("no file uploaded", "error", err)
-		http.Error(w, "No file uploaded", http.StatusBadRequest)
+		var uploadErr *upload.Error
+		if errors.As(err, &uploadErr) {
+			logger.Warn("rejected price import upload", "code", uploadErr.Code, "error", err)
+			h.renderPriceImportPage(w, r, uploadErr.Message)
+			return
+		}
+		logger.Error("failed to read file", "error", err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
 		return
 	}
-	defer file.Close()
 
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	if ext != ".xlsx" && ext != ".xls" {
-		http.Error(w, "Invalid file type. Please upload .xlsx or .xls file", http.StatusBadRequest)
+	// Stop before any import record is created once the month's API budget
+	// is used up
+	allowed, budget, err := h.importAllowed(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to check import budget", "error", err)
+		http.Error(w, "Failed to check import budget", http.StatusInternalServerError)
 		return
 	}
-
-	// Read file into memory so we can process in background
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		logger.Error("failed to read file", "error", err)
-		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+	if !allowed {
+		logger.Warn("price import blocked by budget", "used", budget.Used, "limit", budget.Limit, "unit", budget.Unit)
+		h.renderPriceImportPage(w, r, budgetBlockedMessage(budget))
 		return
 	}
-	filename := header.Filename
+	if budget.Exhausted {
+		logger.Info("price import allowed over budget by override", "used", budget.Used, "limit", budget.Limit, "unit", budget.Unit)
+	}
 
-	// Create import record immediately with "processing" status
-	importID := uuid.New().String()
-	_, err = h.queries.CreatePriceImport(ctx, repository.CreatePriceImportParams{
-		ID:        importID,
-		Filename:  filename,
-		Status:    "processing",
-		TotalRows: 0, // Will be updated after processing
-	})
-	if err != nil {
-		logger.Error("failed to create import record", "error", err)
-		http.Error(w, "Failed to create import", http.StatusInternalServerError)
-		return
+	// Files uploaded together share a batch so they can be reviewed together
+	var batchID sql.NullString
+	if len(parts) > 1 {
+		batch, err := h.queries.CreatePriceImportBatch(ctx, uuid.New().String())
+		if err != nil {
+			logger.Error("failed to create import batch", "error", err)
+			http.Error(w, "Failed to create import", http.StatusInternalServerError)
+			return
+		}
+		batchID = sql.NullString{String: batch.ID, Valid: true}
+	}
+
+	// Create import records immediately with "processing" status. Files that
+	// failed validation are recorded as failed so the rest of the batch goes on.
+	files := make([]importFile, 0, len(parts))
+	for _, part := range parts {
+		importID := uuid.New().String()
+		_, err = h.queries.CreatePriceImport(ctx, repository.CreatePriceImportParams{
+			ID:        importID,
+			Filename:  part.Filename,
+			Status:    "processing",
+			TotalRows: 0, // Will be updated after processing
+			BatchID:   batchID,
+		})
+		if err != nil {
+			logger.Error("failed to create import record", "error", err)
+			http.Error(w, "Failed to create import", http.StatusInternalServerError)
+			return
+		}
+		if part.Err != nil {
+			logger.Warn("rejected file in price import batch", "import_id", importID, "error", part.Err)
+			h.updateImportError(ctx, importID, part.Err.Error())
+			continue
+		}
+		files = append(files, importFile{ID: importID, Filename: part.File.Name, Data: part.File.Data})
 	}
 
-	logger.Info("starting background price import processing", "import_id", importID, "filename", filename)
+	logger.Info("starting background price import processing", "files", len(files), "batch_id", batchID.String)
 
 	// Process in background goroutine
-	go h.processImportInBackground(importID, filename, fileBytes, logger)
+	go h.processImportsInBackground(files, logger)
 
 	// Return immediately to the imports list page
 	if r.Header.Get("HX-Request") == "true" {
@@ -209,6 +283,21 @@ func (h *Handler) UploadPriceFile(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/price-import", http.StatusSeeOther)
 }
 
+// importFile is an uploaded workbook waiting to be processed.
+type importFile struct {
+	ID       string
+	Filename string
+	Data     []byte
+}
+
+// processImportsInBackground processes uploaded files one after another, so
+// a batch makes one API call at a time. Each file succeeds or fails on its own.
+func (h *Handler) processImportsInBackground(files []importFile, logger *slog.Logger) {
+	for _, f := range files {
+		h.processImportInBackground(f.ID, f.Filename, f.Data, logger)
+	}
+}
+
 // processImportInBackground handles the Claude API call and match storage.
 func (h *Handler) processImportInBackground(importID, filename string, fileBytes []byte, logger *slog.Logger) {
 	// Use background context since the request context is gone
@@ -231,8 +320,15 @@ func (h *Handler) processImportInBackground(importID, filename string, fileBytes
 		return
 	}
 
+	// Names corrected in earlier reviews are matched the same way again
+	corrections, err := h.matchCorrections(ctx)
+	if err != nil {
+		logger.Error("failed to list match corrections", "error", err, "import_id", importID)
+	}
+
 	// Call Claude API to extract items and match them
-	extractResult, err := h.matcher.ExtractAndMatchItems(ctx, spreadsheet, templates)
+	extractResult, usage, err := h.matcher.ExtractAndMatchItems(ctx, spreadsheet, templates, corrections)
+	h.recordImportUsage(ctx, importID, usage, logger)
 	if err != nil {
 		logger.Error("failed to extract and match items with Claude", "error", err, "import_id", importID)
 		h.updateImportError(ctx, importID, "AI extraction/matching failed: "+err.Error())
@@ -264,6 +360,11 @@ func (h *Handler) processImportInBackground(importID, filename string, fileBytes
 			matchReason = sql.NullString{String: item.Reason, Valid: true}
 		}
 
+		var sourceCategory sql.NullString
+		if item.Category != "" {
+			sourceCategory = sql.NullString{String: item.Category, Valid: true}
+		}
+
 		_, err = h.queries.CreatePriceImportMatch(ctx, repository.CreatePriceImportMatchParams{
 			ImportID:          importID,
 			RowNumber:         int64(item.RowNumber),
@@ -274,6 +375,7 @@ func (h *Handler) processImportInBackground(importID, filename string, fileBytes
 			Confidence:        item.Confidence,
 			MatchReason:       matchReason,
 			Status:            status,
+			SourceCategory:    sourceCategory,
 		})
 		if err != nil {
 			logger.Error("failed to create match", "error", err, "row", item.RowNumber, "import_id", importID)
@@ -366,12 +468,19 @@ func (h *Handler) GetImportReview(w http.ResponseWriter, r *http.Request) {
 	}
 	unmatchedCount := int64(len(unmatched))
 
+	// Templates a row can be re-matched to
+	templates, err := h.queries.ListItemTemplates(ctx)
+	if err != nil {
+		logger.Error("failed to list templates", "error", err)
+	}
+
 	data := map[string]interface{}{
 		"Import":         priceImport,
 		"Matches":        matches,
 		"StatusCounts":   counts,
 		"Threshold":      h.config.AutoApproveThreshold,
 		"UnmatchedCount": unmatchedCount,
+		"Templates":      templates,
 	}
 
 	if err := h.renderer.Render(w, "price_import_review", data); err != nil {
@@ -429,6 +538,11 @@ func (h *Handler) UpdateMatchStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A renamed match is a person confirming which template the name belongs to
+	if newName != "" && status == "approved" && match.MatchedTemplateID.Valid {
+		h.learnCorrection(ctx, match.SourceName, match.MatchedTemplateID.Int64)
+	}
+
 	// Return updated row partial
 	if r.Header.Get("HX-Request") == "true" {
 		var buf bytes.Buffer
@@ -446,7 +560,134 @@ func (h *Handler) UpdateMatchStatus(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/price-import/"+match.ImportID+"/review", http.StatusSeeOther)
 }
 
-// CreateTemplateFromMatch creates a new item template from an unmatched import row.
+// CreateTemplateForm holds the values of the create-template form on an
+// unmatched import row.
+type CreateTemplateForm struct {
+	Match      repository.PriceImportMatch
+	Name       string
+	Unit       string
+	Type       string
+	Price      float64
+	Category   string
+	Categories []string
+	// Duplicate is set when this import already created a template with the
+	// same name; submitting again requires confirmation.
+	Duplicate bool
+}
+
+// GetCreateTemplateForm returns the create-template form for an unmatched
+// import row, prefilled from the row's name, unit, price and category header.
+func (h *Handler) GetCreateTemplateForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	match, err := h.queries.GetPriceImportMatch(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Match not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get match", "error", err)
+		http.Error(w, "Failed to load match", http.StatusInternalServerError)
+		return
+	}
+
+	form := CreateTemplateForm{
+		Match: match,
+		Name:  match.SourceName,
+		Unit:  match.SourceUnit.String,
+		Type:  "material",
+		Price: match.SourcePrice,
+	}
+	if match.NewName.Valid {
+		form.Name = match.NewName.String
+	}
+
+	if err := h.fillCreateTemplateForm(ctx, &form); err != nil {
+		logger.Error("failed to prefill template form", "error", err)
+		http.Error(w, "Failed to load form", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderCreateTemplateForm(w, r, form)
+}
+
+// fillCreateTemplateForm loads the existing template categories, suggests one
+// from the row's category header when the form has none, and flags a name
+// this import already created a template for.
+func (h *Handler) fillCreateTemplateForm(ctx context.Context, form *CreateTemplateForm) error {
+	templates, err := h.queries.ListItemTemplates(ctx)
+	if err != nil {
+		return err
+	}
+
+	categorySet := make(map[string]bool)
+	for _, t := range templates {
+		if t.Category != "" {
+			categorySet[t.Category] = true
+		}
+	}
+	form.Categories = make([]string, 0, len(categorySet))
+	for cat := range categorySet {
+		form.Categories = append(form.Categories, cat)
+	}
+	sort.Strings(form.Categories)
+
+	if form.Category == "" {
+		form.Category = suggestTemplateCategory(form.Match.SourceCategory.String, form.Categories)
+	}
+
+	count, err := h.queries.CountCreatedTemplatesByName(ctx, repository.CountCreatedTemplatesByNameParams{
+		ImportID: form.Match.ImportID,
+		Name:     form.Name,
+	})
+	if err != nil {
+		return err
+	}
+	form.Duplicate = count > 0
+	return nil
+}
+
+// suggestTemplateCategory returns the existing category that matches an
+// import row's category header, ignoring case and a trailing plural "s", or
+// the header itself when no category matches.
+func suggestTemplateCategory(header string, categories []string) string {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ""
+	}
+	key := strings.TrimSuffix(strings.ToLower(header), "s")
+	for _, cat := range categories {
+		if strings.TrimSuffix(strings.ToLower(cat), "s") == key {
+			return cat
+		}
+	}
+	return header
+}
+
+// renderCreateTemplateForm writes the create-template form partial.
+func (h *Handler) renderCreateTemplateForm(w http.ResponseWriter, r *http.Request, form CreateTemplateForm) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "create_template_form", form); err != nil {
+		logger.Error("failed to render create template form", "error", err)
+		http.Error(w, "Failed to render form", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// CreateTemplateFromMatch creates a new item template from an unmatched import
+// row. A name this import already created a template for is sent back with a
+// warning until the duplicate is confirmed.
 func (h *Handler) CreateTemplateFromMatch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := middleware.LoggerFromContext(ctx)
@@ -478,8 +719,8 @@ func (h *Handler) CreateTemplateFromMatch(w http.ResponseWriter, r *http.Request
 	}
 
 	priceStr := r.FormValue("price")
-	price, err := strconv.ParseFloat(priceStr, 64)
-	if err != nil {
+	price, err := parseMoney(priceStr, h.decimalSeparator())
+	if err != nil || strings.TrimSpace(priceStr) == "" {
 		http.Error(w, "Invalid price", http.StatusBadRequest)
 		return
 	}
@@ -489,6 +730,40 @@ func (h *Handler) CreateTemplateFromMatch(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	existing, err := h.queries.GetPriceImportMatch(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Match not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get match", "error", err)
+		http.Error(w, "Failed to load match", http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("confirm_duplicate") != "true" {
+		form := CreateTemplateForm{
+			Match:    existing,
+			Name:     name,
+			Unit:     unit,
+			Type:     itemType,
+			Price:    price,
+			Category: category,
+		}
+		if err := h.fillCreateTemplateForm(ctx, &form); err != nil {
+			logger.Error("failed to check for duplicate template", "error", err)
+			http.Error(w, "Failed to create template", http.StatusInternalServerError)
+			return
+		}
+		if form.Duplicate {
+			// Swap the form, not the row, so the warning shows in place
+			w.Header().Set("HX-Retarget", fmt.Sprintf("#create-template-%d", id))
+			w.Header().Set("HX-Reswap", "innerHTML")
+			h.renderCreateTemplateForm(w, r, form)
+			return
+		}
+	}
+
 	// Create the new template
 	template, err := h.queries.CreateItemTemplate(ctx, repository.CreateItemTemplateParams{
 		Type:         itemType,
@@ -514,6 +789,8 @@ func (h *Handler) CreateTemplateFromMatch(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	h.learnCorrection(ctx, match.SourceName, template.ID)
+
 	logger.Info("created template from import", "template_id", template.ID, "name", name)
 
 	// Return updated row partial
@@ -524,6 +801,8 @@ func (h *Handler) CreateTemplateFromMatch(w http.ResponseWriter, r *http.Request
 			http.Error(w, "Failed to render", http.StatusInternalServerError)
 			return
 		}
+		// The review page moves focus to the next unmatched row
+		w.Header().Set("HX-Trigger-After-Swap", fmt.Sprintf(`{"templateCreated": {"id": %d}}`, match.ID))
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		_, _ = w.Write(buf.Bytes())
 		return
@@ -546,10 +825,13 @@ func (h *Handler) BulkApproveMatches(w http.ResponseWriter, r *http.Request) {
 
 	// Get threshold from form or use config default
 	threshold := h.config.AutoApproveThreshold
-	if t := r.FormValue("threshold"); t != "" {
-		if parsed, err := strconv.ParseFloat(t, 64); err == nil {
-			threshold = parsed
+	if strings.TrimSpace(r.FormValue("threshold")) != "" {
+		parsed, err := h.formNumber(r, "threshold", "Threshold", parseNumber)
+		if err != nil {
+			h.numberError(w, r, err)
+			return
 		}
+		threshold = parsed
 	}
 
 	// Bulk approve
@@ -645,47 +927,41 @@ func (h *Handler) ApplyPriceUpdates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get approved matches
-	matches, err := h.queries.ListApprovedMatches(ctx, importID)
+	priceImport, err := h.queries.GetPriceImport(ctx, importID)
 	if err != nil {
-		logger.Error("failed to list approved matches", "error", err)
-		http.Error(w, "Failed to load matches", http.StatusInternalServerError)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Import not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get import", "error", err)
+		http.Error(w, "Failed to load import", http.StatusInternalServerError)
+		return
+	}
+	if priceImport.Status == "stale" {
+		http.Error(w, "Import is stale; upload the file again", http.StatusConflict)
 		return
 	}
 
-	// Apply price updates
-	updatedCount := 0
-	for _, match := range matches {
-		if !match.MatchedTemplateID.Valid {
-			continue
-		}
-
-		// If a new name was specified, update both name and price
-		if match.NewName.Valid && match.NewName.String != "" {
-			if err := h.queries.UpdateItemTemplatePriceAndName(ctx, repository.UpdateItemTemplatePriceAndNameParams{
-				ID:           match.MatchedTemplateID.Int64,
-				DefaultPrice: match.SourcePrice,
-				Name:         match.NewName.String,
-			}); err != nil {
-				logger.Error("failed to update template price and name", "error", err, "template_id", match.MatchedTemplateID.Int64)
-				continue
-			}
-		} else {
-			if err := h.queries.UpdateItemTemplatePrice(ctx, repository.UpdateItemTemplatePriceParams{
-				ID:           match.MatchedTemplateID.Int64,
-				DefaultPrice: match.SourcePrice,
-			}); err != nil {
-				logger.Error("failed to update template price", "error", err, "template_id", match.MatchedTemplateID.Int64)
-				continue
-			}
-		}
-		updatedCount++
+	// Price updates and their history entries are written together
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+		return
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	// Mark import as applied
-	_, err = h.queries.MarkPriceImportApplied(ctx, importID)
+	updatedCount, err := h.applyImportMatches(ctx, h.queries.WithTx(tx), priceImport)
 	if err != nil {
-		logger.Error("failed to mark import applied", "error", err)
+		logger.Error("failed to apply price updates", "error", err)
+		http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", "error", err)
+		http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+		return
 	}
 
 	logger.Info("applied price updates", "import_id", importID, "updated", updatedCount)
@@ -697,3 +973,40 @@ func (h *Handler) ApplyPriceUpdates(w http.ResponseWriter, r *http.Request) {
 	}
 	http.Redirect(w, r, "/price-import?success="+strconv.Itoa(updatedCount), http.StatusSeeOther)
 }
+
+// applyImportMatches writes an import's approved prices to their templates
+// and marks the import applied, returning how many templates were updated.
+// A template that fails to update is logged and skipped.
+func (h *Handler) applyImportMatches(ctx context.Context, qtx *repository.Queries, priceImport repository.PriceImport) (int, error) {
+	logger := middleware.LoggerFromContext(ctx)
+
+	matches, err := qtx.ListApprovedMatches(ctx, priceImport.ID)
+	if err != nil {
+		return 0, fmt.Errorf("listing approved matches: %w", err)
+	}
+
+	change := priceChange{
+		Source:         priceSourcePriceImport,
+		ImportID:       priceImport.ID,
+		ImportFilename: priceImport.Filename,
+	}
+
+	updatedCount := 0
+	for _, match := range matches {
+		if !match.MatchedTemplateID.Valid {
+			continue
+		}
+
+		// If a new name was specified, update both name and price
+		if err := setTemplatePrice(ctx, qtx, match.MatchedTemplateID.Int64, match.TemplatePrice, match.SourcePrice, match.NewName.String, change); err != nil {
+			logger.Error("failed to update template price", "error", err, "template_id", match.MatchedTemplateID.Int64)
+			continue
+		}
+		updatedCount++
+	}
+
+	if _, err := qtx.MarkPriceImportApplied(ctx, priceImport.ID); err != nil {
+		logger.Error("failed to mark import applied", "error", err, "import_id", priceImport.ID)
+	}
+	return updatedCount, nil
+}
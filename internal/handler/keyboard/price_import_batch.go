@@ -0,0 +1,165 @@
+package keyboard
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// GetBatchReview pages through the matches of every ready import in a batch,
+// so files uploaded together are reviewed in one pass.
+func (h *Handler) GetBatchReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	batchID := r.PathValue("id")
+
+	batch, err := h.queries.GetPriceImportBatch(ctx, batchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Batch not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get import batch", "error", err)
+		http.Error(w, "Failed to load batch", http.StatusInternalServerError)
+		return
+	}
+
+	imports, err := h.queries.ListImportsByBatch(ctx, batchID)
+	if err != nil {
+		logger.Error("failed to list batch imports", "error", err)
+		http.Error(w, "Failed to load batch", http.StatusInternalServerError)
+		return
+	}
+
+	readyCount := 0
+	hasProcessing := false
+	for _, imp := range imports {
+		switch imp.Status {
+		case "ready":
+			readyCount++
+		case "processing":
+			hasProcessing = true
+		}
+	}
+
+	totalMatches, err := h.queries.CountBatchMatches(ctx, batchID)
+	if err != nil {
+		logger.Error("failed to count batch matches", "error", err)
+	}
+	pagination := newPagination(r, totalMatches, h.pageSize(w, r))
+
+	matches, err := h.queries.ListMatchesByBatch(ctx, repository.ListMatchesByBatchParams{
+		ID:     batchID,
+		Limit:  int64(pagination.PerPage),
+		Offset: int64(pagination.Offset),
+	})
+	if err != nil {
+		logger.Error("failed to list batch matches", "error", err)
+		http.Error(w, "Failed to load matches", http.StatusInternalServerError)
+		return
+	}
+
+	statusCounts, err := h.queries.CountBatchMatchesByStatus(ctx, batchID)
+	if err != nil {
+		logger.Error("failed to count batch matches by status", "error", err)
+	}
+
+	counts := map[string]int64{
+		"pending":       0,
+		"approved":      0,
+		"rejected":      0,
+		"auto_approved": 0,
+		"created":       0,
+	}
+	for _, sc := range statusCounts {
+		counts[sc.Status] = sc.Count
+	}
+
+	templates, err := h.queries.ListItemTemplates(ctx)
+	if err != nil {
+		logger.Error("failed to list templates", "error", err)
+	}
+
+	data := map[string]interface{}{
+		"Batch":         batch,
+		"Imports":       imports,
+		"ReadyCount":    readyCount,
+		"HasProcessing": hasProcessing,
+		"Matches":       matches,
+		"Pagination":    pagination,
+		"StatusCounts":  counts,
+		"Templates":     templates,
+	}
+
+	if err := h.renderer.Render(w, "price_import_batch_review", data); err != nil {
+		logger.Error("failed to render batch review page", "error", err)
+	}
+}
+
+// ApplyBatchPriceUpdates applies the approved matches of every ready import
+// in a batch in one transaction. Failed, stale and already applied files are
+// left alone.
+func (h *Handler) ApplyBatchPriceUpdates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	batchID := r.PathValue("id")
+
+	if _, err := h.queries.GetPriceImportBatch(ctx, batchID); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Batch not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get import batch", "error", err)
+		http.Error(w, "Failed to load batch", http.StatusInternalServerError)
+		return
+	}
+
+	imports, err := h.queries.ListImportsByBatch(ctx, batchID)
+	if err != nil {
+		logger.Error("failed to list batch imports", "error", err)
+		http.Error(w, "Failed to load batch", http.StatusInternalServerError)
+		return
+	}
+
+	// Price updates and their history entries are written together
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+	updatedCount := 0
+	for _, imp := range imports {
+		if imp.Status != "ready" {
+			continue
+		}
+		n, err := h.applyImportMatches(ctx, qtx, imp)
+		if err != nil {
+			logger.Error("failed to apply price updates", "error", err, "import_id", imp.ID)
+			http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+			return
+		}
+		updatedCount += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", "error", err)
+		http.Error(w, "Failed to apply price updates", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("applied batch price updates", "batch_id", batchID, "updated", updatedCount)
+
+	// Redirect with success message
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/price-import?success="+strconv.Itoa(updatedCount))
+		return
+	}
+	http.Redirect(w, r, "/price-import?success="+strconv.Itoa(updatedCount), http.StatusSeeOther)
+}
@@ -0,0 +1,87 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// seedImportBatch uploads two supplier files together, plus a third that
+// failed validation, and one unrelated single-file import.
+func seedImportBatch(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9101, 'material', 'Lumber', '2x4x8', 'ea', 4.10),
+		(9102, 'material', 'Fasteners', '16d nails', 'box', 30.00)`)
+	app.exec(t, `INSERT INTO price_import_batches (id) VALUES ('batch-1')`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status, batch_id, error_message) VALUES
+		('imp-lumber', 'lumber.xlsx', 'ready', 'batch-1', NULL),
+		('imp-nails', 'nails.xlsx', 'ready', 'batch-1', NULL),
+		('imp-bad', 'notes.txt', 'failed', 'batch-1', 'Only .xlsx files are accepted'),
+		('imp-solo', 'solo.xlsx', 'ready', NULL, NULL)`)
+	app.exec(t, `INSERT INTO price_import_matches (import_id, row_number, source_name, source_price, matched_template_id, status) VALUES
+		('imp-lumber', 1, '2X4 8FT', 4.45, 9101, 'approved'),
+		('imp-nails', 1, '16D BOX', 32.50, 9102, 'auto_approved'),
+		('imp-solo', 1, '2X4 8FT', 9.99, 9101, 'approved')`)
+}
+
+func TestGetBatchReview(t *testing.T) {
+	app := newTestApp(t)
+	seedImportBatch(t, app)
+
+	rec := app.get(t, "/price-import/batches/batch-1/review")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"2X4 8FT", "16D BOX", "lumber.xlsx", "nails.xlsx", "Only .xlsx files are accepted", "from 2 Files"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("batch review missing %q", want)
+		}
+	}
+	if strings.Contains(body, "9.99") {
+		t.Errorf("batch review shows matches from another import")
+	}
+
+	if rec := app.get(t, "/price-import/batches/missing/review"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing batch status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPriceImportList_GroupsBatches(t *testing.T) {
+	app := newTestApp(t)
+	seedImportBatch(t, app)
+
+	body := app.get(t, "/price-import").Body.String()
+	if strings.Count(body, "Batch of 3 files") != 1 {
+		t.Errorf("list should show one batch header")
+	}
+	if !strings.Contains(body, `href="/price-import/batches/batch-1/review"`) {
+		t.Errorf("list missing batch review link")
+	}
+}
+
+func TestApplyBatchPriceUpdates(t *testing.T) {
+	app := newTestApp(t)
+	seedImportBatch(t, app)
+
+	if rec := app.postForm(t, http.MethodPost, "/price-import/batches/batch-1/apply", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE (id = 9101 AND default_price = 4.45) OR (id = 9102 AND default_price = 32.50)`); n != 2 {
+		t.Errorf("updated templates = %d, want 2", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE batch_id = 'batch-1' AND status = 'applied'`); n != 2 {
+		t.Errorf("applied imports = %d, want 2", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE id = 'imp-bad' AND status = 'failed'`); n != 1 {
+		t.Errorf("failed import changed status")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE id = 'imp-solo' AND status = 'ready'`); n != 1 {
+		t.Errorf("import outside the batch was applied")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_template_price_history WHERE import_id IN ('imp-lumber', 'imp-nails')`); n != 2 {
+		t.Errorf("price history entries = %d, want 2", n)
+	}
+}
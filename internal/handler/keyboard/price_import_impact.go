@@ -0,0 +1,166 @@
+package keyboard
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// impactWindowDays is how far back the impact report looks for job usage.
+const impactWindowDays = 90
+
+// PriceImpactRow compares an imported price with what recent jobs actually paid.
+type PriceImpactRow struct {
+	TemplateName string
+	Unit         string
+	NewPrice     float64
+	AvgUsedPrice float64
+	UsageCount   int64
+	// Variance is the percent change from the average used price to the new
+	// price. It is only meaningful when HasUsage is true.
+	Variance float64
+	HasUsage bool
+}
+
+// buildPriceImpactRows computes variances and sorts the report by "variance"
+// (largest increase first), "variance_asc", or "name". Rows without recent
+// usage sort last unless sorting by name.
+func buildPriceImpactRows(rows []repository.ListPriceImportImpactRow, sortBy string) []PriceImpactRow {
+	report := make([]PriceImpactRow, 0, len(rows))
+	for _, row := range rows {
+		impact := PriceImpactRow{
+			TemplateName: row.TemplateName,
+			Unit:         row.DefaultUnit,
+			NewPrice:     row.NewPrice,
+			UsageCount:   row.UsageCount,
+		}
+		if row.AvgUsedPrice.Valid && row.UsageCount > 0 {
+			impact.AvgUsedPrice = row.AvgUsedPrice.Float64
+			impact.HasUsage = true
+			if row.AvgUsedPrice.Float64 != 0 {
+				impact.Variance = (row.NewPrice - row.AvgUsedPrice.Float64) / row.AvgUsedPrice.Float64 * 100
+			}
+		}
+		report = append(report, impact)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if sortBy == "name" {
+			return a.TemplateName < b.TemplateName
+		}
+		if a.HasUsage != b.HasUsage {
+			return a.HasUsage
+		}
+		if sortBy == "variance_asc" {
+			return a.Variance < b.Variance
+		}
+		return a.Variance > b.Variance
+	})
+
+	return report
+}
+
+// loadPriceImpact fetches the import and its impact rows for the reporting window.
+func (h *Handler) loadPriceImpact(r *http.Request) (repository.PriceImport, []PriceImpactRow, error) {
+	ctx := r.Context()
+	importID := r.PathValue("id")
+
+	priceImport, err := h.queries.GetPriceImport(ctx, importID)
+	if err != nil {
+		return repository.PriceImport{}, nil, err
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -impactWindowDays).Format("2006-01-02 15:04:05")
+	rows, err := h.queries.ListPriceImportImpact(ctx, repository.ListPriceImportImpactParams{
+		Since:    since,
+		ImportID: importID,
+	})
+	if err != nil {
+		return repository.PriceImport{}, nil, err
+	}
+
+	return priceImport, buildPriceImpactRows(rows, r.URL.Query().Get("sort")), nil
+}
+
+// GetPriceImportImpact renders the price import impact report, comparing each
+// approved supplier price with the average price used on recent jobs.
+func (h *Handler) GetPriceImportImpact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	priceImport, report, err := h.loadPriceImpact(r)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Import not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to load price impact", "error", err)
+		http.Error(w, "Failed to load price impact", http.StatusInternalServerError)
+		return
+	}
+
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = "variance"
+	}
+
+	data := map[string]interface{}{
+		"Import":     priceImport,
+		"Rows":       report,
+		"Sort":       sortBy,
+		"WindowDays": impactWindowDays,
+	}
+
+	if err := h.renderer.Render(w, "price_import_impact", data); err != nil {
+		logger.Error("failed to render price impact page", "error", err)
+	}
+}
+
+// ExportPriceImportImpactCSV downloads the price import impact report as CSV.
+func (h *Handler) ExportPriceImportImpactCSV(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	priceImport, report, err := h.loadPriceImpact(r)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Import not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to load price impact", "error", err)
+		http.Error(w, "Failed to load price impact", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="price-impact-`+priceImport.ID+`.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"Template", "Unit", "New Price", "Recent Avg Price", "Recent Uses", "Variance %"})
+	for _, row := range report {
+		avg, variance := "", ""
+		if row.HasUsage {
+			avg = strconv.FormatFloat(row.AvgUsedPrice, 'f', 2, 64)
+			variance = strconv.FormatFloat(row.Variance, 'f', 1, 64)
+		}
+		_ = cw.Write([]string{
+			row.TemplateName,
+			row.Unit,
+			strconv.FormatFloat(row.NewPrice, 'f', 2, 64),
+			avg,
+			strconv.FormatInt(row.UsageCount, 10),
+			variance,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Error("failed to write price impact csv", "error", err)
+	}
+}
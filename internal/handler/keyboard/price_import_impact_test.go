@@ -0,0 +1,114 @@
+package keyboard_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedPriceImpact(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Lumber', 'Impact Stud', 'ea', 4.00),
+		(9002, 'material', 'Lumber', 'Impact Plywood', 'sheet', 40.00),
+		(9003, 'material', 'Lumber', 'Impact Joist', 'ea', 12.00)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES ('imp-1', 'acme.xlsx', 'ready')`)
+	app.exec(t, `INSERT INTO price_import_matches (import_id, row_number, source_name, source_price, matched_template_id, status) VALUES
+		('imp-1', 1, 'STUD', 5.50, 9001, 'approved'),
+		('imp-1', 2, 'PLY', 36.00, 9002, 'auto_approved'),
+		('imp-1', 3, 'JOIST', 15.00, 9003, 'approved'),
+		('imp-1', 4, 'REJECTED', 1.00, 9003, 'rejected')`)
+
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-recent', 'Recent')`)
+	app.exec(t, `INSERT INTO jobs (id, name, created_at) VALUES ('job-old', 'Old', '2000-01-01 00:00:00')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-recent', 'job-recent', 'Framing'), ('cat-old', 'job-old', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, template_id) VALUES
+		('li-1', 'cat-recent', 'material', 'Impact Stud', 10, 'ea', 4.00, 9001),
+		('li-2', 'cat-recent', 'material', 'Impact Stud', 10, 'ea', 6.00, 9001),
+		('li-3', 'cat-recent', 'material', 'Impact Plywood', 2, 'sheet', 40.00, 9002),
+		('li-4', 'cat-old', 'material', 'Impact Joist', 4, 'ea', 10.00, 9003)`)
+}
+
+func TestPriceImportImpact_CSV(t *testing.T) {
+	app := newTestApp(t)
+	seedPriceImpact(t, app)
+
+	rec := app.get(t, "/price-import/imp-1/impact.csv")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+
+	// Sorted by variance descending with unused templates last; the rejected
+	// match and the joist's old job usage are excluded.
+	want := [][]string{
+		{"Template", "Unit", "New Price", "Recent Avg Price", "Recent Uses", "Variance %"},
+		{"Impact Stud", "ea", "5.50", "5.00", "2", "10.0"},
+		{"Impact Plywood", "sheet", "36.00", "40.00", "1", "-10.0"},
+		{"Impact Joist", "ea", "15.00", "", "0", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(records), len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestPriceImportImpact_Page(t *testing.T) {
+	app := newTestApp(t)
+	seedPriceImpact(t, app)
+
+	rec := app.get(t, "/price-import/imp-1/impact?sort=variance_asc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+
+	plywood := strings.Index(body, "Impact Plywood")
+	stud := strings.Index(body, "Impact Stud")
+	joist := strings.Index(body, "Impact Joist")
+	if plywood < 0 || stud < 0 || joist < 0 || !(plywood < stud && stud < joist) {
+		t.Errorf("rows not sorted by ascending variance with unused last")
+	}
+	if !strings.Contains(body[joist:], "—") {
+		t.Errorf("template without recent usage should show an em dash")
+	}
+
+	if rec := app.get(t, "/price-import/missing/impact"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing import status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCreateLineItem_RecordsTemplateID(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES (9001, 'material', 'Lumber', 'Impact Stud', 'ea', 4.00)`)
+
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-1/items", url.Values{
+		"type":        {"material"},
+		"name":        {"Impact Stud"},
+		"unit_price":  {"4.00"},
+		"template_id": {"9001"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	var templateID int64
+	if err := app.db.QueryRow(`SELECT template_id FROM line_items WHERE category_id = 'cat-1'`).Scan(&templateID); err != nil {
+		t.Fatalf("query template_id: %v", err)
+	}
+	if templateID != 9001 {
+		t.Errorf("template_id = %d, want 9001", templateID)
+	}
+}
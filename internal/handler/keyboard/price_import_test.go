@@ -0,0 +1,64 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCreateTemplateFromMatch_Prefill(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9001, 'material', 'Fasteners', 'Deck Screw Box', 'box', 30.00)`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status) VALUES ('imp-1', 'acme.xlsx', 'ready')`)
+	app.exec(t, `INSERT INTO price_import_matches (id, import_id, row_number, source_name, source_unit, source_price, status, source_category, new_name) VALUES
+		(1, 'imp-1', 4, 'FRMG NAIL 16D', 'box', 42.75, 'pending', 'FASTENER', NULL),
+		(2, 'imp-1', 5, 'FRMG NAIL 16D GALV', 'box', 48.00, 'pending', 'Lumber', 'Framing Nail 16d')`)
+
+	body := app.get(t, "/price-import/imp-1/review").Body.String()
+	if strings.Count(body, `hx-get="/price-import/matches/`) != 2 {
+		t.Errorf("review page missing create template buttons")
+	}
+
+	body = app.get(t, "/price-import/matches/1/create-template").Body.String()
+	for _, want := range []string{`value="FRMG NAIL 16D"`, `value="box"`, `value="42.75"`, `value="Fasteners"`, "FASTENER"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("form missing %s", want)
+		}
+	}
+
+	// The edited name wins, and an unknown header is suggested as written
+	body = app.get(t, "/price-import/matches/2/create-template").Body.String()
+	if !strings.Contains(body, `value="Framing Nail 16d"`) || !strings.Contains(body, `name="category" value="Lumber"`) {
+		t.Errorf("form not prefilled from the edited name and header")
+	}
+
+	form := url.Values{"name": {"Framing Nail 16d"}, "unit": {"box"}, "price": {"42.75"}, "category": {"Fasteners"}, "type": {"material"}}
+	req := httptest.NewRequest(http.MethodPost, "/price-import/matches/1/create-template", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec := app.do(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Header().Get("HX-Trigger-After-Swap"), "templateCreated") {
+		t.Errorf("create did not trigger the move to the next row")
+	}
+
+	// A second template with the same name needs confirming
+	rec = app.postForm(t, http.MethodPost, "/price-import/matches/2/create-template", form)
+	if !strings.Contains(rec.Body.String(), "data-duplicate-warning") || rec.Header().Get("HX-Retarget") != "#create-template-2" {
+		t.Fatalf("duplicate name was not warned about")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE name = 'Framing Nail 16d'`); n != 1 {
+		t.Fatalf("templates = %d before confirming, want 1", n)
+	}
+
+	form.Set("confirm_duplicate", "true")
+	app.postForm(t, http.MethodPost, "/price-import/matches/2/create-template", form)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE name = 'Framing Nail 16d'`); n != 2 {
+		t.Errorf("templates = %d after confirming, want 2", n)
+	}
+}
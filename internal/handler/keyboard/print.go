@@ -0,0 +1,175 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/excel"
+)
+
+// PrintJob renders a job in the print layout: company header, one table per
+// top-level category, and the job totals, without navigation or controls.
+func (h *Handler) PrintJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	data, err := h.jobPrintData(ctx, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to load job for printing", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.renderer.Render(w, "job_print", data); err != nil {
+		logger.Error("failed to render template", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// jobPrint is everything the job print layout shows.
+type jobPrint struct {
+	Job         repository.Job
+	Customer    string
+	Settings    repository.Setting
+	QuoteFields []repository.ListJobCustomFieldValuesRow
+	Categories  []excel.WorkbookCategory
+	ItemCount   int
+	Totals      domain.JobTotal
+}
+
+// jobPrintData loads a job for the print layout.
+func (h *Handler) jobPrintData(ctx context.Context, jobID string) (*jobPrint, error) {
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("listing categories: %w", err)
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("listing line items: %w", err)
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting settings: %w", err)
+	}
+
+	quoteFields, err := h.quoteFields(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("listing job custom fields: %w", err)
+	}
+
+	customer := job.CustomerName.String
+	if job.ClientID.Valid {
+		if client, err := h.queries.GetClient(ctx, job.ClientID.String); err == nil {
+			customer = client.Name
+		}
+	}
+
+	itemsByCategory := groupItemsByCategory(lineItems)
+	sections := make([]excel.WorkbookCategory, 0)
+	for _, node := range buildCategoryTree(categories) {
+		sections = append(sections, h.buildWorkbookCategory(node, job, settings, categories, lineItems, itemsByCategory))
+	}
+
+	return &jobPrint{
+		Job:         job,
+		Customer:    customer,
+		Settings:    settings,
+		QuoteFields: quoteFields,
+		Categories:  sections,
+		ItemCount:   len(lineItems),
+		Totals:      h.calculateTotals(job, settings, categories, lineItems),
+	}, nil
+}
+
+// PrintCategory renders a category and its subcategories in the print layout.
+func (h *Handler) PrintCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	categoryID := r.PathValue("id")
+
+	category, err := h.queries.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, category.JobID)
+	if err != nil {
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, job.ID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	node, ok := findCategoryNode(buildCategoryTree(categories), categoryID)
+	if !ok {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Job":      job,
+		"Settings": settings,
+		"Category": h.buildWorkbookCategory(node, job, settings, categories, lineItems, groupItemsByCategory(lineItems)),
+	}
+
+	if err := h.renderer.Render(w, "category_print", data); err != nil {
+		logger.Error("failed to render template", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// findCategoryNode returns the node with the given ID anywhere in the tree.
+func findCategoryNode(nodes []CategoryTreeNode, id string) (CategoryTreeNode, bool) {
+	for _, node := range nodes {
+		if node.ID == id {
+			return node, true
+		}
+		if found, ok := findCategoryNode(node.Children, id); ok {
+			return found, true
+		}
+	}
+	return CategoryTreeNode{}, false
+}
@@ -0,0 +1,110 @@
+package keyboard
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+// defaultPrintBatchLimit applies when the configuration sets no limit.
+const defaultPrintBatchLimit = 25
+
+// printBatchFailure explains why a quote was left out of a combined print.
+type printBatchFailure struct {
+	JobID  string
+	Name   string
+	Reason string
+}
+
+// PrintJobs renders the selected quotes as one print document, each behind a
+// cover page, for saving as a single PDF from the browser's print dialog.
+// Quotes are written to the response one at a time, and quotes that cannot
+// be printed are listed in a summary at the end instead of failing the batch.
+func (h *Handler) PrintJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	var jobIDs []string
+	seen := make(map[string]bool)
+	for _, id := range r.URL.Query()["id"] {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			jobIDs = append(jobIDs, id)
+		}
+	}
+	if len(jobIDs) == 0 {
+		http.Error(w, "Select at least one quote to print", http.StatusBadRequest)
+		return
+	}
+	if limit := h.printBatchLimit(); len(jobIDs) > limit {
+		http.Error(w, "Select at most "+strconv.Itoa(limit)+" quotes to print at once", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	flusher, _ := w.(http.Flusher)
+	if err := h.renderer.RenderPartial(w, "jobs_print_start", map[string]interface{}{
+		"Count": len(jobIDs),
+	}); err != nil {
+		logger.Error("failed to render template", "error", err)
+		return
+	}
+
+	// Each quote is rendered to its own buffer so a failure part way through
+	// leaves no half-written quote in the document.
+	var (
+		buf      bytes.Buffer
+		printed  int
+		failures []printBatchFailure
+	)
+	for _, jobID := range jobIDs {
+		data, err := h.jobPrintData(ctx, jobID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				failures = append(failures, printBatchFailure{JobID: jobID, Reason: "Quote not found"})
+				continue
+			}
+			logger.Error("failed to load job for printing", "error", err, "job_id", jobID)
+			failures = append(failures, printBatchFailure{JobID: jobID, Reason: "Failed to load quote"})
+			continue
+		}
+		if data.ItemCount == 0 {
+			failures = append(failures, printBatchFailure{JobID: jobID, Name: data.Job.Name, Reason: "Quote has no line items"})
+			continue
+		}
+
+		buf.Reset()
+		if err := h.renderer.RenderPartial(&buf, "jobs_print_job", data); err != nil {
+			logger.Error("failed to render template", "error", err, "job_id", jobID)
+			failures = append(failures, printBatchFailure{JobID: jobID, Name: data.Job.Name, Reason: "Failed to render quote"})
+			continue
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			logger.Error("failed to write print batch", "error", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		printed++
+	}
+
+	if err := h.renderer.RenderPartial(w, "jobs_print_end", map[string]interface{}{
+		"Printed":  printed,
+		"Failures": failures,
+	}); err != nil {
+		logger.Error("failed to render template", "error", err)
+	}
+}
+
+// printBatchLimit returns how many quotes one combined print may contain.
+func (h *Handler) printBatchLimit() int {
+	if h.config.PrintBatchLimit > 0 {
+		return h.config.PrintBatchLimit
+	}
+	return defaultPrintBatchLimit
+}
@@ -0,0 +1,136 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/config"
+)
+
+func seedPrintJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `UPDATE settings SET company_name = 'Rupert Construction', company_phone = '406-555-0100'`)
+	app.exec(t, `INSERT INTO jobs (id, name, surcharge_percent) VALUES ('job-1', 'Garage Addition', 10)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name, sort_order) VALUES
+		('cat-1', 'job-1', 'Framing', 0),
+		('cat-2', 'job-1', 'Electrical', 1)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES ('cat-1a', 'job-1', 'cat-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 3.50, 0),
+		('item-2', 'cat-1a', 'labor', 'Framer', 8, 'hr', 50, 1),
+		('item-3', 'cat-2', 'material', 'Romex 12/2', 2, 'roll', 100, 0)`)
+}
+
+// assertPrintLayout checks that a page has none of the interactive chrome of
+// the regular layout.
+func assertPrintLayout(t *testing.T, body string) {
+	t.Helper()
+	for _, unwanted := range []string{"hx-", "x-data", "<nav", "<kbd", "<script", "help-overlay", "theme-toggle"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("print layout contains %q", unwanted)
+		}
+	}
+	if !strings.Contains(body, "/static/print.css") {
+		t.Errorf("print layout missing print stylesheet")
+	}
+}
+
+func TestPrintJob(t *testing.T) {
+	app := newTestApp(t)
+	seedPrintJob(t, app)
+
+	rec := app.get(t, "/jobs/job-1/print")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	assertPrintLayout(t, body)
+
+	for _, want := range []string{
+		"Rupert Construction",
+		"406-555-0100",
+		"Garage Addition",
+		"<h2>Framing</h2>",
+		"<h2>Electrical</h2>",
+		"Walls",
+		"Romex 12/2",
+		"$400.00",
+		"$698.50",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("print page missing %q", want)
+		}
+	}
+	if strings.Count(body, `class="print-category"`) != 2 {
+		t.Errorf("want one section per top-level category")
+	}
+}
+
+func TestPrintCategory(t *testing.T) {
+	app := newTestApp(t)
+	seedPrintJob(t, app)
+
+	rec := app.get(t, "/categories/cat-1/print")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	assertPrintLayout(t, body)
+
+	for _, want := range []string{"Rupert Construction", "<h2>Framing</h2>", "Framer", "$478.50"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("print page missing %q", want)
+		}
+	}
+	if strings.Contains(body, "Romex") {
+		t.Errorf("print page includes items from other categories")
+	}
+
+	if rec := app.get(t, "/categories/missing/print"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing category status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPrintJobs(t *testing.T) {
+	app := newTestApp(t)
+	seedPrintJob(t, app)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-2', 'Empty Shed')`)
+
+	rec := app.get(t, "/jobs/print?id=job-1&id=job-2&id=missing&id=job-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	assertPrintLayout(t, body)
+
+	if n := strings.Count(body, `class="print-cover"`); n != 1 {
+		t.Errorf("cover pages = %d, want 1", n)
+	}
+	for _, want := range []string{"Garage Addition", "Romex 12/2", "$698.50", "1 quote printed"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("combined print missing %q", want)
+		}
+	}
+	if !strings.Contains(body, `data-print-failure="job-2"`) || !strings.Contains(body, "Quote has no line items") {
+		t.Errorf("summary missing the quote without items")
+	}
+	if !strings.Contains(body, `data-print-failure="missing"`) {
+		t.Errorf("summary missing the unknown quote")
+	}
+}
+
+func TestPrintJobs_BatchLimit(t *testing.T) {
+	app := newTestAppWithConfig(t, &config.Config{PrintBatchLimit: 1})
+	seedPrintJob(t, app)
+
+	if rec := app.get(t, "/jobs/print?id=job-1&id=job-2"); rec.Code != http.StatusBadRequest {
+		t.Errorf("over limit status = %d, want 400", rec.Code)
+	}
+	if rec := app.get(t, "/jobs/print"); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty selection status = %d, want 400", rec.Code)
+	}
+	if rec := app.get(t, "/jobs/print?id=job-1"); rec.Code != http.StatusOK {
+		t.Errorf("within limit status = %d, want 200", rec.Code)
+	}
+}
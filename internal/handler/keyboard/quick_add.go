@@ -0,0 +1,209 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/google/uuid"
+)
+
+// quickAddTargetLimit caps the categories offered by the quick-add picker.
+const quickAddTargetLimit = 10
+
+// GetQuickAddForm returns the quick-add form, which files a line item into a
+// category of any open quote without leaving the current page.
+func (h *Handler) GetQuickAddForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	targets, err := h.searchQuickAddTargets(ctx, "")
+	if err != nil {
+		logger.Error("failed to search quick add targets", "error", err)
+		http.Error(w, "Failed to load quotes", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderQuickAddPartial(w, r, "quick_add_form", map[string]interface{}{
+		"Targets": targets,
+		"Type":    "material",
+	})
+}
+
+// SearchQuickAddTargets returns the categories of open quotes whose own name
+// or quote name contains q.
+func (h *Handler) SearchQuickAddTargets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	targets, err := h.searchQuickAddTargets(ctx, strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		logger.Error("failed to search quick add targets", "error", err)
+		http.Error(w, "Failed to load quotes", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderQuickAddPartial(w, r, "quick_add_targets", map[string]interface{}{
+		"Targets": targets,
+	})
+}
+
+// CreateQuickAddItem validates the quick-add form and creates the line item
+// in the chosen category. Only draft and sent quotes accept new items.
+func (h *Handler) CreateQuickAddItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	categoryID := r.FormValue("category_id")
+	itemType := r.FormValue("type")
+	if itemType == "" {
+		itemType = "material"
+	}
+	unit := strings.TrimSpace(r.FormValue("unit"))
+	if unit == "" {
+		unit = "ea"
+	}
+	quantity, quantityErr := h.formQuantity(r, "quantity", "Quantity")
+	if quantity <= 0 {
+		quantity = 1
+	}
+	unitPrice, priceErr := h.formMoney(r, "unit_price", "Price")
+
+	data := map[string]interface{}{
+		"Search":     r.FormValue("q"),
+		"CategoryID": categoryID,
+		"Type":       itemType,
+		"Name":       r.FormValue("name"),
+		"Quantity":   quantity,
+		"Unit":       unit,
+		"UnitPrice":  unitPrice,
+	}
+	// Validation errors re-render the form so the picker keeps its results.
+	fail := func(message string) {
+		targets, err := h.searchQuickAddTargets(ctx, strings.TrimSpace(r.FormValue("q")))
+		if err != nil {
+			logger.Error("failed to search quick add targets", "error", err)
+		}
+		data["Targets"] = targets
+		data["Error"] = message
+		h.renderQuickAddPartial(w, r, "quick_add_form", data)
+	}
+
+	if err := errors.Join(quantityErr, priceErr); err != nil {
+		fail(err.Error())
+		return
+	}
+	if categoryID == "" {
+		fail("Pick a quote and category")
+		return
+	}
+	category, err := h.queries.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			fail("That category no longer exists")
+			return
+		}
+		logger.Error("failed to get category", "error", err)
+		http.Error(w, "Failed to load category", http.StatusInternalServerError)
+		return
+	}
+	job, err := h.queries.GetJob(ctx, category.JobID)
+	if err != nil {
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	if job.Status != "draft" && job.Status != "sent" {
+		fail("“" + job.Name + "” is " + job.Status + " and can't take new items")
+		return
+	}
+
+	pricing := lineItemPricing(r, domain.LineItemInput{
+		CategoryID: categoryID,
+		Type:       domain.LineItemType(itemType),
+		Name:       strings.TrimSpace(r.FormValue("name")),
+		Quantity:   quantity,
+		Unit:       unit,
+		UnitPrice:  unitPrice,
+	})
+	if errs := pricing.Validate(); len(errs) > 0 {
+		fail(errs[0].Message)
+		return
+	}
+
+	watch, err := h.watchTotal(ctx, categoryID)
+	if err != nil {
+		logger.Error("failed to total job", "error", err)
+	}
+
+	item, err := h.queries.CreateLineItem(ctx, repository.CreateLineItemParams{
+		ID:                  uuid.New().String(),
+		CategoryID:          categoryID,
+		Type:                itemType,
+		Name:                pricing.Name,
+		Description:         sql.NullString{},
+		Quantity:            quantity,
+		Unit:                unit,
+		UnitPrice:           pricing.UnitPrice,
+		SurchargePercent:    sql.NullFloat64{},
+		SortOrder:           0,
+		ExemptFromSurcharge: pricing.ExemptFromSurcharge,
+		TemplateID:          sql.NullInt64{},
+		IsCredit:            pricing.IsCredit,
+		TaxTreatment:        string(pricing.TaxTreatment),
+	})
+	if err != nil {
+		logger.Error("failed to create line item", "error", err)
+		http.Error(w, "Failed to create line item", http.StatusInternalServerError)
+		return
+	}
+	if err := h.checkTotal(ctx, watch, totalAlertCreate, item.ID, nil); err != nil {
+		logger.Error("failed to check total change", "error", err)
+	}
+
+	logger.Info("quick added line item", "job_id", job.ID, "category_id", categoryID, "item_id", item.ID)
+
+	if r.Header.Get("HX-Request") != "true" {
+		http.Redirect(w, r, "/categories/"+categoryID, http.StatusSeeOther)
+		return
+	}
+
+	h.renderQuickAddPartial(w, r, "quick_add_done", map[string]interface{}{
+		"Item":     item,
+		"Job":      job,
+		"Category": category,
+	})
+}
+
+// searchQuickAddTargets lists the open-quote categories matching search.
+func (h *Handler) searchQuickAddTargets(ctx context.Context, search string) ([]repository.SearchQuickAddTargetsRow, error) {
+	return h.queries.SearchQuickAddTargets(ctx, repository.SearchQuickAddTargetsParams{
+		Search: search,
+		Limit:  quickAddTargetLimit,
+	})
+}
+
+// renderQuickAddPartial writes one of the quick-add partials.
+func (h *Handler) renderQuickAddPartial(w http.ResponseWriter, r *http.Request, name string, data map[string]interface{}) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, name, data); err != nil {
+		logger.Error("failed to render quick add", "error", err, "partial", name)
+		http.Error(w, "Failed to render form", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,103 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedQuickAddJobs(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, status) VALUES
+		('job-open', 'Kitchen Remodel', 'draft'),
+		('job-sent', 'Garage', 'sent'),
+		('job-closed', 'Old Kitchen', 'accepted')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES
+		('cat-open', 'job-open', 'Cabinets'),
+		('cat-sent', 'job-sent', 'Framing'),
+		('cat-closed', 'job-closed', 'Cabinets')`)
+}
+
+func TestSearchQuickAddTargets(t *testing.T) {
+	app := newTestApp(t)
+	seedQuickAddJobs(t, app)
+
+	body := app.get(t, "/quick-add/targets?q=kitchen").Body.String()
+	if !strings.Contains(body, `value="cat-open"`) {
+		t.Errorf("expected category of open job matched by job name")
+	}
+	if strings.Contains(body, `value="cat-closed"`) {
+		t.Errorf("closed job offered as a quick add target")
+	}
+	if strings.Contains(body, `value="cat-sent"`) {
+		t.Errorf("non-matching job offered as a quick add target")
+	}
+
+	body = app.get(t, "/quick-add/targets?q=fram").Body.String()
+	if !strings.Contains(body, `value="cat-sent"`) {
+		t.Errorf("expected category of sent job matched by category name")
+	}
+}
+
+func TestCreateQuickAddItem(t *testing.T) {
+	app := newTestApp(t)
+	seedQuickAddJobs(t, app)
+
+	req := quickAddRequest(url.Values{
+		"category_id": {"cat-open"},
+		"type":        {"material"},
+		"name":        {"Base cabinet"},
+		"quantity":    {"3"},
+		"unit":        {"ea"},
+		"unit_price":  {"180"},
+	})
+	rec := app.do(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `href="/categories/cat-open"`) {
+		t.Errorf("confirmation missing link to the category")
+	}
+
+	var count int
+	if err := app.db.QueryRow(`SELECT COUNT(*) FROM line_items WHERE category_id = 'cat-open' AND name = 'Base cabinet'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("line items created = %d, want 1", count)
+	}
+}
+
+func TestCreateQuickAddItem_ClosedJobRejected(t *testing.T) {
+	app := newTestApp(t)
+	seedQuickAddJobs(t, app)
+
+	rec := app.do(quickAddRequest(url.Values{
+		"category_id": {"cat-closed"},
+		"type":        {"material"},
+		"name":        {"Base cabinet"},
+		"quantity":    {"1"},
+		"unit":        {"ea"},
+		"unit_price":  {"180"},
+	}))
+	if !strings.Contains(rec.Body.String(), "data-quick-add-error") {
+		t.Errorf("expected the form to be re-rendered with an error")
+	}
+
+	var count int
+	if err := app.db.QueryRow(`SELECT COUNT(*) FROM line_items WHERE category_id = 'cat-closed'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("line item created in a closed job")
+	}
+}
+
+func quickAddRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/quick-add", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	return req
+}
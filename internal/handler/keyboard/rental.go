@@ -0,0 +1,130 @@
+package keyboard
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// rentalUnit is the unit of an equipment item priced from its template's
+// rental rates; the unit price is the whole rental.
+const rentalUnit = "rental"
+
+// templateRentalRates returns a template's tiered rental rates. Unset rates
+// are zero, which CheapestRental skips.
+func templateRentalRates(t repository.ItemTemplate) domain.RentalRates {
+	return domain.RentalRates{
+		Day:   t.DayRate.Float64,
+		Week:  t.WeekRate.Float64,
+		Month: t.MonthRate.Float64,
+	}
+}
+
+// rentalQuote prices a rental of the given template for days. It returns
+// false when the template has no rental rates or days isn't positive, so the
+// item is priced as usual.
+func (h *Handler) rentalQuote(ctx context.Context, templateID int64, days int) (domain.RentalQuote, domain.RentalRates, bool, error) {
+	if days <= 0 {
+		return domain.RentalQuote{}, domain.RentalRates{}, false, nil
+	}
+	template, err := h.queries.GetItemTemplate(ctx, templateID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.RentalQuote{}, domain.RentalRates{}, false, nil
+		}
+		return domain.RentalQuote{}, domain.RentalRates{}, false, err
+	}
+	rates := templateRentalRates(template)
+	quote, ok := domain.CheapestRental(rates, days)
+	return quote, rates, ok, nil
+}
+
+// formRentalDays reads the rental_days field of a line item form. Blank is
+// zero, meaning the item isn't priced as a rental.
+func formRentalDays(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.FormValue("rental_days"))
+	if value == "" {
+		return 0, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, &NumberError{Field: "rental_days", Label: "Rental days", Value: value, Example: "10"}
+	}
+	return days, nil
+}
+
+// formRentalRates reads the day_rate, week_rate, and month_rate fields of a
+// template form, typed with the given decimal separator, where blank means
+// the period isn't offered. The second result is false when the form has no
+// rate fields; the third describes an invalid rate.
+func formRentalRates(r *http.Request, decimal rune) (repository.UpdateItemTemplateRentalRatesParams, bool, string) {
+	var params repository.UpdateItemTemplateRentalRatesParams
+	if _, ok := r.Form["day_rate"]; !ok {
+		return params, false, ""
+	}
+
+	fields := []struct {
+		name  string
+		label string
+		dest  *sql.NullFloat64
+	}{
+		{"day_rate", "Day rate", &params.DayRate},
+		{"week_rate", "Week rate", &params.WeekRate},
+		{"month_rate", "Month rate", &params.MonthRate},
+	}
+	for _, f := range fields {
+		value := strings.TrimSpace(r.FormValue(f.name))
+		if value == "" {
+			continue
+		}
+		rate, err := parseMoney(value, decimal)
+		if err != nil || rate < 0 {
+			return params, true, f.label + " must be 0 or more"
+		}
+		if rate > 0 {
+			*f.dest = sql.NullFloat64{Float64: rate, Valid: true}
+		}
+	}
+	return params, true, ""
+}
+
+// GetRentalQuote renders the cheapest rental breakdown for the inline form as
+// the duration or selected template changes. Templates without rental rates
+// render nothing.
+func (h *Handler) GetRentalQuote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	data := map[string]interface{}{}
+	templateID, idErr := strconv.ParseInt(r.URL.Query().Get("template_id"), 10, 64)
+	days, daysErr := strconv.Atoi(r.URL.Query().Get("rental_days"))
+	if idErr == nil && daysErr == nil {
+		quote, rates, ok, err := h.rentalQuote(ctx, templateID, days)
+		if err != nil {
+			logger.Error("failed to get item template", "error", err)
+			http.Error(w, "Failed to load template", http.StatusInternalServerError)
+			return
+		}
+		if ok {
+			data["Breakdown"] = quote.Breakdown(rates)
+			data["Total"] = quote.Total
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPartial(&buf, "rental_quote", data); err != nil {
+		logger.Error("failed to render rental quote", "error", err)
+		http.Error(w, "Failed to render quote", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
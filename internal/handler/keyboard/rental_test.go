@@ -0,0 +1,159 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func seedRentalJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price, day_rate, week_rate, month_rate) VALUES
+		(9301, 'equipment', 'Rentals', 'Skid steer', 'day', 250, 250, 900, 2800),
+		(9302, 'equipment', 'Rentals', 'Plate compactor', 'day', 85, NULL, NULL, NULL)`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-rental', 'Driveway')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-rental', 'job-rental', 'Site Work')`)
+}
+
+func TestCreateLineItem_RentalRates(t *testing.T) {
+	app := newTestApp(t)
+	seedRentalJob(t, app)
+
+	app.postForm(t, http.MethodPost, "/categories/cat-rental/items", url.Values{
+		"type": {"equipment"}, "name": {"Skid steer"}, "template_id": {"9301"},
+		"quantity": {"1"}, "unit": {"day"}, "unit_price": {"250"}, "rental_days": {"9"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Skid steer'
+		AND unit_price = 1400 AND unit = 'rental' AND quantity = 1
+		AND description = '9 days: 1 week @ $900.00 + 2 days @ $250.00'`); n != 1 {
+		t.Errorf("skid steer not priced from its rate tiers")
+	}
+
+	// Without rate tiers the form's price and unit are kept.
+	app.postForm(t, http.MethodPost, "/categories/cat-rental/items", url.Values{
+		"type": {"equipment"}, "name": {"Plate compactor"}, "template_id": {"9302"},
+		"quantity": {"3"}, "unit": {"day"}, "unit_price": {"85"}, "rental_days": {"3"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Plate compactor'
+		AND unit_price = 85 AND unit = 'day' AND quantity = 3 AND description IS NULL`); n != 1 {
+		t.Errorf("template without rate tiers was repriced")
+	}
+
+	// Without a duration the item is priced as usual.
+	app.postForm(t, http.MethodPost, "/categories/cat-rental/items", url.Values{
+		"type": {"equipment"}, "name": {"Skid steer"}, "template_id": {"9301"},
+		"quantity": {"2"}, "unit": {"day"}, "unit_price": {"250"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Skid steer' AND unit_price = 250 AND quantity = 2`); n != 1 {
+		t.Errorf("item without a duration was repriced")
+	}
+}
+
+func TestRentalItem_NotComparedWithTemplatePrice(t *testing.T) {
+	app := newTestApp(t)
+	seedRentalJob(t, app)
+
+	app.postForm(t, http.MethodPost, "/categories/cat-rental/items", url.Values{
+		"type": {"equipment"}, "name": {"Skid steer"}, "template_id": {"9301"},
+		"quantity": {"1"}, "unit": {"day"}, "unit_price": {"250"}, "rental_days": {"10"},
+	})
+	var id string
+	var price float64
+	if err := app.db.QueryRow(`SELECT id, unit_price FROM line_items WHERE unit = 'rental'`).Scan(&id, &price); err != nil {
+		t.Fatal(err)
+	}
+	// Put the template's price above the rental total so a per-unit
+	// comparison would call the rental both out of date and below cost.
+	app.exec(t, `UPDATE item_templates SET default_price = 5000 WHERE id = 9301`)
+
+	body := app.get(t, "/categories/cat-rental").Body.String()
+	if strings.Contains(body, `class="price-drift`) || strings.Contains(body, `class="below-cost`) {
+		t.Errorf("rental flagged against the template price: %s", body)
+	}
+
+	for _, action := range []string{"refresh-price", "raise-to-cost"} {
+		rec := app.do(httptest.NewRequest(http.MethodPost, "/items/"+id+"/"+action, nil))
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s status = %d, want 409", action, rec.Code)
+		}
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = '`+id+`' AND unit_price = `+strconv.FormatFloat(price, 'f', -1, 64)); n != 1 {
+		t.Errorf("rental price changed")
+	}
+
+	if body := app.get(t, "/jobs/job-rental/preflight").Body.String(); strings.Contains(body, "Items priced below cost") {
+		t.Errorf("rental reported below cost: %s", body)
+	}
+}
+
+func TestCreateLineItem_MalformedRentalDays(t *testing.T) {
+	app := newTestApp(t)
+	seedRentalJob(t, app)
+
+	rec := app.postForm(t, http.MethodPost, "/categories/cat-rental/items", url.Values{
+		"type": {"equipment"}, "name": {"Skid steer"}, "template_id": {"9301"},
+		"quantity": {"1"}, "unit": {"day"}, "unit_price": {"250"}, "rental_days": {"abc"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Skid steer'`); n != 0 {
+		t.Errorf("line items created = %d, want 0", n)
+	}
+}
+
+func TestGetRentalQuote(t *testing.T) {
+	app := newTestApp(t)
+	seedRentalJob(t, app)
+
+	body := app.get(t, "/items/rental-quote?template_id=9301&rental_days=9").Body.String()
+	if !strings.Contains(body, "9 days: 1 week @ $900.00") || !strings.Contains(body, "2 days @ $250.00 = ") || !strings.Contains(body, "$1400.00") {
+		t.Errorf("quote = %q", body)
+	}
+
+	for _, target := range []string{
+		"/items/rental-quote?template_id=9302&rental_days=9",
+		"/items/rental-quote?template_id=9301",
+		"/items/rental-quote?rental_days=9",
+	} {
+		if body := app.get(t, target).Body.String(); strings.Contains(body, "data-rental-quote") {
+			t.Errorf("%s: unexpected quote %q", target, body)
+		}
+	}
+
+	if body := app.get(t, "/categories/cat-rental/form?type=equipment").Body.String(); !strings.Contains(body, `name="rental_days"`) {
+		t.Errorf("equipment form missing days input")
+	}
+	if body := app.get(t, "/categories/cat-rental/form?type=material").Body.String(); strings.Contains(body, `name="rental_days"`) {
+		t.Errorf("material form has days input")
+	}
+}
+
+func TestUpdateItemTemplate_RentalRates(t *testing.T) {
+	app := newTestApp(t)
+	seedRentalJob(t, app)
+
+	form := url.Values{
+		"type": {"equipment"}, "category": {"Rentals"}, "name": {"Plate compactor"},
+		"default_unit": {"day"}, "default_price": {"85"},
+		"day_rate": {"85"}, "week_rate": {"300"}, "month_rate": {""},
+	}
+	app.postForm(t, http.MethodPut, "/item-templates/9302", form)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9302
+		AND day_rate = 85 AND week_rate = 300 AND month_rate IS NULL`); n != 1 {
+		t.Errorf("rental rates not saved")
+	}
+
+	form.Set("week_rate", "-5")
+	if rec := app.postForm(t, http.MethodPut, "/item-templates/9302", form); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative rate status = %d, want 400", rec.Code)
+	}
+
+	body := app.get(t, "/item-templates/9301/edit").Body.String()
+	if !strings.Contains(body, `name="week_rate"`) || !strings.Contains(body, `value="900.00"`) {
+		t.Errorf("edit form missing rental rates")
+	}
+}
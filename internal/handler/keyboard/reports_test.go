@@ -0,0 +1,127 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOrderList_ExcludesSubcontractsAndFees(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Basement Finish')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-1', 'cat-1', 'material', 'Drywall Sheet', 40, 'sheet', 14),
+		('item-2', 'cat-1', 'subcontract', 'Tape and Mud Crew', 1, 'job', 1800),
+		('item-3', 'cat-1', 'fee', 'Building Permit', 1, 'ea', 250)`)
+
+	for _, target := range []string{"/jobs/job-1/order-list", "/jobs/job-1/site-materials"} {
+		body := app.get(t, target).Body.String()
+		if !strings.Contains(body, "Drywall Sheet") {
+			t.Errorf("%s: missing material item", target)
+		}
+		if strings.Contains(body, "Tape and Mud Crew") || strings.Contains(body, "Building Permit") {
+			t.Errorf("%s: subcontract or fee item listed", target)
+		}
+	}
+}
+
+func seedPhasedJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Kitchen Remodel')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name, phase) VALUES
+		('cat-electrical', 'job-1', NULL, 'Electrical', 'Rough-in'),
+		('cat-kitchen', 'job-1', NULL, 'Kitchen', NULL),
+		('cat-lights', 'job-1', 'cat-kitchen', 'Lighting', 'Finish')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, phase) VALUES
+		('item-wire', 'cat-electrical', 'material', '12/2 Romex', 250, 'ft', 0.9, NULL),
+		('item-box', 'cat-electrical', 'material', 'Old work box', 10, 'ea', 3, NULL),
+		('item-kitchen-wire', 'cat-kitchen', 'material', '12/2 Romex', 100, 'ft', 0.9, 'rough-in '),
+		('item-paint', 'cat-kitchen', 'material', 'Cabinet paint', 2, 'gal', 60, NULL),
+		('item-fixture', 'cat-lights', 'material', 'Pendant', 4, 'ea', 120, NULL),
+		('item-labor', 'cat-lights', 'labor', 'Electrician', 6, 'hr', 95, NULL)`)
+}
+
+func TestSiteMaterials_GroupByPhase(t *testing.T) {
+	app := newTestApp(t)
+	seedPhasedJob(t, app)
+
+	body := app.get(t, "/jobs/job-1/site-materials?group=phase").Body.String()
+
+	finish := strings.Index(body, ">Finish<")
+	rough := strings.Index(body, ">Rough-in<")
+	other := strings.Index(body, ">Unphased<")
+	if finish < 0 || rough < 0 || other < 0 {
+		t.Fatalf("missing phase groups: finish=%d rough-in=%d unphased=%d", finish, rough, other)
+	}
+	if !(finish < rough && rough < other) {
+		t.Errorf("phases out of order: finish=%d rough-in=%d unphased=%d", finish, rough, other)
+	}
+	if strings.Count(body, ">Rough-in<") != 1 {
+		t.Errorf("item phase tag not merged with its category's phase")
+	}
+
+	sections := map[string]string{
+		"Finish":   body[finish:rough],
+		"Rough-in": body[rough:other],
+		"Unphased": body[other:],
+	}
+	for phase, wants := range map[string][]string{
+		"Finish":   {"Pendant", "4.00"},
+		"Rough-in": {"12/2 Romex", "350.00", "Old work box"},
+		"Unphased": {"Cabinet paint"},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(sections[phase], want) {
+				t.Errorf("%s group missing %q", phase, want)
+			}
+		}
+	}
+	if strings.Contains(body, "Electrician") {
+		t.Errorf("labor listed in site materials")
+	}
+
+	// The default grouping is unchanged.
+	body = app.get(t, "/jobs/job-1/site-materials").Body.String()
+	if !strings.Contains(body, "Kitchen &gt; Lighting") || strings.Contains(body, ">Unphased<") {
+		t.Errorf("category grouping changed")
+	}
+}
+
+func TestPhaseTags_Forms(t *testing.T) {
+	app := newTestApp(t)
+	seedPhasedJob(t, app)
+
+	body := app.get(t, "/categories/cat-kitchen/form?type=material").Body.String()
+	for _, want := range []string{`<option value="Finish">`, `<option value="Rough-in">`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("phase autocomplete missing %s", want)
+		}
+	}
+	if strings.Contains(body, `<option value="rough-in`) {
+		t.Errorf("phase autocomplete lists the same phase twice")
+	}
+
+	app.postForm(t, http.MethodPost, "/categories/cat-kitchen/items", url.Values{
+		"type": {"material"}, "name": {"Tile"}, "quantity": {"30"}, "unit": {"sqft"}, "unit_price": {"8"}, "phase": {" Finish "},
+	})
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Tile' AND phase = 'Finish'`); got != 1 {
+		t.Errorf("new item phase not saved")
+	}
+
+	// Editing an item without a phase field keeps its phase.
+	app.postForm(t, http.MethodPut, "/items/item-kitchen-wire", url.Values{"name": {"12/2 Romex"}, "quantity": {"120"}, "unit_price": {"0.9"}})
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-kitchen-wire' AND phase = 'rough-in '`); got != 1 {
+		t.Errorf("item phase lost on edit")
+	}
+	app.postForm(t, http.MethodPut, "/items/item-kitchen-wire", url.Values{"name": {"12/2 Romex"}, "quantity": {"120"}, "unit_price": {"0.9"}, "phase": {""}})
+	if got := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-kitchen-wire' AND phase IS NULL`); got != 1 {
+		t.Errorf("item phase not cleared")
+	}
+
+	app.postForm(t, http.MethodPut, "/categories/cat-kitchen/markup", url.Values{"surcharge_percent": {""}, "phase": {"Finish"}})
+	if got := countRows(t, app, `SELECT COUNT(*) FROM categories WHERE id = 'cat-kitchen' AND phase = 'Finish'`); got != 1 {
+		t.Errorf("category phase not saved")
+	}
+}
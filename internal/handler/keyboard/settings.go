@@ -1,8 +1,13 @@
 package keyboard
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dukerupert/skalkaho/internal/middleware"
 	"github.com/dukerupert/skalkaho/internal/repository"
@@ -20,8 +25,36 @@ func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	jobFields, err := h.queries.ListJobCustomFields(ctx)
+	if err != nil {
+		logger.Error("failed to list job custom fields", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	suppliers, err := h.supplierMappings(ctx)
+	if err != nil {
+		logger.Error("failed to list supplier export mappings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	lastCleanup, err := h.queries.GetLatestCleanupRun(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("failed to get last cleanup run", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
 	data := map[string]interface{}{
-		"Settings": settings,
+		"Settings":    settings,
+		"JobFields":   jobFields,
+		"Suppliers":   suppliers,
+		"NewSupplier": newSupplierMapping(),
+		"LastCleanup": nil,
+	}
+	if err == nil {
+		data["LastCleanup"] = lastCleanup
 	}
 
 	if err := h.renderer.Render(w, "settings", data); err != nil {
@@ -39,11 +72,94 @@ func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	surchargePercent, _ := strconv.ParseFloat(r.FormValue("default_surcharge_percent"), 64)
+	surchargePercent, err := h.formPercent(r, "default_surcharge_percent", "Default markup")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
+	minimumJobTotal, err := h.formMoney(r, "minimum_job_total", "Minimum job total")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
+	mobilizationFee, err := h.formMoney(r, "mobilization_fee", "Mobilization fee")
+	if err != nil {
+		h.numberError(w, r, err)
+		return
+	}
+	if minimumJobTotal < 0 || mobilizationFee < 0 {
+		http.Error(w, "Minimum job total and mobilization fee cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+	declineStreak := settings.DeclineStreak
+	if value := r.FormValue("decline_streak"); value != "" {
+		declineStreak, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || declineStreak < 1 {
+			http.Error(w, "Declined quotes before warning must be at least 1", http.StatusBadRequest)
+			return
+		}
+	}
+	totalAlertPercent := settings.TotalAlertPercent
+	if value := r.FormValue("total_alert_percent"); value != "" {
+		totalAlertPercent, err = parsePercent(value, h.decimalSeparator())
+		if err != nil || totalAlertPercent < 0 {
+			http.Error(w, "Total change alert must be 0 or more", http.StatusBadRequest)
+			return
+		}
+	}
+	costMarginPercent := settings.CostMarginPercent
+	if value := r.FormValue("cost_margin_percent"); value != "" {
+		costMarginPercent, err = parsePercent(value, h.decimalSeparator())
+		if err != nil || costMarginPercent < 0 {
+			http.Error(w, "Margin over cost must be 0 or more", http.StatusBadRequest)
+			return
+		}
+	}
+	aiBudget := settings.AiBudget
+	if value := r.FormValue("ai_budget"); value != "" {
+		aiBudget, err = parseMoney(value, h.decimalSeparator())
+		if err != nil || aiBudget < 0 {
+			http.Error(w, "Monthly import budget must be 0 or more", http.StatusBadRequest)
+			return
+		}
+	}
+	aiBudgetUnit := settings.AiBudgetUnit
+	if value := r.FormValue("ai_budget_unit"); value != "" {
+		if value != budgetDollars && value != budgetTokens {
+			http.Error(w, "Invalid budget unit", http.StatusBadRequest)
+			return
+		}
+		aiBudgetUnit = value
+	}
+	pageSize := settings.PageSize
+	if value := r.FormValue("page_size"); value != "" {
+		pageSize, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || pageSize < minPageSize || pageSize > maxPageSize {
+			http.Error(w, fmt.Sprintf("Rows per page must be between %d and %d", minPageSize, maxPageSize), http.StatusBadRequest)
+			return
+		}
+	}
 
-	_, err := h.queries.UpdateSettings(ctx, repository.UpdateSettingsParams{
+	_, err = h.queries.UpdateSettings(ctx, repository.UpdateSettingsParams{
 		DefaultSurchargeMode:    r.FormValue("default_surcharge_mode"),
 		DefaultSurchargePercent: surchargePercent,
+		MinimumJobTotal:         minimumJobTotal,
+		MobilizationFee:         mobilizationFee,
+		PageSize:                pageSize,
+		SurchargeCredits:        r.FormValue("surcharge_credits") == "true",
+		DeclineWarning:          r.FormValue("decline_warning") == "true",
+		DeclineStreak:           declineStreak,
+		TotalAlertPercent:       totalAlertPercent,
+		CostMarginPercent:       costMarginPercent,
+		AiBudget:                aiBudget,
+		AiBudgetUnit:            aiBudgetUnit,
 	})
 	if err != nil {
 		logger.Error("failed to update settings", "error", err)
@@ -60,3 +176,135 @@ func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/settings", http.StatusSeeOther)
 }
+
+// UpdateCompanySettings updates the company details printed on quotes.
+func (h *Handler) UpdateCompanySettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.queries.UpdateCompanySettings(ctx, repository.UpdateCompanySettingsParams{
+		CompanyName:    strings.TrimSpace(r.FormValue("company_name")),
+		CompanyAddress: strings.TrimSpace(r.FormValue("company_address")),
+		CompanyPhone:   strings.TrimSpace(r.FormValue("company_phone")),
+		CompanyEmail:   strings.TrimSpace(r.FormValue("company_email")),
+	})
+	if err != nil {
+		logger.Error("failed to update company settings", "error", err)
+		http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Company details saved", "type": "success"}}`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// UpdateCleanupSettings updates the retention settings used by the background cleanup.
+func (h *Handler) UpdateCleanupSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	days := make(map[string]int64)
+	for _, field := range []string{"cleanup_empty_job_days", "cleanup_import_days", "cleanup_activity_days", "import_reminder_days", "import_stale_days"} {
+		value := strings.TrimSpace(r.FormValue(field))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Retention days %q isn't a whole number of days", value), http.StatusBadRequest)
+			return
+		}
+		if n < 0 {
+			http.Error(w, "Retention days cannot be negative", http.StatusBadRequest)
+			return
+		}
+		days[field] = n
+	}
+
+	_, err := h.queries.UpdateCleanupSettings(ctx, repository.UpdateCleanupSettingsParams{
+		CleanupEmptyJobDays: days["cleanup_empty_job_days"],
+		CleanupImportDays:   days["cleanup_import_days"],
+		CleanupActivityDays: days["cleanup_activity_days"],
+		CleanupDryRun:       r.FormValue("cleanup_dry_run") == "true",
+		ImportReminderDays:  days["import_reminder_days"],
+		ImportStaleDays:     days["import_stale_days"],
+	})
+	if err != nil {
+		logger.Error("failed to update cleanup settings", "error", err)
+		http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Cleanup settings saved", "type": "success"}}`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// RunCleanup runs the cleanup immediately and returns to the settings page,
+// which shows the results of the run.
+func (h *Handler) RunCleanup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if _, err := h.cleanup.Run(ctx, time.Now()); err != nil {
+		logger.Error("cleanup run failed", "error", err)
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/settings")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// UpdateTheme saves the color theme preference and reloads the page so it is
+// rendered with the new theme.
+func (h *Handler) UpdateTheme(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	theme := r.FormValue("theme")
+	switch theme {
+	case "light", "dark", "system":
+	default:
+		http.Error(w, "Theme must be light, dark, or system", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.UpdateTheme(ctx, theme); err != nil {
+		logger.Error("failed to update theme", "error", err)
+		http.Error(w, "Failed to update theme", http.StatusInternalServerError)
+		return
+	}
+	h.renderer.SetTheme(theme)
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+
+	back := r.Header.Get("Referer")
+	if back == "" {
+		back = "/"
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
@@ -0,0 +1,205 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedCleanupData(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name, status, created_at) VALUES
+		('old-empty', 'New Quote', 'draft', datetime('now', '-60 days')),
+		('new-empty', 'New Quote', 'draft', datetime('now', '-1 days')),
+		('old-sent', 'Sent', 'sent', datetime('now', '-60 days')),
+		('old-items', 'Garage', 'draft', datetime('now', '-60 days')),
+		('old-outline', 'Deck', 'draft', datetime('now', '-60 days'))`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'old-outline', 'General'), ('cat-2', 'old-items', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES ('item-1', 'cat-2', 'material', '2x4x8', 1, 'ea', 3.5)`)
+	app.exec(t, `UPDATE jobs SET updated_at = created_at`)
+	app.exec(t, `INSERT INTO price_imports (id, filename, status, created_at) VALUES
+		('imp-old', 'old.xlsx', 'ready', datetime('now', '-60 days')),
+		('imp-failed', 'failed.xlsx', 'failed', datetime('now', '-60 days')),
+		('imp-stale', 'stale.xlsx', 'stale', datetime('now', '-60 days')),
+		('imp-applied', 'applied.xlsx', 'applied', datetime('now', '-60 days')),
+		('imp-new', 'new.xlsx', 'ready', datetime('now'))`)
+	app.exec(t, `INSERT INTO job_activity (job_id, action, detail, created_at) VALUES
+		('old-items', 'price_adjust', 'old', datetime('now', '-400 days')),
+		('old-items', 'price_adjust', 'new', datetime('now'))`)
+}
+
+func countRows(t *testing.T, app *testApp, query string) int {
+	t.Helper()
+	var n int
+	if err := app.db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("query %q: %v", query, err)
+	}
+	return n
+}
+
+func TestRunCleanup(t *testing.T) {
+	app := newTestApp(t)
+	seedCleanupData(t, app)
+
+	rec := app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_empty_job_days": {"30"},
+		"cleanup_import_days":    {"30"},
+		"cleanup_activity_days":  {"365"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("save status = %d, want 303", rec.Code)
+	}
+
+	rec = app.postForm(t, http.MethodPost, "/settings/cleanup/run", nil)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("run status = %d, want 303", rec.Code)
+	}
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs`); n != 4 {
+		t.Errorf("jobs = %d, want 4", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs WHERE id = 'old-empty'`); n != 0 {
+		t.Errorf("old empty draft was kept")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs WHERE id = 'old-outline'`); n != 1 {
+		t.Errorf("draft with categories was deleted")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports`); n != 4 {
+		t.Errorf("price imports = %d, want 4", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports WHERE id = 'imp-failed'`); n != 0 {
+		t.Errorf("old failed import was kept")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM job_activity`); n != 1 {
+		t.Errorf("activity entries = %d, want 1", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM cleanup_runs WHERE vacuumed = 1 AND empty_jobs = 1 AND stale_imports = 1 AND activity_entries = 1`); n != 1 {
+		t.Errorf("cleanup run not recorded")
+	}
+
+	body := app.get(t, "/settings").Body.String()
+	if !strings.Contains(body, "1 empty quotes, 1 price imports, 1 activity entries") {
+		t.Errorf("settings page missing last run stats: %s", body)
+	}
+}
+
+func TestRunCleanup_DryRun(t *testing.T) {
+	app := newTestApp(t)
+	seedCleanupData(t, app)
+
+	app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_empty_job_days": {"30"},
+		"cleanup_import_days":    {"30"},
+		"cleanup_activity_days":  {"365"},
+		"cleanup_dry_run":        {"true"},
+	})
+	app.postForm(t, http.MethodPost, "/settings/cleanup/run", nil)
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs`); n != 5 {
+		t.Errorf("jobs = %d, want 5", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM price_imports`); n != 5 {
+		t.Errorf("price imports = %d, want 5", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM cleanup_runs WHERE dry_run = 1 AND vacuumed = 0 AND empty_jobs = 1 AND stale_imports = 1`); n != 1 {
+		t.Errorf("dry run not recorded")
+	}
+
+	body := app.get(t, "/settings").Body.String()
+	if !strings.Contains(body, "(dry run)") || !strings.Contains(body, "Would remove") {
+		t.Errorf("settings page missing dry run stats")
+	}
+}
+
+func TestRunCleanup_KeepsRecentlyEditedDraft(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name, status, created_at) VALUES
+		('edited', 'Basement', 'draft', datetime('now', '-31 days')),
+		('renamed', 'Porch', 'draft', datetime('now', '-31 days'))`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'edited', 'Demo')`)
+	app.exec(t, `UPDATE jobs SET updated_at = datetime('now', '-1 days')`)
+
+	app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_empty_job_days": {"30"},
+		"cleanup_import_days":    {"30"},
+		"cleanup_activity_days":  {"365"},
+	})
+	app.postForm(t, http.MethodPost, "/settings/cleanup/run", nil)
+
+	if n := countRows(t, app, `SELECT COUNT(*) FROM jobs`); n != 2 {
+		t.Errorf("jobs = %d, want both recently edited drafts kept", n)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM categories WHERE id = 'cat-1'`); n != 1 {
+		t.Errorf("category of kept draft was deleted")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM cleanup_runs WHERE empty_jobs = 0`); n != 1 {
+		t.Errorf("cleanup run counted edited drafts as empty")
+	}
+}
+
+func TestUpdateCleanupSettings_InvalidDays(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.postForm(t, http.MethodPut, "/settings/cleanup", url.Values{
+		"cleanup_empty_job_days": {"0"},
+		"cleanup_import_days":    {"30d"},
+		"cleanup_activity_days":  {"365"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT cleanup_import_days FROM settings`); n != 30 {
+		t.Errorf("cleanup_import_days = %d, want 30 left alone", n)
+	}
+}
+
+func TestUpdateTheme(t *testing.T) {
+	app := newTestApp(t)
+
+	if body := app.get(t, "/settings").Body.String(); !strings.Contains(body, `<html lang="en" class="system">`) {
+		t.Fatalf("default theme not rendered on <html>")
+	}
+
+	rec := app.postForm(t, http.MethodPost, "/preferences/theme", url.Values{"theme": {"dark"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	body := app.get(t, "/").Body.String()
+	if !strings.Contains(body, `<html lang="en" class="dark">`) {
+		t.Errorf("dark theme not rendered on <html>")
+	}
+	if !strings.Contains(body, `hx-vals='{"theme": "system"}'`) {
+		t.Errorf("toggle does not offer the next theme")
+	}
+
+	var theme string
+	if err := app.db.QueryRow(`SELECT theme FROM settings`).Scan(&theme); err != nil || theme != "dark" {
+		t.Errorf("saved theme = %q, err = %v, want dark", theme, err)
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/preferences/theme", url.Values{"theme": {"neon"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid theme status = %d, want 400", rec.Code)
+	}
+}
+
+func TestUpdateCompanySettings(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.postForm(t, http.MethodPut, "/settings/company", url.Values{
+		"company_name":  {"  Rupert Construction "},
+		"company_email": {"office@example.com"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rec.Code)
+	}
+
+	var name, email string
+	if err := app.db.QueryRow(`SELECT company_name, company_email FROM settings`).Scan(&name, &email); err != nil {
+		t.Fatalf("reading settings: %v", err)
+	}
+	if name != "Rupert Construction" || email != "office@example.com" {
+		t.Errorf("saved company = %q, %q", name, email)
+	}
+}
@@ -0,0 +1,396 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+	"github.com/dukerupert/skalkaho/internal/service/excel"
+)
+
+// supplierExportFields are the order list fields a supplier mapping can
+// place, in the order the settings form shows them, with their default
+// headers.
+var supplierExportFields = []struct {
+	Field  string
+	Header string
+}{
+	{excel.OrderFieldSKU, "SKU"},
+	{excel.OrderFieldDescription, "Description"},
+	{excel.OrderFieldQuantity, "Qty"},
+	{excel.OrderFieldUnit, "Unit"},
+}
+
+// SupplierMapping is a supplier export mapping with a form input for every
+// field it can place.
+type SupplierMapping struct {
+	ID     int64
+	Name   string
+	Fields []SupplierFieldInput
+}
+
+// SupplierFieldInput is one field of the mapping form. A blank Position
+// leaves the field out of the export.
+type SupplierFieldInput struct {
+	Field    string
+	Position string
+	Header   string
+}
+
+// Summary lists the mapping's headers in column order.
+func (m SupplierMapping) Summary() string {
+	columns := m.columns()
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+	}
+	return strings.Join(headers, ", ")
+}
+
+// columns returns the placed fields in column order.
+func (m SupplierMapping) columns() []excel.OrderColumn {
+	type placed struct {
+		position int
+		column   excel.OrderColumn
+	}
+	var fields []placed
+	for _, f := range m.Fields {
+		position, err := strconv.Atoi(f.Position)
+		if err != nil {
+			continue
+		}
+		fields = append(fields, placed{position, excel.OrderColumn{Field: f.Field, Header: f.Header}})
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].position < fields[j].position })
+
+	columns := make([]excel.OrderColumn, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	return columns
+}
+
+// newSupplierMapping returns the form for a new mapping, with every field
+// placed in its default column.
+func newSupplierMapping() SupplierMapping {
+	mapping := SupplierMapping{}
+	for i, f := range supplierExportFields {
+		mapping.Fields = append(mapping.Fields, SupplierFieldInput{
+			Field:    f.Field,
+			Position: strconv.Itoa(i + 1),
+			Header:   f.Header,
+		})
+	}
+	return mapping
+}
+
+// supplierMappings loads every export mapping with its columns.
+func (h *Handler) supplierMappings(ctx context.Context) ([]SupplierMapping, error) {
+	rows, err := h.queries.ListSupplierExportMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]SupplierMapping, len(rows))
+	for i, row := range rows {
+		if mappings[i], err = h.supplierMapping(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+// supplierMapping fills in the form inputs for a stored mapping.
+func (h *Handler) supplierMapping(ctx context.Context, row repository.SupplierExportMapping) (SupplierMapping, error) {
+	columns, err := h.queries.ListSupplierExportColumns(ctx, row.ID)
+	if err != nil {
+		return SupplierMapping{}, err
+	}
+	byField := make(map[string]repository.SupplierExportColumn, len(columns))
+	for _, col := range columns {
+		byField[col.Field] = col
+	}
+
+	mapping := SupplierMapping{ID: row.ID, Name: row.Name}
+	for _, f := range supplierExportFields {
+		input := SupplierFieldInput{Field: f.Field, Header: f.Header}
+		if col, ok := byField[f.Field]; ok {
+			input.Position = strconv.FormatInt(col.Position, 10)
+			input.Header = col.Header
+		}
+		mapping.Fields = append(mapping.Fields, input)
+	}
+	return mapping, nil
+}
+
+// formSupplierMapping reads and validates a submitted mapping form. It
+// returns a description of the first problem, or "" if the form is valid.
+func formSupplierMapping(r *http.Request) (SupplierMapping, string) {
+	mapping := SupplierMapping{Name: strings.TrimSpace(r.FormValue("name"))}
+	if mapping.Name == "" {
+		return mapping, "Supplier name is required"
+	}
+
+	used := make(map[int]bool)
+	placed := make(map[string]bool)
+	for _, f := range supplierExportFields {
+		input := SupplierFieldInput{
+			Field:    f.Field,
+			Position: strings.TrimSpace(r.FormValue(f.Field + "_position")),
+			Header:   strings.TrimSpace(r.FormValue(f.Field + "_header")),
+		}
+		if input.Header == "" {
+			input.Header = f.Header
+		}
+		if input.Position != "" {
+			position, err := strconv.Atoi(input.Position)
+			if err != nil || position < 1 {
+				return mapping, f.Header + " column must be a whole number of 1 or more"
+			}
+			if used[position] {
+				return mapping, "Two fields can't share column " + input.Position
+			}
+			used[position] = true
+			placed[f.Field] = true
+		}
+		mapping.Fields = append(mapping.Fields, input)
+	}
+
+	if !placed[excel.OrderFieldQuantity] {
+		return mapping, "The quantity must have a column"
+	}
+	if !placed[excel.OrderFieldSKU] && !placed[excel.OrderFieldDescription] {
+		return mapping, "Either the SKU or the description must have a column"
+	}
+	return mapping, ""
+}
+
+// saveSupplierColumns replaces a mapping's columns, numbering them from 1 in
+// the submitted order so gaps in the form's positions don't leave blank
+// columns in the export.
+func saveSupplierColumns(ctx context.Context, q *repository.Queries, id int64, mapping SupplierMapping) error {
+	if err := q.DeleteSupplierExportColumns(ctx, id); err != nil {
+		return err
+	}
+	for i, col := range mapping.columns() {
+		if err := q.CreateSupplierExportColumn(ctx, repository.CreateSupplierExportColumnParams{
+			MappingID: id,
+			Field:     col.Field,
+			Position:  int64(i + 1),
+			Header:    col.Header,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSupplierExportMapping adds a supplier's order template layout.
+func (h *Handler) CreateSupplierExportMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	mapping, problem := formSupplierMapping(r)
+	if problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to create supplier", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+	created, err := qtx.CreateSupplierExportMapping(ctx, mapping.Name)
+	if err == nil {
+		err = saveSupplierColumns(ctx, qtx, created.ID, mapping)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		logger.Error("failed to create supplier export mapping", "error", err)
+		http.Error(w, "Failed to create supplier", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// UpdateSupplierExportMapping renames a supplier and replaces its columns.
+func (h *Handler) UpdateSupplierExportMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	mapping, problem := formSupplierMapping(r)
+	if problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to update supplier", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := h.queries.WithTx(tx)
+	n, err := qtx.UpdateSupplierExportMapping(ctx, repository.UpdateSupplierExportMappingParams{
+		Name: mapping.Name,
+		ID:   id,
+	})
+	if err == nil && n == 0 {
+		http.Error(w, "Supplier not found", http.StatusNotFound)
+		return
+	}
+	if err == nil {
+		err = saveSupplierColumns(ctx, qtx, id, mapping)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		logger.Error("failed to update supplier export mapping", "error", err)
+		http.Error(w, "Failed to update supplier", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// DeleteSupplierExportMapping removes a supplier's order template layout.
+func (h *Handler) DeleteSupplierExportMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.queries.DeleteSupplierExportMapping(ctx, id)
+	if err != nil {
+		logger.Error("failed to delete supplier export mapping", "error", err)
+		http.Error(w, "Failed to delete supplier", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Supplier not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// ExportOrderList downloads a job's order list laid out in a supplier's
+// order template.
+func (h *Handler) ExportOrderList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	supplierID, err := strconv.ParseInt(r.URL.Query().Get("supplier"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	row, err := h.queries.GetSupplierExportMapping(ctx, supplierID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Supplier not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get supplier export mapping", "error", err)
+		http.Error(w, "Failed to load supplier", http.StatusInternalServerError)
+		return
+	}
+	mapping, err := h.supplierMapping(ctx, row)
+	if err != nil {
+		logger.Error("failed to list supplier export columns", "error", err)
+		http.Error(w, "Failed to load supplier", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := h.queries.ListOrderItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load items", http.StatusInternalServerError)
+		return
+	}
+
+	order := excel.SupplierOrder{
+		Supplier: mapping.Name,
+		JobName:  job.Name,
+		Columns:  mapping.columns(),
+	}
+	for _, item := range orderListItems(rows) {
+		order.Items = append(order.Items, excel.OrderItem{
+			SKU:         item.SKU,
+			Description: item.Name,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+		})
+	}
+
+	filename := strings.Trim(exportFilenameUnsafe.ReplaceAllString(job.Name+"-"+mapping.Name, "-"), "-")
+	if filename == "" {
+		filename = "order"
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.xlsx"`)
+	if err := excel.WriteSupplierOrder(w, order); err != nil {
+		logger.Error("failed to write supplier order", "error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// seedSupplierOrderJob builds a job whose studs come from templates with a
+// SKU, split over two lines, and whose screws have no SKU.
+func seedSupplierOrderJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price, sku) VALUES
+		(9201, 'material', 'Lumber', '2x4x8', 'ea', 4.10, 'LBR-248'),
+		(9202, 'material', 'Fasteners', 'Deck screws', 'box', 30, NULL)`)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-order', 'Deck Build')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-order', 'job-order', 'Framing')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, template_id) VALUES
+		('item-studs-1', 'cat-order', 'material', '2x4x8', 30, 'ea', 4.10, 9201),
+		('item-studs-2', 'cat-order', 'material', '2x4x8', 12, 'ea', 4.10, 9201),
+		('item-screws', 'cat-order', 'material', 'Deck screws', 2, 'box', 30, 9202),
+		('item-labor', 'cat-order', 'labor', 'Framer', 16, 'hr', 50, NULL)`)
+}
+
+func TestSupplierExportMappings_CRUD(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.postForm(t, http.MethodPost, "/settings/suppliers", url.Values{
+		"name":                 {"Valley Lumber"},
+		"sku_position":         {"1"},
+		"sku_header":           {"Item #"},
+		"description_position": {"3"},
+		"description_header":   {""},
+		"quantity_position":    {"2"},
+		"quantity_header":      {"Order Qty"},
+		"unit_position":        {""},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM supplier_export_columns c
+		JOIN supplier_export_mappings m ON m.id = c.mapping_id WHERE m.name = 'Valley Lumber'`); n != 3 {
+		t.Fatalf("columns = %d, want 3", n)
+	}
+
+	body := app.get(t, "/settings").Body.String()
+	if !strings.Contains(body, "Item #, Order Qty, Description") {
+		t.Errorf("settings missing mapping summary")
+	}
+
+	// Gaps in the positions are closed up when saved.
+	app.postForm(t, http.MethodPut, "/settings/suppliers/1", url.Values{
+		"name":                 {"Valley Lumber Co"},
+		"description_position": {"5"},
+		"quantity_position":    {"9"},
+		"unit_position":        {"7"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM supplier_export_columns WHERE mapping_id = 1 AND
+		((field = 'description' AND position = 1) OR (field = 'unit' AND position = 2) OR (field = 'quantity' AND position = 3))`); n != 3 {
+		t.Errorf("columns not renumbered on update")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM supplier_export_columns WHERE mapping_id = 1 AND field = 'sku'`); n != 0 {
+		t.Errorf("sku column kept after it was left blank")
+	}
+
+	invalid := []url.Values{
+		{"name": {""}, "quantity_position": {"1"}, "description_position": {"2"}},
+		{"name": {"X"}, "description_position": {"1"}},
+		{"name": {"X"}, "quantity_position": {"1"}},
+		{"name": {"X"}, "quantity_position": {"1"}, "description_position": {"1"}},
+		{"name": {"X"}, "quantity_position": {"0"}, "description_position": {"1"}},
+	}
+	for _, form := range invalid {
+		if rec := app.postForm(t, http.MethodPost, "/settings/suppliers", form); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", form, rec.Code)
+		}
+	}
+
+	if rec := app.postForm(t, http.MethodDelete, "/settings/suppliers/1", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("delete status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM supplier_export_columns`); n != 0 {
+		t.Errorf("columns left after deleting the mapping")
+	}
+	if rec := app.postForm(t, http.MethodDelete, "/settings/suppliers/1", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+}
+
+func TestExportOrderList(t *testing.T) {
+	app := newTestApp(t)
+	seedSupplierOrderJob(t, app)
+	app.exec(t, `INSERT INTO supplier_export_mappings (id, name) VALUES (1, 'Valley Lumber'), (2, 'Hardware Store')`)
+	app.exec(t, `INSERT INTO supplier_export_columns (mapping_id, field, position, header) VALUES
+		(1, 'sku', 1, 'Item #'), (1, 'description', 2, 'Desc'), (1, 'quantity', 3, 'Qty'), (1, 'unit', 4, 'UOM'),
+		(2, 'quantity', 1, 'Qty'), (2, 'description', 2, 'Item')`)
+
+	body := app.get(t, "/jobs/job-order/order-list").Body.String()
+	for _, want := range []string{`href="/jobs/job-order/order-list.xlsx?supplier=1"`, "LBR-248"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("order list missing %q", want)
+		}
+	}
+
+	rec := app.get(t, "/jobs/job-order/order-list.xlsx?supplier=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	f, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+
+	if sheets := strings.Join(f.GetSheetList(), "|"); sheets != "Cover|Order" {
+		t.Fatalf("sheets = %q, want Cover|Order", sheets)
+	}
+	rows, _ := f.GetRows("Order")
+	want := [][]string{{"Item #", "Desc", "Qty", "UOM"}, {"LBR-248", "2x4x8", "42", "ea"}}
+	if len(rows) != len(want) || strings.Join(rows[0], "|") != strings.Join(want[0], "|") || strings.Join(rows[1], "|") != strings.Join(want[1], "|") {
+		t.Errorf("order rows = %q, want %q", rows, want)
+	}
+	cover, _ := f.GetRows("Cover")
+	if !strings.Contains(strings.Join(flatten(cover), "|"), "Deck screws|2|box") {
+		t.Errorf("cover sheet missing item without SKU: %q", cover)
+	}
+
+	// Without a SKU column every item goes on the order sheet.
+	rec = app.get(t, "/jobs/job-order/order-list.xlsx?supplier=2")
+	f2, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f2.Close()
+	if sheets := strings.Join(f2.GetSheetList(), "|"); sheets != "Order" {
+		t.Errorf("sheets = %q, want Order", sheets)
+	}
+	rows, _ = f2.GetRows("Order")
+	if len(rows) != 3 || strings.Join(rows[0], "|") != "Qty|Item" || strings.Join(rows[2], "|") != "2|Deck screws" {
+		t.Errorf("order rows = %q", rows)
+	}
+
+	if rec := app.get(t, "/jobs/job-order/order-list.xlsx?supplier=99"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown supplier status = %d, want 404", rec.Code)
+	}
+}
+
+func TestUpdateItemTemplate_SKU(t *testing.T) {
+	app := newTestApp(t)
+	seedSupplierOrderJob(t, app)
+
+	app.postForm(t, http.MethodPut, "/item-templates/9202", url.Values{
+		"type": {"material"}, "category": {"Fasteners"}, "name": {"Deck screws"},
+		"default_unit": {"box"}, "default_price": {"30"}, "sku": {" FST-DS3 "},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9202 AND sku = 'FST-DS3'`); n != 1 {
+		t.Errorf("sku not saved")
+	}
+
+	// A form without the field keeps the SKU.
+	app.postForm(t, http.MethodPut, "/item-templates/9202", url.Values{
+		"type": {"material"}, "category": {"Fasteners"}, "name": {"Deck screws"},
+		"default_unit": {"box"}, "default_price": {"31"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM item_templates WHERE id = 9202 AND sku = 'FST-DS3'`); n != 1 {
+		t.Errorf("sku cleared by a form without it")
+	}
+}
+
+func flatten(rows [][]string) []string {
+	var cells []string
+	for _, row := range rows {
+		cells = append(cells, row...)
+	}
+	return cells
+}
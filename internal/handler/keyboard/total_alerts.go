@@ -0,0 +1,373 @@
+package keyboard
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// Line item edits recorded by a total change alert.
+const (
+	totalAlertCreate = "create"
+	totalAlertUpdate = "update"
+	totalAlertDelete = "delete"
+)
+
+// TotalAlert describes a single line item edit that moved a job's grand total
+// by more than the configured percentage.
+type TotalAlert struct {
+	ID       int64
+	ItemName string
+	Change   string // signed percentage, such as "+412%"
+	OldTotal float64
+	NewTotal float64
+}
+
+// totalWatch holds a job's grand total from before a line item edit.
+type totalWatch struct {
+	jobID   string
+	before  float64
+	percent float64
+}
+
+// jobGrandTotal totals a job the same way the job page does.
+func (h *Handler) jobGrandTotal(ctx context.Context, jobID string) (float64, error) {
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return h.calculateTotals(job, settings, categories, lineItems).GrandTotal, nil
+}
+
+// watchTotal records the grand total of the category's job before an edit.
+// It returns nil when the alert is turned off.
+func (h *Handler) watchTotal(ctx context.Context, categoryID string) (*totalWatch, error) {
+	category, err := h.queries.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return h.watchJobTotal(ctx, category.JobID)
+}
+
+// watchJobTotal is watchTotal for an edit that may not have a category yet.
+func (h *Handler) watchJobTotal(ctx context.Context, jobID string) (*totalWatch, error) {
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.TotalAlertPercent <= 0 {
+		return nil, nil
+	}
+	before, err := h.jobGrandTotal(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &totalWatch{jobID: jobID, before: before, percent: settings.TotalAlertPercent}, nil
+}
+
+// checkTotal compares the job's grand total after an edit with the one
+// recorded by watchTotal and raises an alert when it moved by more than the
+// threshold. previous is the item before the edit, or nil when it was
+// created; the item as the edit left it is saved too, so an undo can tell
+// whether it has changed since. Any earlier alert on the job is dismissed,
+// since only the latest edit can be undone on its own.
+func (h *Handler) checkTotal(ctx context.Context, watch *totalWatch, action, itemID string, previous *repository.LineItem) error {
+	if watch == nil {
+		return nil
+	}
+	after, err := h.jobGrandTotal(ctx, watch.jobID)
+	if err != nil {
+		return err
+	}
+	if err := h.queries.DismissOpenTotalChangeAlerts(ctx, watch.jobID); err != nil {
+		return err
+	}
+	if !sharpChange(watch.before, after, watch.percent) {
+		return nil
+	}
+
+	stored, err := lineItemJSON(previous)
+	if err != nil {
+		return err
+	}
+	var edited *repository.LineItem
+	if action != totalAlertDelete {
+		item, err := h.queries.GetLineItem(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		edited = &item
+	}
+	result, err := lineItemJSON(edited)
+	if err != nil {
+		return err
+	}
+	_, err = h.queries.CreateTotalChangeAlert(ctx, repository.CreateTotalChangeAlertParams{
+		JobID:      watch.jobID,
+		LineItemID: itemID,
+		Action:     action,
+		Previous:   stored,
+		OldTotal:   watch.before,
+		NewTotal:   after,
+		Result:     result,
+	})
+	return err
+}
+
+// lineItemJSON encodes an item for a total change alert; nil is NULL.
+func lineItemJSON(item *repository.LineItem) (sql.NullString, error) {
+	if item == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unchangedSinceAlert reports whether the alert's item is still as the edit
+// left it, so undoing the edit won't overwrite or resurrect later changes.
+// Alerts saved before results were recorded are taken as unchanged.
+func unchangedSinceAlert(ctx context.Context, q *repository.Queries, alert repository.TotalChangeAlert) (bool, error) {
+	current, err := q.GetLineItem(ctx, alert.LineItemID)
+	if err == sql.ErrNoRows {
+		return alert.Action == totalAlertDelete, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if alert.Action == totalAlertDelete {
+		return false, nil
+	}
+	if !alert.Result.Valid {
+		return true, nil
+	}
+	var result repository.LineItem
+	if err := json.Unmarshal([]byte(alert.Result.String), &result); err != nil {
+		return false, err
+	}
+	return current == result, nil
+}
+
+// sharpChange reports whether a total moving from before to after crosses the
+// percent threshold. A drop is measured the same way as the matching jump, so
+// 200% catches a total that triples or falls to a third. A job with nothing on
+// it yet has no total to compare against.
+func sharpChange(before, after, percent float64) bool {
+	if before <= 0 {
+		return false
+	}
+	if after <= 0 {
+		return true
+	}
+	return (math.Max(before, after)/math.Min(before, after)-1)*100 > percent
+}
+
+// openTotalAlert returns the job's open total change alert, or nil if it has
+// none.
+func (h *Handler) openTotalAlert(ctx context.Context, jobID string) (*TotalAlert, error) {
+	alert, err := h.queries.GetOpenTotalChangeAlert(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var name string
+	if alert.Previous.Valid {
+		var previous repository.LineItem
+		if err := json.Unmarshal([]byte(alert.Previous.String), &previous); err != nil {
+			return nil, err
+		}
+		name = previous.Name
+	} else if item, err := h.queries.GetLineItem(ctx, alert.LineItemID); err == nil {
+		name = item.Name
+	}
+
+	return &TotalAlert{
+		ID:       alert.ID,
+		ItemName: name,
+		Change:   fmt.Sprintf("%+.0f%%", (alert.NewTotal-alert.OldTotal)/alert.OldTotal*100),
+		OldTotal: alert.OldTotal,
+		NewTotal: alert.NewTotal,
+	}, nil
+}
+
+// UndoTotalChange reverts the single line item edit behind a total change
+// alert: a created item is removed, an updated one gets its previous values
+// back, and a deleted one is restored. The alert is closed in the same
+// transaction, so it stays open when the revert fails.
+func (h *Handler) UndoTotalChange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		http.Error(w, "Failed to undo change", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	qtx := h.queries.WithTx(tx)
+
+	alert, ok := h.claimTotalAlert(w, r, qtx, "undone")
+	if !ok {
+		return
+	}
+
+	unchanged, err := unchangedSinceAlert(ctx, qtx, alert)
+	if err != nil {
+		logger.Error("failed to compare line item with total change alert", "error", err)
+		http.Error(w, "Failed to undo change", http.StatusInternalServerError)
+		return
+	}
+	if !unchanged {
+		http.Error(w, "The item has changed since; undo the edit by hand", http.StatusConflict)
+		return
+	}
+
+	var previous repository.LineItem
+	if alert.Previous.Valid {
+		if err := json.Unmarshal([]byte(alert.Previous.String), &previous); err != nil {
+			logger.Error("failed to decode previous line item", "error", err)
+			http.Error(w, "Failed to undo change", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	switch alert.Action {
+	case totalAlertCreate:
+		err = qtx.DeleteLineItem(ctx, alert.LineItemID)
+	case totalAlertUpdate:
+		_, err = qtx.UpdateLineItem(ctx, repository.UpdateLineItemParams{
+			ID:                  previous.ID,
+			Type:                previous.Type,
+			Name:                previous.Name,
+			Description:         previous.Description,
+			Quantity:            previous.Quantity,
+			Unit:                previous.Unit,
+			UnitPrice:           previous.UnitPrice,
+			SurchargePercent:    previous.SurchargePercent,
+			SortOrder:           previous.SortOrder,
+			ExemptFromSurcharge: previous.ExemptFromSurcharge,
+			IsCredit:            previous.IsCredit,
+			TaxTreatment:        previous.TaxTreatment,
+			Phase:               previous.Phase,
+		})
+	case totalAlertDelete:
+		if _, err = qtx.GetCategory(ctx, previous.CategoryID); err == nil {
+			_, err = qtx.CreateLineItem(ctx, repository.CreateLineItemParams{
+				ID:                  previous.ID,
+				CategoryID:          previous.CategoryID,
+				Type:                previous.Type,
+				Name:                previous.Name,
+				Description:         previous.Description,
+				Quantity:            previous.Quantity,
+				Unit:                previous.Unit,
+				UnitPrice:           previous.UnitPrice,
+				SurchargePercent:    previous.SurchargePercent,
+				SortOrder:           previous.SortOrder,
+				ExemptFromSurcharge: previous.ExemptFromSurcharge,
+				TemplateID:          previous.TemplateID,
+				IsCredit:            previous.IsCredit,
+				TaxTreatment:        previous.TaxTreatment,
+				Phase:               previous.Phase,
+			})
+		}
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "The item has since been removed", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logger.Error("failed to undo total change", "error", err)
+		http.Error(w, "Failed to undo change", http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("failed to commit transaction", "error", err)
+		http.Error(w, "Failed to undo change", http.StatusInternalServerError)
+		return
+	}
+
+	h.redirectAfterTotalAlert(w, r, alert.JobID)
+}
+
+// DismissTotalChange hides a total change alert, keeping the edit.
+func (h *Handler) DismissTotalChange(w http.ResponseWriter, r *http.Request) {
+	alert, ok := h.claimTotalAlert(w, r, h.queries, "dismissed")
+	if !ok {
+		return
+	}
+	h.redirectAfterTotalAlert(w, r, alert.JobID)
+}
+
+// claimTotalAlert closes the open alert named in the path with status, so it
+// is acted on once. It writes the error response and returns false when the
+// alert is missing or already closed.
+func (h *Handler) claimTotalAlert(w http.ResponseWriter, r *http.Request, q *repository.Queries, status string) (repository.TotalChangeAlert, bool) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Alert not found", http.StatusNotFound)
+		return repository.TotalChangeAlert{}, false
+	}
+	alert, err := q.GetTotalChangeAlert(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Alert not found", http.StatusNotFound)
+			return alert, false
+		}
+		logger.Error("failed to get total change alert", "error", err)
+		http.Error(w, "Failed to load alert", http.StatusInternalServerError)
+		return alert, false
+	}
+
+	n, err := q.UpdateTotalChangeAlertStatus(ctx, repository.UpdateTotalChangeAlertStatusParams{
+		Status: status,
+		ID:     id,
+	})
+	if err != nil {
+		logger.Error("failed to update total change alert", "error", err)
+		http.Error(w, "Failed to update alert", http.StatusInternalServerError)
+		return alert, false
+	}
+	if n == 0 {
+		http.Error(w, "This change was already undone or dismissed", http.StatusConflict)
+		return alert, false
+	}
+	return alert, true
+}
+
+// redirectAfterTotalAlert reloads the page the alert was shown on, or sends
+// plain form posts to the job.
+func (h *Handler) redirectAfterTotalAlert(w http.ResponseWriter, r *http.Request, jobID string) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/jobs/"+jobID, http.StatusSeeOther)
+}
@@ -0,0 +1,229 @@
+package keyboard_test
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func seedTotalAlertJob(t *testing.T, app *testApp) {
+	t.Helper()
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-alert', 'Fence')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-alert', 'job-alert', 'Posts')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-posts', 'cat-alert', 'material', 'Cedar post', 100, 'ea', 10),
+		('item-gate', 'cat-alert', 'material', 'Gate', 1, 'ea', 50)`)
+}
+
+func TestTotalChangeAlert_UndoUpdate(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+
+	body := html.UnescapeString(app.get(t, "/categories/cat-alert").Body.String())
+	if !strings.Contains(body, "Total changed by +857% — was $1050.00, now $10050.00 after editing Cedar post") {
+		t.Fatalf("category page missing total alert")
+	}
+	if body := app.get(t, "/jobs/job-alert").Body.String(); !strings.Contains(body, `hx-post="/total-alerts/1/undo"`) {
+		t.Errorf("job page missing undo button")
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("undo status = %d, want 303", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-posts' AND quantity = 100`); n != 1 {
+		t.Errorf("quantity not restored")
+	}
+	if body := app.get(t, "/categories/cat-alert").Body.String(); strings.Contains(body, "total-alert") {
+		t.Errorf("alert still shown after undo")
+	}
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusConflict {
+		t.Errorf("second undo status = %d, want 409", rec.Code)
+	}
+}
+
+func TestTotalChangeAlert_UndoDeleteAndCreate(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	// Deleting the posts drops the total to a twentieth.
+	app.postForm(t, http.MethodDelete, "/items/item-posts", nil)
+	body := html.UnescapeString(app.get(t, "/jobs/job-alert").Body.String())
+	if !strings.Contains(body, "Total changed by -95% — was $1050.00, now $50.00") {
+		t.Errorf("job page missing total alert for a drop")
+	}
+	app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-posts' AND quantity = 100 AND unit_price = 10`); n != 1 {
+		t.Errorf("deleted item not restored")
+	}
+
+	app.postForm(t, http.MethodPost, "/categories/cat-alert/items", url.Values{
+		"type": {"material"}, "name": {"Concrete"}, "quantity": {"1"}, "unit": {"yd"}, "unit_price": {"50000"},
+	})
+	app.postForm(t, http.MethodPost, "/total-alerts/2/undo", nil)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Concrete'`); n != 0 {
+		t.Errorf("created item not removed")
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items`); n != 2 {
+		t.Errorf("undo touched other items")
+	}
+}
+
+func TestTotalChangeAlert_Threshold(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	// A small edit raises nothing.
+	app.postForm(t, http.MethodPut, "/items/item-gate", url.Values{
+		"name": {"Gate"}, "quantity": {"2"}, "unit": {"ea"}, "unit_price": {"50"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts`); n != 0 {
+		t.Errorf("alert raised for a small edit")
+	}
+
+	// A later edit supersedes an open alert.
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	app.postForm(t, http.MethodPut, "/items/item-gate", url.Values{
+		"name": {"Gate"}, "quantity": {"3"}, "unit": {"ea"}, "unit_price": {"50"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE status = 'open'`); n != 0 {
+		t.Errorf("earlier alert left open")
+	}
+
+	if rec := app.postForm(t, http.MethodPut, "/settings", url.Values{
+		"default_surcharge_mode": {"stacking"}, "total_alert_percent": {"-1"},
+	}); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative threshold status = %d, want 400", rec.Code)
+	}
+	app.postForm(t, http.MethodPut, "/settings", url.Values{
+		"default_surcharge_mode": {"stacking"}, "total_alert_percent": {"0"},
+	})
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts`); n != 1 {
+		t.Errorf("alert raised with the threshold off")
+	}
+}
+
+func TestTotalChangeAlert_UndoFailureKeepsAlert(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	app.exec(t, `DELETE FROM line_items WHERE id = 'item-posts'`)
+
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusConflict {
+		t.Fatalf("undo status = %d, want 409", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE id = 1 AND status = 'open'`); n != 1 {
+		t.Errorf("alert closed by a failed undo")
+	}
+}
+
+func TestTotalChangeAlert_OtherEdits(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+	app.exec(t, `UPDATE settings SET mobilization_fee = 20000`)
+	app.exec(t, `INSERT INTO item_templates (id, type, category, name, default_unit, default_price) VALUES
+		(9501, 'material', 'Fencing', 'Gate opener', 'ea', 5000)`)
+
+	app.do(quickAddRequest(url.Values{
+		"category_id": {"cat-alert"}, "type": {"material"}, "name": {"Concrete"},
+		"quantity": {"1"}, "unit_price": {"50000"},
+	}))
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE action = 'create' AND status = 'open'`); n != 1 {
+		t.Fatalf("quick add raised no alert")
+	}
+	app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil)
+
+	app.postForm(t, http.MethodPost, "/jobs/job-alert/mobilization", url.Values{})
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE id = 2 AND action = 'create'`); n != 1 {
+		t.Fatalf("mobilization fee raised no alert")
+	}
+	app.postForm(t, http.MethodPost, "/total-alerts/2/undo", nil)
+
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, template_id) VALUES
+		('item-opener', 'cat-alert', 'material', 'Gate opener', 1, 'ea', 10, 9501)`)
+	app.postForm(t, http.MethodPost, "/items/item-opener/refresh-price", nil)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE id = 3 AND action = 'update'`); n != 1 {
+		t.Fatalf("price refresh raised no alert")
+	}
+	app.postForm(t, http.MethodPost, "/total-alerts/3/undo", nil)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-opener' AND unit_price = 10`); n != 1 {
+		t.Fatalf("refreshed price not undone")
+	}
+
+	app.postForm(t, http.MethodPost, "/items/item-opener/raise-to-cost", nil)
+	if n := countRows(t, app, `SELECT COUNT(*) FROM total_change_alerts WHERE id = 4 AND action = 'update'`); n != 1 {
+		t.Errorf("raise to cost raised no alert")
+	}
+}
+
+func TestTotalChangeAlert_UndoAfterLaterEdit(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	app.exec(t, `UPDATE line_items SET unit_price = 11 WHERE id = 'item-posts'`)
+
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusConflict {
+		t.Fatalf("undo status = %d, want 409", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-posts' AND quantity = 1000 AND unit_price = 11`); n != 1 {
+		t.Errorf("later edit overwritten by undo")
+	}
+}
+
+func TestTotalChangeAlert_UndoAfterMerge(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, sort_order) VALUES
+		('item-posts-2', 'cat-alert', 'material', 'Cedar post', 5, 'ea', 10, 2)`)
+
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	if rec := app.postForm(t, http.MethodPost, "/categories/cat-alert/merge-duplicates", url.Values{"apply": {"true"}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("merge status = %d, want 303", rec.Code)
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusConflict {
+		t.Fatalf("undo status = %d, want 409", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE name = 'Cedar post' AND quantity = 1005`); n != 1 {
+		t.Errorf("merged quantity lost by undo")
+	}
+}
+
+func TestTotalChangeAlert_UndoAfterPriceAdjustment(t *testing.T) {
+	app := newTestApp(t)
+	seedTotalAlertJob(t, app)
+
+	app.postForm(t, http.MethodPut, "/items/item-posts", url.Values{
+		"name": {"Cedar post"}, "quantity": {"1000"}, "unit": {"ea"}, "unit_price": {"10"},
+	})
+	if rec := app.postForm(t, http.MethodPost, "/jobs/job-alert/adjust-prices", url.Values{
+		"percent": {"10"}, "apply": {"true"},
+	}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("adjust status = %d, want 303", rec.Code)
+	}
+
+	if rec := app.postForm(t, http.MethodPost, "/total-alerts/1/undo", nil); rec.Code != http.StatusConflict {
+		t.Fatalf("undo status = %d, want 409", rec.Code)
+	}
+	if n := countRows(t, app, `SELECT COUNT(*) FROM line_items WHERE id = 'item-posts' AND quantity = 1000 AND unit_price = 11`); n != 1 {
+		t.Errorf("adjusted price lost by undo")
+	}
+}
@@ -0,0 +1,112 @@
+package keyboard
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+	"github.com/dukerupert/skalkaho/internal/templates/keyboard"
+)
+
+// JobTree is the response body of GET /jobs/{id}/tree.json, which the
+// sidebar renders client-side.
+type JobTree struct {
+	JobID      string        `json:"job_id"`
+	Name       string        `json:"name"`
+	UpdatedAt  string        `json:"updated_at"`
+	Categories []JobTreeNode `json:"categories"`
+}
+
+// JobTreeNode is a category in the job tree. Total and ItemCount include the
+// category's subcategories. TotalDisplay is Total formatted the way pages
+// show money, with credits in parentheses.
+type JobTreeNode struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Total        float64       `json:"total"`
+	TotalDisplay string        `json:"total_display"`
+	ItemCount    int           `json:"item_count"`
+	Children     []JobTreeNode `json:"children"`
+}
+
+// GetJobTree returns a job's category tree with totals and item counts as
+// JSON. The ETag follows the job's updated_at, which triggers bump on any
+// change to the job, its categories, or its items, and the settings that
+// change totals.
+func (h *Handler) GetJobTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := middleware.LoggerFromContext(ctx)
+	jobID := r.PathValue("id")
+
+	job, err := h.queries.GetJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get job", "error", err)
+		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.queries.GetSettings(ctx)
+	if err != nil {
+		logger.Error("failed to get settings", "error", err)
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
+
+	if notModified(w, r, apiETag("tree", job.ID, job.UpdatedAt, totalsSettingsTag(settings))) {
+		return
+	}
+
+	categories, err := h.queries.ListCategoriesByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list categories", "error", err)
+		http.Error(w, "Failed to load categories", http.StatusInternalServerError)
+		return
+	}
+
+	lineItems, err := h.queries.ListLineItemsByJob(ctx, jobID)
+	if err != nil {
+		logger.Error("failed to list line items", "error", err)
+		http.Error(w, "Failed to load line items", http.StatusInternalServerError)
+		return
+	}
+
+	itemCounts := make(map[string]int)
+	for _, item := range lineItems {
+		itemCounts[item.CategoryID]++
+	}
+
+	var convert func(node CategoryTreeNode) JobTreeNode
+	convert = func(node CategoryTreeNode) JobTreeNode {
+		total := h.calculateCategoryTotal(node.ID, job, settings, categories, lineItems).Total
+		out := JobTreeNode{
+			ID:           node.ID,
+			Name:         node.Name,
+			Total:        total,
+			TotalDisplay: keyboard.FormatMoney(total),
+			ItemCount:    itemCounts[node.ID],
+			Children:     []JobTreeNode{},
+		}
+		for _, child := range node.Children {
+			converted := convert(child)
+			out.ItemCount += converted.ItemCount
+			out.Children = append(out.Children, converted)
+		}
+		return out
+	}
+
+	tree := JobTree{
+		JobID:      job.ID,
+		Name:       job.Name,
+		UpdatedAt:  job.UpdatedAt,
+		Categories: []JobTreeNode{},
+	}
+	for _, node := range buildCategoryTree(categories) {
+		tree.Categories = append(tree.Categories, convert(node))
+	}
+
+	writeJSON(w, tree)
+}
@@ -0,0 +1,113 @@
+package keyboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetJobTree(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name, sort_order) VALUES
+		('cat-1', 'job-1', 'Framing', 0),
+		('cat-2', 'job-1', 'Electrical', 1)`)
+	app.exec(t, `INSERT INTO categories (id, job_id, parent_id, name) VALUES ('cat-1a', 'job-1', 'cat-1', 'Walls')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+		('item-1', 'cat-1', 'material', '2x4x8', 10, 'ea', 5),
+		('item-2', 'cat-1a', 'labor', 'Framer', 2, 'hr', 50),
+		('item-3', 'cat-1a', 'material', 'Nails', 1, 'box', 50)`)
+
+	rec := app.get(t, "/jobs/job-1/tree.json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := decodeJSONObject(t, rec)
+
+	if got, want := sortedKeys(body), []string{"categories", "job_id", "name", "updated_at"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+
+	categories := body["categories"].([]interface{})
+	if len(categories) != 2 {
+		t.Fatalf("top-level categories = %d, want 2", len(categories))
+	}
+	framing := categories[0].(map[string]interface{})
+	if got, want := sortedKeys(framing), []string{"children", "id", "item_count", "name", "total", "total_display"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("node keys = %v, want %v", got, want)
+	}
+	if framing["name"] != "Framing" || framing["item_count"] != 3.0 || framing["total"] != 200.0 {
+		t.Errorf("framing = %v, want 3 items totalling 200", framing)
+	}
+	walls := framing["children"].([]interface{})[0].(map[string]interface{})
+	if walls["name"] != "Walls" || walls["item_count"] != 2.0 || walls["total"] != 150.0 {
+		t.Errorf("walls = %v, want 2 items totalling 150", walls)
+	}
+	if framing["total_display"] != "$200.00" {
+		t.Errorf("total_display = %v, want $200.00", framing["total_display"])
+	}
+	electrical := categories[1].(map[string]interface{})
+	if children, ok := electrical["children"].([]interface{}); !ok || len(children) != 0 {
+		t.Errorf("leaf children = %v, want empty array", electrical["children"])
+	}
+
+	if rec := app.get(t, "/jobs/missing/tree.json"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing job status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetJobTree_ETag(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+
+	etag := app.get(t, "/jobs/job-1/tree.json").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/job-1/tree.json", nil)
+	req.Header.Set("If-None-Match", etag)
+	if rec := app.do(req); rec.Code != http.StatusNotModified {
+		t.Fatalf("unchanged job status = %d, want 304", rec.Code)
+	}
+
+	// updated_at has millisecond resolution.
+	time.Sleep(5 * time.Millisecond)
+	app.postForm(t, http.MethodPost, "/jobs/job-1/categories", url.Values{"name": {"Framing"}})
+
+	req = httptest.NewRequest(http.MethodGet, "/jobs/job-1/tree.json", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := app.do(req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Framing") {
+		t.Errorf("tree not refreshed after adding a category: status %d", rec.Code)
+	}
+}
+
+func TestJobPage_LazyLoadsTree(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Framing')`)
+
+	body := app.get(t, "/categories/cat-1").Body.String()
+	if !strings.Contains(body, `hx-get="/jobs/job-1/tree.json"`) || !strings.Contains(body, `data-current-id="cat-1"`) {
+		t.Errorf("category page does not lazy-load the sidebar tree")
+	}
+}
+
+func TestGetJobTree_CreditTotalDisplay(t *testing.T) {
+	app := newTestApp(t)
+	app.exec(t, `INSERT INTO jobs (id, name) VALUES ('job-1', 'Garage')`)
+	app.exec(t, `INSERT INTO categories (id, job_id, name) VALUES ('cat-1', 'job-1', 'Allowances')`)
+	app.exec(t, `INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price, is_credit) VALUES
+		('item-1', 'cat-1', 'material', 'Returned lumber', 1, 'ea', -1200, 1)`)
+
+	body := decodeJSONObject(t, app.get(t, "/jobs/job-1/tree.json"))
+	node := body["categories"].([]interface{})[0].(map[string]interface{})
+	if node["total_display"] != "($1200.00)" {
+		t.Errorf("total_display = %v, want ($1200.00)", node["total_display"])
+	}
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxErrorMessage caps how much of a failed response body is kept and shown.
+const maxErrorMessage = 500
+
+// ErrorEntry is a failed request kept by an ErrorLog.
+type ErrorEntry struct {
+	Time      time.Time
+	RequestID string
+	Method    string
+	Path      string
+	Status    int
+	Message   string
+	Stack     string // Set for recovered panics
+}
+
+// ErrorLog keeps the most recent failed requests in memory.
+type ErrorLog struct {
+	mu      sync.Mutex
+	entries []ErrorEntry
+	next    int
+	full    bool
+}
+
+// NewErrorLog creates an error log holding up to size entries.
+func NewErrorLog(size int) *ErrorLog {
+	return &ErrorLog{entries: make([]ErrorEntry, size)}
+}
+
+// Record adds an entry, replacing the oldest once the log is full.
+func (l *ErrorLog) Record(entry ErrorEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Entries returns the recorded entries, newest first.
+func (l *ErrorLog) Entries() []ErrorEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	entries := make([]ErrorEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		entries = append(entries, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return entries
+}
+
+// errorReport collects details about a failing request that only inner
+// middleware knows, such as the stack of a recovered panic.
+type errorReport struct {
+	stack string
+}
+
+const errorReportKey contextKey = "errorReport"
+
+// reportPanic attaches a panic's stack to the request's error report.
+func reportPanic(ctx context.Context, stack string) {
+	if report, ok := ctx.Value(errorReportKey).(*errorReport); ok {
+		report.stack = stack
+	}
+}
+
+// errorResponseWriter holds back the body of 5xx responses so it can be
+// replaced by an error report. Other responses pass straight through.
+type errorResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	captured bool
+	body     bytes.Buffer
+}
+
+func (w *errorResponseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	// JSON API errors are left to their clients.
+	if code >= 500 && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.captured = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *errorResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.captured {
+		if room := maxErrorMessage - w.body.Len(); room > 0 {
+			w.body.Write(b[:min(len(b), room)])
+		}
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorResponseWriter) Flush() {
+	if w.captured {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReportErrors records server errors in errs and replaces their response with
+// an error report showing the request ID as a reference, so a user can quote
+// it and the failure can be found in the logs and on the admin errors page.
+// The report keeps the handler's short message and, when supportEmail is set,
+// links to a prefilled email. Panic values and stacks are never shown.
+func ReportErrors(errs *ErrorLog, supportEmail string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			report := &errorReport{}
+			wrapped := &errorResponseWriter{ResponseWriter: w}
+
+			next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), errorReportKey, report)))
+
+			if wrapped.status < 500 {
+				return
+			}
+
+			requestID := RequestIDFromContext(r.Context())
+			message := strings.TrimSpace(wrapped.body.String())
+			errs.Record(ErrorEntry{
+				Time:      time.Now(),
+				RequestID: requestID,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    wrapped.status,
+				Message:   message,
+				Stack:     report.stack,
+			})
+
+			if !wrapped.captured {
+				return
+			}
+			if message == "" {
+				message = http.StatusText(wrapped.status)
+			}
+			data := errorReportData{
+				Message:   message,
+				Ref:       requestID,
+				ReportURL: errorReportURL(supportEmail, requestID, r),
+			}
+
+			name := "error_page"
+			if r.Header.Get("HX-Request") == "true" {
+				name = "error_report"
+			}
+			w.Header().Del("Content-Length")
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(wrapped.status)
+			if err := errorTemplates.ExecuteTemplate(w, name, data); err != nil {
+				LoggerFromContext(r.Context()).Error("failed to render error report", "error", err)
+			}
+		})
+	}
+}
+
+// errorReportData is what the error report templates show.
+type errorReportData struct {
+	Message   string
+	Ref       string
+	ReportURL template.URL
+}
+
+// errorReportURL returns a mailto link with the reference and request line
+// filled in, or "" when no support address is configured.
+func errorReportURL(supportEmail, requestID string, r *http.Request) template.URL {
+	if supportEmail == "" {
+		return ""
+	}
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+	subject := "Skalkaho error " + requestID
+	body := "Error ref: " + requestID + "\nRequest: " + r.Method + " " + r.URL.Path + "\n\nWhat I was doing:\n"
+	return template.URL("mailto:" + url.PathEscape(supportEmail) + "?subject=" + escape(subject) + "&body=" + escape(body))
+}
+
+// errorTemplates render the error report: error_report is the fragment HTMX
+// swaps into the error toast, error_page the standalone page around it.
+var errorTemplates = template.Must(template.New("").Parse(`
+{{define "error_report"}}
+<div class="fixed bottom-14 right-4 z-50 max-w-sm bg-red-50 border border-red-200 text-red-800 rounded shadow-lg px-4 py-3 text-sm" role="alert" data-error-report>
+    <div class="flex items-start justify-between gap-3">
+        <p>{{.Message}}</p>
+        <button type="button" onclick="this.closest('[data-error-report]').remove()" class="text-red-400 hover:text-red-700">×</button>
+    </div>
+    <p class="mt-1 text-xs text-red-600">Error ref: <code class="font-mono select-all">{{.Ref}}</code></p>
+    {{if .ReportURL}}<a href="{{.ReportURL}}" class="mt-1 inline-block text-xs underline">Report this problem</a>{{end}}
+</div>
+{{end}}
+{{define "error_page"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Something went wrong - Skalkaho</title>
+</head>
+<body style="font-family: ui-sans-serif, system-ui, sans-serif; max-width: 32rem; margin: 4rem auto; padding: 0 1rem; color: #0f172a;">
+<h1 style="font-size: 1.25rem;">Something went wrong</h1>
+<p>{{.Message}}</p>
+<p style="color: #64748b;">Error ref: <code>{{.Ref}}</code></p>
+{{if .ReportURL}}<p><a href="{{.ReportURL}}">Report this problem</a></p>{{end}}
+<p><a href="/">Back to quotes</a></p>
+</body>
+</html>
+{{end}}
+`))
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+func serve(errs *middleware.ErrorLog, supportEmail string, h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	handler := middleware.Chain(h,
+		middleware.RequestID,
+		middleware.ReportErrors(errs, supportEmail),
+		middleware.Recover,
+	)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReportErrors_Panic(t *testing.T) {
+	errs := middleware.NewErrorLog(10)
+	req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "7f3a9c")
+
+	rec := serve(errs, "", func(w http.ResponseWriter, r *http.Request) {
+		panic("db password is hunter2")
+	}, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Error ref: <code>7f3a9c</code>") {
+		t.Errorf("error page missing the request ID: %s", body)
+	}
+	if strings.Contains(body, "hunter2") || strings.Contains(body, "goroutine") {
+		t.Errorf("error page leaks panic details: %s", body)
+	}
+	if strings.Contains(body, "mailto:") {
+		t.Errorf("report link shown without a support address")
+	}
+
+	entries := errs.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if e := entries[0]; e.RequestID != "7f3a9c" || e.Path != "/jobs/job-1" || !strings.Contains(e.Stack, "goroutine") {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestReportErrors_HTMXFragment(t *testing.T) {
+	errs := middleware.NewErrorLog(10)
+	req := httptest.NewRequest(http.MethodPut, "/jobs/job-1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc123")
+	req.Header.Set("HX-Request", "true")
+
+	rec := serve(errs, "help@example.com", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Failed to update job", http.StatusInternalServerError)
+	}, req)
+
+	body := rec.Body.String()
+	if strings.Contains(body, "<html") || !strings.Contains(body, "data-error-report") {
+		t.Errorf("want the toast fragment, got %s", body)
+	}
+	for _, want := range []string{"Failed to update job", "abc123", "mailto:help@example.com?subject=Skalkaho%20error%20abc123"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("fragment missing %q: %s", want, body)
+		}
+	}
+	if entries := errs.Entries(); len(entries) != 1 || entries[0].Message != "Failed to update job" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestReportErrors_PassThrough(t *testing.T) {
+	errs := middleware.NewErrorLog(10)
+
+	rec := serve(errs, "", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+	}, httptest.NewRequest(http.MethodGet, "/jobs/missing", nil))
+	if rec.Code != http.StatusNotFound || strings.TrimSpace(rec.Body.String()) != "Job not found" {
+		t.Errorf("client error rewritten: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(errs, "", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":"failed"}`)
+	}, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/totals", nil))
+	if rec.Body.String() != `{"error":"failed"}` {
+		t.Errorf("JSON error rewritten: %q", rec.Body.String())
+	}
+
+	if entries := errs.Entries(); len(entries) != 1 || entries[0].Status != http.StatusInternalServerError {
+		t.Errorf("want only the JSON server error recorded, got %+v", entries)
+	}
+}
+
+func TestErrorLog_KeepsNewest(t *testing.T) {
+	errs := middleware.NewErrorLog(3)
+	for i := 1; i <= 5; i++ {
+		errs.Record(middleware.ErrorEntry{RequestID: fmt.Sprint(i)})
+	}
+
+	entries := errs.Entries()
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.RequestID)
+	}
+	if got := strings.Join(ids, ","); got != "5,4,3" {
+		t.Errorf("entries = %s, want 5,4,3", got)
+	}
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader carries a client-chosen key that identifies one form
+// submission, so repeats of it can be told apart from new submissions.
+const IdempotencyKeyHeader = "X-Idempotency-Key"
+
+// maxIdempotentBody is the largest response kept for replay. Larger responses
+// are not remembered, and their duplicates run the handler again.
+const maxIdempotentBody = 1 << 20
+
+// IdempotencyCache remembers the responses to keyed requests for a window so
+// duplicates can be answered without running the handler again.
+type IdempotencyCache struct {
+	mu      sync.Mutex
+	size    int
+	window  time.Duration
+	entries map[string]*idempotentResponse
+	order   []string // Keys in insertion order, oldest first
+}
+
+// idempotentResponse is the response to the first request with a key. done
+// is closed once the response is known; until then duplicates wait on it.
+type idempotentResponse struct {
+	created time.Time
+	done    chan struct{}
+	stored  bool
+	status  int
+	header  http.Header
+	body    []byte
+}
+
+// NewIdempotencyCache creates a cache remembering up to size responses for
+// window each.
+func NewIdempotencyCache(size int, window time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{
+		size:    size,
+		window:  window,
+		entries: make(map[string]*idempotentResponse),
+	}
+}
+
+// begin returns the entry for key and whether this request is the first to
+// use it. The first request must call finish.
+func (c *IdempotencyCache) begin(key string) (*idempotentResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := c.entries[key]; ok && now.Sub(entry.created) < c.window {
+		return entry, false
+	}
+
+	// Forget expired entries and, past the size limit, the oldest ones.
+	kept := c.order[:0]
+	for _, k := range c.order {
+		if entry, ok := c.entries[k]; ok && k != key && now.Sub(entry.created) < c.window {
+			kept = append(kept, k)
+		} else {
+			delete(c.entries, k)
+		}
+	}
+	for len(kept) >= c.size && len(kept) > 0 {
+		delete(c.entries, kept[0])
+		kept = kept[1:]
+	}
+	c.order = kept
+
+	entry := &idempotentResponse{created: now, done: make(chan struct{})}
+	c.entries[key] = entry
+	c.order = append(c.order, key)
+	return entry, true
+}
+
+// finish records the response to the first request with key. Failed
+// responses are forgotten so the submission can be retried.
+func (c *IdempotencyCache) finish(key string, entry *idempotentResponse, rec *recordingResponseWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A status of 0 means the handler panicked before responding.
+	if rec.status != 0 && rec.status < 400 && !rec.overflow {
+		entry.stored = true
+		entry.status = rec.status
+		entry.header = rec.header
+		entry.body = rec.body.Bytes()
+	} else if c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	close(entry.done)
+}
+
+// recordingResponseWriter keeps a copy of the response it passes through.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingResponseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	w.header = w.Header().Clone()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxIdempotentBody {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotent makes a handler safe against duplicate submissions. A request
+// carrying an X-Idempotency-Key already seen for the same method and path
+// within the cache window gets the original response replayed instead of
+// running the handler again. A duplicate that arrives while the original is
+// still running waits for it. Requests without a key are not affected.
+func Idempotent(cache *IdempotencyCache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key = r.Method + " " + r.URL.Path + " " + key
+
+			entry, first := cache.begin(key)
+			if first {
+				rec := &recordingResponseWriter{ResponseWriter: w}
+				defer func() { cache.finish(key, entry, rec) }()
+				next.ServeHTTP(rec, r)
+				if rec.status == 0 {
+					rec.status = http.StatusOK
+					rec.header = w.Header().Clone()
+				}
+				return
+			}
+
+			select {
+			case <-entry.done:
+			case <-r.Context().Done():
+				return
+			}
+			// The original failed, so this is a retry rather than a duplicate.
+			if !entry.stored {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			LoggerFromContext(r.Context()).Info("replaying idempotent response", "status", entry.status)
+			for name, values := range entry.header {
+				if name == RequestIDHeader {
+					continue
+				}
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(entry.status)
+			_, _ = w.Write(entry.body)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/middleware"
+)
+
+func keyedPost(key string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/jobs", nil)
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	return req
+}
+
+func TestIdempotent_ConcurrentDuplicatesRunOnce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handler := middleware.Idempotent(middleware.NewIdempotencyCache(10, time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, keyedPost("k1"))
+			codes[i] = rec.Code
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("handler calls = %d, want 1", calls.Load())
+	}
+	if codes[0] != http.StatusCreated || codes[1] != http.StatusCreated {
+		t.Errorf("codes = %v, want both 201", codes)
+	}
+}
+
+func TestIdempotent_FailuresCanBeRetried(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.Idempotent(middleware.NewIdempotencyCache(10, time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, want := range []int{http.StatusBadRequest, http.StatusCreated, http.StatusCreated} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, keyedPost("k1"))
+		if rec.Code != want {
+			t.Errorf("status = %d, want %d", rec.Code, want)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestIdempotent_WindowExpires(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.Idempotent(middleware.NewIdempotencyCache(10, 10*time.Millisecond))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), keyedPost("k1"))
+	handler.ServeHTTP(httptest.NewRecorder(), keyedPost("k1"))
+	time.Sleep(20 * time.Millisecond)
+	handler.ServeHTTP(httptest.NewRecorder(), keyedPost("k1"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/jobs", nil))
+
+	if calls.Load() != 3 {
+		t.Errorf("handler calls = %d, want 3", calls.Load())
+	}
+}
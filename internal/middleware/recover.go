@@ -5,16 +5,20 @@ import (
 	"runtime/debug"
 )
 
-// Recover catches panics and returns a 500 error.
+// Recover catches panics and returns a 500 error. The panic and its stack are
+// logged and passed to ReportErrors; the response only says that the request
+// failed.
 func Recover(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				stack := string(debug.Stack())
 				logger := LoggerFromContext(r.Context())
 				logger.Error("panic recovered",
 					"error", err,
-					"stack", string(debug.Stack()),
+					"stack", stack,
 				)
+				reportPanic(r.Context(), stack)
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
 		}()
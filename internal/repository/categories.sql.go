@@ -8,6 +8,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const countCategoryAncestors = `-- name: CountCategoryAncestors :one
@@ -33,7 +34,7 @@ func (q *Queries) CountCategoryAncestors(ctx context.Context, id string) (interf
 const createCategory = `-- name: CreateCategory :one
 INSERT INTO categories (id, job_id, parent_id, name, surcharge_percent, sort_order)
 VALUES (?, ?, ?, ?, ?, ?)
-RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order
+RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit
 `
 
 type CreateCategoryParams struct {
@@ -62,6 +63,10 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.Name,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.TaxTreatment,
+		&i.Phase,
+		&i.DefaultItemType,
+		&i.DefaultUnit,
 	)
 	return i, err
 }
@@ -77,7 +82,7 @@ func (q *Queries) DeleteCategory(ctx context.Context, id string) error {
 }
 
 const getCategory = `-- name: GetCategory :one
-SELECT id, job_id, parent_id, name, surcharge_percent, sort_order FROM categories
+SELECT id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit FROM categories
 WHERE id = ?
 `
 
@@ -91,12 +96,16 @@ func (q *Queries) GetCategory(ctx context.Context, id string) (Category, error)
 		&i.Name,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.TaxTreatment,
+		&i.Phase,
+		&i.DefaultItemType,
+		&i.DefaultUnit,
 	)
 	return i, err
 }
 
 const listCategoriesByJob = `-- name: ListCategoriesByJob :many
-SELECT id, job_id, parent_id, name, surcharge_percent, sort_order FROM categories
+SELECT id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit FROM categories
 WHERE job_id = ?
 ORDER BY sort_order ASC
 `
@@ -117,6 +126,60 @@ func (q *Queries) ListCategoriesByJob(ctx context.Context, jobID string) ([]Cate
 			&i.Name,
 			&i.SurchargePercent,
 			&i.SortOrder,
+			&i.TaxTreatment,
+			&i.Phase,
+			&i.DefaultItemType,
+			&i.DefaultUnit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoriesByJobIDs = `-- name: ListCategoriesByJobIDs :many
+SELECT id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit FROM categories
+WHERE job_id IN (/*SLICE:job_ids*/?)
+ORDER BY sort_order ASC
+`
+
+func (q *Queries) ListCategoriesByJobIDs(ctx context.Context, jobIds []string) ([]Category, error) {
+	query := listCategoriesByJobIDs
+	var queryParams []interface{}
+	if len(jobIds) > 0 {
+		for _, v := range jobIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", strings.Repeat(",?", len(jobIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Category{}
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.ParentID,
+			&i.Name,
+			&i.SurchargePercent,
+			&i.SortOrder,
+			&i.TaxTreatment,
+			&i.Phase,
+			&i.DefaultItemType,
+			&i.DefaultUnit,
 		); err != nil {
 			return nil, err
 		}
@@ -132,7 +195,7 @@ func (q *Queries) ListCategoriesByJob(ctx context.Context, jobID string) ([]Cate
 }
 
 const listChildCategories = `-- name: ListChildCategories :many
-SELECT id, job_id, parent_id, name, surcharge_percent, sort_order FROM categories
+SELECT id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit FROM categories
 WHERE parent_id = ?
 ORDER BY sort_order ASC
 `
@@ -153,6 +216,10 @@ func (q *Queries) ListChildCategories(ctx context.Context, parentID sql.NullStri
 			&i.Name,
 			&i.SurchargePercent,
 			&i.SortOrder,
+			&i.TaxTreatment,
+			&i.Phase,
+			&i.DefaultItemType,
+			&i.DefaultUnit,
 		); err != nil {
 			return nil, err
 		}
@@ -167,8 +234,42 @@ func (q *Queries) ListChildCategories(ctx context.Context, parentID sql.NullStri
 	return items, nil
 }
 
+const listJobPhases = `-- name: ListJobPhases :many
+SELECT DISTINCT phase FROM (
+    SELECT c.phase, c.job_id FROM categories c
+    UNION ALL
+    SELECT li.phase, c.job_id FROM line_items li
+    JOIN categories c ON li.category_id = c.id
+)
+WHERE job_id = ? AND phase IS NOT NULL AND phase != ''
+ORDER BY phase
+`
+
+func (q *Queries) ListJobPhases(ctx context.Context, jobID string) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPhases, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []sql.NullString{}
+	for rows.Next() {
+		var phase sql.NullString
+		if err := rows.Scan(&phase); err != nil {
+			return nil, err
+		}
+		items = append(items, phase)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTopLevelCategories = `-- name: ListTopLevelCategories :many
-SELECT id, job_id, parent_id, name, surcharge_percent, sort_order FROM categories
+SELECT id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit FROM categories
 WHERE job_id = ? AND parent_id IS NULL
 ORDER BY sort_order ASC
 `
@@ -189,6 +290,60 @@ func (q *Queries) ListTopLevelCategories(ctx context.Context, jobID string) ([]C
 			&i.Name,
 			&i.SurchargePercent,
 			&i.SortOrder,
+			&i.TaxTreatment,
+			&i.Phase,
+			&i.DefaultItemType,
+			&i.DefaultUnit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchQuickAddTargets = `-- name: SearchQuickAddTargets :many
+SELECT c.id AS category_id, c.name AS category_name, j.id AS job_id, j.name AS job_name
+FROM categories c
+JOIN jobs j ON j.id = c.job_id
+WHERE j.status IN ('draft', 'sent')
+  AND (c.name LIKE '%' || ?1 || '%' OR j.name LIKE '%' || ?1 || '%')
+ORDER BY j.updated_at DESC, j.name, c.sort_order
+LIMIT ?2
+`
+
+type SearchQuickAddTargetsRow struct {
+	CategoryID   string `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+}
+
+type SearchQuickAddTargetsParams struct {
+	Search interface{} `json:"search"`
+	Limit  int64       `json:"limit"`
+}
+
+func (q *Queries) SearchQuickAddTargets(ctx context.Context, arg SearchQuickAddTargetsParams) ([]SearchQuickAddTargetsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchQuickAddTargets, arg.Search, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchQuickAddTargetsRow{}
+	for rows.Next() {
+		var i SearchQuickAddTargetsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.JobID,
+			&i.JobName,
 		); err != nil {
 			return nil, err
 		}
@@ -209,7 +364,7 @@ UPDATE categories SET
     surcharge_percent = ?,
     sort_order = ?
 WHERE id = ?
-RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order
+RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit
 `
 
 type UpdateCategoryParams struct {
@@ -234,15 +389,34 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		&i.Name,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.TaxTreatment,
+		&i.Phase,
+		&i.DefaultItemType,
+		&i.DefaultUnit,
 	)
 	return i, err
 }
 
+const updateCategoryDefaults = `-- name: UpdateCategoryDefaults :exec
+UPDATE categories SET default_item_type = ?, default_unit = ? WHERE id = ?
+`
+
+type UpdateCategoryDefaultsParams struct {
+	DefaultItemType sql.NullString `json:"default_item_type"`
+	DefaultUnit     sql.NullString `json:"default_unit"`
+	ID              string         `json:"id"`
+}
+
+func (q *Queries) UpdateCategoryDefaults(ctx context.Context, arg UpdateCategoryDefaultsParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategoryDefaults, arg.DefaultItemType, arg.DefaultUnit, arg.ID)
+	return err
+}
+
 const updateCategoryParent = `-- name: UpdateCategoryParent :one
 UPDATE categories SET
     parent_id = ?
 WHERE id = ?
-RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order
+RETURNING id, job_id, parent_id, name, surcharge_percent, sort_order, tax_treatment, phase, default_item_type, default_unit
 `
 
 type UpdateCategoryParentParams struct {
@@ -260,6 +434,38 @@ func (q *Queries) UpdateCategoryParent(ctx context.Context, arg UpdateCategoryPa
 		&i.Name,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.TaxTreatment,
+		&i.Phase,
+		&i.DefaultItemType,
+		&i.DefaultUnit,
 	)
 	return i, err
 }
+
+const updateCategoryPhase = `-- name: UpdateCategoryPhase :exec
+UPDATE categories SET phase = ? WHERE id = ?
+`
+
+type UpdateCategoryPhaseParams struct {
+	Phase sql.NullString `json:"phase"`
+	ID    string         `json:"id"`
+}
+
+func (q *Queries) UpdateCategoryPhase(ctx context.Context, arg UpdateCategoryPhaseParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategoryPhase, arg.Phase, arg.ID)
+	return err
+}
+
+const updateCategoryTaxTreatment = `-- name: UpdateCategoryTaxTreatment :exec
+UPDATE categories SET tax_treatment = ? WHERE id = ?
+`
+
+type UpdateCategoryTaxTreatmentParams struct {
+	TaxTreatment string `json:"tax_treatment"`
+	ID           string `json:"id"`
+}
+
+func (q *Queries) UpdateCategoryTaxTreatment(ctx context.Context, arg UpdateCategoryTaxTreatmentParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategoryTaxTreatment, arg.TaxTreatment, arg.ID)
+	return err
+}
@@ -8,6 +8,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const clientHasJobs = `-- name: ClientHasJobs :one
@@ -182,6 +183,57 @@ func (q *Queries) ListClients(ctx context.Context) ([]Client, error) {
 	return items, nil
 }
 
+const listClientsByIDs = `-- name: ListClientsByIDs :many
+SELECT id, name, company, email, phone, address, city, state, zip, tax_id, notes, created_at FROM clients
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListClientsByIDs(ctx context.Context, ids []string) ([]Client, error) {
+	query := listClientsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Client{}
+	for rows.Next() {
+		var i Client
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Company,
+			&i.Email,
+			&i.Phone,
+			&i.Address,
+			&i.City,
+			&i.State,
+			&i.Zip,
+			&i.TaxID,
+			&i.Notes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listClientsPaginated = `-- name: ListClientsPaginated :many
 SELECT id, name, company, email, phone, address, city, state, zip, tax_id, notes, created_at FROM clients
 WHERE (?1 = '' OR name LIKE '%' || ?1 || '%' OR company LIKE '%' || ?1 || '%')
@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+func TestCountOpenItemCommentsByJob(t *testing.T) {
+	db := openTestDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO jobs (id, name) VALUES ('job', 'Job'), ('other', 'Other')`,
+		`INSERT INTO categories (id, job_id, name) VALUES ('c-1', 'job', 'Framing'), ('o-1', 'other', 'Framing')`,
+		`INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+			('i-1', 'c-1', 'material', '2x4', 10, 'ea', 3.5),
+			('i-2', 'c-1', 'labor', 'Framer', 8, 'hr', 50),
+			('i-3', 'o-1', 'material', '2x6', 4, 'ea', 6)`,
+		`INSERT INTO item_comments (item_id, author, body, resolved) VALUES
+			('i-1', 'Sam', 'Length?', 0),
+			('i-1', 'Sam', 'Grade?', 0),
+			('i-2', 'Sam', 'Rate ok', 1),
+			('i-3', 'Sam', 'Other job', 0)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seeding: %v", err)
+		}
+	}
+
+	rows, err := repository.New(db).CountOpenItemCommentsByJob(context.Background(), "job")
+	if err != nil {
+		t.Fatalf("CountOpenItemCommentsByJob: %v", err)
+	}
+	if len(rows) != 1 || rows[0].ItemID != "i-1" || rows[0].CategoryID != "c-1" || rows[0].OpenCount != 2 {
+		t.Errorf("rows = %+v, want i-1 with 2 open", rows)
+	}
+
+	// Deleting an item takes its comments with it.
+	if _, err := db.Exec(`DELETE FROM line_items WHERE id = 'i-1'`); err != nil {
+		t.Fatalf("deleting item: %v", err)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM item_comments WHERE item_id = 'i-1'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("comments left = %d, err = %v", n, err)
+	}
+}
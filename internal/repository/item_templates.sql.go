@@ -13,7 +13,7 @@ import (
 const createItemTemplate = `-- name: CreateItemTemplate :one
 INSERT INTO item_templates (type, category, name, default_unit, default_price)
 VALUES (?, ?, ?, ?, ?)
-RETURNING id, type, category, name, default_unit, default_price
+RETURNING id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate
 `
 
 type CreateItemTemplateParams struct {
@@ -40,10 +40,42 @@ func (q *Queries) CreateItemTemplate(ctx context.Context, arg CreateItemTemplate
 		&i.Name,
 		&i.DefaultUnit,
 		&i.DefaultPrice,
+		&i.Sku,
+		&i.DayRate,
+		&i.WeekRate,
+		&i.MonthRate,
 	)
 	return i, err
 }
 
+const createItemTemplatePriceHistory = `-- name: CreateItemTemplatePriceHistory :exec
+INSERT INTO item_template_price_history (template_id, old_price, new_price, source, import_id, import_filename, request_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateItemTemplatePriceHistoryParams struct {
+	TemplateID     int64          `json:"template_id"`
+	OldPrice       float64        `json:"old_price"`
+	NewPrice       float64        `json:"new_price"`
+	Source         string         `json:"source"`
+	ImportID       sql.NullString `json:"import_id"`
+	ImportFilename sql.NullString `json:"import_filename"`
+	RequestID      sql.NullString `json:"request_id"`
+}
+
+func (q *Queries) CreateItemTemplatePriceHistory(ctx context.Context, arg CreateItemTemplatePriceHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, createItemTemplatePriceHistory,
+		arg.TemplateID,
+		arg.OldPrice,
+		arg.NewPrice,
+		arg.Source,
+		arg.ImportID,
+		arg.ImportFilename,
+		arg.RequestID,
+	)
+	return err
+}
+
 const deleteItemTemplate = `-- name: DeleteItemTemplate :exec
 DELETE FROM item_templates
 WHERE id = ?
@@ -55,7 +87,7 @@ func (q *Queries) DeleteItemTemplate(ctx context.Context, id int64) error {
 }
 
 const getItemTemplate = `-- name: GetItemTemplate :one
-SELECT id, type, category, name, default_unit, default_price FROM item_templates
+SELECT id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate FROM item_templates
 WHERE id = ?
 `
 
@@ -69,12 +101,40 @@ func (q *Queries) GetItemTemplate(ctx context.Context, id int64) (ItemTemplate,
 		&i.Name,
 		&i.DefaultUnit,
 		&i.DefaultPrice,
+		&i.Sku,
+		&i.DayRate,
+		&i.WeekRate,
+		&i.MonthRate,
+	)
+	return i, err
+}
+
+const getLatestItemTemplatePriceChange = `-- name: GetLatestItemTemplatePriceChange :one
+SELECT id, template_id, old_price, new_price, source, import_id, import_filename, request_id, created_at FROM item_template_price_history
+WHERE template_id = ?
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestItemTemplatePriceChange(ctx context.Context, templateID int64) (ItemTemplatePriceHistory, error) {
+	row := q.db.QueryRowContext(ctx, getLatestItemTemplatePriceChange, templateID)
+	var i ItemTemplatePriceHistory
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.OldPrice,
+		&i.NewPrice,
+		&i.Source,
+		&i.ImportID,
+		&i.ImportFilename,
+		&i.RequestID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const listItemTemplates = `-- name: ListItemTemplates :many
-SELECT id, type, category, name, default_unit, default_price FROM item_templates
+SELECT id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate FROM item_templates
 ORDER BY category, name
 `
 
@@ -94,6 +154,10 @@ func (q *Queries) ListItemTemplates(ctx context.Context) ([]ItemTemplate, error)
 			&i.Name,
 			&i.DefaultUnit,
 			&i.DefaultPrice,
+			&i.Sku,
+			&i.DayRate,
+			&i.WeekRate,
+			&i.MonthRate,
 		); err != nil {
 			return nil, err
 		}
@@ -109,7 +173,7 @@ func (q *Queries) ListItemTemplates(ctx context.Context) ([]ItemTemplate, error)
 }
 
 const listItemTemplatesByCategory = `-- name: ListItemTemplatesByCategory :many
-SELECT id, type, category, name, default_unit, default_price FROM item_templates
+SELECT id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate FROM item_templates
 WHERE category = ?
 ORDER BY name
 `
@@ -130,6 +194,10 @@ func (q *Queries) ListItemTemplatesByCategory(ctx context.Context, category stri
 			&i.Name,
 			&i.DefaultUnit,
 			&i.DefaultPrice,
+			&i.Sku,
+			&i.DayRate,
+			&i.WeekRate,
+			&i.MonthRate,
 		); err != nil {
 			return nil, err
 		}
@@ -145,7 +213,7 @@ func (q *Queries) ListItemTemplatesByCategory(ctx context.Context, category stri
 }
 
 const searchItemTemplates = `-- name: SearchItemTemplates :many
-SELECT id, type, category, name, default_unit, default_price FROM item_templates
+SELECT id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate FROM item_templates
 WHERE name LIKE '%' || ? || '%'
 ORDER BY name
 LIMIT 10
@@ -167,6 +235,10 @@ func (q *Queries) SearchItemTemplates(ctx context.Context, dollar_1 sql.NullStri
 			&i.Name,
 			&i.DefaultUnit,
 			&i.DefaultPrice,
+			&i.Sku,
+			&i.DayRate,
+			&i.WeekRate,
+			&i.MonthRate,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +254,7 @@ func (q *Queries) SearchItemTemplates(ctx context.Context, dollar_1 sql.NullStri
 }
 
 const searchItemTemplatesByType = `-- name: SearchItemTemplatesByType :many
-SELECT id, type, category, name, default_unit, default_price FROM item_templates
+SELECT id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate FROM item_templates
 WHERE type = ? AND name LIKE '%' || ? || '%'
 ORDER BY name
 LIMIT 10
@@ -209,6 +281,10 @@ func (q *Queries) SearchItemTemplatesByType(ctx context.Context, arg SearchItemT
 			&i.Name,
 			&i.DefaultUnit,
 			&i.DefaultPrice,
+			&i.Sku,
+			&i.DayRate,
+			&i.WeekRate,
+			&i.MonthRate,
 		); err != nil {
 			return nil, err
 		}
@@ -227,7 +303,7 @@ const updateItemTemplate = `-- name: UpdateItemTemplate :one
 UPDATE item_templates
 SET type = ?, category = ?, name = ?, default_unit = ?, default_price = ?
 WHERE id = ?
-RETURNING id, type, category, name, default_unit, default_price
+RETURNING id, type, category, name, default_unit, default_price, sku, day_rate, week_rate, month_rate
 `
 
 type UpdateItemTemplateParams struct {
@@ -256,6 +332,10 @@ func (q *Queries) UpdateItemTemplate(ctx context.Context, arg UpdateItemTemplate
 		&i.Name,
 		&i.DefaultUnit,
 		&i.DefaultPrice,
+		&i.Sku,
+		&i.DayRate,
+		&i.WeekRate,
+		&i.MonthRate,
 	)
 	return i, err
 }
@@ -288,3 +368,38 @@ func (q *Queries) UpdateItemTemplatePriceAndName(ctx context.Context, arg Update
 	_, err := q.db.ExecContext(ctx, updateItemTemplatePriceAndName, arg.DefaultPrice, arg.Name, arg.ID)
 	return err
 }
+
+const updateItemTemplateRentalRates = `-- name: UpdateItemTemplateRentalRates :exec
+UPDATE item_templates SET day_rate = ?, week_rate = ?, month_rate = ? WHERE id = ?
+`
+
+type UpdateItemTemplateRentalRatesParams struct {
+	DayRate   sql.NullFloat64 `json:"day_rate"`
+	WeekRate  sql.NullFloat64 `json:"week_rate"`
+	MonthRate sql.NullFloat64 `json:"month_rate"`
+	ID        int64           `json:"id"`
+}
+
+func (q *Queries) UpdateItemTemplateRentalRates(ctx context.Context, arg UpdateItemTemplateRentalRatesParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemTemplateRentalRates,
+		arg.DayRate,
+		arg.WeekRate,
+		arg.MonthRate,
+		arg.ID,
+	)
+	return err
+}
+
+const updateItemTemplateSku = `-- name: UpdateItemTemplateSku :exec
+UPDATE item_templates SET sku = ? WHERE id = ?
+`
+
+type UpdateItemTemplateSkuParams struct {
+	Sku sql.NullString `json:"sku"`
+	ID  int64          `json:"id"`
+}
+
+func (q *Queries) UpdateItemTemplateSku(ctx context.Context, arg UpdateItemTemplateSkuParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemTemplateSku, arg.Sku, arg.ID)
+	return err
+}
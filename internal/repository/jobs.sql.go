@@ -8,8 +8,56 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
+const countJobContents = `-- name: CountJobContents :many
+SELECT c.job_id, COUNT(DISTINCT c.id) AS category_count, COUNT(li.id) AS item_count
+FROM categories c
+LEFT JOIN line_items li ON li.category_id = c.id
+WHERE c.job_id IN (/*SLICE:job_ids*/?)
+GROUP BY c.job_id
+`
+
+type CountJobContentsRow struct {
+	JobID         string `json:"job_id"`
+	CategoryCount int64  `json:"category_count"`
+	ItemCount     int64  `json:"item_count"`
+}
+
+func (q *Queries) CountJobContents(ctx context.Context, jobIds []string) ([]CountJobContentsRow, error) {
+	query := countJobContents
+	var queryParams []interface{}
+	if len(jobIds) > 0 {
+		for _, v := range jobIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", strings.Repeat(",?", len(jobIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountJobContentsRow{}
+	for rows.Next() {
+		var i CountJobContentsRow
+		if err := rows.Scan(&i.JobID, &i.CategoryCount, &i.ItemCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countJobs = `-- name: CountJobs :one
 SELECT COUNT(*) FROM jobs
 WHERE (?1 = '' OR status = ?1)
@@ -25,7 +73,7 @@ func (q *Queries) CountJobs(ctx context.Context, status interface{}) (int64, err
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (id, name, customer_name, surcharge_percent, surcharge_mode, status, expires_at, client_id)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id
+RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent
 `
 
 type CreateJobParams struct {
@@ -61,6 +109,9 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.Status,
 		&i.ExpiresAt,
 		&i.ClientID,
+		&i.FollowUpAt,
+		&i.UpdatedAt,
+		&i.TaxPercent,
 	)
 	return i, err
 }
@@ -76,7 +127,7 @@ func (q *Queries) DeleteJob(ctx context.Context, id string) error {
 }
 
 const getJob = `-- name: GetJob :one
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 WHERE id = ?
 `
 
@@ -93,12 +144,32 @@ func (q *Queries) GetJob(ctx context.Context, id string) (Job, error) {
 		&i.Status,
 		&i.ExpiresAt,
 		&i.ClientID,
+		&i.FollowUpAt,
+		&i.UpdatedAt,
+		&i.TaxPercent,
 	)
 	return i, err
 }
 
+const linkJobClient = `-- name: LinkJobClient :execrows
+UPDATE jobs SET client_id = ? WHERE id = ? AND client_id IS NULL
+`
+
+type LinkJobClientParams struct {
+	ClientID sql.NullString `json:"client_id"`
+	ID       string         `json:"id"`
+}
+
+func (q *Queries) LinkJobClient(ctx context.Context, arg LinkJobClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, linkJobClient, arg.ClientID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const listJobs = `-- name: ListJobs :many
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 ORDER BY created_at DESC
 `
 
@@ -121,6 +192,157 @@ func (q *Queries) ListJobs(ctx context.Context) ([]Job, error) {
 			&i.Status,
 			&i.ExpiresAt,
 			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsCreatedBetween = `-- name: ListJobsCreatedBetween :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE (?1 = '' OR status = ?1)
+  AND (?2 = '' OR created_at >= ?2)
+  AND (?3 = '' OR created_at < ?3)
+ORDER BY created_at, id
+`
+
+type ListJobsCreatedBetweenParams struct {
+	Status   interface{} `json:"status"`
+	FromDate interface{} `json:"from_date"`
+	ToDate   interface{} `json:"to_date"`
+}
+
+func (q *Queries) ListJobsCreatedBetween(ctx context.Context, arg ListJobsCreatedBetweenParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsCreatedBetween, arg.Status, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsExpiringBetween = `-- name: ListJobsExpiringBetween :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE expires_at >= ?1 AND expires_at < ?2
+  AND (?3 = '' OR status = ?3)
+ORDER BY expires_at, name
+`
+
+type ListJobsExpiringBetweenParams struct {
+	StartDate sql.NullString `json:"start_date"`
+	EndDate   sql.NullString `json:"end_date"`
+	Status    interface{}    `json:"status"`
+}
+
+func (q *Queries) ListJobsExpiringBetween(ctx context.Context, arg ListJobsExpiringBetweenParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsExpiringBetween, arg.StartDate, arg.EndDate, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsFollowUpBetween = `-- name: ListJobsFollowUpBetween :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE follow_up_at >= ?1 AND follow_up_at < ?2
+  AND (?3 = '' OR status = ?3)
+ORDER BY follow_up_at, name
+`
+
+type ListJobsFollowUpBetweenParams struct {
+	StartDate sql.NullString `json:"start_date"`
+	EndDate   sql.NullString `json:"end_date"`
+	Status    interface{}    `json:"status"`
+}
+
+func (q *Queries) ListJobsFollowUpBetween(ctx context.Context, arg ListJobsFollowUpBetweenParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsFollowUpBetween, arg.StartDate, arg.EndDate, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
 		); err != nil {
 			return nil, err
 		}
@@ -136,7 +358,7 @@ func (q *Queries) ListJobs(ctx context.Context) ([]Job, error) {
 }
 
 const listJobsPaginated = `-- name: ListJobsPaginated :many
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 WHERE (?1 = '' OR status = ?1)
 ORDER BY created_at DESC
 LIMIT ?3 OFFSET ?2
@@ -167,6 +389,9 @@ func (q *Queries) ListJobsPaginated(ctx context.Context, arg ListJobsPaginatedPa
 			&i.Status,
 			&i.ExpiresAt,
 			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +407,7 @@ func (q *Queries) ListJobsPaginated(ctx context.Context, arg ListJobsPaginatedPa
 }
 
 const listJobsPaginatedByName = `-- name: ListJobsPaginatedByName :many
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 WHERE (?1 = '' OR status = ?1)
 ORDER BY name ASC
 LIMIT ?3 OFFSET ?2
@@ -213,6 +438,9 @@ func (q *Queries) ListJobsPaginatedByName(ctx context.Context, arg ListJobsPagin
 			&i.Status,
 			&i.ExpiresAt,
 			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
 		); err != nil {
 			return nil, err
 		}
@@ -228,7 +456,7 @@ func (q *Queries) ListJobsPaginatedByName(ctx context.Context, arg ListJobsPagin
 }
 
 const listJobsPaginatedByNameDesc = `-- name: ListJobsPaginatedByNameDesc :many
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 WHERE (?1 = '' OR status = ?1)
 ORDER BY name DESC
 LIMIT ?3 OFFSET ?2
@@ -259,6 +487,9 @@ func (q *Queries) ListJobsPaginatedByNameDesc(ctx context.Context, arg ListJobsP
 			&i.Status,
 			&i.ExpiresAt,
 			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
 		); err != nil {
 			return nil, err
 		}
@@ -274,7 +505,7 @@ func (q *Queries) ListJobsPaginatedByNameDesc(ctx context.Context, arg ListJobsP
 }
 
 const listJobsPaginatedOldest = `-- name: ListJobsPaginatedOldest :many
-SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id FROM jobs
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
 WHERE (?1 = '' OR status = ?1)
 ORDER BY created_at ASC
 LIMIT ?3 OFFSET ?2
@@ -305,6 +536,149 @@ func (q *Queries) ListJobsPaginatedOldest(ctx context.Context, arg ListJobsPagin
 			&i.Status,
 			&i.ExpiresAt,
 			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueFollowUps = `-- name: ListOverdueFollowUps :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE follow_up_at < ?1
+  AND status IN ('draft', 'sent')
+  AND (?2 = '' OR status = ?2)
+ORDER BY follow_up_at, name
+`
+
+type ListOverdueFollowUpsParams struct {
+	Today  sql.NullString `json:"today"`
+	Status interface{}    `json:"status"`
+}
+
+func (q *Queries) ListOverdueFollowUps(ctx context.Context, arg ListOverdueFollowUpsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdueFollowUps, arg.Today, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentClientQuotes = `-- name: ListRecentClientQuotes :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE client_id = ? AND id != ? AND status != 'draft'
+ORDER BY created_at DESC
+LIMIT ?
+`
+
+type ListRecentClientQuotesParams struct {
+	ClientID sql.NullString `json:"client_id"`
+	ID       string         `json:"id"`
+	Limit    int64          `json:"limit"`
+}
+
+func (q *Queries) ListRecentClientQuotes(ctx context.Context, arg ListRecentClientQuotesParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentClientQuotes, arg.ClientID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnlinkedCustomerJobs = `-- name: ListUnlinkedCustomerJobs :many
+SELECT id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent FROM jobs
+WHERE client_id IS NULL AND TRIM(COALESCE(customer_name, '')) != ''
+ORDER BY created_at
+`
+
+func (q *Queries) ListUnlinkedCustomerJobs(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listUnlinkedCustomerJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CustomerName,
+			&i.SurchargePercent,
+			&i.SurchargeMode,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.ClientID,
+			&i.FollowUpAt,
+			&i.UpdatedAt,
+			&i.TaxPercent,
 		); err != nil {
 			return nil, err
 		}
@@ -329,7 +703,7 @@ UPDATE jobs SET
     expires_at = ?,
     client_id = ?
 WHERE id = ?
-RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id
+RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent
 `
 
 type UpdateJobParams struct {
@@ -365,12 +739,44 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, erro
 		&i.Status,
 		&i.ExpiresAt,
 		&i.ClientID,
+		&i.FollowUpAt,
+		&i.UpdatedAt,
+		&i.TaxPercent,
+	)
+	return i, err
+}
+
+const updateJobFollowUp = `-- name: UpdateJobFollowUp :one
+UPDATE jobs SET follow_up_at = ? WHERE id = ? RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent
+`
+
+type UpdateJobFollowUpParams struct {
+	FollowUpAt sql.NullString `json:"follow_up_at"`
+	ID         string         `json:"id"`
+}
+
+func (q *Queries) UpdateJobFollowUp(ctx context.Context, arg UpdateJobFollowUpParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, updateJobFollowUp, arg.FollowUpAt, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CustomerName,
+		&i.SurchargePercent,
+		&i.SurchargeMode,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.ClientID,
+		&i.FollowUpAt,
+		&i.UpdatedAt,
+		&i.TaxPercent,
 	)
 	return i, err
 }
 
 const updateJobStatus = `-- name: UpdateJobStatus :one
-UPDATE jobs SET status = ? WHERE id = ? RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id
+UPDATE jobs SET status = ? WHERE id = ? RETURNING id, name, customer_name, surcharge_percent, surcharge_mode, created_at, status, expires_at, client_id, follow_up_at, updated_at, tax_percent
 `
 
 type UpdateJobStatusParams struct {
@@ -391,6 +797,23 @@ func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams
 		&i.Status,
 		&i.ExpiresAt,
 		&i.ClientID,
+		&i.FollowUpAt,
+		&i.UpdatedAt,
+		&i.TaxPercent,
 	)
 	return i, err
 }
+
+const updateJobTaxPercent = `-- name: UpdateJobTaxPercent :exec
+UPDATE jobs SET tax_percent = ? WHERE id = ?
+`
+
+type UpdateJobTaxPercentParams struct {
+	TaxPercent float64 `json:"tax_percent"`
+	ID         string  `json:"id"`
+}
+
+func (q *Queries) UpdateJobTaxPercent(ctx context.Context, arg UpdateJobTaxPercentParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobTaxPercent, arg.TaxPercent, arg.ID)
+	return err
+}
@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// openTestDB returns a fresh in-memory database with all migrations applied.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	// Every connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	goose.SetBaseFS(os.DirFS("../../migrations"))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatalf("setting dialect: %v", err)
+	}
+	if err := goose.Up(db, "."); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	return db
+}
+
+func TestCountJobContents(t *testing.T) {
+	db := openTestDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO jobs (id, name) VALUES
+			('built', 'Built'), ('shell', 'Shell'), ('bare', 'Bare'), ('other', 'Other')`,
+		`INSERT INTO categories (id, job_id, parent_id, name) VALUES
+			('b-1', 'built', NULL, 'Framing'),
+			('b-1a', 'built', 'b-1', 'Walls'),
+			('b-2', 'built', NULL, 'Electrical'),
+			('s-1', 'shell', NULL, 'Framing'),
+			('o-1', 'other', NULL, 'Framing')`,
+		`INSERT INTO line_items (id, category_id, type, name, quantity, unit, unit_price) VALUES
+			('i-1', 'b-1', 'material', '2x4', 10, 'ea', 3.5),
+			('i-2', 'b-1a', 'labor', 'Framer', 8, 'hr', 50),
+			('i-3', 'b-1a', 'material', 'Nails', 1, 'box', 20),
+			('i-4', 'o-1', 'material', '2x6', 4, 'ea', 6)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seeding: %v", err)
+		}
+	}
+
+	rows, err := repository.New(db).CountJobContents(context.Background(), []string{"built", "shell", "bare"})
+	if err != nil {
+		t.Fatalf("CountJobContents: %v", err)
+	}
+
+	got := make(map[string]repository.CountJobContentsRow)
+	for _, row := range rows {
+		got[row.JobID] = row
+	}
+	if len(got) != 2 {
+		t.Errorf("rows = %+v, want built and shell only", rows)
+	}
+	if c := got["built"]; c.CategoryCount != 3 || c.ItemCount != 3 {
+		t.Errorf("built = %d categories, %d items; want 3, 3", c.CategoryCount, c.ItemCount)
+	}
+	if c := got["shell"]; c.CategoryCount != 1 || c.ItemCount != 0 {
+		t.Errorf("shell = %d categories, %d items; want 1, 0", c.CategoryCount, c.ItemCount)
+	}
+
+	if rows, err := repository.New(db).CountJobContents(context.Background(), nil); err != nil || len(rows) != 0 {
+		t.Errorf("no job IDs: rows = %v, err = %v", rows, err)
+	}
+}
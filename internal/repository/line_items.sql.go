@@ -8,25 +8,31 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const createLineItem = `-- name: CreateLineItem :one
-INSERT INTO line_items (id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order
+INSERT INTO line_items (id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase
 `
 
 type CreateLineItemParams struct {
-	ID               string          `json:"id"`
-	CategoryID       string          `json:"category_id"`
-	Type             string          `json:"type"`
-	Name             string          `json:"name"`
-	Description      sql.NullString  `json:"description"`
-	Quantity         float64         `json:"quantity"`
-	Unit             string          `json:"unit"`
-	UnitPrice        float64         `json:"unit_price"`
-	SurchargePercent sql.NullFloat64 `json:"surcharge_percent"`
-	SortOrder        int64           `json:"sort_order"`
+	ID                  string          `json:"id"`
+	CategoryID          string          `json:"category_id"`
+	Type                string          `json:"type"`
+	Name                string          `json:"name"`
+	Description         sql.NullString  `json:"description"`
+	Quantity            float64         `json:"quantity"`
+	Unit                string          `json:"unit"`
+	UnitPrice           float64         `json:"unit_price"`
+	SurchargePercent    sql.NullFloat64 `json:"surcharge_percent"`
+	SortOrder           int64           `json:"sort_order"`
+	ExemptFromSurcharge bool            `json:"exempt_from_surcharge"`
+	TemplateID          sql.NullInt64   `json:"template_id"`
+	IsCredit            bool            `json:"is_credit"`
+	TaxTreatment        string          `json:"tax_treatment"`
+	Phase               sql.NullString  `json:"phase"`
 }
 
 func (q *Queries) CreateLineItem(ctx context.Context, arg CreateLineItemParams) (LineItem, error) {
@@ -41,6 +47,11 @@ func (q *Queries) CreateLineItem(ctx context.Context, arg CreateLineItemParams)
 		arg.UnitPrice,
 		arg.SurchargePercent,
 		arg.SortOrder,
+		arg.ExemptFromSurcharge,
+		arg.TemplateID,
+		arg.IsCredit,
+		arg.TaxTreatment,
+		arg.Phase,
 	)
 	var i LineItem
 	err := row.Scan(
@@ -54,6 +65,11 @@ func (q *Queries) CreateLineItem(ctx context.Context, arg CreateLineItemParams)
 		&i.UnitPrice,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.ExemptFromSurcharge,
+		&i.TemplateID,
+		&i.IsCredit,
+		&i.TaxTreatment,
+		&i.Phase,
 	)
 	return i, err
 }
@@ -69,7 +85,7 @@ func (q *Queries) DeleteLineItem(ctx context.Context, id string) error {
 }
 
 const getLineItem = `-- name: GetLineItem :one
-SELECT id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order FROM line_items
+SELECT id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase FROM line_items
 WHERE id = ?
 `
 
@@ -87,12 +103,17 @@ func (q *Queries) GetLineItem(ctx context.Context, id string) (LineItem, error)
 		&i.UnitPrice,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.ExemptFromSurcharge,
+		&i.TemplateID,
+		&i.IsCredit,
+		&i.TaxTreatment,
+		&i.Phase,
 	)
 	return i, err
 }
 
 const listLineItemsByCategory = `-- name: ListLineItemsByCategory :many
-SELECT id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order FROM line_items
+SELECT id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase FROM line_items
 WHERE category_id = ?
 ORDER BY sort_order ASC
 `
@@ -117,6 +138,77 @@ func (q *Queries) ListLineItemsByCategory(ctx context.Context, categoryID string
 			&i.UnitPrice,
 			&i.SurchargePercent,
 			&i.SortOrder,
+			&i.ExemptFromSurcharge,
+			&i.TemplateID,
+			&i.IsCredit,
+			&i.TaxTreatment,
+			&i.Phase,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLineItemsByCategoryWithTemplatePrice = `-- name: ListLineItemsByCategoryWithTemplatePrice :many
+SELECT li.id, li.category_id, li.type, li.name, li.description, li.quantity, li.unit, li.unit_price, li.surcharge_percent, li.sort_order, li.exempt_from_surcharge, li.template_id, li.is_credit, li.tax_treatment, li.phase, t.default_price AS template_price FROM line_items li
+LEFT JOIN item_templates t ON li.template_id = t.id
+WHERE li.category_id = ?
+ORDER BY li.sort_order ASC
+`
+
+type ListLineItemsByCategoryWithTemplatePriceRow struct {
+	ID                  string          `json:"id"`
+	CategoryID          string          `json:"category_id"`
+	Type                string          `json:"type"`
+	Name                string          `json:"name"`
+	Description         sql.NullString  `json:"description"`
+	Quantity            float64         `json:"quantity"`
+	Unit                string          `json:"unit"`
+	UnitPrice           float64         `json:"unit_price"`
+	SurchargePercent    sql.NullFloat64 `json:"surcharge_percent"`
+	SortOrder           int64           `json:"sort_order"`
+	ExemptFromSurcharge bool            `json:"exempt_from_surcharge"`
+	TemplateID          sql.NullInt64   `json:"template_id"`
+	IsCredit            bool            `json:"is_credit"`
+	TaxTreatment        string          `json:"tax_treatment"`
+	Phase               sql.NullString  `json:"phase"`
+	TemplatePrice       sql.NullFloat64 `json:"template_price"`
+}
+
+func (q *Queries) ListLineItemsByCategoryWithTemplatePrice(ctx context.Context, categoryID string) ([]ListLineItemsByCategoryWithTemplatePriceRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLineItemsByCategoryWithTemplatePrice, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLineItemsByCategoryWithTemplatePriceRow{}
+	for rows.Next() {
+		var i ListLineItemsByCategoryWithTemplatePriceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Type,
+			&i.Name,
+			&i.Description,
+			&i.Quantity,
+			&i.Unit,
+			&i.UnitPrice,
+			&i.SurchargePercent,
+			&i.SortOrder,
+			&i.ExemptFromSurcharge,
+			&i.TemplateID,
+			&i.IsCredit,
+			&i.TaxTreatment,
+			&i.Phase,
+			&i.TemplatePrice,
 		); err != nil {
 			return nil, err
 		}
@@ -132,7 +224,7 @@ func (q *Queries) ListLineItemsByCategory(ctx context.Context, categoryID string
 }
 
 const listLineItemsByJob = `-- name: ListLineItemsByJob :many
-SELECT li.id, li.category_id, li.type, li.name, li.description, li.quantity, li.unit, li.unit_price, li.surcharge_percent, li.sort_order FROM line_items li
+SELECT li.id, li.category_id, li.type, li.name, li.description, li.quantity, li.unit, li.unit_price, li.surcharge_percent, li.sort_order, li.exempt_from_surcharge, li.template_id, li.is_credit, li.tax_treatment, li.phase FROM line_items li
 JOIN categories c ON li.category_id = c.id
 WHERE c.job_id = ?
 ORDER BY li.sort_order ASC
@@ -158,6 +250,112 @@ func (q *Queries) ListLineItemsByJob(ctx context.Context, jobID string) ([]LineI
 			&i.UnitPrice,
 			&i.SurchargePercent,
 			&i.SortOrder,
+			&i.ExemptFromSurcharge,
+			&i.TemplateID,
+			&i.IsCredit,
+			&i.TaxTreatment,
+			&i.Phase,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLineItemsByJobIDs = `-- name: ListLineItemsByJobIDs :many
+SELECT li.id, li.category_id, li.type, li.name, li.description, li.quantity, li.unit, li.unit_price, li.surcharge_percent, li.sort_order, li.exempt_from_surcharge, li.template_id, li.is_credit, li.tax_treatment, li.phase FROM line_items li
+JOIN categories c ON li.category_id = c.id
+WHERE c.job_id IN (/*SLICE:job_ids*/?)
+ORDER BY li.sort_order ASC
+`
+
+func (q *Queries) ListLineItemsByJobIDs(ctx context.Context, jobIds []string) ([]LineItem, error) {
+	query := listLineItemsByJobIDs
+	var queryParams []interface{}
+	if len(jobIds) > 0 {
+		for _, v := range jobIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", strings.Repeat(",?", len(jobIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:job_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LineItem{}
+	for rows.Next() {
+		var i LineItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Type,
+			&i.Name,
+			&i.Description,
+			&i.Quantity,
+			&i.Unit,
+			&i.UnitPrice,
+			&i.SurchargePercent,
+			&i.SortOrder,
+			&i.ExemptFromSurcharge,
+			&i.TemplateID,
+			&i.IsCredit,
+			&i.TaxTreatment,
+			&i.Phase,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderItemsByJob = `-- name: ListOrderItemsByJob :many
+SELECT li.type, li.name, li.quantity, li.unit, t.sku FROM line_items li
+JOIN categories c ON li.category_id = c.id
+LEFT JOIN item_templates t ON li.template_id = t.id
+WHERE c.job_id = ?
+ORDER BY li.sort_order ASC
+`
+
+type ListOrderItemsByJobRow struct {
+	Type     string         `json:"type"`
+	Name     string         `json:"name"`
+	Quantity float64        `json:"quantity"`
+	Unit     string         `json:"unit"`
+	Sku      sql.NullString `json:"sku"`
+}
+
+func (q *Queries) ListOrderItemsByJob(ctx context.Context, jobID string) ([]ListOrderItemsByJobRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderItemsByJob, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrderItemsByJobRow{}
+	for rows.Next() {
+		var i ListOrderItemsByJobRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.Name,
+			&i.Quantity,
+			&i.Unit,
+			&i.Sku,
 		); err != nil {
 			return nil, err
 		}
@@ -172,6 +370,41 @@ func (q *Queries) ListLineItemsByJob(ctx context.Context, jobID string) ([]LineI
 	return items, nil
 }
 
+const listTemplatePricesByJob = `-- name: ListTemplatePricesByJob :many
+SELECT li.id, t.default_price FROM line_items li
+JOIN categories c ON li.category_id = c.id
+JOIN item_templates t ON li.template_id = t.id
+WHERE c.job_id = ?
+`
+
+type ListTemplatePricesByJobRow struct {
+	ID           string  `json:"id"`
+	DefaultPrice float64 `json:"default_price"`
+}
+
+func (q *Queries) ListTemplatePricesByJob(ctx context.Context, jobID string) ([]ListTemplatePricesByJobRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTemplatePricesByJob, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTemplatePricesByJobRow{}
+	for rows.Next() {
+		var i ListTemplatePricesByJobRow
+		if err := rows.Scan(&i.ID, &i.DefaultPrice); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateLineItem = `-- name: UpdateLineItem :one
 UPDATE line_items SET
     type = ?,
@@ -181,21 +414,29 @@ UPDATE line_items SET
     unit = ?,
     unit_price = ?,
     surcharge_percent = ?,
-    sort_order = ?
+    sort_order = ?,
+    exempt_from_surcharge = ?,
+    is_credit = ?,
+    tax_treatment = ?,
+    phase = ?
 WHERE id = ?
-RETURNING id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order
+RETURNING id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase
 `
 
 type UpdateLineItemParams struct {
-	Type             string          `json:"type"`
-	Name             string          `json:"name"`
-	Description      sql.NullString  `json:"description"`
-	Quantity         float64         `json:"quantity"`
-	Unit             string          `json:"unit"`
-	UnitPrice        float64         `json:"unit_price"`
-	SurchargePercent sql.NullFloat64 `json:"surcharge_percent"`
-	SortOrder        int64           `json:"sort_order"`
-	ID               string          `json:"id"`
+	Type                string          `json:"type"`
+	Name                string          `json:"name"`
+	Description         sql.NullString  `json:"description"`
+	Quantity            float64         `json:"quantity"`
+	Unit                string          `json:"unit"`
+	UnitPrice           float64         `json:"unit_price"`
+	SurchargePercent    sql.NullFloat64 `json:"surcharge_percent"`
+	SortOrder           int64           `json:"sort_order"`
+	ExemptFromSurcharge bool            `json:"exempt_from_surcharge"`
+	IsCredit            bool            `json:"is_credit"`
+	TaxTreatment        string          `json:"tax_treatment"`
+	Phase               sql.NullString  `json:"phase"`
+	ID                  string          `json:"id"`
 }
 
 func (q *Queries) UpdateLineItem(ctx context.Context, arg UpdateLineItemParams) (LineItem, error) {
@@ -208,6 +449,10 @@ func (q *Queries) UpdateLineItem(ctx context.Context, arg UpdateLineItemParams)
 		arg.UnitPrice,
 		arg.SurchargePercent,
 		arg.SortOrder,
+		arg.ExemptFromSurcharge,
+		arg.IsCredit,
+		arg.TaxTreatment,
+		arg.Phase,
 		arg.ID,
 	)
 	var i LineItem
@@ -222,6 +467,41 @@ func (q *Queries) UpdateLineItem(ctx context.Context, arg UpdateLineItemParams)
 		&i.UnitPrice,
 		&i.SurchargePercent,
 		&i.SortOrder,
+		&i.ExemptFromSurcharge,
+		&i.TemplateID,
+		&i.IsCredit,
+		&i.TaxTreatment,
+		&i.Phase,
 	)
 	return i, err
 }
+
+const updateLineItemPrice = `-- name: UpdateLineItemPrice :exec
+UPDATE line_items SET unit_price = ?
+WHERE id = ?
+`
+
+type UpdateLineItemPriceParams struct {
+	UnitPrice float64 `json:"unit_price"`
+	ID        string  `json:"id"`
+}
+
+func (q *Queries) UpdateLineItemPrice(ctx context.Context, arg UpdateLineItemPriceParams) error {
+	_, err := q.db.ExecContext(ctx, updateLineItemPrice, arg.UnitPrice, arg.ID)
+	return err
+}
+
+const updateLineItemQuantity = `-- name: UpdateLineItemQuantity :exec
+UPDATE line_items SET quantity = ?
+WHERE id = ?
+`
+
+type UpdateLineItemQuantityParams struct {
+	Quantity float64 `json:"quantity"`
+	ID       string  `json:"id"`
+}
+
+func (q *Queries) UpdateLineItemQuantity(ctx context.Context, arg UpdateLineItemQuantityParams) error {
+	_, err := q.db.ExecContext(ctx, updateLineItemQuantity, arg.Quantity, arg.ID)
+	return err
+}
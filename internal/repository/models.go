@@ -15,6 +15,32 @@ type Category struct {
 	Name             string          `json:"name"`
 	SurchargePercent sql.NullFloat64 `json:"surcharge_percent"`
 	SortOrder        int64           `json:"sort_order"`
+	TaxTreatment     string          `json:"tax_treatment"`
+	Phase            sql.NullString  `json:"phase"`
+	DefaultItemType  sql.NullString  `json:"default_item_type"`
+	DefaultUnit      sql.NullString  `json:"default_unit"`
+}
+
+type CategoryShare struct {
+	Token        string         `json:"token"`
+	CategoryID   string         `json:"category_id"`
+	Mode         string         `json:"mode"`
+	ViewCount    int64          `json:"view_count"`
+	LastViewedAt sql.NullString `json:"last_viewed_at"`
+	RevokedAt    sql.NullString `json:"revoked_at"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+type CleanupRun struct {
+	ID              int64          `json:"id"`
+	DryRun          bool           `json:"dry_run"`
+	EmptyJobs       int64          `json:"empty_jobs"`
+	StaleImports    int64          `json:"stale_imports"`
+	ActivityEntries int64          `json:"activity_entries"`
+	Vacuumed        bool           `json:"vacuumed"`
+	ErrorMessage    sql.NullString `json:"error_message"`
+	CreatedAt       string         `json:"created_at"`
+	ExpiredImports  int64          `json:"expired_imports"`
 }
 
 type Client struct {
@@ -32,13 +58,76 @@ type Client struct {
 	CreatedAt string         `json:"created_at"`
 }
 
+type ImportUsage struct {
+	ID           int64  `json:"id"`
+	ImportID     string `json:"import_id"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type ItemComment struct {
+	ID        int64  `json:"id"`
+	ItemID    string `json:"item_id"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	Resolved  bool   `json:"resolved"`
+	CreatedAt string `json:"created_at"`
+}
+
 type ItemTemplate struct {
-	ID           int64   `json:"id"`
-	Type         string  `json:"type"`
-	Category     string  `json:"category"`
-	Name         string  `json:"name"`
-	DefaultUnit  string  `json:"default_unit"`
-	DefaultPrice float64 `json:"default_price"`
+	ID           int64           `json:"id"`
+	Type         string          `json:"type"`
+	Category     string          `json:"category"`
+	Name         string          `json:"name"`
+	DefaultUnit  string          `json:"default_unit"`
+	DefaultPrice float64         `json:"default_price"`
+	Sku          sql.NullString  `json:"sku"`
+	DayRate      sql.NullFloat64 `json:"day_rate"`
+	WeekRate     sql.NullFloat64 `json:"week_rate"`
+	MonthRate    sql.NullFloat64 `json:"month_rate"`
+}
+
+type ItemTemplatePriceHistory struct {
+	ID             int64          `json:"id"`
+	TemplateID     int64          `json:"template_id"`
+	OldPrice       float64        `json:"old_price"`
+	NewPrice       float64        `json:"new_price"`
+	Source         string         `json:"source"`
+	ImportID       sql.NullString `json:"import_id"`
+	ImportFilename sql.NullString `json:"import_filename"`
+	RequestID      sql.NullString `json:"request_id"`
+	CreatedAt      string         `json:"created_at"`
+}
+
+type JobActivity struct {
+	ID        int64  `json:"id"`
+	JobID     string `json:"job_id"`
+	Action    string `json:"action"`
+	Detail    string `json:"detail"`
+	CreatedAt string `json:"created_at"`
+	Actor     string `json:"actor"`
+}
+
+type JobCustomField struct {
+	ID          int64          `json:"id"`
+	Label       string         `json:"label"`
+	FieldType   string         `json:"field_type"`
+	ShowOnQuote bool           `json:"show_on_quote"`
+	ArchivedAt  sql.NullString `json:"archived_at"`
+	CreatedAt   string         `json:"created_at"`
+}
+
+type JobCustomFieldValue struct {
+	JobID   string `json:"job_id"`
+	FieldID int64  `json:"field_id"`
+	Value   string `json:"value"`
+}
+
+type JobPreflightDismissal struct {
+	JobID     string `json:"job_id"`
+	Rule      string `json:"rule"`
+	CreatedAt string `json:"created_at"`
 }
 
 type Job struct {
@@ -51,19 +140,34 @@ type Job struct {
 	Status           string         `json:"status"`
 	ExpiresAt        sql.NullString `json:"expires_at"`
 	ClientID         sql.NullString `json:"client_id"`
+	FollowUpAt       sql.NullString `json:"follow_up_at"`
+	UpdatedAt        string         `json:"updated_at"`
+	TaxPercent       float64        `json:"tax_percent"`
 }
 
 type LineItem struct {
-	ID               string          `json:"id"`
-	CategoryID       string          `json:"category_id"`
-	Type             string          `json:"type"`
-	Name             string          `json:"name"`
-	Description      sql.NullString  `json:"description"`
-	Quantity         float64         `json:"quantity"`
-	Unit             string          `json:"unit"`
-	UnitPrice        float64         `json:"unit_price"`
-	SurchargePercent sql.NullFloat64 `json:"surcharge_percent"`
-	SortOrder        int64           `json:"sort_order"`
+	ID                  string          `json:"id"`
+	CategoryID          string          `json:"category_id"`
+	Type                string          `json:"type"`
+	Name                string          `json:"name"`
+	Description         sql.NullString  `json:"description"`
+	Quantity            float64         `json:"quantity"`
+	Unit                string          `json:"unit"`
+	UnitPrice           float64         `json:"unit_price"`
+	SurchargePercent    sql.NullFloat64 `json:"surcharge_percent"`
+	SortOrder           int64           `json:"sort_order"`
+	ExemptFromSurcharge bool            `json:"exempt_from_surcharge"`
+	TemplateID          sql.NullInt64   `json:"template_id"`
+	IsCredit            bool            `json:"is_credit"`
+	TaxTreatment        string          `json:"tax_treatment"`
+	Phase               sql.NullString  `json:"phase"`
+}
+
+type MatchCorrection struct {
+	ID         int64  `json:"id"`
+	SourceName string `json:"source_name"`
+	TemplateID int64  `json:"template_id"`
+	CreatedAt  string `json:"created_at"`
 }
 
 type PriceImport struct {
@@ -75,6 +179,12 @@ type PriceImport struct {
 	ErrorMessage sql.NullString `json:"error_message"`
 	CreatedAt    string         `json:"created_at"`
 	AppliedAt    sql.NullString `json:"applied_at"`
+	BatchID      sql.NullString `json:"batch_id"`
+}
+
+type PriceImportBatch struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"created_at"`
 }
 
 type PriceImportMatch struct {
@@ -90,10 +200,59 @@ type PriceImportMatch struct {
 	Status            string         `json:"status"`
 	NewName           sql.NullString `json:"new_name"`
 	CreatedAt         string         `json:"created_at"`
+	SourceCategory    sql.NullString `json:"source_category"`
 }
 
 type Setting struct {
 	ID                      string  `json:"id"`
 	DefaultSurchargeMode    string  `json:"default_surcharge_mode"`
 	DefaultSurchargePercent float64 `json:"default_surcharge_percent"`
+	MinimumJobTotal         float64 `json:"minimum_job_total"`
+	MobilizationFee         float64 `json:"mobilization_fee"`
+	CleanupEmptyJobDays     int64   `json:"cleanup_empty_job_days"`
+	CleanupImportDays       int64   `json:"cleanup_import_days"`
+	CleanupActivityDays     int64   `json:"cleanup_activity_days"`
+	CleanupDryRun           bool    `json:"cleanup_dry_run"`
+	Theme                   string  `json:"theme"`
+	CompanyName             string  `json:"company_name"`
+	CompanyAddress          string  `json:"company_address"`
+	CompanyPhone            string  `json:"company_phone"`
+	CompanyEmail            string  `json:"company_email"`
+	PageSize                int64   `json:"page_size"`
+	SurchargeCredits        bool    `json:"surcharge_credits"`
+	DeclineWarning          bool    `json:"decline_warning"`
+	DeclineStreak           int64   `json:"decline_streak"`
+	ImportReminderDays      int64   `json:"import_reminder_days"`
+	ImportStaleDays         int64   `json:"import_stale_days"`
+	TotalAlertPercent       float64 `json:"total_alert_percent"`
+	CostMarginPercent       float64 `json:"cost_margin_percent"`
+	AiBudget                float64 `json:"ai_budget"`
+	AiBudgetUnit            string  `json:"ai_budget_unit"`
+	AiBudgetOverride        bool    `json:"ai_budget_override"`
+}
+
+type SupplierExportColumn struct {
+	MappingID int64  `json:"mapping_id"`
+	Field     string `json:"field"`
+	Position  int64  `json:"position"`
+	Header    string `json:"header"`
+}
+
+type SupplierExportMapping struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+type TotalChangeAlert struct {
+	ID         int64          `json:"id"`
+	JobID      string         `json:"job_id"`
+	LineItemID string         `json:"line_item_id"`
+	Action     string         `json:"action"`
+	Previous   sql.NullString `json:"previous"`
+	OldTotal   float64        `json:"old_total"`
+	NewTotal   float64        `json:"new_total"`
+	Status     string         `json:"status"`
+	CreatedAt  string         `json:"created_at"`
+	Result     sql.NullString `json:"result"`
 }
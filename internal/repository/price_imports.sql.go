@@ -26,6 +26,34 @@ func (q *Queries) BulkAutoApproveMatches(ctx context.Context, arg BulkAutoApprov
 	return err
 }
 
+const clearMatchedTemplate = `-- name: ClearMatchedTemplate :exec
+UPDATE price_import_matches SET matched_template_id = NULL
+WHERE matched_template_id = ?
+`
+
+func (q *Queries) ClearMatchedTemplate(ctx context.Context, matchedTemplateID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, clearMatchedTemplate, matchedTemplateID)
+	return err
+}
+
+const countCreatedTemplatesByName = `-- name: CountCreatedTemplatesByName :one
+SELECT COUNT(*) FROM price_import_matches m
+JOIN item_templates t ON t.id = m.matched_template_id
+WHERE m.import_id = ? AND m.status = 'created' AND t.name = ? COLLATE NOCASE
+`
+
+type CountCreatedTemplatesByNameParams struct {
+	ImportID string `json:"import_id"`
+	Name     string `json:"name"`
+}
+
+func (q *Queries) CountCreatedTemplatesByName(ctx context.Context, arg CountCreatedTemplatesByNameParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCreatedTemplatesByName, arg.ImportID, arg.Name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countMatchesByStatus = `-- name: CountMatchesByStatus :many
 SELECT status, COUNT(*) as count
 FROM price_import_matches
@@ -61,17 +89,29 @@ func (q *Queries) CountMatchesByStatus(ctx context.Context, importID string) ([]
 	return items, nil
 }
 
+const countPriceImports = `-- name: CountPriceImports :one
+SELECT COUNT(*) FROM price_imports
+`
+
+func (q *Queries) CountPriceImports(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPriceImports)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPriceImport = `-- name: CreatePriceImport :one
-INSERT INTO price_imports (id, filename, status, total_rows)
-VALUES (?, ?, ?, ?)
-RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at
+INSERT INTO price_imports (id, filename, status, total_rows, batch_id)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at, batch_id
 `
 
 type CreatePriceImportParams struct {
-	ID        string `json:"id"`
-	Filename  string `json:"filename"`
-	Status    string `json:"status"`
-	TotalRows int64  `json:"total_rows"`
+	ID        string         `json:"id"`
+	Filename  string         `json:"filename"`
+	Status    string         `json:"status"`
+	TotalRows int64          `json:"total_rows"`
+	BatchID   sql.NullString `json:"batch_id"`
 }
 
 func (q *Queries) CreatePriceImport(ctx context.Context, arg CreatePriceImportParams) (PriceImport, error) {
@@ -80,6 +120,7 @@ func (q *Queries) CreatePriceImport(ctx context.Context, arg CreatePriceImportPa
 		arg.Filename,
 		arg.Status,
 		arg.TotalRows,
+		arg.BatchID,
 	)
 	var i PriceImport
 	err := row.Scan(
@@ -91,6 +132,7 @@ func (q *Queries) CreatePriceImport(ctx context.Context, arg CreatePriceImportPa
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.AppliedAt,
+		&i.BatchID,
 	)
 	return i, err
 }
@@ -98,9 +140,9 @@ func (q *Queries) CreatePriceImport(ctx context.Context, arg CreatePriceImportPa
 const createPriceImportMatch = `-- name: CreatePriceImportMatch :one
 INSERT INTO price_import_matches (
     import_id, row_number, source_name, source_unit, source_price,
-    matched_template_id, confidence, match_reason, status
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at
+    matched_template_id, confidence, match_reason, status, source_category
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category
 `
 
 type CreatePriceImportMatchParams struct {
@@ -113,6 +155,7 @@ type CreatePriceImportMatchParams struct {
 	Confidence        float64        `json:"confidence"`
 	MatchReason       sql.NullString `json:"match_reason"`
 	Status            string         `json:"status"`
+	SourceCategory    sql.NullString `json:"source_category"`
 }
 
 func (q *Queries) CreatePriceImportMatch(ctx context.Context, arg CreatePriceImportMatchParams) (PriceImportMatch, error) {
@@ -126,6 +169,7 @@ func (q *Queries) CreatePriceImportMatch(ctx context.Context, arg CreatePriceImp
 		arg.Confidence,
 		arg.MatchReason,
 		arg.Status,
+		arg.SourceCategory,
 	)
 	var i PriceImportMatch
 	err := row.Scan(
@@ -141,12 +185,32 @@ func (q *Queries) CreatePriceImportMatch(ctx context.Context, arg CreatePriceImp
 		&i.Status,
 		&i.NewName,
 		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
 
+const getImportReminder = `-- name: GetImportReminder :one
+SELECT
+    COUNT(CASE WHEN status = 'ready' AND created_at < ? THEN 1 END) AS overdue,
+    COUNT(CASE WHEN status = 'stale' THEN 1 END) AS stale
+FROM price_imports
+`
+
+type GetImportReminderRow struct {
+	Overdue int64 `json:"overdue"`
+	Stale   int64 `json:"stale"`
+}
+
+func (q *Queries) GetImportReminder(ctx context.Context, createdAt string) (GetImportReminderRow, error) {
+	row := q.db.QueryRowContext(ctx, getImportReminder, createdAt)
+	var i GetImportReminderRow
+	err := row.Scan(&i.Overdue, &i.Stale)
+	return i, err
+}
+
 const getPriceImport = `-- name: GetPriceImport :one
-SELECT id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at FROM price_imports WHERE id = ?
+SELECT id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at, batch_id FROM price_imports WHERE id = ?
 `
 
 func (q *Queries) GetPriceImport(ctx context.Context, id string) (PriceImport, error) {
@@ -161,14 +225,42 @@ func (q *Queries) GetPriceImport(ctx context.Context, id string) (PriceImport, e
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.AppliedAt,
+		&i.BatchID,
+	)
+	return i, err
+}
+
+const getPriceImportMatch = `-- name: GetPriceImportMatch :one
+SELECT id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category FROM price_import_matches
+WHERE id = ?
+`
+
+func (q *Queries) GetPriceImportMatch(ctx context.Context, id int64) (PriceImportMatch, error) {
+	row := q.db.QueryRowContext(ctx, getPriceImportMatch, id)
+	var i PriceImportMatch
+	err := row.Scan(
+		&i.ID,
+		&i.ImportID,
+		&i.RowNumber,
+		&i.SourceName,
+		&i.SourceUnit,
+		&i.SourcePrice,
+		&i.MatchedTemplateID,
+		&i.Confidence,
+		&i.MatchReason,
+		&i.Status,
+		&i.NewName,
+		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
 
 const listApprovedMatches = `-- name: ListApprovedMatches :many
 SELECT
-    m.id, m.import_id, m.row_number, m.source_name, m.source_unit, m.source_price, m.matched_template_id, m.confidence, m.match_reason, m.status, m.new_name, m.created_at,
-    t.name as template_name
+    m.id, m.import_id, m.row_number, m.source_name, m.source_unit, m.source_price, m.matched_template_id, m.confidence, m.match_reason, m.status, m.new_name, m.created_at, m.source_category,
+    t.name as template_name,
+    t.default_price as template_price
 FROM price_import_matches m
 JOIN item_templates t ON m.matched_template_id = t.id
 WHERE m.import_id = ? AND m.status IN ('approved', 'auto_approved')
@@ -187,7 +279,9 @@ type ListApprovedMatchesRow struct {
 	Status            string         `json:"status"`
 	NewName           sql.NullString `json:"new_name"`
 	CreatedAt         string         `json:"created_at"`
+	SourceCategory    sql.NullString `json:"source_category"`
 	TemplateName      string         `json:"template_name"`
+	TemplatePrice     float64        `json:"template_price"`
 }
 
 func (q *Queries) ListApprovedMatches(ctx context.Context, importID string) ([]ListApprovedMatchesRow, error) {
@@ -212,7 +306,9 @@ func (q *Queries) ListApprovedMatches(ctx context.Context, importID string) ([]L
 			&i.Status,
 			&i.NewName,
 			&i.CreatedAt,
+			&i.SourceCategory,
 			&i.TemplateName,
+			&i.TemplatePrice,
 		); err != nil {
 			return nil, err
 		}
@@ -229,7 +325,7 @@ func (q *Queries) ListApprovedMatches(ctx context.Context, importID string) ([]L
 
 const listMatchesByImport = `-- name: ListMatchesByImport :many
 SELECT
-    m.id, m.import_id, m.row_number, m.source_name, m.source_unit, m.source_price, m.matched_template_id, m.confidence, m.match_reason, m.status, m.new_name, m.created_at,
+    m.id, m.import_id, m.row_number, m.source_name, m.source_unit, m.source_price, m.matched_template_id, m.confidence, m.match_reason, m.status, m.new_name, m.created_at, m.source_category,
     t.name as template_name,
     t.default_unit as template_unit,
     t.default_price as template_price
@@ -252,6 +348,7 @@ type ListMatchesByImportRow struct {
 	Status            string          `json:"status"`
 	NewName           sql.NullString  `json:"new_name"`
 	CreatedAt         string          `json:"created_at"`
+	SourceCategory    sql.NullString  `json:"source_category"`
 	TemplateName      sql.NullString  `json:"template_name"`
 	TemplateUnit      sql.NullString  `json:"template_unit"`
 	TemplatePrice     sql.NullFloat64 `json:"template_price"`
@@ -279,6 +376,7 @@ func (q *Queries) ListMatchesByImport(ctx context.Context, importID string) ([]L
 			&i.Status,
 			&i.NewName,
 			&i.CreatedAt,
+			&i.SourceCategory,
 			&i.TemplateName,
 			&i.TemplateUnit,
 			&i.TemplatePrice,
@@ -296,9 +394,93 @@ func (q *Queries) ListMatchesByImport(ctx context.Context, importID string) ([]L
 	return items, nil
 }
 
+const listPriceImportImpact = `-- name: ListPriceImportImpact :many
+SELECT
+    t.id AS template_id,
+    t.name AS template_name,
+    t.default_unit,
+    m.source_price AS new_price,
+    AVG(used.unit_price) AS avg_used_price,
+    COUNT(used.id) AS usage_count
+FROM price_import_matches m
+JOIN item_templates t ON m.matched_template_id = t.id
+LEFT JOIN (
+    SELECT li.id, li.template_id, li.unit_price
+    FROM line_items li
+    JOIN categories c ON li.category_id = c.id
+    JOIN jobs j ON c.job_id = j.id
+    WHERE j.created_at >= ?1
+) used ON used.template_id = t.id
+WHERE m.import_id = ?2 AND m.status IN ('approved', 'auto_approved')
+GROUP BY m.id
+ORDER BY t.name
+`
+
+type ListPriceImportImpactParams struct {
+	Since    string `json:"since"`
+	ImportID string `json:"import_id"`
+}
+
+type ListPriceImportImpactRow struct {
+	TemplateID   int64           `json:"template_id"`
+	TemplateName string          `json:"template_name"`
+	DefaultUnit  string          `json:"default_unit"`
+	NewPrice     float64         `json:"new_price"`
+	AvgUsedPrice sql.NullFloat64 `json:"avg_used_price"`
+	UsageCount   int64           `json:"usage_count"`
+}
+
+func (q *Queries) ListPriceImportImpact(ctx context.Context, arg ListPriceImportImpactParams) ([]ListPriceImportImpactRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPriceImportImpact, arg.Since, arg.ImportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPriceImportImpactRow{}
+	for rows.Next() {
+		var i ListPriceImportImpactRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.TemplateName,
+			&i.DefaultUnit,
+			&i.NewPrice,
+			&i.AvgUsedPrice,
+			&i.UsageCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPriceImports = `-- name: ListPriceImports :many
-SELECT id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at FROM price_imports
-ORDER BY created_at DESC
+SELECT id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at, batch_id FROM price_imports
+ORDER BY
+    (
+        SELECT MIN(CASE
+            WHEN b.status = 'stale' THEN 0
+            WHEN b.status = 'ready' AND EXISTS (
+                SELECT 1 FROM price_import_matches m
+                WHERE m.import_id = b.id AND m.status = 'pending'
+            ) THEN 1
+            ELSE 2
+        END)
+        FROM price_imports b
+        WHERE b.id = price_imports.id OR b.batch_id = price_imports.batch_id
+    ),
+    (
+        SELECT MAX(b.created_at) FROM price_imports b
+        WHERE b.id = price_imports.id OR b.batch_id = price_imports.batch_id
+    ) DESC,
+    batch_id,
+    filename
 LIMIT ? OFFSET ?
 `
 
@@ -325,6 +507,7 @@ func (q *Queries) ListPriceImports(ctx context.Context, arg ListPriceImportsPara
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.AppliedAt,
+			&i.BatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -339,8 +522,48 @@ func (q *Queries) ListPriceImports(ctx context.Context, arg ListPriceImportsPara
 	return items, nil
 }
 
+const listUnappliedTemplateMatchImports = `-- name: ListUnappliedTemplateMatchImports :many
+SELECT i.id AS import_id, i.filename, COUNT(*) AS match_count
+FROM price_import_matches m
+JOIN price_imports i ON m.import_id = i.id
+WHERE m.matched_template_id = ?
+  AND m.status IN ('pending', 'approved', 'auto_approved')
+  AND i.status != 'applied'
+GROUP BY i.id, i.filename
+ORDER BY i.created_at
+`
+
+type ListUnappliedTemplateMatchImportsRow struct {
+	ImportID   string `json:"import_id"`
+	Filename   string `json:"filename"`
+	MatchCount int64  `json:"match_count"`
+}
+
+func (q *Queries) ListUnappliedTemplateMatchImports(ctx context.Context, matchedTemplateID sql.NullInt64) ([]ListUnappliedTemplateMatchImportsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnappliedTemplateMatchImports, matchedTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnappliedTemplateMatchImportsRow{}
+	for rows.Next() {
+		var i ListUnappliedTemplateMatchImportsRow
+		if err := rows.Scan(&i.ImportID, &i.Filename, &i.MatchCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUnmatchedItems = `-- name: ListUnmatchedItems :many
-SELECT id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at FROM price_import_matches
+SELECT id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category FROM price_import_matches
 WHERE import_id = ? AND matched_template_id IS NULL AND status = 'pending'
 ORDER BY row_number
 `
@@ -367,6 +590,7 @@ func (q *Queries) ListUnmatchedItems(ctx context.Context, importID string) ([]Pr
 			&i.Status,
 			&i.NewName,
 			&i.CreatedAt,
+			&i.SourceCategory,
 		); err != nil {
 			return nil, err
 		}
@@ -385,7 +609,7 @@ const markMatchAsCreated = `-- name: MarkMatchAsCreated :one
 UPDATE price_import_matches
 SET status = 'created', matched_template_id = ?
 WHERE id = ?
-RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at
+RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category
 `
 
 type MarkMatchAsCreatedParams struct {
@@ -409,6 +633,7 @@ func (q *Queries) MarkMatchAsCreated(ctx context.Context, arg MarkMatchAsCreated
 		&i.Status,
 		&i.NewName,
 		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
@@ -417,7 +642,7 @@ const markPriceImportApplied = `-- name: MarkPriceImportApplied :one
 UPDATE price_imports
 SET status = 'applied', applied_at = datetime('now')
 WHERE id = ?
-RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at
+RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at, batch_id
 `
 
 func (q *Queries) MarkPriceImportApplied(ctx context.Context, id string) (PriceImport, error) {
@@ -432,12 +657,62 @@ func (q *Queries) MarkPriceImportApplied(ctx context.Context, id string) (PriceI
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.AppliedAt,
+		&i.BatchID,
+	)
+	return i, err
+}
+
+const rejectUnappliedTemplateMatches = `-- name: RejectUnappliedTemplateMatches :execrows
+UPDATE price_import_matches SET status = 'rejected'
+WHERE matched_template_id = ?
+  AND status IN ('pending', 'approved', 'auto_approved')
+  AND import_id IN (SELECT id FROM price_imports WHERE status != 'applied')
+`
+
+func (q *Queries) RejectUnappliedTemplateMatches(ctx context.Context, matchedTemplateID sql.NullInt64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rejectUnappliedTemplateMatches, matchedTemplateID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rematchPriceImportMatch = `-- name: RematchPriceImportMatch :one
+UPDATE price_import_matches
+SET status = 'approved', matched_template_id = ?, confidence = 1.0,
+    match_reason = 'Matched by hand', new_name = NULL
+WHERE id = ?
+RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category
+`
+
+type RematchPriceImportMatchParams struct {
+	MatchedTemplateID sql.NullInt64 `json:"matched_template_id"`
+	ID                int64         `json:"id"`
+}
+
+func (q *Queries) RematchPriceImportMatch(ctx context.Context, arg RematchPriceImportMatchParams) (PriceImportMatch, error) {
+	row := q.db.QueryRowContext(ctx, rematchPriceImportMatch, arg.MatchedTemplateID, arg.ID)
+	var i PriceImportMatch
+	err := row.Scan(
+		&i.ID,
+		&i.ImportID,
+		&i.RowNumber,
+		&i.SourceName,
+		&i.SourceUnit,
+		&i.SourcePrice,
+		&i.MatchedTemplateID,
+		&i.Confidence,
+		&i.MatchReason,
+		&i.Status,
+		&i.NewName,
+		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
 
 const updateMatchStatus = `-- name: UpdateMatchStatus :one
-UPDATE price_import_matches SET status = ? WHERE id = ? RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at
+UPDATE price_import_matches SET status = ? WHERE id = ? RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category
 `
 
 type UpdateMatchStatusParams struct {
@@ -461,6 +736,7 @@ func (q *Queries) UpdateMatchStatus(ctx context.Context, arg UpdateMatchStatusPa
 		&i.Status,
 		&i.NewName,
 		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
@@ -469,7 +745,7 @@ const updateMatchWithName = `-- name: UpdateMatchWithName :one
 UPDATE price_import_matches
 SET status = ?, new_name = ?
 WHERE id = ?
-RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at
+RETURNING id, import_id, row_number, source_name, source_unit, source_price, matched_template_id, confidence, match_reason, status, new_name, created_at, source_category
 `
 
 type UpdateMatchWithNameParams struct {
@@ -494,6 +770,7 @@ func (q *Queries) UpdateMatchWithName(ctx context.Context, arg UpdateMatchWithNa
 		&i.Status,
 		&i.NewName,
 		&i.CreatedAt,
+		&i.SourceCategory,
 	)
 	return i, err
 }
@@ -502,7 +779,7 @@ const updatePriceImportStatus = `-- name: UpdatePriceImportStatus :one
 UPDATE price_imports
 SET status = ?, matched_rows = ?, error_message = ?, total_rows = ?
 WHERE id = ?
-RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at
+RETURNING id, filename, status, total_rows, matched_rows, error_message, created_at, applied_at, batch_id
 `
 
 type UpdatePriceImportStatusParams struct {
@@ -531,6 +808,7 @@ func (q *Queries) UpdatePriceImportStatus(ctx context.Context, arg UpdatePriceIm
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.AppliedAt,
+		&i.BatchID,
 	)
 	return i, err
 }
@@ -9,34 +9,297 @@ import (
 	"context"
 )
 
+const allowBudgetOverride = `-- name: AllowBudgetOverride :exec
+UPDATE settings SET ai_budget_override = 1
+WHERE id = 'default'
+`
+
+func (q *Queries) AllowBudgetOverride(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, allowBudgetOverride)
+	return err
+}
+
 const getSettings = `-- name: GetSettings :one
-SELECT id, default_surcharge_mode, default_surcharge_percent FROM settings
+SELECT id, default_surcharge_mode, default_surcharge_percent, minimum_job_total, mobilization_fee, cleanup_empty_job_days, cleanup_import_days, cleanup_activity_days, cleanup_dry_run, theme, company_name, company_address, company_phone, company_email, page_size, surcharge_credits, decline_warning, decline_streak, import_reminder_days, import_stale_days, total_alert_percent, cost_margin_percent, ai_budget, ai_budget_unit, ai_budget_override FROM settings
 WHERE id = 'default'
 `
 
 func (q *Queries) GetSettings(ctx context.Context) (Setting, error) {
 	row := q.db.QueryRowContext(ctx, getSettings)
 	var i Setting
-	err := row.Scan(&i.ID, &i.DefaultSurchargeMode, &i.DefaultSurchargePercent)
+	err := row.Scan(
+		&i.ID,
+		&i.DefaultSurchargeMode,
+		&i.DefaultSurchargePercent,
+		&i.MinimumJobTotal,
+		&i.MobilizationFee,
+		&i.CleanupEmptyJobDays,
+		&i.CleanupImportDays,
+		&i.CleanupActivityDays,
+		&i.CleanupDryRun,
+		&i.Theme,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.CompanyEmail,
+		&i.PageSize,
+		&i.SurchargeCredits,
+		&i.DeclineWarning,
+		&i.DeclineStreak,
+		&i.ImportReminderDays,
+		&i.ImportStaleDays,
+		&i.TotalAlertPercent,
+		&i.CostMarginPercent,
+		&i.AiBudget,
+		&i.AiBudgetUnit,
+		&i.AiBudgetOverride,
+	)
+	return i, err
+}
+
+const updateCleanupSettings = `-- name: UpdateCleanupSettings :one
+UPDATE settings SET
+    cleanup_empty_job_days = ?,
+    cleanup_import_days = ?,
+    cleanup_activity_days = ?,
+    cleanup_dry_run = ?,
+    import_reminder_days = ?,
+    import_stale_days = ?
+WHERE id = 'default'
+RETURNING id, default_surcharge_mode, default_surcharge_percent, minimum_job_total, mobilization_fee, cleanup_empty_job_days, cleanup_import_days, cleanup_activity_days, cleanup_dry_run, theme, company_name, company_address, company_phone, company_email, page_size, surcharge_credits, decline_warning, decline_streak, import_reminder_days, import_stale_days, total_alert_percent, cost_margin_percent, ai_budget, ai_budget_unit, ai_budget_override
+`
+
+type UpdateCleanupSettingsParams struct {
+	CleanupEmptyJobDays int64 `json:"cleanup_empty_job_days"`
+	CleanupImportDays   int64 `json:"cleanup_import_days"`
+	CleanupActivityDays int64 `json:"cleanup_activity_days"`
+	CleanupDryRun       bool  `json:"cleanup_dry_run"`
+	ImportReminderDays  int64 `json:"import_reminder_days"`
+	ImportStaleDays     int64 `json:"import_stale_days"`
+}
+
+func (q *Queries) UpdateCleanupSettings(ctx context.Context, arg UpdateCleanupSettingsParams) (Setting, error) {
+	row := q.db.QueryRowContext(ctx, updateCleanupSettings,
+		arg.CleanupEmptyJobDays,
+		arg.CleanupImportDays,
+		arg.CleanupActivityDays,
+		arg.CleanupDryRun,
+		arg.ImportReminderDays,
+		arg.ImportStaleDays,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.DefaultSurchargeMode,
+		&i.DefaultSurchargePercent,
+		&i.MinimumJobTotal,
+		&i.MobilizationFee,
+		&i.CleanupEmptyJobDays,
+		&i.CleanupImportDays,
+		&i.CleanupActivityDays,
+		&i.CleanupDryRun,
+		&i.Theme,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.CompanyEmail,
+		&i.PageSize,
+		&i.SurchargeCredits,
+		&i.DeclineWarning,
+		&i.DeclineStreak,
+		&i.ImportReminderDays,
+		&i.ImportStaleDays,
+		&i.TotalAlertPercent,
+		&i.CostMarginPercent,
+		&i.AiBudget,
+		&i.AiBudgetUnit,
+		&i.AiBudgetOverride,
+	)
+	return i, err
+}
+
+const updateCompanySettings = `-- name: UpdateCompanySettings :one
+UPDATE settings SET
+    company_name = ?,
+    company_address = ?,
+    company_phone = ?,
+    company_email = ?
+WHERE id = 'default'
+RETURNING id, default_surcharge_mode, default_surcharge_percent, minimum_job_total, mobilization_fee, cleanup_empty_job_days, cleanup_import_days, cleanup_activity_days, cleanup_dry_run, theme, company_name, company_address, company_phone, company_email, page_size, surcharge_credits, decline_warning, decline_streak, import_reminder_days, import_stale_days, total_alert_percent, cost_margin_percent, ai_budget, ai_budget_unit, ai_budget_override
+`
+
+type UpdateCompanySettingsParams struct {
+	CompanyName    string `json:"company_name"`
+	CompanyAddress string `json:"company_address"`
+	CompanyPhone   string `json:"company_phone"`
+	CompanyEmail   string `json:"company_email"`
+}
+
+func (q *Queries) UpdateCompanySettings(ctx context.Context, arg UpdateCompanySettingsParams) (Setting, error) {
+	row := q.db.QueryRowContext(ctx, updateCompanySettings,
+		arg.CompanyName,
+		arg.CompanyAddress,
+		arg.CompanyPhone,
+		arg.CompanyEmail,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.DefaultSurchargeMode,
+		&i.DefaultSurchargePercent,
+		&i.MinimumJobTotal,
+		&i.MobilizationFee,
+		&i.CleanupEmptyJobDays,
+		&i.CleanupImportDays,
+		&i.CleanupActivityDays,
+		&i.CleanupDryRun,
+		&i.Theme,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.CompanyEmail,
+		&i.PageSize,
+		&i.SurchargeCredits,
+		&i.DeclineWarning,
+		&i.DeclineStreak,
+		&i.ImportReminderDays,
+		&i.ImportStaleDays,
+		&i.TotalAlertPercent,
+		&i.CostMarginPercent,
+		&i.AiBudget,
+		&i.AiBudgetUnit,
+		&i.AiBudgetOverride,
+	)
 	return i, err
 }
 
 const updateSettings = `-- name: UpdateSettings :one
 UPDATE settings SET
     default_surcharge_mode = ?,
-    default_surcharge_percent = ?
+    default_surcharge_percent = ?,
+    minimum_job_total = ?,
+    mobilization_fee = ?,
+    page_size = ?,
+    surcharge_credits = ?,
+    decline_warning = ?,
+    decline_streak = ?,
+    total_alert_percent = ?,
+    cost_margin_percent = ?,
+    ai_budget = ?,
+    ai_budget_unit = ?
 WHERE id = 'default'
-RETURNING id, default_surcharge_mode, default_surcharge_percent
+RETURNING id, default_surcharge_mode, default_surcharge_percent, minimum_job_total, mobilization_fee, cleanup_empty_job_days, cleanup_import_days, cleanup_activity_days, cleanup_dry_run, theme, company_name, company_address, company_phone, company_email, page_size, surcharge_credits, decline_warning, decline_streak, import_reminder_days, import_stale_days, total_alert_percent, cost_margin_percent, ai_budget, ai_budget_unit, ai_budget_override
 `
 
 type UpdateSettingsParams struct {
 	DefaultSurchargeMode    string  `json:"default_surcharge_mode"`
 	DefaultSurchargePercent float64 `json:"default_surcharge_percent"`
+	MinimumJobTotal         float64 `json:"minimum_job_total"`
+	MobilizationFee         float64 `json:"mobilization_fee"`
+	PageSize                int64   `json:"page_size"`
+	SurchargeCredits        bool    `json:"surcharge_credits"`
+	DeclineWarning          bool    `json:"decline_warning"`
+	DeclineStreak           int64   `json:"decline_streak"`
+	TotalAlertPercent       float64 `json:"total_alert_percent"`
+	CostMarginPercent       float64 `json:"cost_margin_percent"`
+	AiBudget                float64 `json:"ai_budget"`
+	AiBudgetUnit            string  `json:"ai_budget_unit"`
 }
 
 func (q *Queries) UpdateSettings(ctx context.Context, arg UpdateSettingsParams) (Setting, error) {
-	row := q.db.QueryRowContext(ctx, updateSettings, arg.DefaultSurchargeMode, arg.DefaultSurchargePercent)
+	row := q.db.QueryRowContext(ctx, updateSettings,
+		arg.DefaultSurchargeMode,
+		arg.DefaultSurchargePercent,
+		arg.MinimumJobTotal,
+		arg.MobilizationFee,
+		arg.PageSize,
+		arg.SurchargeCredits,
+		arg.DeclineWarning,
+		arg.DeclineStreak,
+		arg.TotalAlertPercent,
+		arg.CostMarginPercent,
+		arg.AiBudget,
+		arg.AiBudgetUnit,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.DefaultSurchargeMode,
+		&i.DefaultSurchargePercent,
+		&i.MinimumJobTotal,
+		&i.MobilizationFee,
+		&i.CleanupEmptyJobDays,
+		&i.CleanupImportDays,
+		&i.CleanupActivityDays,
+		&i.CleanupDryRun,
+		&i.Theme,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.CompanyEmail,
+		&i.PageSize,
+		&i.SurchargeCredits,
+		&i.DeclineWarning,
+		&i.DeclineStreak,
+		&i.ImportReminderDays,
+		&i.ImportStaleDays,
+		&i.TotalAlertPercent,
+		&i.CostMarginPercent,
+		&i.AiBudget,
+		&i.AiBudgetUnit,
+		&i.AiBudgetOverride,
+	)
+	return i, err
+}
+
+const updateTheme = `-- name: UpdateTheme :one
+UPDATE settings SET theme = ?
+WHERE id = 'default'
+RETURNING id, default_surcharge_mode, default_surcharge_percent, minimum_job_total, mobilization_fee, cleanup_empty_job_days, cleanup_import_days, cleanup_activity_days, cleanup_dry_run, theme, company_name, company_address, company_phone, company_email, page_size, surcharge_credits, decline_warning, decline_streak, import_reminder_days, import_stale_days, total_alert_percent, cost_margin_percent, ai_budget, ai_budget_unit, ai_budget_override
+`
+
+func (q *Queries) UpdateTheme(ctx context.Context, theme string) (Setting, error) {
+	row := q.db.QueryRowContext(ctx, updateTheme, theme)
 	var i Setting
-	err := row.Scan(&i.ID, &i.DefaultSurchargeMode, &i.DefaultSurchargePercent)
+	err := row.Scan(
+		&i.ID,
+		&i.DefaultSurchargeMode,
+		&i.DefaultSurchargePercent,
+		&i.MinimumJobTotal,
+		&i.MobilizationFee,
+		&i.CleanupEmptyJobDays,
+		&i.CleanupImportDays,
+		&i.CleanupActivityDays,
+		&i.CleanupDryRun,
+		&i.Theme,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.CompanyEmail,
+		&i.PageSize,
+		&i.SurchargeCredits,
+		&i.DeclineWarning,
+		&i.DeclineStreak,
+		&i.ImportReminderDays,
+		&i.ImportStaleDays,
+		&i.TotalAlertPercent,
+		&i.CostMarginPercent,
+		&i.AiBudget,
+		&i.AiBudgetUnit,
+		&i.AiBudgetOverride,
+	)
 	return i, err
 }
+
+const useBudgetOverride = `-- name: UseBudgetOverride :execrows
+UPDATE settings SET ai_budget_override = 0
+WHERE id = 'default' AND ai_budget_override = 1
+`
+
+func (q *Queries) UseBudgetOverride(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useBudgetOverride)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
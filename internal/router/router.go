@@ -6,7 +6,8 @@ import (
 	"github.com/dukerupert/skalkaho/internal/handler/keyboard"
 )
 
-// Register sets up all routes.
+// Register sets up all routes. Creation routes are wrapped in h.Idempotent so
+// double-submitted forms don't create duplicate rows.
 func Register(mux *http.ServeMux, h *keyboard.Handler) {
 	// Health check
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
@@ -20,42 +21,83 @@ func Register(mux *http.ServeMux, h *keyboard.Handler) {
 	// Jobs
 	mux.HandleFunc("GET /", h.ListJobs)
 	mux.HandleFunc("GET /jobs/{id}", h.GetJob)
-	mux.HandleFunc("POST /jobs", h.CreateJob)
+	mux.Handle("POST /jobs", h.Idempotent(h.CreateJob))
+	mux.Handle("POST /jobs/import.xlsx", h.Idempotent(h.ImportJobWorkbook))
+	mux.HandleFunc("GET /jobs/print", h.PrintJobs)
 	mux.HandleFunc("PUT /jobs/{id}", h.UpdateJob)
 	mux.HandleFunc("DELETE /jobs/{id}", h.DeleteJob)
+	mux.HandleFunc("GET /jobs/{id}/delete", h.GetJobDeleteForm)
 	mux.HandleFunc("GET /job-form", h.GetJobForm)
 	mux.HandleFunc("GET /jobs/{id}/markup", h.GetMarkupForm)
 	mux.HandleFunc("PUT /jobs/{id}/markup", h.UpdateMarkup)
 	mux.HandleFunc("GET /jobs/{id}/rename", h.GetJobRenameForm)
 	mux.HandleFunc("PUT /jobs/{id}/name", h.UpdateJobName)
 	mux.HandleFunc("GET /jobs/{id}/order-list", h.GetOrderList)
+	mux.HandleFunc("GET /jobs/{id}/order-list.xlsx", h.ExportOrderList)
 	mux.HandleFunc("GET /jobs/{id}/site-materials", h.GetSiteMaterials)
+	mux.HandleFunc("GET /jobs/{id}/export.xlsx", h.ExportJobWorkbook)
+	mux.HandleFunc("GET /jobs/{id}/print", h.PrintJob)
+	mux.HandleFunc("GET /jobs/{id}/tree.json", h.GetJobTree)
 	mux.HandleFunc("GET /jobs/{id}/client", h.GetJobClientForm)
 	mux.HandleFunc("PUT /jobs/{id}/client", h.UpdateJobClient)
+	mux.HandleFunc("PUT /jobs/{id}/follow-up", h.UpdateJobFollowUp)
+	mux.HandleFunc("PUT /jobs/{id}/fields", h.UpdateJobCustomFields)
+	mux.Handle("POST /jobs/{id}/mobilization", h.Idempotent(h.AddMobilizationFee))
+	mux.HandleFunc("POST /jobs/{id}/adjust-prices", h.AdjustJobPrices)
+	mux.HandleFunc("GET /jobs/{id}/preflight", h.GetJobPreflight)
+	mux.HandleFunc("POST /jobs/{id}/preflight/{rule}/dismiss", h.DismissJobPreflightRule)
+	mux.HandleFunc("DELETE /jobs/{id}/preflight/{rule}/dismiss", h.RestoreJobPreflightRule)
+
+	// Calendar
+	mux.HandleFunc("GET /calendar", h.GetCalendar)
 
 	// Categories
 	mux.HandleFunc("GET /categories/{id}", h.GetCategory)
-	mux.HandleFunc("POST /jobs/{jobID}/categories", h.CreateCategory)
-	mux.HandleFunc("POST /categories/{parentID}/subcategories", h.CreateSubcategory)
+	mux.Handle("POST /jobs/{jobID}/categories", h.Idempotent(h.CreateCategory))
+	mux.Handle("POST /categories/{parentID}/subcategories", h.Idempotent(h.CreateSubcategory))
 	mux.HandleFunc("DELETE /categories/{id}", h.DeleteCategory)
 	mux.HandleFunc("GET /category-form", h.GetCategoryForm)
 	mux.HandleFunc("GET /categories/{id}/markup", h.GetCategoryMarkupForm)
 	mux.HandleFunc("PUT /categories/{id}/markup", h.UpdateCategoryMarkup)
 	mux.HandleFunc("GET /categories/{id}/rename", h.GetCategoryRenameForm)
 	mux.HandleFunc("PUT /categories/{id}/name", h.UpdateCategoryName)
+	mux.HandleFunc("POST /categories/{id}/adjust-prices", h.AdjustCategoryPrices)
+	mux.HandleFunc("GET /categories/{id}/merge-duplicates", h.MergeCategoryDuplicates)
+	mux.HandleFunc("POST /categories/{id}/merge-duplicates", h.MergeCategoryDuplicates)
+	mux.HandleFunc("GET /categories/{id}/print", h.PrintCategory)
+	mux.Handle("POST /categories/{id}/share", h.Idempotent(h.CreateCategoryShare))
+	mux.HandleFunc("DELETE /categories/{id}/share/{token}", h.RevokeCategoryShare)
+
+	// Shared category links, public and read-only
+	mux.HandleFunc("GET /c/{token}", h.GetSharedCategory)
 
 	// Line Items
-	mux.HandleFunc("POST /categories/{categoryID}/items", h.CreateLineItem)
+	mux.Handle("POST /categories/{categoryID}/items", h.Idempotent(h.CreateLineItem))
 	mux.HandleFunc("GET /categories/{categoryID}/form", h.GetInlineForm)
 	mux.HandleFunc("GET /items/search", h.SearchItems)
+	mux.HandleFunc("GET /items/rental-quote", h.GetRentalQuote)
 	mux.HandleFunc("GET /items/{id}/edit", h.GetEditForm)
 	mux.HandleFunc("PUT /items/{id}", h.UpdateLineItem)
 	mux.HandleFunc("DELETE /items/{id}", h.DeleteLineItem)
+	mux.HandleFunc("POST /total-alerts/{id}/undo", h.UndoTotalChange)
+	mux.HandleFunc("POST /total-alerts/{id}/dismiss", h.DismissTotalChange)
+	mux.HandleFunc("POST /items/{id}/refresh-price", h.RefreshLineItemPrice)
+	mux.HandleFunc("POST /items/{id}/raise-to-cost", h.RaiseLineItemToCost)
+	mux.HandleFunc("GET /items/{id}/comments", h.GetItemComments)
+	mux.Handle("POST /items/{id}/comments", h.Idempotent(h.CreateItemComment))
+	mux.HandleFunc("POST /comments/{id}/resolve", h.ResolveItemComment)
+
+	// Quick add
+	mux.HandleFunc("GET /quick-add", h.GetQuickAddForm)
+	mux.HandleFunc("GET /quick-add/targets", h.SearchQuickAddTargets)
+	mux.Handle("POST /quick-add", h.Idempotent(h.CreateQuickAddItem))
 
 	// Item Templates
 	mux.HandleFunc("GET /items", h.ListItemTemplates)
 	mux.HandleFunc("POST /items", h.CreateItemTemplate)
 	mux.HandleFunc("GET /items/new", h.GetItemTemplateForm)
+	mux.HandleFunc("GET /items/export.json", h.ExportItemTemplates)
+	mux.HandleFunc("POST /items/import.json", h.ImportItemTemplates)
 	mux.HandleFunc("GET /item-templates/{id}/edit", h.GetItemTemplateEditForm)
 	mux.HandleFunc("PUT /item-templates/{id}", h.UpdateItemTemplate)
 	mux.HandleFunc("DELETE /item-templates/{id}", h.DeleteItemTemplate)
@@ -64,23 +106,52 @@ func Register(mux *http.ServeMux, h *keyboard.Handler) {
 	mux.HandleFunc("GET /clients", h.ListClients)
 	mux.HandleFunc("GET /clients/{id}", h.GetClient)
 	mux.HandleFunc("POST /clients", h.CreateClient)
+	mux.Handle("POST /clients/inline", h.Idempotent(h.CreateClientInline))
 	mux.HandleFunc("PUT /clients/{id}", h.UpdateClient)
 	mux.HandleFunc("DELETE /clients/{id}", h.DeleteClient)
+	mux.HandleFunc("GET /clients/link", h.GetClientLinkReview)
+	mux.HandleFunc("POST /clients/link", h.ApplyClientLinks)
 	mux.HandleFunc("GET /client-form", h.GetClientForm)
 	mux.HandleFunc("GET /clients/{id}/edit", h.GetClientEditForm)
 
 	// Settings
 	mux.HandleFunc("GET /settings", h.GetSettings)
 	mux.HandleFunc("PUT /settings", h.UpdateSettings)
+	mux.HandleFunc("PUT /settings/company", h.UpdateCompanySettings)
+	mux.HandleFunc("PUT /settings/cleanup", h.UpdateCleanupSettings)
+	mux.HandleFunc("POST /settings/cleanup/run", h.RunCleanup)
+	mux.HandleFunc("POST /settings/job-fields", h.CreateJobCustomField)
+	mux.HandleFunc("DELETE /settings/job-fields/{id}", h.ArchiveJobCustomField)
+	mux.HandleFunc("POST /settings/suppliers", h.CreateSupplierExportMapping)
+	mux.HandleFunc("PUT /settings/suppliers/{id}", h.UpdateSupplierExportMapping)
+	mux.HandleFunc("DELETE /settings/suppliers/{id}", h.DeleteSupplierExportMapping)
+	mux.HandleFunc("POST /preferences/theme", h.UpdateTheme)
+
+	// Admin
+	mux.HandleFunc("GET /admin/errors", h.ListErrors)
+	mux.HandleFunc("GET /admin/metrics", h.Metrics)
+	mux.HandleFunc("POST /admin/import-budget/override", h.AllowBudgetOverride)
+
+	// Read-only JSON API
+	mux.HandleFunc("GET /api/v1/jobs/{id}/totals", h.GetAPIJobTotals)
+	mux.HandleFunc("GET /api/v1/totals/summary", h.GetAPITotalsSummary)
 
 	// Price Import
 	mux.HandleFunc("GET /price-import", h.GetPriceImportPage)
 	mux.HandleFunc("POST /price-import/auth", h.ValidatePriceImportToken)
-	mux.HandleFunc("POST /price-import/upload", h.UploadPriceFile)
+	mux.Handle("POST /price-import/upload", h.Idempotent(h.UploadPriceFile))
 	mux.HandleFunc("GET /price-import/{id}/review", h.GetImportReview)
+	mux.HandleFunc("GET /price-import/{id}/impact", h.GetPriceImportImpact)
+	mux.HandleFunc("GET /price-import/{id}/impact.csv", h.ExportPriceImportImpactCSV)
 	mux.HandleFunc("PUT /price-import/matches/{id}", h.UpdateMatchStatus)
+	mux.HandleFunc("PUT /price-import/matches/{id}/template", h.RematchImportMatch)
+	mux.HandleFunc("GET /price-import/matches/{id}/create-template", h.GetCreateTemplateForm)
 	mux.HandleFunc("POST /price-import/matches/{id}/create-template", h.CreateTemplateFromMatch)
 	mux.HandleFunc("POST /price-import/{id}/bulk-approve", h.BulkApproveMatches)
 	mux.HandleFunc("POST /price-import/{id}/bulk-create", h.BulkCreateTemplates)
 	mux.HandleFunc("POST /price-import/{id}/apply", h.ApplyPriceUpdates)
+	mux.HandleFunc("GET /price-import/batches/{id}/review", h.GetBatchReview)
+	mux.HandleFunc("POST /price-import/batches/{id}/apply", h.ApplyBatchPriceUpdates)
+	mux.HandleFunc("GET /price-import/corrections", h.GetMatchCorrections)
+	mux.HandleFunc("DELETE /price-import/corrections/{id}", h.DeleteMatchCorrection)
 }
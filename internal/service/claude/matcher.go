@@ -31,6 +31,7 @@ type ExtractedItemWithMatch struct {
 	Name         string  `json:"name"`
 	Unit         string  `json:"unit,omitempty"`
 	Price        float64 `json:"price"`
+	Category     string  `json:"category,omitempty"` // nearest category header above the row
 	TemplateID   *int64  `json:"template_id,omitempty"`
 	TemplateName string  `json:"template_name,omitempty"`
 	Confidence   float64 `json:"confidence"`
@@ -51,6 +52,76 @@ type MatchResponse struct {
 	Matches []MatchResult `json:"matches"`
 }
 
+// LearnedReason is the match reason of an item matched from a prior correction.
+const LearnedReason = "learned from prior correction"
+
+// maxPromptCorrections caps how many corrections are shown to Claude as
+// examples, so a long history doesn't crowd out the spreadsheet.
+const maxPromptCorrections = 25
+
+// Correction is a supplier item name that was matched to a template by hand
+// during an earlier import review.
+type Correction struct {
+	SourceName   string
+	TemplateID   int64
+	TemplateName string
+}
+
+// correctionKey compares supplier item names ignoring case and spacing.
+func correctionKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ApplyCorrections matches every item whose name was corrected before to the
+// corrected template at full confidence, whatever Claude proposed. It returns
+// how many items it matched.
+func ApplyCorrections(items []ExtractedItemWithMatch, corrections []Correction) int {
+	if len(corrections) == 0 {
+		return 0
+	}
+	byName := make(map[string]Correction, len(corrections))
+	for _, c := range corrections {
+		byName[correctionKey(c.SourceName)] = c
+	}
+
+	learned := 0
+	for i := range items {
+		c, ok := byName[correctionKey(items[i].Name)]
+		if !ok {
+			continue
+		}
+		templateID := c.TemplateID
+		items[i].TemplateID = &templateID
+		items[i].TemplateName = c.TemplateName
+		items[i].Confidence = 1.0
+		items[i].Reason = LearnedReason
+		learned++
+	}
+	return learned
+}
+
+// Prices per million tokens, in dollars, for the model price imports use.
+const (
+	InputDollarsPerMillion  = 3.0
+	OutputDollarsPerMillion = 15.0
+)
+
+// Usage is the tokens one API call used.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Tokens returns the input and output tokens together.
+func (u Usage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Dollars returns what the tokens cost at the model's list prices.
+func (u Usage) Dollars() float64 {
+	return (float64(u.InputTokens)*InputDollarsPerMillion + float64(u.OutputTokens)*OutputDollarsPerMillion) / 1e6
+}
+
 // Matcher handles matching spreadsheet items to templates using Claude AI.
 type Matcher struct {
 	client anthropic.Client
@@ -109,8 +180,10 @@ func (m *Matcher) MatchItems(ctx context.Context, rows []excel.Row, templates []
 
 // ExtractAndMatchItems extracts items from raw spreadsheet text and matches them against templates.
 // This uses a single Claude API call to both parse the spreadsheet and match items.
-func (m *Matcher) ExtractAndMatchItems(ctx context.Context, spreadsheet *excel.RawSpreadsheet, templates []repository.ItemTemplate) (*ExtractAndMatchResponse, error) {
-	prompt := m.buildExtractAndMatchPrompt(spreadsheet, templates)
+// Corrections, most recent first, are shown to Claude as examples, and items
+// whose names were corrected before take the corrected template.
+func (m *Matcher) ExtractAndMatchItems(ctx context.Context, spreadsheet *excel.RawSpreadsheet, templates []repository.ItemTemplate, corrections []Correction) (*ExtractAndMatchResponse, Usage, error) {
+	prompt := m.buildExtractAndMatchPrompt(spreadsheet, templates, corrections)
 
 	resp, err := m.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.ModelClaudeSonnet4_5_20250929,
@@ -120,12 +193,15 @@ func (m *Matcher) ExtractAndMatchItems(ctx context.Context, spreadsheet *excel.R
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("claude API error: %w", err)
+		return nil, Usage{}, fmt.Errorf("claude API error: %w", err)
 	}
 
+	// The tokens are spent even if the response turns out to be unusable
+	usage := Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+
 	// Extract text content from response
 	if len(resp.Content) == 0 {
-		return nil, fmt.Errorf("empty response from Claude")
+		return nil, usage, fmt.Errorf("empty response from Claude")
 	}
 
 	textContent := ""
@@ -137,19 +213,20 @@ func (m *Matcher) ExtractAndMatchItems(ctx context.Context, spreadsheet *excel.R
 	}
 
 	if textContent == "" {
-		return nil, fmt.Errorf("no text content in Claude response")
+		return nil, usage, fmt.Errorf("no text content in Claude response")
 	}
 
 	// Parse JSON response
 	result, err := m.parseExtractAndMatchResponse(textContent)
 	if err != nil {
-		return nil, fmt.Errorf("parsing claude response: %w", err)
+		return nil, usage, fmt.Errorf("parsing claude response: %w", err)
 	}
 
-	return result, nil
+	ApplyCorrections(result.Items, corrections)
+	return result, usage, nil
 }
 
-func (m *Matcher) buildExtractAndMatchPrompt(spreadsheet *excel.RawSpreadsheet, templates []repository.ItemTemplate) string {
+func (m *Matcher) buildExtractAndMatchPrompt(spreadsheet *excel.RawSpreadsheet, templates []repository.ItemTemplate, corrections []Correction) string {
 	var sb strings.Builder
 
 	sb.WriteString(`You are a construction materials data extraction and matching assistant. Your task is to:
@@ -160,6 +237,7 @@ func (m *Matcher) buildExtractAndMatchPrompt(spreadsheet *excel.RawSpreadsheet,
 - The spreadsheet may have category headers (rows with a category name but no price)
 - When you encounter a category header, PREPEND that category to all subsequent item names until a new category is found
 - For example, if you see "Sheeting" as a category, then "3/8 CDX" should become "Sheeting 3/8 CDX"
+- Also return that category header, as written, in the item's "category" field (empty if the row has none)
 - Only extract rows that have both a name AND a price
 - Look for price columns (may be labeled "Price", "Cost", "Rate", or just contain dollar amounts)
 - Look for unit columns (may be labeled "Unit", "UOM", "Measure")
@@ -174,6 +252,18 @@ func (m *Matcher) buildExtractAndMatchPrompt(spreadsheet *excel.RawSpreadsheet,
 			t.ID, t.Name, t.DefaultUnit, t.DefaultPrice))
 	}
 
+	if len(corrections) > 0 {
+		sb.WriteString(`
+## Corrections From Earlier Imports
+A person reviewed these supplier names and matched them to templates by hand.
+Match the same names to the same templates, and use them as examples of how
+this supplier's naming maps to ours.
+`)
+		for _, c := range corrections[:min(maxPromptCorrections, len(corrections))] {
+			sb.WriteString(fmt.Sprintf("- %q -> ID: %d, Name: %s\n", c.SourceName, c.TemplateID, c.TemplateName))
+		}
+	}
+
 	sb.WriteString(`
 ## Raw Spreadsheet Content
 `)
@@ -199,6 +289,7 @@ After extracting items, match each one to the most appropriate template:
       "name": "Sheeting 3/8 CDX",
       "unit": "sheet",
       "price": 25.99,
+      "category": "Sheeting",
       "template_id": 42,
       "template_name": "Sheeting 3/8 CDX Plywood",
       "confidence": 0.95,
@@ -209,6 +300,7 @@ After extracting items, match each one to the most appropriate template:
       "name": "Sheeting 1/2 CDX",
       "unit": "sheet",
       "price": 32.50,
+      "category": "Sheeting",
       "template_id": null,
       "template_name": "",
       "confidence": 0.0,
@@ -0,0 +1,181 @@
+package cleanup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// vacuumInterval is how often the database is vacuumed and analyzed.
+const vacuumInterval = 7 * 24 * time.Hour
+
+// timestampLayout matches SQLite's datetime('now') so cutoffs compare as text.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Result counts the rows a cleanup run removed or expired, or would have in a
+// dry run.
+type Result struct {
+	DryRun          bool
+	EmptyJobs       int64
+	StaleImports    int64
+	ExpiredImports  int64
+	ActivityEntries int64
+	Vacuumed        bool
+}
+
+// Runner deletes abandoned data according to the retention settings.
+type Runner struct {
+	db      *sql.DB
+	queries *repository.Queries
+	logger  *slog.Logger
+}
+
+// NewRunner creates a cleanup runner.
+func NewRunner(db *sql.DB, queries *repository.Queries, logger *slog.Logger) *Runner {
+	return &Runner{db: db, queries: queries, logger: logger}
+}
+
+// Start runs the cleanup once at startup and then on every interval until ctx
+// is cancelled. Failed runs are logged and recorded; they never stop the loop.
+func (r *Runner) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.Run(ctx, time.Now()); err != nil {
+				r.logger.Error("cleanup run failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run removes, in one transaction:
+//   - draft jobs with no line items created more than CleanupEmptyJobDays ago
+//   - price imports that were never applied, older than CleanupImportDays
+//   - ready price imports older than ImportStaleDays, which are marked stale
+//   - job activity entries older than CleanupActivityDays
+//
+// A setting of 0 days disables that cleanup. When the dry-run setting is on,
+// matching rows are only counted. Outside dry runs the database is vacuumed
+// and analyzed if it has not been in the last week. Every run is recorded.
+func (r *Runner) Run(ctx context.Context, now time.Time) (Result, error) {
+	settings, err := r.queries.GetSettings(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("loading settings: %w", err)
+	}
+
+	result, err := r.prune(ctx, settings, now)
+	if err == nil && !result.DryRun {
+		result.Vacuumed, err = r.vacuumIfDue(ctx, now)
+	}
+
+	var errorMessage sql.NullString
+	if err != nil {
+		errorMessage = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if _, recordErr := r.queries.CreateCleanupRun(ctx, repository.CreateCleanupRunParams{
+		DryRun:          result.DryRun,
+		EmptyJobs:       result.EmptyJobs,
+		StaleImports:    result.StaleImports,
+		ExpiredImports:  result.ExpiredImports,
+		ActivityEntries: result.ActivityEntries,
+		Vacuumed:        result.Vacuumed,
+		ErrorMessage:    errorMessage,
+	}); recordErr != nil && err == nil {
+		err = fmt.Errorf("recording cleanup run: %w", recordErr)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	r.logger.Info("cleanup run finished",
+		"dry_run", result.DryRun,
+		"empty_jobs", result.EmptyJobs,
+		"stale_imports", result.StaleImports,
+		"expired_imports", result.ExpiredImports,
+		"activity_entries", result.ActivityEntries,
+		"vacuumed", result.Vacuumed,
+	)
+	return result, nil
+}
+
+func (r *Runner) prune(ctx context.Context, settings repository.Setting, now time.Time) (Result, error) {
+	result := Result{DryRun: settings.CleanupDryRun}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	qtx := r.queries.WithTx(tx)
+
+	steps := []struct {
+		name   string
+		days   int64
+		count  func(context.Context, string) (int64, error)
+		apply  func(context.Context, string) (int64, error)
+		target *int64
+	}{
+		{"empty jobs", settings.CleanupEmptyJobDays, qtx.CountEmptyJobs, qtx.DeleteEmptyJobs, &result.EmptyJobs},
+		{"stale imports", settings.CleanupImportDays, qtx.CountStaleImports, qtx.DeleteStaleImports, &result.StaleImports},
+		{"expired imports", settings.ImportStaleDays, qtx.CountExpiringImports, qtx.ExpireImports, &result.ExpiredImports},
+		{"job activity", settings.CleanupActivityDays, qtx.CountJobActivityBefore, qtx.DeleteJobActivityBefore, &result.ActivityEntries},
+	}
+	for _, step := range steps {
+		if step.days <= 0 {
+			continue
+		}
+		run := step.apply
+		if result.DryRun {
+			run = step.count
+		}
+		n, err := run(ctx, cutoff(now, step.days))
+		if err != nil {
+			return result, fmt.Errorf("cleaning %s: %w", step.name, err)
+		}
+		*step.target = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("committing cleanup: %w", err)
+	}
+	return result, nil
+}
+
+// vacuumIfDue vacuums and analyzes the database when the last vacuum is more
+// than vacuumInterval ago.
+func (r *Runner) vacuumIfDue(ctx context.Context, now time.Time) (bool, error) {
+	last, err := r.queries.GetLatestVacuum(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("loading last vacuum: %w", err)
+	}
+	if err == nil {
+		if at, err := time.Parse(timestampLayout, last); err == nil && now.Sub(at) < vacuumInterval {
+			return false, nil
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return false, fmt.Errorf("vacuuming database: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return false, fmt.Errorf("analyzing database: %w", err)
+	}
+	return true, nil
+}
+
+// cutoff returns the timestamp days before now in SQLite's text format.
+func cutoff(now time.Time, days int64) string {
+	return now.UTC().AddDate(0, 0, -int(days)).Format(timestampLayout)
+}
@@ -0,0 +1,219 @@
+// Package clientlink proposes client records for jobs that only carry a
+// free-text customer name, so older quotes can be linked to clients.
+package clientlink
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dukerupert/skalkaho/internal/repository"
+)
+
+// Actions describe what linking would do with a group of jobs.
+// They are plain strings so templates can compare them directly.
+const (
+	ActionCreate = "create"
+	ActionLink   = "link"
+	ActionSkip   = "skip"
+)
+
+// minSimilarity is how close two names must be, from 0 to 1, for a group to
+// be linked to an existing client whose name isn't an exact match.
+const minSimilarity = 0.85
+
+// businessSuffixes are dropped when comparing names, so "Acme Inc." and
+// "Acme" group together.
+var businessSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true,
+	"co": true, "corp": true, "corporation": true, "company": true,
+}
+
+// Group is a set of unlinked jobs whose customer names normalize to the same key.
+type Group struct {
+	Key          string
+	Names        []string // distinct spellings, most used first
+	Jobs         []repository.Job
+	Action       string
+	ProposedName string             // client to create, for ActionCreate
+	Client       *repository.Client // client to link, for ActionLink
+	Note         string
+}
+
+// Summary counts the outcome of an applied link run.
+type Summary struct {
+	Created int // clients created
+	Linked  int // jobs linked to a client
+	Skipped int // jobs left unlinked
+}
+
+// Normalize returns the comparison key for a customer or client name:
+// lower case, punctuation removed, and business suffixes dropped.
+func Normalize(name string) string {
+	key, _ := split(name)
+	return key
+}
+
+// split returns the comparison key and the business suffix of name, if any.
+func split(name string) (string, string) {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	suffix := ""
+	for len(words) > 1 && businessSuffixes[words[len(words)-1]] {
+		if suffix == "" {
+			suffix = words[len(words)-1]
+		}
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " "), suffix
+}
+
+// Plan groups jobs that have a customer name but no client by normalized
+// name and decides, for each group, whether to link it to an existing client,
+// create a new client, or leave it alone. A group is skipped when its
+// spellings carry different business suffixes ("Acme Inc" and "Acme LLC"),
+// or when it matches more than one existing client.
+func Plan(jobs []repository.Job, clients []repository.Client) []Group {
+	byKey := make(map[string]*Group)
+	counts := make(map[string]map[string]int)
+	suffixes := make(map[string]map[string]bool)
+	var keys []string
+	for _, job := range jobs {
+		if job.ClientID.Valid {
+			continue
+		}
+		name := strings.TrimSpace(job.CustomerName.String)
+		key, suffix := split(name)
+		if key == "" {
+			continue
+		}
+		group, ok := byKey[key]
+		if !ok {
+			group = &Group{Key: key}
+			byKey[key] = group
+			counts[key] = make(map[string]int)
+			suffixes[key] = make(map[string]bool)
+			keys = append(keys, key)
+		}
+		group.Jobs = append(group.Jobs, job)
+		if counts[key][name] == 0 {
+			group.Names = append(group.Names, name)
+		}
+		counts[key][name]++
+		if suffix != "" {
+			suffixes[key][suffix] = true
+		}
+	}
+
+	clientKeys := make(map[string][]repository.Client)
+	for _, client := range clients {
+		for _, name := range []string{client.Name, client.Company.String} {
+			if key := Normalize(name); key != "" && !containsClient(clientKeys[key], client.ID) {
+				clientKeys[key] = append(clientKeys[key], client)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	groups := make([]Group, 0, len(keys))
+	for _, key := range keys {
+		group := byKey[key]
+		sort.SliceStable(group.Names, func(i, j int) bool {
+			return counts[key][group.Names[i]] > counts[key][group.Names[j]]
+		})
+
+		if len(suffixes[key]) > 1 {
+			group.Action = ActionSkip
+			group.Note = "Spelled with different business suffixes; link these jobs by hand"
+			groups = append(groups, *group)
+			continue
+		}
+
+		matches := clientKeys[key]
+		similar := false
+		if len(matches) == 0 {
+			matches = similarClients(key, clientKeys)
+			similar = len(matches) > 0
+		}
+
+		switch len(matches) {
+		case 0:
+			group.Action = ActionCreate
+			group.ProposedName = group.Names[0]
+		case 1:
+			group.Action = ActionLink
+			group.Client = &matches[0]
+			if similar {
+				group.Note = "Similar name"
+			}
+		default:
+			group.Action = ActionSkip
+			group.Note = fmt.Sprintf("Matches %d existing clients; link these jobs by hand", len(matches))
+		}
+		groups = append(groups, *group)
+	}
+	return groups
+}
+
+// similarClients returns the clients whose normalized name is close to key.
+func similarClients(key string, clientKeys map[string][]repository.Client) []repository.Client {
+	var matches []repository.Client
+	for clientKey, clients := range clientKeys {
+		if similarity(key, clientKey) < minSimilarity {
+			continue
+		}
+		for _, client := range clients {
+			if !containsClient(matches, client.ID) {
+				matches = append(matches, client)
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
+
+func containsClient(clients []repository.Client, id string) bool {
+	for _, client := range clients {
+		if client.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// similarity scores two strings from 0 (nothing alike) to 1 (identical)
+// by their edit distance relative to the longer string.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single-rune edits that turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
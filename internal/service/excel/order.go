@@ -0,0 +1,179 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Order list fields a supplier column can hold.
+const (
+	OrderFieldSKU         = "sku"
+	OrderFieldDescription = "description"
+	OrderFieldQuantity    = "quantity"
+	OrderFieldUnit        = "unit"
+)
+
+const (
+	// orderSheet holds the rows in the supplier's column layout.
+	orderSheet = "Order"
+	// coverSheet lists the items that couldn't go on the order sheet.
+	coverSheet = "Cover"
+)
+
+// SupplierOrder is a job's order list laid out in a supplier's columns.
+type SupplierOrder struct {
+	Supplier string
+	JobName  string
+	Columns  []OrderColumn
+	Items    []OrderItem
+}
+
+// OrderColumn is one column of a supplier's order template.
+type OrderColumn struct {
+	Field  string
+	Header string
+}
+
+// OrderItem is one aggregated line of a job's order list.
+type OrderItem struct {
+	SKU         string
+	Description string
+	Quantity    float64
+	Unit        string
+}
+
+// RequiresSKU reports whether the order's columns include a SKU, in which
+// case items without one can't be ordered through the template.
+func (o SupplierOrder) RequiresSKU() bool {
+	for _, col := range o.Columns {
+		if col.Field == OrderFieldSKU {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSupplierOrder writes the order as a header row and one row per item
+// in the supplier's column order. When the layout needs SKUs, items without
+// one are left off the order and listed on a cover sheet in front of it to
+// be ordered by hand.
+func WriteSupplierOrder(w io.Writer, order SupplierOrder) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	var ordered, missing []OrderItem
+	for _, item := range order.Items {
+		if order.RequiresSKU() && item.SKU == "" {
+			missing = append(missing, item)
+			continue
+		}
+		ordered = append(ordered, item)
+	}
+
+	if err := f.SetSheetName("Sheet1", orderSheet); err != nil {
+		return fmt.Errorf("naming order sheet: %w", err)
+	}
+	if err := writeOrderSheet(f, order.Columns, ordered); err != nil {
+		return fmt.Errorf("writing order sheet: %w", err)
+	}
+
+	if len(missing) > 0 {
+		bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return fmt.Errorf("creating header style: %w", err)
+		}
+		if _, err := f.NewSheet(coverSheet); err != nil {
+			return fmt.Errorf("creating cover sheet: %w", err)
+		}
+		if err := f.MoveSheet(coverSheet, orderSheet); err != nil {
+			return fmt.Errorf("moving cover sheet: %w", err)
+		}
+		if err := writeCoverSheet(f, order, missing, bold); err != nil {
+			return fmt.Errorf("writing cover sheet: %w", err)
+		}
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("writing workbook: %w", err)
+	}
+	return nil
+}
+
+// writeOrderSheet writes the supplier's headers and the item rows with no
+// other content, so the sheet can be uploaded to their ordering system as is.
+func writeOrderSheet(f *excelize.File, columns []OrderColumn, items []OrderItem) error {
+	sw, err := f.NewStreamWriter(orderSheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		row := make([]interface{}, len(columns))
+		for j, col := range columns {
+			row[j] = item.value(col.Field)
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	return sw.Flush()
+}
+
+// writeCoverSheet names the job and supplier and lists the items missing a
+// SKU.
+func writeCoverSheet(f *excelize.File, order SupplierOrder, missing []OrderItem, bold int) error {
+	sw, err := f.NewStreamWriter(coverSheet)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]interface{}{
+		{excelize.Cell{StyleID: bold, Value: "Job"}, order.JobName},
+		{excelize.Cell{StyleID: bold, Value: "Supplier"}, order.Supplier},
+		{},
+		{fmt.Sprintf("%d item(s) have no SKU and are not on the Order sheet. Order them by hand.", len(missing))},
+		{
+			excelize.Cell{StyleID: bold, Value: "Description"},
+			excelize.Cell{StyleID: bold, Value: "Quantity"},
+			excelize.Cell{StyleID: bold, Value: "Unit"},
+		},
+	}
+	for _, item := range missing {
+		rows = append(rows, []interface{}{item.Description, item.Quantity, item.Unit})
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	return sw.Flush()
+}
+
+// value returns the item's value for an order field.
+func (item OrderItem) value(field string) interface{} {
+	switch field {
+	case OrderFieldSKU:
+		return item.SKU
+	case OrderFieldDescription:
+		return item.Description
+	case OrderFieldQuantity:
+		return item.Quantity
+	case OrderFieldUnit:
+		return item.Unit
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ type Row struct {
 	Name      string
 	Unit      string
 	Price     float64
+	Category  string // category header the row appeared under, if any
 }
 
 // ParseResult contains the parsed data from an Excel file.
@@ -168,6 +169,7 @@ func (p *Parser) Parse(r io.Reader, filename string) (*ParseResult, error) {
 			Name:      fullName,
 			Unit:      unit,
 			Price:     price,
+			Category:  currentCategory,
 		})
 	}
 
@@ -0,0 +1,371 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// summarySheet is the first sheet of a job workbook.
+	summarySheet = "Summary"
+	// metaSheet is a hidden sheet identifying the workbook layout and mapping
+	// category sheets back to their category names.
+	metaSheet = "_meta"
+	// workbookFormat marks a workbook as a job export in cell A1 of the meta sheet.
+	workbookFormat = "skalkaho-job-workbook"
+	// workbookVersion is the layout version written to cell B1 of the meta sheet.
+	workbookVersion = "1"
+	// maxSheetNameLen is Excel's limit on sheet name length.
+	maxSheetNameLen = 31
+	// currencyFormat is the number format applied to money cells. Negative
+	// amounts, such as credits, show in red parentheses.
+	currencyFormat = `"$"#,##0.00;[Red]("$"#,##0.00)`
+)
+
+// JobWorkbook is a job laid out for export as a workbook.
+type JobWorkbook struct {
+	SourceJobID      string
+	ClientID         string
+	Name             string
+	Customer         string
+	Status           string
+	CreatedAt        string
+	SurchargePercent float64
+	SurchargeMode    string
+	Fields           []WorkbookField
+	TypeTotals       []WorkbookTotal
+	Subtotal         float64
+	SurchargeTotal   float64
+	GrandTotal       float64
+	// The tax rows are only written when TaxPercent is set.
+	TaxPercent  float64
+	TaxableBase float64
+	ExemptBase  float64
+	TaxTotal    float64
+	Categories  []WorkbookCategory
+}
+
+// WorkbookField is a job custom field shown under the job details on the
+// summary sheet.
+type WorkbookField struct {
+	Label string
+	Value string
+}
+
+// WorkbookTotal is a labelled amount on the summary sheet.
+type WorkbookTotal struct {
+	Label  string
+	Amount float64
+}
+
+// WorkbookCategory is a top-level category and every item beneath it.
+type WorkbookCategory struct {
+	Name           string
+	Sheet          string // sheet the category was read from; set on import
+	Subtotal       float64
+	SurchargeTotal float64
+	Total          float64
+	Items          []WorkbookItem
+}
+
+// WorkbookItem is a single line item row on a category sheet.
+type WorkbookItem struct {
+	Row         int    // sheet row the item was read from; set on import
+	Section     string // subcategory path below the top-level category, if any
+	Type        string
+	Name        string
+	Description string
+	Quantity    float64
+	Unit        string
+	UnitPrice   float64
+}
+
+// WriteJobWorkbook writes a Summary sheet followed by one sheet per top-level
+// category. Rows are written with stream writers so memory stays bounded for
+// large jobs.
+func WriteJobWorkbook(w io.Writer, job JobWorkbook) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("creating header style: %w", err)
+	}
+	numFmt := currencyFormat
+	money, err := f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+	if err != nil {
+		return fmt.Errorf("creating currency style: %w", err)
+	}
+	boldMoney, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, CustomNumFmt: &numFmt})
+	if err != nil {
+		return fmt.Errorf("creating currency style: %w", err)
+	}
+
+	if err := f.SetSheetName("Sheet1", summarySheet); err != nil {
+		return fmt.Errorf("naming summary sheet: %w", err)
+	}
+	if err := writeSummarySheet(f, job, bold, money, boldMoney); err != nil {
+		return fmt.Errorf("writing summary sheet: %w", err)
+	}
+
+	names := make([]string, len(job.Categories))
+	for i, cat := range job.Categories {
+		names[i] = cat.Name
+	}
+	sheets := SheetNames(names)
+	for i, name := range sheets {
+		if _, err := f.NewSheet(name); err != nil {
+			return fmt.Errorf("creating sheet %q: %w", name, err)
+		}
+		if err := writeCategorySheet(f, name, job.Categories[i], bold, money, boldMoney); err != nil {
+			return fmt.Errorf("writing sheet %q: %w", name, err)
+		}
+	}
+
+	if err := writeMetaSheet(f, job, sheets); err != nil {
+		return fmt.Errorf("writing metadata sheet: %w", err)
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("writing workbook: %w", err)
+	}
+	return nil
+}
+
+func writeSummarySheet(f *excelize.File, job JobWorkbook, bold, money, boldMoney int) error {
+	sw, err := f.NewStreamWriter(summarySheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(1, 1, 28); err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(2, 4, 16); err != nil {
+		return err
+	}
+
+	markup := fmt.Sprintf("%.2f%% (%s)", job.SurchargePercent, job.SurchargeMode)
+	rows := [][]interface{}{
+		{excelize.Cell{StyleID: bold, Value: "Job"}, job.Name},
+		{excelize.Cell{StyleID: bold, Value: "Customer"}, job.Customer},
+		{excelize.Cell{StyleID: bold, Value: "Status"}, job.Status},
+		{excelize.Cell{StyleID: bold, Value: "Created"}, job.CreatedAt},
+		{excelize.Cell{StyleID: bold, Value: "Markup"}, markup},
+	}
+	for _, field := range job.Fields {
+		rows = append(rows, []interface{}{excelize.Cell{StyleID: bold, Value: field.Label}, field.Value})
+	}
+	rows = append(rows,
+		nil,
+		[]interface{}{excelize.Cell{StyleID: bold, Value: "Totals by Type"}, excelize.Cell{StyleID: bold, Value: "Amount"}},
+	)
+	for _, total := range job.TypeTotals {
+		rows = append(rows, []interface{}{total.Label, excelize.Cell{StyleID: money, Value: total.Amount}})
+	}
+	rows = append(rows,
+		[]interface{}{"Subtotal", excelize.Cell{StyleID: money, Value: job.Subtotal}},
+		[]interface{}{"Markup", excelize.Cell{StyleID: money, Value: job.SurchargeTotal}},
+	)
+	if job.TaxPercent != 0 {
+		rows = append(rows,
+			[]interface{}{"Taxable", excelize.Cell{StyleID: money, Value: job.TaxableBase}},
+			[]interface{}{"Tax Exempt", excelize.Cell{StyleID: money, Value: job.ExemptBase}},
+			[]interface{}{fmt.Sprintf("Tax (%.2f%%)", job.TaxPercent), excelize.Cell{StyleID: money, Value: job.TaxTotal}},
+		)
+	}
+	rows = append(rows,
+		[]interface{}{excelize.Cell{StyleID: bold, Value: "Grand Total"}, excelize.Cell{StyleID: boldMoney, Value: job.GrandTotal}},
+		nil,
+		[]interface{}{
+			excelize.Cell{StyleID: bold, Value: "Category"},
+			excelize.Cell{StyleID: bold, Value: "Subtotal"},
+			excelize.Cell{StyleID: bold, Value: "Markup"},
+			excelize.Cell{StyleID: bold, Value: "Total"},
+		},
+	)
+	for _, cat := range job.Categories {
+		rows = append(rows, []interface{}{
+			cat.Name,
+			excelize.Cell{StyleID: money, Value: cat.Subtotal},
+			excelize.Cell{StyleID: money, Value: cat.SurchargeTotal},
+			excelize.Cell{StyleID: money, Value: cat.Total},
+		})
+	}
+
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+func writeCategorySheet(f *excelize.File, sheet string, cat WorkbookCategory, bold, money, boldMoney int) error {
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	// Panes and column widths must be set before any rows are written.
+	if err := sw.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+	widths := []float64{20, 12, 32, 32, 10, 10, 14, 14}
+	for i, width := range widths {
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+
+	header := []interface{}{}
+	for _, title := range []string{"Section", "Type", "Item", "Description", "Qty", "Unit", "Unit Price", "Amount"} {
+		header = append(header, excelize.Cell{StyleID: bold, Value: title})
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	row := 2
+	for _, item := range cat.Items {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := sw.SetRow(cell, []interface{}{
+			item.Section,
+			item.Type,
+			item.Name,
+			item.Description,
+			item.Quantity,
+			item.Unit,
+			excelize.Cell{StyleID: money, Value: item.UnitPrice},
+			excelize.Cell{StyleID: money, Value: item.Quantity * item.UnitPrice},
+		}); err != nil {
+			return err
+		}
+		row++
+	}
+
+	// Leave a blank row between the items and the category totals.
+	row++
+	for _, total := range []WorkbookTotal{
+		{Label: "Subtotal", Amount: cat.Subtotal},
+		{Label: "Markup", Amount: cat.SurchargeTotal},
+		{Label: "Total", Amount: cat.Total},
+	} {
+		style := money
+		if total.Label == "Total" {
+			style = boldMoney
+		}
+		cell, _ := excelize.CoordinatesToCellName(7, row)
+		if err := sw.SetRow(cell, []interface{}{
+			excelize.Cell{StyleID: bold, Value: total.Label},
+			excelize.Cell{StyleID: style, Value: total.Amount},
+		}); err != nil {
+			return err
+		}
+		row++
+	}
+
+	return sw.Flush()
+}
+
+// writeMetaSheet records the layout marker, job settings, and the category
+// behind each sheet on a hidden sheet so the workbook can be imported again.
+func writeMetaSheet(f *excelize.File, job JobWorkbook, sheets []string) error {
+	if _, err := f.NewSheet(metaSheet); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(metaSheet)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]interface{}{
+		{workbookFormat, workbookVersion},
+		{"job_id", job.SourceJobID},
+		{"job_name", job.Name},
+		{"client_id", job.ClientID},
+		{"surcharge_percent", job.SurchargePercent},
+		{"surcharge_mode", job.SurchargeMode},
+	}
+	for i, sheet := range sheets {
+		rows = append(rows, []interface{}{"category", sheet, job.Categories[i].Name})
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	return f.SetSheetVisible(metaSheet, false)
+}
+
+// SheetNames converts category names into valid, unique Excel sheet names.
+// Characters Excel forbids are replaced, names are truncated to 31
+// characters, and duplicates (compared case-insensitively, including the
+// Summary and metadata sheets) get a numeric suffix.
+func SheetNames(names []string) []string {
+	used := map[string]bool{
+		strings.ToLower(summarySheet): true,
+		strings.ToLower(metaSheet):    true,
+	}
+	result := make([]string, len(names))
+
+	for i, name := range names {
+		base := sanitizeSheetName(name)
+		candidate := base
+		for n := 2; used[strings.ToLower(candidate)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			candidate = truncateRunes(base, maxSheetNameLen-len(suffix)) + suffix
+		}
+		used[strings.ToLower(candidate)] = true
+		result[i] = candidate
+	}
+
+	return result
+}
+
+// sanitizeSheetName strips characters Excel rejects in sheet names.
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '\\', '/', '?', '*', '[', ']':
+			return '-'
+		}
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(strings.Trim(name, "'"))
+	if name == "" {
+		name = "Category"
+	}
+	return strings.TrimSpace(truncateRunes(name, maxSheetNameLen))
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
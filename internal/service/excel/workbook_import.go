@@ -0,0 +1,142 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrNotJobWorkbook is returned when a workbook was not produced by the job
+// export, or its layout has been altered beyond what the importer accepts.
+var ErrNotJobWorkbook = errors.New("not a job workbook")
+
+// RowError describes a category sheet row that could not be imported.
+type RowError struct {
+	Sheet   string
+	Row     int
+	Message string
+}
+
+// ReadJobWorkbook reads a workbook written by WriteJobWorkbook. Job settings
+// and category names come from the hidden metadata sheet; items come from the
+// category sheets. Rows that can't be parsed are skipped and reported as
+// RowErrors rather than failing the whole import. Totals are not read back.
+func ReadJobWorkbook(r io.Reader) (*JobWorkbook, []RowError, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrNotJobWorkbook, err)
+	}
+	defer f.Close()
+
+	meta, err := f.GetRows(metaSheet)
+	if err != nil || len(meta) == 0 || len(meta[0]) < 2 || meta[0][0] != workbookFormat {
+		return nil, nil, fmt.Errorf("%w: missing export metadata", ErrNotJobWorkbook)
+	}
+	if meta[0][1] != workbookVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported layout version %q", ErrNotJobWorkbook, meta[0][1])
+	}
+
+	job := &JobWorkbook{}
+	for _, row := range meta[1:] {
+		if len(row) < 2 {
+			continue
+		}
+		switch row[0] {
+		case "job_id":
+			job.SourceJobID = row[1]
+		case "job_name":
+			job.Name = row[1]
+		case "client_id":
+			job.ClientID = row[1]
+		case "surcharge_percent":
+			percent, err := strconv.ParseFloat(row[1], 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: invalid markup %q", ErrNotJobWorkbook, row[1])
+			}
+			job.SurchargePercent = percent
+		case "surcharge_mode":
+			job.SurchargeMode = row[1]
+		case "category":
+			if len(row) < 3 {
+				return nil, nil, fmt.Errorf("%w: category %q has no name", ErrNotJobWorkbook, row[1])
+			}
+			job.Categories = append(job.Categories, WorkbookCategory{Name: row[2], Sheet: row[1]})
+		}
+	}
+
+	var problems []RowError
+	for i := range job.Categories {
+		items, rowErrors, err := readCategorySheet(f, job.Categories[i].Sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+		job.Categories[i].Items = items
+		problems = append(problems, rowErrors...)
+	}
+
+	return job, problems, nil
+}
+
+// itemColumns is the header row every category sheet must start with.
+var itemColumns = []string{"Section", "Type", "Item", "Description", "Qty", "Unit", "Unit Price", "Amount"}
+
+// readCategorySheet reads the item rows of one category sheet. Rows with no
+// values in the item columns (blank rows and the totals block) are skipped.
+func readCategorySheet(f *excelize.File, sheet string) ([]WorkbookItem, []RowError, error) {
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: sheet %q is missing", ErrNotJobWorkbook, sheet)
+	}
+	if len(rows) == 0 || len(rows[0]) < len(itemColumns) {
+		return nil, nil, fmt.Errorf("%w: sheet %q has no item header", ErrNotJobWorkbook, sheet)
+	}
+	for i, title := range itemColumns {
+		if strings.TrimSpace(rows[0][i]) != title {
+			return nil, nil, fmt.Errorf("%w: sheet %q column %d should be %q", ErrNotJobWorkbook, sheet, i+1, title)
+		}
+	}
+
+	var items []WorkbookItem
+	var problems []RowError
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		cells := make([]string, len(itemColumns))
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = strings.TrimSpace(row[j])
+			}
+		}
+
+		if strings.Join(cells[:6], "") == "" {
+			continue
+		}
+
+		quantity, err := strconv.ParseFloat(cells[4], 64)
+		if err != nil {
+			problems = append(problems, RowError{Sheet: sheet, Row: rowNumber, Message: fmt.Sprintf("Qty %q is not a number", cells[4])})
+			continue
+		}
+		price, err := strconv.ParseFloat(strings.TrimPrefix(cells[6], "$"), 64)
+		if err != nil {
+			problems = append(problems, RowError{Sheet: sheet, Row: rowNumber, Message: fmt.Sprintf("Unit Price %q is not a number", cells[6])})
+			continue
+		}
+
+		items = append(items, WorkbookItem{
+			Row:         rowNumber,
+			Section:     cells[0],
+			Type:        strings.ToLower(cells[1]),
+			Name:        cells[2],
+			Description: cells[3],
+			Quantity:    quantity,
+			Unit:        cells[5],
+			UnitPrice:   price,
+		})
+	}
+
+	return items, problems, nil
+}
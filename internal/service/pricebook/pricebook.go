@@ -0,0 +1,198 @@
+package pricebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dukerupert/skalkaho/internal/domain"
+)
+
+// SchemaVersion is the current version of the price book exchange format.
+// Bump it whenever a field is renamed or its meaning changes.
+const SchemaVersion = 1
+
+// Document is the JSON exchange format for sharing item templates between installs.
+type Document struct {
+	SchemaVersion  int        `json:"schema_version"`
+	IncludesPrices bool       `json:"includes_prices"`
+	Templates      []Template `json:"templates"`
+}
+
+// Template is a single item template in an exchange document.
+// DefaultPrice is omitted when the document was exported without prices.
+type Template struct {
+	Type         string   `json:"type"`
+	Category     string   `json:"category"`
+	Name         string   `json:"name"`
+	DefaultUnit  string   `json:"default_unit"`
+	DefaultPrice *float64 `json:"default_price,omitempty"`
+}
+
+// Encode writes the document as indented JSON with a trailing newline.
+// The output is stable so that unchanged data round-trips byte for byte.
+func Encode(w io.Writer, doc *Document) error {
+	if doc.Templates == nil {
+		doc.Templates = []Template{}
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding price book: %w", err)
+	}
+	b = append(b, '\n')
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("writing price book: %w", err)
+	}
+	return nil
+}
+
+// Decode reads and validates a document.
+func Decode(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading price book: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("price book is empty")
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing price book: %w", err)
+	}
+	if doc.SchemaVersion == 0 {
+		return nil, fmt.Errorf("price book is missing schema_version")
+	}
+	if doc.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("price book schema_version %d is newer than supported version %d", doc.SchemaVersion, SchemaVersion)
+	}
+	if doc.Templates == nil {
+		doc.Templates = []Template{}
+	}
+	return &doc, nil
+}
+
+// Actions describe what an import would do with an incoming template.
+// They are plain strings so templates can compare them directly.
+const (
+	ActionNew       = "new"
+	ActionUnchanged = "unchanged"
+	ActionConflict  = "conflict"
+	ActionInvalid   = "invalid"
+)
+
+// Resolution is the user's choice for an incoming template.
+type Resolution string
+
+const (
+	ResolutionSkip         Resolution = "skip"
+	ResolutionTakeIncoming Resolution = "take_incoming"
+	ResolutionKeepMine     Resolution = "keep_mine"
+)
+
+// Change pairs an incoming template with the existing template of the same name.
+type Change struct {
+	Index    int
+	Incoming Template
+	Existing *Template
+	Action   string
+	Problem  string
+}
+
+// DefaultResolution returns the pre-selected choice for the change in the preview.
+func (c Change) DefaultResolution() Resolution {
+	switch c.Action {
+	case ActionNew:
+		return ResolutionTakeIncoming
+	case ActionConflict:
+		return ResolutionKeepMine
+	default:
+		return ResolutionSkip
+	}
+}
+
+// Plan compares incoming templates against existing ones by name and
+// reports what importing each would do. Existing templates are matched on
+// their exact name; the first template with a given name wins.
+func Plan(existing []Template, incoming []Template, includesPrices bool) []Change {
+	byName := make(map[string]Template, len(existing))
+	for _, t := range existing {
+		if _, ok := byName[t.Name]; !ok {
+			byName[t.Name] = t
+		}
+	}
+
+	seen := make(map[string]bool, len(incoming))
+	changes := make([]Change, 0, len(incoming))
+	for i, in := range incoming {
+		change := Change{Index: i, Incoming: in}
+
+		if problem := validateTemplate(in); problem != "" {
+			change.Action = ActionInvalid
+			change.Problem = problem
+			changes = append(changes, change)
+			continue
+		}
+		if seen[in.Name] {
+			change.Action = ActionInvalid
+			change.Problem = "Duplicate name in import file"
+			changes = append(changes, change)
+			continue
+		}
+		seen[in.Name] = true
+
+		mine, ok := byName[in.Name]
+		if !ok {
+			change.Action = ActionNew
+			changes = append(changes, change)
+			continue
+		}
+
+		change.Existing = &mine
+		if sameTemplate(mine, in, includesPrices) {
+			change.Action = ActionUnchanged
+		} else {
+			change.Action = ActionConflict
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// validateTemplate returns a description of the first problem with t, if any.
+func validateTemplate(t Template) string {
+	if strings.TrimSpace(t.Name) == "" {
+		return "Name is required"
+	}
+	if !domain.LineItemType(t.Type).Valid() {
+		return "Type must be 'material', 'labor', 'equipment', 'subcontract', or 'fee'"
+	}
+	if t.DefaultPrice != nil && *t.DefaultPrice < 0 {
+		return "Price cannot be negative"
+	}
+	return ""
+}
+
+// sameTemplate reports whether importing in over mine would change anything.
+func sameTemplate(mine, in Template, includesPrices bool) bool {
+	if mine.Type != in.Type || mine.Category != in.Category || mine.DefaultUnit != in.DefaultUnit {
+		return false
+	}
+	if includesPrices && in.DefaultPrice != nil {
+		return mine.DefaultPrice != nil && *mine.DefaultPrice == *in.DefaultPrice
+	}
+	return true
+}
+
+// Summary counts the outcome of an applied import.
+type Summary struct {
+	Created   int
+	Updated   int
+	Kept      int
+	Skipped   int
+	Unchanged int
+	Invalid   int
+}
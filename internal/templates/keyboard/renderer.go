@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"math"
 	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
 )
 
 //go:embed layouts/*.html pages/*.html partials/*.html
@@ -14,16 +18,39 @@ var templateFS embed.FS
 // Renderer handles keyboard template rendering.
 type Renderer struct {
 	templates *template.Template
+
+	mu    sync.RWMutex
+	theme string
 }
 
 // NewRenderer creates a new keyboard template renderer.
 func NewRenderer() (*Renderer, error) {
-	tmpl, err := template.New("").Funcs(templateFuncs()).ParseFS(templateFS, "layouts/*.html", "pages/*.html", "partials/*.html")
+	r := &Renderer{theme: "system"}
+
+	funcs := templateFuncs()
+	funcs["theme"] = r.Theme
+
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(templateFS, "layouts/*.html", "pages/*.html", "partials/*.html")
 	if err != nil {
 		return nil, fmt.Errorf("parsing keyboard templates: %w", err)
 	}
 
-	return &Renderer{templates: tmpl}, nil
+	r.templates = tmpl
+	return r, nil
+}
+
+// Theme returns the color theme pages are rendered with: light, dark, or system.
+func (r *Renderer) Theme() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.theme
+}
+
+// SetTheme changes the color theme used for subsequently rendered pages.
+func (r *Renderer) SetTheme(theme string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.theme = theme
 }
 
 // Render renders a full page template.
@@ -47,15 +74,18 @@ func (r *Renderer) RenderPartial(w io.Writer, name string, data interface{}) err
 // templateFuncs returns custom template functions.
 func templateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"formatMoney":   formatMoney,
+		"formatMoney":   FormatMoney,
 		"formatPercent": formatPercent,
 		"add":           add,
 		"sub":           sub,
 		"mul":           func(a, b float64) float64 { return a * b },
+		"abs":           math.Abs,
 		"eq":            func(a, b interface{}) bool { return a == b },
 		"gt":            gt,
 		"typeIndicator": typeIndicator,
 		"dict":          dict,
+		// idempotencyKey gives each rendered form a key for X-Idempotency-Key.
+		"idempotencyKey": func() string { return uuid.New().String() },
 	}
 }
 
@@ -106,8 +136,14 @@ func dict(values ...interface{}) map[string]interface{} {
 	return d
 }
 
-func formatMoney(amount float64) string {
-	return fmt.Sprintf("$%.2f", amount)
+// FormatMoney formats an amount as dollars, with negative amounts such as
+// credits in parentheses. Handlers use it for amounts the browser displays
+// as-is, so they match the server-rendered pages.
+func FormatMoney(amount float64) string {
+	if amount < -0.005 {
+		return fmt.Sprintf("($%.2f)", -amount)
+	}
+	return fmt.Sprintf("$%.2f", math.Abs(amount))
 }
 
 func formatPercent(amount float64) string {
@@ -122,6 +158,10 @@ func typeIndicator(itemType string) string {
 		return "◐" // half circle
 	case "equipment":
 		return "○" // empty circle
+	case "subcontract":
+		return "◆" // filled diamond
+	case "fee":
+		return "◇" // empty diamond
 	default:
 		return "•"
 	}
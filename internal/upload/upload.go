@@ -0,0 +1,272 @@
+// Package upload validates files submitted through multipart forms.
+package upload
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind identifies the content of an uploaded file.
+type Kind string
+
+const (
+	KindXLSX Kind = "xlsx"
+	KindCSV  Kind = "csv"
+	KindJSON Kind = "json"
+)
+
+// Rule describes a file type an upload field accepts.
+type Rule struct {
+	Kind       Kind
+	Label      string
+	Extensions []string
+	MaxBytes   int64
+}
+
+// Rules for the file types the app accepts.
+var (
+	XLSX = Rule{Kind: KindXLSX, Label: "an Excel workbook", Extensions: []string{".xlsx"}, MaxBytes: 10 << 20}
+	CSV  = Rule{Kind: KindCSV, Label: "a CSV file", Extensions: []string{".csv"}, MaxBytes: 5 << 20}
+	JSON = Rule{Kind: KindJSON, Label: "a JSON file", Extensions: []string{".json"}, MaxBytes: 5 << 20}
+)
+
+// Error codes returned in Error.Code.
+const (
+	CodeMissing   = "missing"
+	CodeTooLarge  = "too_large"
+	CodeExtension = "extension"
+	CodeContent   = "content"
+	CodeTooMany   = "too_many"
+)
+
+// Error is a validation failure meant to be shown next to the upload field.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// File is an upload that passed validation.
+type File struct {
+	Name string // sanitized base name, safe to store and display
+	Kind Kind
+	Data []byte
+}
+
+// FromRequest reads the named multipart field and validates it against the
+// accepted rules. Validation failures are returned as *Error.
+func FromRequest(w http.ResponseWriter, r *http.Request, field string, rules ...Rule) (*File, error) {
+	var limit int64
+	for _, rule := range rules {
+		if rule.MaxBytes > limit {
+			limit = rule.MaxBytes
+		}
+	}
+
+	// Allow room for the multipart framing and other form fields.
+	r.Body = http.MaxBytesReader(w, r.Body, limit+(1<<20))
+	if err := r.ParseMultipartForm(limit); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &Error{Code: CodeTooLarge, Message: fmt.Sprintf("File is larger than the %s limit", formatSize(limit))}
+		}
+		return nil, &Error{Code: CodeMissing, Message: "No file uploaded"}
+	}
+
+	file, header, err := r.FormFile(field)
+	if err != nil {
+		return nil, &Error{Code: CodeMissing, Message: "No file uploaded"}
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading upload: %w", err)
+	}
+
+	return Validate(header.Filename, data, rules...)
+}
+
+// Part is one file of a multi-file upload: either a File that passed
+// validation or the *Error explaining why it didn't.
+type Part struct {
+	Filename string // sanitized name as submitted
+	File     *File
+	Err      error
+}
+
+// FilesFromRequest reads every file in the named multipart field, up to
+// maxFiles, and validates each against the accepted rules. A file that fails
+// validation is returned as a Part with Err set so the others can still be
+// used; the error result is only for problems with the request as a whole.
+func FilesFromRequest(w http.ResponseWriter, r *http.Request, field string, maxFiles int, rules ...Rule) ([]Part, error) {
+	var limit int64
+	for _, rule := range rules {
+		if rule.MaxBytes > limit {
+			limit = rule.MaxBytes
+		}
+	}
+
+	total := limit * int64(maxFiles)
+	r.Body = http.MaxBytesReader(w, r.Body, total+(1<<20))
+	if err := r.ParseMultipartForm(limit); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &Error{Code: CodeTooLarge, Message: fmt.Sprintf("Files are larger than the %s limit", formatSize(total))}
+		}
+		return nil, &Error{Code: CodeMissing, Message: "No file uploaded"}
+	}
+
+	headers := r.MultipartForm.File[field]
+	if len(headers) == 0 {
+		return nil, &Error{Code: CodeMissing, Message: "No file uploaded"}
+	}
+	if len(headers) > maxFiles {
+		return nil, &Error{Code: CodeTooMany, Message: fmt.Sprintf("Upload at most %d files at a time", maxFiles)}
+	}
+
+	parts := make([]Part, 0, len(headers))
+	for _, header := range headers {
+		part := Part{Filename: SanitizeFilename(header.Filename)}
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening upload %s: %w", part.Filename, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, limit+1))
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading upload %s: %w", part.Filename, err)
+		}
+		part.File, part.Err = Validate(header.Filename, data, rules...)
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// Validate checks a file's extension, size, and content against the accepted
+// rules. The extension picks the rule; the content must then match its kind.
+func Validate(filename string, data []byte, rules ...Rule) (*File, error) {
+	name := SanitizeFilename(filename)
+	ext := strings.ToLower(filepath.Ext(name))
+
+	var rule *Rule
+	var allowed []string
+	for i := range rules {
+		for _, e := range rules[i].Extensions {
+			allowed = append(allowed, e)
+			if e == ext && rule == nil {
+				rule = &rules[i]
+			}
+		}
+	}
+	if rule == nil {
+		return nil, &Error{
+			Code:    CodeExtension,
+			Message: fmt.Sprintf("%s is not an accepted file type (expected %s)", name, strings.Join(allowed, " or ")),
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, &Error{Code: CodeContent, Message: fmt.Sprintf("%s is empty", name)}
+	}
+	if int64(len(data)) > rule.MaxBytes {
+		return nil, &Error{Code: CodeTooLarge, Message: fmt.Sprintf("%s is larger than the %s limit", name, formatSize(rule.MaxBytes))}
+	}
+	if !Matches(rule.Kind, data) {
+		return nil, &Error{Code: CodeContent, Message: fmt.Sprintf("%s does not contain %s", name, rule.Label)}
+	}
+
+	return &File{Name: name, Kind: rule.Kind, Data: data}, nil
+}
+
+// Matches reports whether data looks like a file of the given kind, judged by
+// its magic bytes or, for text formats, its characters.
+func Matches(kind Kind, data []byte) bool {
+	switch kind {
+	case KindXLSX:
+		return isXLSX(data)
+	case KindCSV:
+		return isText(data)
+	case KindJSON:
+		return isText(data) && json.Valid(data)
+	}
+	return false
+}
+
+// isXLSX checks for a zip archive containing an Excel workbook part.
+func isXLSX(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return false
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, f := range archive.File {
+		if f.Name == "xl/workbook.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// isText reports whether data is UTF-8 text with no binary control characters.
+func isText(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeFilename reduces a client-supplied file name to a safe base name:
+// directory components, control characters, and characters outside a
+// conservative set are removed, and the result is capped at 100 characters.
+func SanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), strings.ContainsRune("._- ()", r):
+			sb.WriteRune(r)
+		case unicode.IsSpace(r):
+			sb.WriteRune(' ')
+		}
+	}
+
+	name = strings.Trim(strings.Join(strings.Fields(sb.String()), " "), ". ")
+	if runes := []rune(name); len(runes) > 100 {
+		ext := filepath.Ext(name)
+		name = string(runes[:100-utf8.RuneCountInString(ext)]) + ext
+	}
+	if name == "" {
+		name = "upload"
+	}
+	return name
+}
+
+// formatSize renders a byte limit as whole megabytes.
+func formatSize(n int64) string {
+	return fmt.Sprintf("%dMB", n>>20)
+}
@@ -0,0 +1,148 @@
+package upload_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dukerupert/skalkaho/internal/upload"
+	"github.com/xuri/excelize/v2"
+)
+
+func workbookBytes(t *testing.T) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("write workbook: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	xlsx := workbookBytes(t)
+	csv := []byte("name,unit,price\n2x4x8,ea,3.27\n")
+	json := []byte(`{"version":1,"templates":[]}`)
+	binary := []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00")
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		rules    []upload.Rule
+		wantCode string
+		wantName string
+	}{
+		{"xlsx", "prices.xlsx", xlsx, []upload.Rule{upload.XLSX}, "", "prices.xlsx"},
+		{"double extension executable", "quote.xlsx.exe", binary, []upload.Rule{upload.XLSX}, upload.CodeExtension, ""},
+		{"csv renamed to xlsx", "prices.xlsx", csv, []upload.Rule{upload.XLSX}, upload.CodeContent, ""},
+		{"plain zip renamed to xlsx", "prices.xlsx", []byte("PK\x03\x04junk"), []upload.Rule{upload.XLSX}, upload.CodeContent, ""},
+		{"xlsx renamed to csv", "prices.csv", xlsx, []upload.Rule{upload.CSV}, upload.CodeContent, ""},
+		{"csv", "prices.csv", csv, []upload.Rule{upload.CSV}, "", "prices.csv"},
+		{"csv with byte order mark", "prices.csv", append([]byte("\xef\xbb\xbf"), csv...), []upload.Rule{upload.CSV}, "", "prices.csv"},
+		{"binary renamed to csv", "prices.csv", binary, []upload.Rule{upload.CSV}, upload.CodeContent, ""},
+		{"json", "templates.json", json, []upload.Rule{upload.JSON}, "", "templates.json"},
+		{"csv renamed to json", "templates.json", csv, []upload.Rule{upload.JSON}, upload.CodeContent, ""},
+		{"second rule by extension", "prices.csv", csv, []upload.Rule{upload.XLSX, upload.CSV}, "", "prices.csv"},
+		{"empty file", "prices.csv", nil, []upload.Rule{upload.CSV}, upload.CodeContent, ""},
+		{"too large", "prices.csv", bytes.Repeat([]byte("a,b\n"), 2<<20), []upload.Rule{upload.CSV}, upload.CodeTooLarge, ""},
+		{"path is stripped", `..\..\etc/prices.xlsx`, xlsx, []upload.Rule{upload.XLSX}, "", "prices.xlsx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := upload.Validate(tt.filename, tt.data, tt.rules...)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if file.Name != tt.wantName {
+					t.Errorf("Name = %q, want %q", file.Name, tt.wantName)
+				}
+				return
+			}
+			var uerr *upload.Error
+			if !errors.As(err, &uerr) {
+				t.Fatalf("error = %v, want *upload.Error", err)
+			}
+			if uerr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q (%s)", uerr.Code, tt.wantCode, uerr.Message)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"Acme Prices (Nov).xlsx":  "Acme Prices (Nov).xlsx",
+		"../../secret.xlsx":       "secret.xlsx",
+		`C:\Users\me\prices.xlsx`: "prices.xlsx",
+		"quote<1>?*.xlsx":         "quote1.xlsx",
+		"line\nbreak.csv":         "line break.csv",
+		"...":                     "upload",
+		"":                        "upload",
+	}
+	for in, want := range tests {
+		if got := upload.SanitizeFilename(in); got != want {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func multipartRequest(t *testing.T, field string, files map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := mw.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestFilesFromRequest(t *testing.T) {
+	xlsx := workbookBytes(t)
+
+	req := multipartRequest(t, "file", map[string][]byte{
+		"acme.xlsx":  xlsx,
+		"build.xlsx": xlsx,
+		"notes.txt":  []byte("not a price list"),
+	})
+	parts, err := upload.FilesFromRequest(httptest.NewRecorder(), req, "file", 10, upload.XLSX)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("parts = %d, want 3", len(parts))
+	}
+	for _, part := range parts {
+		var uerr *upload.Error
+		switch part.Filename {
+		case "notes.txt":
+			if !errors.As(part.Err, &uerr) || uerr.Code != upload.CodeExtension {
+				t.Errorf("notes.txt error = %v, want extension error", part.Err)
+			}
+		default:
+			if part.Err != nil || part.File == nil {
+				t.Errorf("%s: unexpected error %v", part.Filename, part.Err)
+			}
+		}
+	}
+
+	req = multipartRequest(t, "file", map[string][]byte{"a.xlsx": xlsx, "b.xlsx": xlsx, "c.xlsx": xlsx})
+	_, err = upload.FilesFromRequest(httptest.NewRecorder(), req, "file", 2, upload.XLSX)
+	var uerr *upload.Error
+	if !errors.As(err, &uerr) || uerr.Code != upload.CodeTooMany {
+		t.Errorf("error = %v, want too many files", err)
+	}
+}
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Audit trail of bulk changes made to a job
+CREATE TABLE job_activity (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    detail TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_job_activity_job ON job_activity(job_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_job_activity_job;
+DROP TABLE IF EXISTS job_activity;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Retention settings for the background cleanup task. A value of 0 disables that cleanup.
+ALTER TABLE settings ADD COLUMN cleanup_empty_job_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN cleanup_import_days INTEGER NOT NULL DEFAULT 30;
+ALTER TABLE settings ADD COLUMN cleanup_activity_days INTEGER NOT NULL DEFAULT 365;
+ALTER TABLE settings ADD COLUMN cleanup_dry_run BOOLEAN NOT NULL DEFAULT 0;
+
+-- One row per cleanup run, recording what was (or in a dry run, would have been) removed
+CREATE TABLE cleanup_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dry_run BOOLEAN NOT NULL DEFAULT 0,
+    empty_jobs INTEGER NOT NULL DEFAULT 0,
+    stale_imports INTEGER NOT NULL DEFAULT 0,
+    activity_entries INTEGER NOT NULL DEFAULT 0,
+    vacuumed BOOLEAN NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS cleanup_runs;
+ALTER TABLE settings DROP COLUMN cleanup_dry_run;
+ALTER TABLE settings DROP COLUMN cleanup_activity_days;
+ALTER TABLE settings DROP COLUMN cleanup_import_days;
+ALTER TABLE settings DROP COLUMN cleanup_empty_job_days;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Company details shown on printed quotes
+ALTER TABLE settings ADD COLUMN company_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE settings ADD COLUMN company_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE settings ADD COLUMN company_phone TEXT NOT NULL DEFAULT '';
+ALTER TABLE settings ADD COLUMN company_email TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN company_email;
+ALTER TABLE settings DROP COLUMN company_phone;
+ALTER TABLE settings DROP COLUMN company_address;
+ALTER TABLE settings DROP COLUMN company_name;
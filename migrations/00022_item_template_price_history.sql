@@ -0,0 +1,19 @@
+-- +goose Up
+-- Every change to an item template's default price, with where it came from
+CREATE TABLE item_template_price_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL REFERENCES item_templates(id) ON DELETE CASCADE,
+    old_price REAL NOT NULL,
+    new_price REAL NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('manual', 'price_import', 'template_import')),
+    import_id TEXT REFERENCES price_imports(id) ON DELETE SET NULL,
+    import_filename TEXT,
+    request_id TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_item_template_price_history_template ON item_template_price_history(template_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_item_template_price_history_template;
+DROP TABLE IF EXISTS item_template_price_history;
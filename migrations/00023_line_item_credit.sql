@@ -0,0 +1,11 @@
+-- +goose Up
+-- Credits are deductions, such as fixtures supplied by the client, stored
+-- with a negative unit price
+ALTER TABLE line_items ADD COLUMN is_credit BOOLEAN NOT NULL DEFAULT 0;
+
+-- Whether markup applies to credits; off by default
+ALTER TABLE settings ADD COLUMN surcharge_credits BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN surcharge_credits;
+ALTER TABLE line_items DROP COLUMN is_credit;
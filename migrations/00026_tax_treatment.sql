@@ -0,0 +1,15 @@
+-- +goose Up
+-- Sales tax percent applied to a job's taxable items
+ALTER TABLE jobs ADD COLUMN tax_percent REAL NOT NULL DEFAULT 0;
+
+-- Whether items are taxed: 'default' inherits from the parent category, and
+-- items with no explicit treatment anywhere in their chain are taxable
+ALTER TABLE categories ADD COLUMN tax_treatment TEXT NOT NULL DEFAULT 'default'
+    CHECK (tax_treatment IN ('default', 'taxable', 'exempt'));
+ALTER TABLE line_items ADD COLUMN tax_treatment TEXT NOT NULL DEFAULT 'default'
+    CHECK (tax_treatment IN ('default', 'taxable', 'exempt'));
+
+-- +goose Down
+ALTER TABLE line_items DROP COLUMN tax_treatment;
+ALTER TABLE categories DROP COLUMN tax_treatment;
+ALTER TABLE jobs DROP COLUMN tax_percent;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The section header above each import row, such as "Lumber" or "Fasteners",
+-- used to suggest a category when creating a template from the row
+ALTER TABLE price_import_matches ADD COLUMN source_category TEXT;
+
+-- +goose Down
+ALTER TABLE price_import_matches DROP COLUMN source_category;
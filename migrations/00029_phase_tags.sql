@@ -0,0 +1,9 @@
+-- +goose Up
+-- Free-text delivery phase (rough-in, finish, ...) for staging site
+-- materials. Items without a phase inherit their category's.
+ALTER TABLE categories ADD COLUMN phase TEXT;
+ALTER TABLE line_items ADD COLUMN phase TEXT;
+
+-- +goose Down
+ALTER TABLE line_items DROP COLUMN phase;
+ALTER TABLE categories DROP COLUMN phase;
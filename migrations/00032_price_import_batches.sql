@@ -0,0 +1,14 @@
+-- +goose Up
+-- Files uploaded together are reviewed and applied together.
+CREATE TABLE price_import_batches (
+    id TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+ALTER TABLE price_imports ADD COLUMN batch_id TEXT REFERENCES price_import_batches(id);
+CREATE INDEX idx_price_imports_batch ON price_imports(batch_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_price_imports_batch;
+ALTER TABLE price_imports DROP COLUMN batch_id;
+DROP TABLE IF EXISTS price_import_batches;
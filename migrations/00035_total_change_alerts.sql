@@ -0,0 +1,26 @@
+-- +goose Up
+-- Percentage change in a job's grand total, from a single line item edit,
+-- that raises an alert. 0 turns the alert off.
+ALTER TABLE settings ADD COLUMN total_alert_percent REAL NOT NULL DEFAULT 200;
+
+-- An edit that moved a job's grand total sharply. previous holds the line
+-- item as it was before the edit, as JSON, so the edit can be undone; it is
+-- NULL when the item was created.
+CREATE TABLE total_change_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    line_item_id TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    previous TEXT,
+    old_total REAL NOT NULL,
+    new_total REAL NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'undone', 'dismissed')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_total_change_alerts_job ON total_change_alerts(job_id, status);
+
+-- +goose Down
+DROP INDEX idx_total_change_alerts_job;
+DROP TABLE total_change_alerts;
+ALTER TABLE settings DROP COLUMN total_alert_percent;
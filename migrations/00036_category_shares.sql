@@ -0,0 +1,19 @@
+-- +goose Up
+-- Read-only links to a single category and its subcategories, for sending
+-- scope to a sub. mode decides whether prices are shown: crew links leave
+-- them out, customer links include them.
+CREATE TABLE category_shares (
+    token TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('crew', 'customer')),
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TEXT,
+    revoked_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_category_shares_category ON category_shares(category_id);
+
+-- +goose Down
+DROP INDEX idx_category_shares_category;
+DROP TABLE category_shares;
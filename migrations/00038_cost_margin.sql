@@ -0,0 +1,7 @@
+-- +goose Up
+-- Margin over a template's price that the "raise to cost" quick action
+-- prices a below-cost line item at.
+ALTER TABLE settings ADD COLUMN cost_margin_percent REAL NOT NULL DEFAULT 10;
+
+-- +goose Down
+ALTER TABLE settings DROP COLUMN cost_margin_percent;
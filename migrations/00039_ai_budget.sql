@@ -0,0 +1,26 @@
+-- +goose Up
+-- Monthly cap on price import API spend, in dollars or tokens. 0 means no
+-- cap. ai_budget_override lets the next upload through once the cap is hit,
+-- and is cleared by that upload.
+ALTER TABLE settings ADD COLUMN ai_budget REAL NOT NULL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN ai_budget_unit TEXT NOT NULL DEFAULT 'dollars' CHECK (ai_budget_unit IN ('dollars', 'tokens'));
+ALTER TABLE settings ADD COLUMN ai_budget_override BOOLEAN NOT NULL DEFAULT 0;
+
+-- Tokens used by each price import's API call. Rows are kept when the import
+-- itself is cleaned up, so the month's spend stays accurate.
+CREATE TABLE import_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    import_id TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL,
+    output_tokens INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_import_usage_created_at ON import_usage(created_at);
+
+-- +goose Down
+DROP INDEX idx_import_usage_created_at;
+DROP TABLE import_usage;
+ALTER TABLE settings DROP COLUMN ai_budget_override;
+ALTER TABLE settings DROP COLUMN ai_budget_unit;
+ALTER TABLE settings DROP COLUMN ai_budget;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Internal notes on a line item, such as "verify this count against the
+-- plan". Never shown to customers.
+CREATE TABLE item_comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id TEXT NOT NULL REFERENCES line_items(id) ON DELETE CASCADE,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    resolved BOOLEAN NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_item_comments_item ON item_comments(item_id, resolved);
+
+-- +goose Down
+DROP INDEX idx_item_comments_item;
+DROP TABLE item_comments;
@@ -12,6 +12,11 @@ SELECT * FROM categories
 WHERE job_id = ?
 ORDER BY sort_order ASC;
 
+-- name: ListCategoriesByJobIDs :many
+SELECT * FROM categories
+WHERE job_id IN (sqlc.slice('job_ids'))
+ORDER BY sort_order ASC;
+
 -- name: ListTopLevelCategories :many
 SELECT * FROM categories
 WHERE job_id = ? AND parent_id IS NULL
@@ -22,6 +27,16 @@ SELECT * FROM categories
 WHERE parent_id = ?
 ORDER BY sort_order ASC;
 
+-- name: ListJobPhases :many
+SELECT DISTINCT phase FROM (
+    SELECT c.phase, c.job_id FROM categories c
+    UNION ALL
+    SELECT li.phase, c.job_id FROM line_items li
+    JOIN categories c ON li.category_id = c.id
+)
+WHERE job_id = ? AND phase IS NOT NULL AND phase != ''
+ORDER BY phase;
+
 -- name: UpdateCategory :one
 UPDATE categories SET
     name = ?,
@@ -30,6 +45,15 @@ UPDATE categories SET
 WHERE id = ?
 RETURNING *;
 
+-- name: UpdateCategoryTaxTreatment :exec
+UPDATE categories SET tax_treatment = ? WHERE id = ?;
+
+-- name: UpdateCategoryPhase :exec
+UPDATE categories SET phase = ? WHERE id = ?;
+
+-- name: UpdateCategoryDefaults :exec
+UPDATE categories SET default_item_type = ?, default_unit = ? WHERE id = ?;
+
 -- name: UpdateCategoryParent :one
 UPDATE categories SET
     parent_id = ?
@@ -51,3 +75,12 @@ WITH RECURSIVE ancestors AS (
     JOIN ancestors a ON c.id = a.parent_id
 )
 SELECT MAX(depth) as max_depth FROM ancestors;
+
+-- name: SearchQuickAddTargets :many
+SELECT c.id AS category_id, c.name AS category_name, j.id AS job_id, j.name AS job_name
+FROM categories c
+JOIN jobs j ON j.id = c.job_id
+WHERE j.status IN ('draft', 'sent')
+  AND (c.name LIKE '%' || @search || '%' OR j.name LIKE '%' || @search || '%')
+ORDER BY j.updated_at DESC, j.name, c.sort_order
+LIMIT @limit;
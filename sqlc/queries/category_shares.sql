@@ -0,0 +1,23 @@
+-- name: CreateCategoryShare :one
+INSERT INTO category_shares (token, category_id, mode)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetCategoryShare :one
+SELECT * FROM category_shares
+WHERE token = ?;
+
+-- name: ListCategoryShares :many
+SELECT * FROM category_shares
+WHERE category_id = ? AND revoked_at IS NULL
+ORDER BY created_at DESC, token;
+
+-- name: RecordCategoryShareView :exec
+UPDATE category_shares SET
+    view_count = view_count + 1,
+    last_viewed_at = datetime('now')
+WHERE token = ?;
+
+-- name: RevokeCategoryShare :execrows
+UPDATE category_shares SET revoked_at = datetime('now')
+WHERE token = ? AND category_id = ? AND revoked_at IS NULL;
@@ -0,0 +1,55 @@
+-- name: CountEmptyJobs :one
+SELECT COUNT(*) FROM jobs j
+WHERE j.status = 'draft'
+  AND j.updated_at < ?
+  AND NOT EXISTS (
+      SELECT 1 FROM categories c WHERE c.job_id = j.id
+  );
+
+-- name: DeleteEmptyJobs :execrows
+DELETE FROM jobs
+WHERE status = 'draft'
+  AND updated_at < ?
+  AND NOT EXISTS (
+      SELECT 1 FROM categories c WHERE c.job_id = jobs.id
+  );
+
+-- name: CountStaleImports :one
+SELECT COUNT(*) FROM price_imports
+WHERE status IN ('failed', 'pending', 'processing') AND created_at < ?;
+
+-- name: DeleteStaleImports :execrows
+DELETE FROM price_imports
+WHERE status IN ('failed', 'pending', 'processing') AND created_at < ?;
+
+-- name: CountExpiringImports :one
+SELECT COUNT(*) FROM price_imports
+WHERE status = 'ready' AND created_at < ?;
+
+-- name: ExpireImports :execrows
+UPDATE price_imports SET status = 'stale'
+WHERE status = 'ready' AND created_at < ?;
+
+-- name: CountJobActivityBefore :one
+SELECT COUNT(*) FROM job_activity
+WHERE created_at < ?;
+
+-- name: DeleteJobActivityBefore :execrows
+DELETE FROM job_activity
+WHERE created_at < ?;
+
+-- name: CreateCleanupRun :one
+INSERT INTO cleanup_runs (dry_run, empty_jobs, stale_imports, expired_imports, activity_entries, vacuumed, error_message)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetLatestCleanupRun :one
+SELECT * FROM cleanup_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetLatestVacuum :one
+SELECT created_at FROM cleanup_runs
+WHERE vacuumed = 1
+ORDER BY id DESC
+LIMIT 1;
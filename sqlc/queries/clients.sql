@@ -12,6 +12,10 @@ SELECT * FROM clients WHERE name = ?;
 -- name: ListClients :many
 SELECT * FROM clients ORDER BY name ASC;
 
+-- name: ListClientsByIDs :many
+SELECT * FROM clients
+WHERE id IN (sqlc.slice('ids'));
+
 -- name: ListClientsPaginated :many
 SELECT * FROM clients
 WHERE (@search = '' OR name LIKE '%' || @search || '%' OR company LIKE '%' || @search || '%')
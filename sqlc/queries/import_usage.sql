@@ -0,0 +1,9 @@
+-- name: CreateImportUsage :exec
+INSERT INTO import_usage (import_id, input_tokens, output_tokens)
+VALUES (?, ?, ?);
+
+-- name: GetImportUsageSince :one
+SELECT CAST(COALESCE(SUM(input_tokens), 0) AS INTEGER) AS input_tokens,
+       CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS output_tokens
+FROM import_usage
+WHERE created_at >= ?;
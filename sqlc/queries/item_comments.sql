@@ -0,0 +1,29 @@
+-- name: CreateItemComment :one
+INSERT INTO item_comments (item_id, author, body)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetItemComment :one
+SELECT * FROM item_comments
+WHERE id = ?;
+
+-- name: ListItemComments :many
+SELECT * FROM item_comments
+WHERE item_id = ?
+ORDER BY created_at ASC, id ASC;
+
+-- name: MoveItemComments :exec
+UPDATE item_comments SET item_id = @to_item_id
+WHERE item_id = @from_item_id;
+
+-- name: ResolveItemComment :execrows
+UPDATE item_comments SET resolved = 1
+WHERE id = ? AND resolved = 0;
+
+-- name: CountOpenItemCommentsByJob :many
+SELECT li.id AS item_id, li.category_id, COUNT(*) AS open_count
+FROM item_comments ic
+JOIN line_items li ON ic.item_id = li.id
+JOIN categories c ON li.category_id = c.id
+WHERE c.job_id = ? AND ic.resolved = 0
+GROUP BY li.id, li.category_id;
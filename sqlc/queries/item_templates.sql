@@ -43,3 +43,19 @@ UPDATE item_templates SET default_price = ? WHERE id = ?;
 
 -- name: UpdateItemTemplatePriceAndName :exec
 UPDATE item_templates SET default_price = ?, name = ? WHERE id = ?;
+
+-- name: UpdateItemTemplateRentalRates :exec
+UPDATE item_templates SET day_rate = ?, week_rate = ?, month_rate = ? WHERE id = ?;
+
+-- name: UpdateItemTemplateSku :exec
+UPDATE item_templates SET sku = ? WHERE id = ?;
+
+-- name: CreateItemTemplatePriceHistory :exec
+INSERT INTO item_template_price_history (template_id, old_price, new_price, source, import_id, import_filename, request_id)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetLatestItemTemplatePriceChange :one
+SELECT * FROM item_template_price_history
+WHERE template_id = ?
+ORDER BY id DESC
+LIMIT 1;
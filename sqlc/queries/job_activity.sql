@@ -0,0 +1,10 @@
+-- name: CreateJobActivity :one
+INSERT INTO job_activity (job_id, action, detail, actor)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListJobActivity :many
+SELECT * FROM job_activity
+WHERE job_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
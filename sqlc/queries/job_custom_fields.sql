@@ -0,0 +1,28 @@
+-- name: CreateJobCustomField :one
+INSERT INTO job_custom_fields (label, field_type, show_on_quote)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: ListJobCustomFields :many
+SELECT * FROM job_custom_fields
+WHERE archived_at IS NULL
+ORDER BY id;
+
+-- name: ArchiveJobCustomField :execrows
+UPDATE job_custom_fields SET archived_at = datetime('now')
+WHERE id = ? AND archived_at IS NULL;
+
+-- name: ListJobCustomFieldValues :many
+SELECT f.id AS field_id, f.label, f.field_type, f.show_on_quote, COALESCE(v.value, '') AS value
+FROM job_custom_fields f
+LEFT JOIN job_custom_field_values v ON v.field_id = f.id AND v.job_id = ?
+WHERE f.archived_at IS NULL
+ORDER BY f.id;
+
+-- name: SetJobCustomFieldValue :exec
+INSERT INTO job_custom_field_values (job_id, field_id, value)
+VALUES (?, ?, ?)
+ON CONFLICT (job_id, field_id) DO UPDATE SET value = excluded.value;
+
+-- name: DeleteJobCustomFieldValue :exec
+DELETE FROM job_custom_field_values WHERE job_id = ? AND field_id = ?;
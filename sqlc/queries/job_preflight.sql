@@ -0,0 +1,12 @@
+-- name: ListPreflightDismissals :many
+SELECT rule FROM job_preflight_dismissals
+WHERE job_id = ?
+ORDER BY rule;
+
+-- name: DismissPreflightRule :exec
+INSERT OR IGNORE INTO job_preflight_dismissals (job_id, rule)
+VALUES (?, ?);
+
+-- name: RestorePreflightRule :exec
+DELETE FROM job_preflight_dismissals
+WHERE job_id = ? AND rule = ?;
@@ -42,6 +42,20 @@ WHERE (@status = '' OR status = @status);
 -- name: UpdateJobStatus :one
 UPDATE jobs SET status = ? WHERE id = ? RETURNING *;
 
+-- name: ListRecentClientQuotes :many
+SELECT * FROM jobs
+WHERE client_id = ? AND id != ? AND status != 'draft'
+ORDER BY created_at DESC
+LIMIT ?;
+
+-- name: ListUnlinkedCustomerJobs :many
+SELECT * FROM jobs
+WHERE client_id IS NULL AND TRIM(COALESCE(customer_name, '')) != ''
+ORDER BY created_at;
+
+-- name: LinkJobClient :execrows
+UPDATE jobs SET client_id = ? WHERE id = ? AND client_id IS NULL;
+
 -- name: UpdateJob :one
 UPDATE jobs SET
     name = ?,
@@ -54,6 +68,45 @@ UPDATE jobs SET
 WHERE id = ?
 RETURNING *;
 
+-- name: UpdateJobTaxPercent :exec
+UPDATE jobs SET tax_percent = ? WHERE id = ?;
+
 -- name: DeleteJob :exec
 DELETE FROM jobs
 WHERE id = ?;
+
+-- name: UpdateJobFollowUp :one
+UPDATE jobs SET follow_up_at = ? WHERE id = ? RETURNING *;
+
+-- name: ListJobsExpiringBetween :many
+SELECT * FROM jobs
+WHERE expires_at >= @start_date AND expires_at < @end_date
+  AND (@status = '' OR status = @status)
+ORDER BY expires_at, name;
+
+-- name: ListJobsFollowUpBetween :many
+SELECT * FROM jobs
+WHERE follow_up_at >= @start_date AND follow_up_at < @end_date
+  AND (@status = '' OR status = @status)
+ORDER BY follow_up_at, name;
+
+-- name: ListOverdueFollowUps :many
+SELECT * FROM jobs
+WHERE follow_up_at < @today
+  AND status IN ('draft', 'sent')
+  AND (@status = '' OR status = @status)
+ORDER BY follow_up_at, name;
+
+-- name: ListJobsCreatedBetween :many
+SELECT * FROM jobs
+WHERE (@status = '' OR status = @status)
+  AND (@from_date = '' OR created_at >= @from_date)
+  AND (@to_date = '' OR created_at < @to_date)
+ORDER BY created_at, id;
+
+-- name: CountJobContents :many
+SELECT c.job_id, COUNT(DISTINCT c.id) AS category_count, COUNT(li.id) AS item_count
+FROM categories c
+LEFT JOIN line_items li ON li.category_id = c.id
+WHERE c.job_id IN (sqlc.slice('job_ids'))
+GROUP BY c.job_id;
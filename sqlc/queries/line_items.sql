@@ -1,6 +1,6 @@
 -- name: CreateLineItem :one
-INSERT INTO line_items (id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO line_items (id, category_id, type, name, description, quantity, unit, unit_price, surcharge_percent, sort_order, exempt_from_surcharge, template_id, is_credit, tax_treatment, phase)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetLineItem :one
@@ -18,6 +18,19 @@ JOIN categories c ON li.category_id = c.id
 WHERE c.job_id = ?
 ORDER BY li.sort_order ASC;
 
+-- name: ListLineItemsByJobIDs :many
+SELECT li.* FROM line_items li
+JOIN categories c ON li.category_id = c.id
+WHERE c.job_id IN (sqlc.slice('job_ids'))
+ORDER BY li.sort_order ASC;
+
+-- name: ListOrderItemsByJob :many
+SELECT li.type, li.name, li.quantity, li.unit, t.sku FROM line_items li
+JOIN categories c ON li.category_id = c.id
+LEFT JOIN item_templates t ON li.template_id = t.id
+WHERE c.job_id = ?
+ORDER BY li.sort_order ASC;
+
 -- name: UpdateLineItem :one
 UPDATE line_items SET
     type = ?,
@@ -27,10 +40,34 @@ UPDATE line_items SET
     unit = ?,
     unit_price = ?,
     surcharge_percent = ?,
-    sort_order = ?
+    sort_order = ?,
+    exempt_from_surcharge = ?,
+    is_credit = ?,
+    tax_treatment = ?,
+    phase = ?
 WHERE id = ?
 RETURNING *;
 
 -- name: DeleteLineItem :exec
 DELETE FROM line_items
 WHERE id = ?;
+
+-- name: UpdateLineItemPrice :exec
+UPDATE line_items SET unit_price = ?
+WHERE id = ?;
+
+-- name: ListLineItemsByCategoryWithTemplatePrice :many
+SELECT li.*, t.default_price AS template_price FROM line_items li
+LEFT JOIN item_templates t ON li.template_id = t.id
+WHERE li.category_id = ?
+ORDER BY li.sort_order ASC;
+
+-- name: UpdateLineItemQuantity :exec
+UPDATE line_items SET quantity = ?
+WHERE id = ?;
+
+-- name: ListTemplatePricesByJob :many
+SELECT li.id, t.default_price FROM line_items li
+JOIN categories c ON li.category_id = c.id
+JOIN item_templates t ON li.template_id = t.id
+WHERE c.job_id = ?;
@@ -0,0 +1,16 @@
+-- name: UpsertMatchCorrection :exec
+INSERT INTO match_corrections (source_name, template_id)
+VALUES (?, ?)
+ON CONFLICT (source_name) DO UPDATE SET
+    template_id = excluded.template_id,
+    created_at = datetime('now');
+
+-- name: ListMatchCorrections :many
+SELECT c.id, c.source_name, c.template_id, c.created_at, t.name AS template_name
+FROM match_corrections c
+JOIN item_templates t ON t.id = c.template_id
+ORDER BY c.created_at DESC, c.id DESC;
+
+-- name: DeleteMatchCorrection :execrows
+DELETE FROM match_corrections
+WHERE id = ?;
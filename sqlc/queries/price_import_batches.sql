@@ -0,0 +1,52 @@
+-- name: CreatePriceImportBatch :one
+INSERT INTO price_import_batches (id)
+VALUES (?)
+RETURNING *;
+
+-- name: GetPriceImportBatch :one
+SELECT * FROM price_import_batches WHERE id = ?;
+
+-- name: ListImportsByBatch :many
+SELECT i.* FROM price_imports i
+JOIN price_import_batches b ON b.id = i.batch_id
+WHERE b.id = ?
+ORDER BY i.filename;
+
+-- name: CountBatchImports :many
+SELECT
+    b.id,
+    COUNT(i.id) AS files,
+    COUNT(CASE WHEN i.status = 'ready' THEN 1 END) AS ready
+FROM price_import_batches b
+JOIN price_imports i ON i.batch_id = b.id
+WHERE b.id IN (sqlc.slice('batch_ids'))
+GROUP BY b.id;
+
+-- name: CountBatchMatches :one
+SELECT COUNT(*) FROM price_import_matches m
+JOIN price_imports i ON m.import_id = i.id
+JOIN price_import_batches b ON b.id = i.batch_id
+WHERE b.id = ? AND i.status = 'ready';
+
+-- name: CountBatchMatchesByStatus :many
+SELECT m.status, COUNT(*) AS count
+FROM price_import_matches m
+JOIN price_imports i ON m.import_id = i.id
+JOIN price_import_batches b ON b.id = i.batch_id
+WHERE b.id = ? AND i.status = 'ready'
+GROUP BY m.status;
+
+-- name: ListMatchesByBatch :many
+SELECT
+    m.*,
+    i.filename,
+    t.name as template_name,
+    t.default_unit as template_unit,
+    t.default_price as template_price
+FROM price_import_matches m
+JOIN price_imports i ON m.import_id = i.id
+JOIN price_import_batches b ON b.id = i.batch_id
+LEFT JOIN item_templates t ON m.matched_template_id = t.id
+WHERE b.id = ? AND i.status = 'ready'
+ORDER BY i.filename, m.confidence DESC, m.row_number
+LIMIT ? OFFSET ?;
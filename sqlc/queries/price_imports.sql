@@ -1,6 +1,6 @@
 -- name: CreatePriceImport :one
-INSERT INTO price_imports (id, filename, status, total_rows)
-VALUES (?, ?, ?, ?)
+INSERT INTO price_imports (id, filename, status, total_rows, batch_id)
+VALUES (?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetPriceImport :one
@@ -8,9 +8,36 @@ SELECT * FROM price_imports WHERE id = ?;
 
 -- name: ListPriceImports :many
 SELECT * FROM price_imports
-ORDER BY created_at DESC
+ORDER BY
+    (
+        SELECT MIN(CASE
+            WHEN b.status = 'stale' THEN 0
+            WHEN b.status = 'ready' AND EXISTS (
+                SELECT 1 FROM price_import_matches m
+                WHERE m.import_id = b.id AND m.status = 'pending'
+            ) THEN 1
+            ELSE 2
+        END)
+        FROM price_imports b
+        WHERE b.id = price_imports.id OR b.batch_id = price_imports.batch_id
+    ),
+    (
+        SELECT MAX(b.created_at) FROM price_imports b
+        WHERE b.id = price_imports.id OR b.batch_id = price_imports.batch_id
+    ) DESC,
+    batch_id,
+    filename
 LIMIT ? OFFSET ?;
 
+-- name: GetImportReminder :one
+SELECT
+    COUNT(CASE WHEN status = 'ready' AND created_at < ? THEN 1 END) AS overdue,
+    COUNT(CASE WHEN status = 'stale' THEN 1 END) AS stale
+FROM price_imports;
+
+-- name: CountPriceImports :one
+SELECT COUNT(*) FROM price_imports;
+
 -- name: UpdatePriceImportStatus :one
 UPDATE price_imports
 SET status = ?, matched_rows = ?, error_message = ?, total_rows = ?
@@ -26,8 +53,8 @@ RETURNING *;
 -- name: CreatePriceImportMatch :one
 INSERT INTO price_import_matches (
     import_id, row_number, source_name, source_unit, source_price,
-    matched_template_id, confidence, match_reason, status
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    matched_template_id, confidence, match_reason, status, source_category
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: ListMatchesByImport :many
@@ -52,7 +79,8 @@ WHERE import_id = ? AND confidence >= ? AND status = 'pending';
 -- name: ListApprovedMatches :many
 SELECT
     m.*,
-    t.name as template_name
+    t.name as template_name,
+    t.default_price as template_price
 FROM price_import_matches m
 JOIN item_templates t ON m.matched_template_id = t.id
 WHERE m.import_id = ? AND m.status IN ('approved', 'auto_approved');
@@ -79,3 +107,60 @@ UPDATE price_import_matches
 SET status = 'created', matched_template_id = ?
 WHERE id = ?
 RETURNING *;
+
+-- name: ListPriceImportImpact :many
+SELECT
+    t.id AS template_id,
+    t.name AS template_name,
+    t.default_unit,
+    m.source_price AS new_price,
+    AVG(used.unit_price) AS avg_used_price,
+    COUNT(used.id) AS usage_count
+FROM price_import_matches m
+JOIN item_templates t ON m.matched_template_id = t.id
+LEFT JOIN (
+    SELECT li.id, li.template_id, li.unit_price
+    FROM line_items li
+    JOIN categories c ON li.category_id = c.id
+    JOIN jobs j ON c.job_id = j.id
+    WHERE j.created_at >= @since
+) used ON used.template_id = t.id
+WHERE m.import_id = @import_id AND m.status IN ('approved', 'auto_approved')
+GROUP BY m.id
+ORDER BY t.name;
+
+-- name: ListUnappliedTemplateMatchImports :many
+SELECT i.id AS import_id, i.filename, COUNT(*) AS match_count
+FROM price_import_matches m
+JOIN price_imports i ON m.import_id = i.id
+WHERE m.matched_template_id = ?
+  AND m.status IN ('pending', 'approved', 'auto_approved')
+  AND i.status != 'applied'
+GROUP BY i.id, i.filename
+ORDER BY i.created_at;
+
+-- name: RejectUnappliedTemplateMatches :execrows
+UPDATE price_import_matches SET status = 'rejected'
+WHERE matched_template_id = ?
+  AND status IN ('pending', 'approved', 'auto_approved')
+  AND import_id IN (SELECT id FROM price_imports WHERE status != 'applied');
+
+-- name: ClearMatchedTemplate :exec
+UPDATE price_import_matches SET matched_template_id = NULL
+WHERE matched_template_id = ?;
+
+-- name: GetPriceImportMatch :one
+SELECT * FROM price_import_matches
+WHERE id = ?;
+
+-- name: CountCreatedTemplatesByName :one
+SELECT COUNT(*) FROM price_import_matches m
+JOIN item_templates t ON t.id = m.matched_template_id
+WHERE m.import_id = ? AND m.status = 'created' AND t.name = ? COLLATE NOCASE;
+
+-- name: RematchPriceImportMatch :one
+UPDATE price_import_matches
+SET status = 'approved', matched_template_id = ?, confidence = 1.0,
+    match_reason = 'Matched by hand', new_name = NULL
+WHERE id = ?
+RETURNING *;
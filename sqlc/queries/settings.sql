@@ -5,6 +5,49 @@ WHERE id = 'default';
 -- name: UpdateSettings :one
 UPDATE settings SET
     default_surcharge_mode = ?,
-    default_surcharge_percent = ?
+    default_surcharge_percent = ?,
+    minimum_job_total = ?,
+    mobilization_fee = ?,
+    page_size = ?,
+    surcharge_credits = ?,
+    decline_warning = ?,
+    decline_streak = ?,
+    total_alert_percent = ?,
+    cost_margin_percent = ?,
+    ai_budget = ?,
+    ai_budget_unit = ?
 WHERE id = 'default'
 RETURNING *;
+
+-- name: UpdateCleanupSettings :one
+UPDATE settings SET
+    cleanup_empty_job_days = ?,
+    cleanup_import_days = ?,
+    cleanup_activity_days = ?,
+    cleanup_dry_run = ?,
+    import_reminder_days = ?,
+    import_stale_days = ?
+WHERE id = 'default'
+RETURNING *;
+
+-- name: UpdateTheme :one
+UPDATE settings SET theme = ?
+WHERE id = 'default'
+RETURNING *;
+
+-- name: UpdateCompanySettings :one
+UPDATE settings SET
+    company_name = ?,
+    company_address = ?,
+    company_phone = ?,
+    company_email = ?
+WHERE id = 'default'
+RETURNING *;
+
+-- name: AllowBudgetOverride :exec
+UPDATE settings SET ai_budget_override = 1
+WHERE id = 'default';
+
+-- name: UseBudgetOverride :execrows
+UPDATE settings SET ai_budget_override = 0
+WHERE id = 'default' AND ai_budget_override = 1;
@@ -0,0 +1,33 @@
+-- name: CreateSupplierExportMapping :one
+INSERT INTO supplier_export_mappings (name)
+VALUES (?)
+RETURNING *;
+
+-- name: GetSupplierExportMapping :one
+SELECT * FROM supplier_export_mappings
+WHERE id = ?;
+
+-- name: ListSupplierExportMappings :many
+SELECT * FROM supplier_export_mappings
+ORDER BY name, id;
+
+-- name: UpdateSupplierExportMapping :execrows
+UPDATE supplier_export_mappings SET name = ?
+WHERE id = ?;
+
+-- name: DeleteSupplierExportMapping :execrows
+DELETE FROM supplier_export_mappings
+WHERE id = ?;
+
+-- name: ListSupplierExportColumns :many
+SELECT * FROM supplier_export_columns
+WHERE mapping_id = ?
+ORDER BY position, field;
+
+-- name: CreateSupplierExportColumn :exec
+INSERT INTO supplier_export_columns (mapping_id, field, position, header)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteSupplierExportColumns :exec
+DELETE FROM supplier_export_columns
+WHERE mapping_id = ?;
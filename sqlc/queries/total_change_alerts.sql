@@ -0,0 +1,22 @@
+-- name: CreateTotalChangeAlert :one
+INSERT INTO total_change_alerts (job_id, line_item_id, action, previous, old_total, new_total, result)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetTotalChangeAlert :one
+SELECT * FROM total_change_alerts
+WHERE id = ?;
+
+-- name: GetOpenTotalChangeAlert :one
+SELECT * FROM total_change_alerts
+WHERE job_id = ? AND status = 'open'
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: UpdateTotalChangeAlertStatus :execrows
+UPDATE total_change_alerts SET status = ?
+WHERE id = ? AND status = 'open';
+
+-- name: DismissOpenTotalChangeAlerts :exec
+UPDATE total_change_alerts SET status = 'dismissed'
+WHERE job_id = ? AND status = 'open';